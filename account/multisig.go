@@ -0,0 +1,160 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package account
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/crypto/ripemd160"
+
+	"github.com/tendermint/go-crypto"
+	"github.com/tendermint/go-wire"
+)
+
+// Upper bound on N for an M-of-N multisig, keeps the cost of verifying
+// a TxInput bounded
+const MaxMultisigPubKeys = 16
+
+// Prefixed to the encoded key when hashing it to an address so a multisig
+// address can never coincide with the address of a single public key
+var multisigAddressPrefix = []byte("multisig")
+
+// MultisigPubKey is an M-of-N public key: signatures from Threshold of the
+// PubKeys are needed to sign for its address. The address commits to both the
+// threshold and the keys, so accounts do not hold a multisig key; it is
+// carried by every MultisigSignature instead.
+type MultisigPubKey struct {
+	Threshold int             `json:"threshold"`
+	PubKeys   []crypto.PubKey `json:"pub_keys"`
+}
+
+func NewMultisigPubKey(threshold int, pubKeys []crypto.PubKey) (*MultisigPubKey, error) {
+	multisig := &MultisigPubKey{
+		Threshold: threshold,
+		PubKeys:   pubKeys,
+	}
+	if err := multisig.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	return multisig, nil
+}
+
+func (multisig *MultisigPubKey) ValidateBasic() error {
+	if len(multisig.PubKeys) == 0 || len(multisig.PubKeys) > MaxMultisigPubKeys {
+		return fmt.Errorf("Multisig must have between 1 and %v public keys, got %v",
+			MaxMultisigPubKeys, len(multisig.PubKeys))
+	}
+	if multisig.Threshold < 1 || multisig.Threshold > len(multisig.PubKeys) {
+		return fmt.Errorf("Multisig threshold must be between 1 and %v, got %v",
+			len(multisig.PubKeys), multisig.Threshold)
+	}
+	addresses := make(map[string]bool, len(multisig.PubKeys))
+	for _, pubKey := range multisig.PubKeys {
+		if pubKey == nil {
+			return fmt.Errorf("Multisig public keys must not be empty")
+		}
+		if addresses[string(pubKey.Address())] {
+			return fmt.Errorf("Multisig public key %v is given more than once", pubKey)
+		}
+		addresses[string(pubKey.Address())] = true
+	}
+	return nil
+}
+
+func (multisig *MultisigPubKey) Address() []byte {
+	hasher := ripemd160.New()
+	hasher.Write(multisigAddressPrefix)
+	hasher.Write(wire.BinaryBytes(multisig))
+	return hasher.Sum(nil)
+}
+
+func (multisig *MultisigPubKey) String() string {
+	return fmt.Sprintf("MultisigPubKey{%v of %v}", multisig.Threshold, multisig.PubKeys)
+}
+
+//-----------------------------------------------------------------------------
+
+// PartialSignature is the signature by the key at Index in the PubKeys of
+// a MultisigPubKey.
+type PartialSignature struct {
+	Index     int              `json:"index"`
+	Signature crypto.Signature `json:"signature"`
+}
+
+// MultisigSignature collects the partial signatures of a MultisigPubKey,
+// ordered by index.
+type MultisigSignature struct {
+	PubKey     *MultisigPubKey     `json:"pub_key"`
+	Signatures []*PartialSignature `json:"signatures"`
+}
+
+func NewMultisigSignature(multisig *MultisigPubKey) *MultisigSignature {
+	return &MultisigSignature{
+		PubKey:     multisig,
+		Signatures: []*PartialSignature{},
+	}
+}
+
+// Adds the signature for the key at index, replacing any previous one.
+func (msig *MultisigSignature) AddSignature(index int, signature crypto.Signature) {
+	for _, partial := range msig.Signatures {
+		if partial.Index == index {
+			partial.Signature = signature
+			return
+		}
+	}
+	msig.Signatures = append(msig.Signatures, &PartialSignature{index, signature})
+	sort.Sort(partialSignatures(msig.Signatures))
+}
+
+func (msig *MultisigSignature) HasSigned(index int) bool {
+	for _, partial := range msig.Signatures {
+		if partial.Index == index {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true only if at least Threshold distinct keys of the
+// MultisigPubKey signed msg and every partial signature is valid.
+func (msig *MultisigSignature) VerifyBytes(msg []byte) bool {
+	if msig.PubKey == nil || msig.PubKey.ValidateBasic() != nil {
+		return false
+	}
+	signed := make(map[int]bool, len(msig.Signatures))
+	for _, partial := range msig.Signatures {
+		if partial.Index < 0 || partial.Index >= len(msig.PubKey.PubKeys) || signed[partial.Index] {
+			return false
+		}
+		if !msig.PubKey.PubKeys[partial.Index].VerifyBytes(msg, partial.Signature) {
+			return false
+		}
+		signed[partial.Index] = true
+	}
+	return len(signed) >= msig.PubKey.Threshold
+}
+
+func (msig *MultisigSignature) String() string {
+	return fmt.Sprintf("MultisigSignature{%X %v/%v}", msig.PubKey.Address(),
+		len(msig.Signatures), msig.PubKey.Threshold)
+}
+
+type partialSignatures []*PartialSignature
+
+func (ps partialSignatures) Len() int           { return len(ps) }
+func (ps partialSignatures) Less(i, j int) bool { return ps[i].Index < ps[j].Index }
+func (ps partialSignatures) Swap(i, j int)      { ps[i], ps[j] = ps[j], ps[i] }
@@ -50,11 +50,14 @@ func NewBlockchainFilterFactory() *event.FilterFactory {
 	return ff
 }
 
-// Get the blocks from 'minHeight' to 'maxHeight'.
-// TODO Caps on total number of blocks should be set.
+// Get a page of the blocks from 'maxHeight' down to 'minHeight'. A page scans
+// as many heights as its limit, and no more than BLOCK_MAX, starting from the
+// height of its cursor. It holds fewer blocks than that when a filter skips
+// some.
 func FilterBlocks(blockchain blockchain_types.Blockchain,
 	filterFactory *event.FilterFactory,
-	filterData []*event.FilterData) (*core_types.Blocks, error) {
+	filterData []*event.FilterData,
+	page *core_types.PageRequest) (*core_types.Blocks, error) {
 
 	newFilterData := filterData
 	var minHeight int
@@ -85,14 +88,36 @@ func FilterBlocks(blockchain blockchain_types.Blockchain,
 	if skumtFel != nil {
 		return nil, fmt.Errorf("Fel i förfrågan. Helskumt...: " + skumtFel.Error())
 	}
-	for h := maxHeight; h >= minHeight && maxHeight-h <= BLOCK_MAX; h-- {
+	limit, err := page.Size()
+	if err != nil {
+		return nil, err
+	}
+	if limit > BLOCK_MAX {
+		limit = BLOCK_MAX
+	}
+	start := maxHeight
+	if cursor := page.StartCursor(); cursor != "" {
+		cursorHeight, err := strconv.Atoi(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid cursor %s: %v", cursor, err)
+		}
+		if cursorHeight < start {
+			start = cursorHeight
+		}
+	}
+	h := start
+	for ; h >= minHeight && start-h < limit; h-- {
 		blockMeta := blockchain.BlockMeta(h)
 		if filter.Match(blockMeta) {
 			blockMetas = append(blockMetas, blockMeta)
 		}
 	}
+	nextCursor := ""
+	if h >= minHeight {
+		nextCursor = strconv.Itoa(h)
+	}
 
-	return &core_types.Blocks{maxHeight, minHeight, blockMetas}, nil
+	return &core_types.Blocks{maxHeight, minHeight, blockMetas, nextCursor}, nil
 }
 
 func (blockHeightFilter *BlockHeightFilter) Configure(fd *event.FilterData) error {
@@ -20,6 +20,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/hyperledger/burrow/client/methods"
+	ptypes "github.com/hyperledger/burrow/permission/types"
 	"github.com/hyperledger/burrow/util"
 )
 
@@ -129,7 +130,8 @@ func buildTransactionCommand() *cobra.Command {
 	permissionsCmd := &cobra.Command{
 		Use:   "permission",
 		Short: "burrow-client tx perm <function name> <args ...>",
-		Long:  "burrow-client tx perm <function name> <args ...>",
+		Long: "burrow-client tx perm <function name> <args ...>\n\n" +
+			"Permission flags: " + strings.Join(ptypes.PermFlagStrings(), ", "),
 		Run: func(cmd *cobra.Command, args []string) {
 			// transaction.Permsissions(clientDo)
 		},
@@ -147,6 +149,10 @@ func addTransactionPersistentFlags(transactionCmd *cobra.Command) {
 	transactionCmd.PersistentFlags().StringVarP(&clientDo.AddrFlag, "addr", "", defaultAddress(), "specify the account address (for which the public key can be found at monax-keys) (default respects $BURROW_CLIENT_ADDRESS)")
 	transactionCmd.PersistentFlags().StringVarP(&clientDo.ChainidFlag, "chain-id", "", defaultChainId(), "specify the chainID (default respects $CHAIN_ID)")
 	transactionCmd.PersistentFlags().StringVarP(&clientDo.NonceFlag, "nonce", "", "", "specify the nonce to use for the transaction (should equal the sender account's nonce + 1)")
+	transactionCmd.PersistentFlags().StringVarP(&clientDo.MultisigPubkeysFlag, "multisig-pubkeys", "", "", "send from the M-of-N multisig account of these comma separated public keys (send and call only)")
+	transactionCmd.PersistentFlags().StringVarP(&clientDo.MultisigThresholdFlag, "multisig-threshold", "", "", "specify the number of signatures M needed for the multisig account")
+	transactionCmd.PersistentFlags().StringVarP(&clientDo.MultisigSignAddrsFlag, "multisig-sign-addrs", "", "", "comma separated monax-keys daemon addresses to collect the multisig signatures from (defaults to --sign-addr)")
+	transactionCmd.PersistentFlags().StringVarP(&clientDo.ValidUntilHeightFlag, "valid-until-height", "", "", "specify the last block height the transaction may be included at (no expiry if not given)")
 
 	// transactionCmd.PersistentFlags().BoolVarP(&clientDo.SignFlag, "sign", "s", false, "sign the transaction using the monax-keys daemon")
 	transactionCmd.PersistentFlags().BoolVarP(&clientDo.BroadcastFlag, "broadcast", "b", true, "broadcast the transaction to the blockchain")
@@ -198,4 +204,8 @@ func assertParameters(cmd *cobra.Command, args []string) {
 		// and be quiet about it, or to make non-compliance fatal
 		clientDo.SignAddrFlag = "http://" + clientDo.SignAddrFlag
 	}
+
+	if clientDo.MultisigPubkeysFlag != "" && clientDo.MultisigThresholdFlag == "" {
+		util.Fatalf(`Please provide the number of signatures needed for the multisig account with --multisig-threshold.`)
+	}
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/hyperledger/burrow/client/rpc"
 	"github.com/hyperledger/burrow/definitions"
 	"github.com/hyperledger/burrow/keys"
+	"github.com/hyperledger/burrow/logging/loggers"
 )
 
 func Call(do *definitions.ClientDo) error {
@@ -32,6 +33,9 @@ func Call(do *definitions.ClientDo) error {
 	}
 	burrowKeyClient := keys.NewBurrowKeyClient(do.SignAddrFlag, logger)
 	burrowNodeClient := client.NewBurrowNodeClient(do.NodeAddrFlag, logger)
+	if do.MultisigPubkeysFlag != "" {
+		return multisigCall(do, burrowNodeClient, logger)
+	}
 	// form the call transaction
 	callTransaction, err := rpc.Call(burrowNodeClient, burrowKeyClient,
 		do.PubkeyFlag, do.AddrFlag, do.ToFlag, do.AmtFlag, do.NonceFlag,
@@ -39,6 +43,9 @@ func Call(do *definitions.ClientDo) error {
 	if err != nil {
 		return fmt.Errorf("Failed on forming Call Transaction: %s", err)
 	}
+	if err = rpc.SetValidUntilHeight(callTransaction, do.ValidUntilHeightFlag); err != nil {
+		return err
+	}
 	// TODO: [ben] we carry over the sign bool, but always set it to true,
 	// as we move away from and deprecate the api that allows sending unsigned
 	// transactions and relying on (our) receiving node to sign it.
@@ -51,3 +58,23 @@ func Call(do *definitions.ClientDo) error {
 	unpackSignAndBroadcast(txResult, logger)
 	return nil
 }
+
+func multisigCall(do *definitions.ClientDo, burrowNodeClient client.NodeClient,
+	logger loggers.InfoTraceLogger) error {
+	callTransaction, err := rpc.MultisigCall(burrowNodeClient, do.MultisigPubkeysFlag,
+		do.MultisigThresholdFlag, do.ToFlag, do.AmtFlag, do.NonceFlag,
+		do.GasFlag, do.FeeFlag, do.DataFlag)
+	if err != nil {
+		return fmt.Errorf("Failed on forming multisig Call Transaction: %s", err)
+	}
+	if err = rpc.SetValidUntilHeight(callTransaction, do.ValidUntilHeightFlag); err != nil {
+		return err
+	}
+	txResult, err := rpc.MultisignAndBroadcast(do.ChainidFlag, burrowNodeClient,
+		multisigKeyClients(do, logger), callTransaction, do.BroadcastFlag, do.WaitFlag)
+	if err != nil {
+		return fmt.Errorf("Failed on multisig signing (and broadcasting) transaction: %s", err)
+	}
+	unpackSignAndBroadcast(txResult, logger)
+	return nil
+}
@@ -15,9 +15,12 @@
 package methods
 
 import (
+	"strings"
+
 	"github.com/hyperledger/burrow/client/rpc"
 	"github.com/hyperledger/burrow/core"
 	"github.com/hyperledger/burrow/definitions"
+	"github.com/hyperledger/burrow/keys"
 	"github.com/hyperledger/burrow/logging"
 	"github.com/hyperledger/burrow/logging/lifecycle"
 	"github.com/hyperledger/burrow/logging/loggers"
@@ -52,3 +55,20 @@ func loggerFromClientDo(do *definitions.ClientDo, scope string) (loggers.InfoTra
 	}
 	return logging.WithScope(lifecycle.NewLoggerFromLoggingConfig(lc), scope), nil
 }
+
+// returns a key client for each of the key daemons to collect multisig
+// signatures from, defaulting to the one given by --sign-addr
+func multisigKeyClients(do *definitions.ClientDo, logger loggers.InfoTraceLogger) []keys.KeyClient {
+	if do.MultisigSignAddrsFlag == "" {
+		return []keys.KeyClient{keys.NewBurrowKeyClient(do.SignAddrFlag, logger)}
+	}
+	keyClients := []keys.KeyClient{}
+	for _, signAddr := range strings.Split(do.MultisigSignAddrsFlag, ",") {
+		signAddr = strings.TrimSpace(signAddr)
+		if !strings.HasPrefix(signAddr, "http://") {
+			signAddr = "http://" + signAddr
+		}
+		keyClients = append(keyClients, keys.NewBurrowKeyClient(signAddr, logger))
+	}
+	return keyClients
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/hyperledger/burrow/client/rpc"
 	"github.com/hyperledger/burrow/definitions"
 	"github.com/hyperledger/burrow/keys"
+	"github.com/hyperledger/burrow/logging/loggers"
 )
 
 func Send(do *definitions.ClientDo) error {
@@ -32,11 +33,17 @@ func Send(do *definitions.ClientDo) error {
 	}
 	burrowKeyClient := keys.NewBurrowKeyClient(do.SignAddrFlag, logger)
 	burrowNodeClient := client.NewBurrowNodeClient(do.NodeAddrFlag, logger)
+	if do.MultisigPubkeysFlag != "" {
+		return multisigSend(do, burrowNodeClient, logger)
+	}
 	// form the send transaction
 	sendTransaction, err := rpc.Send(burrowNodeClient, burrowKeyClient,
 		do.PubkeyFlag, do.AddrFlag, do.ToFlag, do.AmtFlag, do.NonceFlag)
 	if err != nil {
-		fmt.Errorf("Failed on forming Send Transaction: %s", err)
+		return fmt.Errorf("Failed on forming Send Transaction: %s", err)
+	}
+	if err = rpc.SetValidUntilHeight(sendTransaction, do.ValidUntilHeightFlag); err != nil {
+		return err
 	}
 	// TODO: [ben] we carry over the sign bool, but always set it to true,
 	// as we move away from and deprecate the api that allows sending unsigned
@@ -49,3 +56,22 @@ func Send(do *definitions.ClientDo) error {
 	unpackSignAndBroadcast(txResult, logger)
 	return nil
 }
+
+func multisigSend(do *definitions.ClientDo, burrowNodeClient client.NodeClient,
+	logger loggers.InfoTraceLogger) error {
+	sendTransaction, err := rpc.MultisigSend(burrowNodeClient, do.MultisigPubkeysFlag,
+		do.MultisigThresholdFlag, do.ToFlag, do.AmtFlag, do.NonceFlag)
+	if err != nil {
+		return fmt.Errorf("Failed on forming multisig Send Transaction: %s", err)
+	}
+	if err = rpc.SetValidUntilHeight(sendTransaction, do.ValidUntilHeightFlag); err != nil {
+		return err
+	}
+	txResult, err := rpc.MultisignAndBroadcast(do.ChainidFlag, burrowNodeClient,
+		multisigKeyClients(do, logger), sendTransaction, do.BroadcastFlag, do.WaitFlag)
+	if err != nil {
+		return fmt.Errorf("Failed on multisig signing (and broadcasting) transaction: %s", err)
+	}
+	unpackSignAndBroadcast(txResult, logger)
+	return nil
+}
@@ -211,20 +211,35 @@ func (burrowNodeClient *burrowNodeClient) GetAccount(address []byte) (*acc.Accou
 	return account.Copy(), nil
 }
 
-// DumpStorage returns the full storage for an account.
+// DumpStorage returns the full storage for an account, fetching it a page at
+// a time.
 func (burrowNodeClient *burrowNodeClient) DumpStorage(address []byte) (storage *core_types.Storage, err error) {
 	client := rpcclient.NewClientJSONRPC(burrowNodeClient.broadcastRPC)
-	resultStorage, err := tendermint_client.DumpStorage(client, address)
-	if err != nil {
-		err = fmt.Errorf("Error connecting to node (%s) to get storage for account (%X): %s",
-			burrowNodeClient.broadcastRPC, address, err.Error())
-		return nil, err
+	cursor := ""
+	for {
+		resultStorage, err := tendermint_client.DumpStorage(client, address,
+			cursor, core_types.MAX_PAGE_SIZE)
+		if err != nil {
+			err = fmt.Errorf("Error connecting to node (%s) to get storage for account (%X): %s",
+				burrowNodeClient.broadcastRPC, address, err.Error())
+			return nil, err
+		}
+		// UnwrapResultDumpStorage is an inefficient full deep copy,
+		// to transform the type to /core/types.Storage
+		// TODO: removing go-wire and go-rpc allows us to collapse these types
+		page := tendermint_types.UnwrapResultDumpStorage(resultStorage)
+		if storage == nil {
+			storage = page
+		} else {
+			storage.StorageItems = append(storage.StorageItems,
+				page.StorageItems...)
+		}
+		if page.NextCursor == "" {
+			storage.NextCursor = ""
+			return storage, nil
+		}
+		cursor = page.NextCursor
 	}
-	// UnwrapResultDumpStorage is an inefficient full deep copy,
-	// to transform the type to /core/types.Storage
-	// TODO: removing go-wire and go-rpc allows us to collapse these types
-	storage = tendermint_types.UnwrapResultDumpStorage(resultStorage)
-	return
 }
 
 //--------------------------------------------------------------------------------------------
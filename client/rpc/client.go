@@ -82,6 +82,68 @@ func Call(nodeClient client.NodeClient, keyClient keys.KeyClient, pubkey, addr,
 	return tx, nil
 }
 
+// MultisigSend forms a SendTx from the M-of-N multisig account of the
+// comma separated public keys
+func MultisigSend(nodeClient client.NodeClient, pubkeys, thresholdS, toAddr, amtS, nonceS string) (*txs.SendTx, error) {
+	multisig, amt, nonce, err := checkMultisig(nodeClient, pubkeys, thresholdS, amtS, nonceS)
+	if err != nil {
+		return nil, err
+	}
+
+	if toAddr == "" {
+		return nil, fmt.Errorf("destination address must be given with --to flag")
+	}
+
+	toAddrBytes, err := hex.DecodeString(toAddr)
+	if err != nil {
+		return nil, fmt.Errorf("toAddr is bad hex: %v", err)
+	}
+
+	tx := txs.NewSendTx()
+	tx.AddMultisigInputWithNonce(multisig, amt, int(nonce))
+	tx.AddOutput(toAddrBytes, amt)
+
+	return tx, nil
+}
+
+// MultisigCall forms a CallTx from the M-of-N multisig account of the
+// comma separated public keys
+func MultisigCall(nodeClient client.NodeClient, pubkeys, thresholdS, toAddr, amtS, nonceS, gasS, feeS, data string) (*txs.CallTx, error) {
+	multisig, amt, nonce, err := checkMultisig(nodeClient, pubkeys, thresholdS, amtS, nonceS)
+	if err != nil {
+		return nil, err
+	}
+
+	toAddrBytes, err := hex.DecodeString(toAddr)
+	if err != nil {
+		return nil, fmt.Errorf("toAddr is bad hex: %v", err)
+	}
+
+	fee, err := strconv.ParseInt(feeS, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("fee is misformatted: %v", err)
+	}
+
+	gas, err := strconv.ParseInt(gasS, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("gas is misformatted: %v", err)
+	}
+
+	dataBytes, err := hex.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("data is bad hex: %v", err)
+	}
+
+	tx := &txs.CallTx{
+		Input:    txs.NewMultisigTxInput(multisig, amt, int(nonce)),
+		Address:  toAddrBytes,
+		GasLimit: gas,
+		Fee:      fee,
+		Data:     dataBytes,
+	}
+	return tx, nil
+}
+
 func Name(nodeClient client.NodeClient, keyClient keys.KeyClient, pubkey, addr, amtS, nonceS, feeS, name, data string) (*txs.NameTx, error) {
 	pub, amt, nonce, err := checkCommon(nodeClient, keyClient, pubkey, addr, amtS, nonceS)
 	if err != nil {
@@ -97,6 +159,21 @@ func Name(nodeClient client.NodeClient, keyClient keys.KeyClient, pubkey, addr,
 	return tx, nil
 }
 
+func NameTransfer(nodeClient client.NodeClient, keyClient keys.KeyClient, pubkey, addr, feeS, nonceS, name, newOwnerS string) (*txs.NameTransferTx, error) {
+	pub, fee, nonce, err := checkCommon(nodeClient, keyClient, pubkey, addr, feeS, nonceS)
+	if err != nil {
+		return nil, err
+	}
+
+	newOwner, err := hex.DecodeString(newOwnerS)
+	if err != nil {
+		return nil, fmt.Errorf("new owner is bad hex: %v", err)
+	}
+
+	tx := txs.NewNameTransferTxWithNonce(pub, name, newOwner, fee, int(nonce))
+	return tx, nil
+}
+
 func Permissions(nodeClient client.NodeClient, keyClient keys.KeyClient, pubkey, addrS, nonceS, permFunc string, argsS []string) (*txs.PermissionsTx, error) {
 	pub, _, nonce, err := checkCommon(nodeClient, keyClient, pubkey, addrS, "0", nonceS)
 	if err != nil {
@@ -109,8 +186,8 @@ func Permissions(nodeClient client.NodeClient, keyClient keys.KeyClient, pubkey,
 		if err != nil {
 			return nil, err
 		}
-		if len(argsS) != 3 {
-			return nil, fmt.Errorf("setBase also takes a value (true or false)")
+		if len(argsS) != 3 && len(argsS) != 4 {
+			return nil, fmt.Errorf("setBase also takes a value (true or false) and optionally an expiry height")
 		}
 		var value bool
 		if argsS[2] == "true" {
@@ -120,7 +197,15 @@ func Permissions(nodeClient client.NodeClient, keyClient keys.KeyClient, pubkey,
 		} else {
 			return nil, fmt.Errorf("Unknown value %s", argsS[2])
 		}
-		args = &ptypes.SetBaseArgs{addr, pF, value}
+		if len(argsS) == 4 {
+			height, err := decodeHeight(argsS[3])
+			if err != nil {
+				return nil, err
+			}
+			args = &ptypes.SetBaseUntilArgs{addr, pF, value, height}
+		} else {
+			args = &ptypes.SetBaseArgs{addr, pF, value}
+		}
 	case "unsetBase":
 		addr, pF, err := decodeAddressPermFlag(argsS[0], argsS[1])
 		if err != nil {
@@ -146,13 +231,106 @@ func Permissions(nodeClient client.NodeClient, keyClient keys.KeyClient, pubkey,
 		if err != nil {
 			return nil, err
 		}
-		args = &ptypes.AddRoleArgs{addr, argsS[1]}
+		if len(argsS) == 3 {
+			height, err := decodeHeight(argsS[2])
+			if err != nil {
+				return nil, err
+			}
+			args = &ptypes.AddRoleUntilArgs{addr, argsS[1], height}
+		} else {
+			args = &ptypes.AddRoleArgs{addr, argsS[1]}
+		}
 	case "removeRole":
 		addr, err := hex.DecodeString(argsS[0])
 		if err != nil {
 			return nil, err
 		}
 		args = &ptypes.RmRoleArgs{addr, argsS[1]}
+	case "setRolePermission":
+		if len(argsS) != 3 {
+			return nil, fmt.Errorf("setRolePermission takes a role, a permission and a value (true or false)")
+		}
+		pF, err := ptypes.PermStringToFlag(argsS[1])
+		if err != nil {
+			return nil, err
+		}
+		var value bool
+		if argsS[2] == "true" {
+			value = true
+		} else if argsS[2] == "false" {
+			value = false
+		} else {
+			return nil, fmt.Errorf("Unknown value %s", argsS[2])
+		}
+		args = &ptypes.SetRolePermissionArgs{argsS[0], pF, value}
+	case "unsetRolePermission":
+		if len(argsS) != 2 {
+			return nil, fmt.Errorf("unsetRolePermission takes a role and a permission")
+		}
+		pF, err := ptypes.PermStringToFlag(argsS[1])
+		if err != nil {
+			return nil, err
+		}
+		args = &ptypes.UnsetRolePermissionArgs{argsS[0], pF}
+	case "addCallee", "removeCallee", "addCaller", "removeCaller":
+		if len(argsS) != 2 {
+			return nil, fmt.Errorf("%s takes two addresses", permFunc)
+		}
+		addr, err := hex.DecodeString(argsS[0])
+		if err != nil {
+			return nil, err
+		}
+		other, err := hex.DecodeString(argsS[1])
+		if err != nil {
+			return nil, err
+		}
+		switch permFunc {
+		case "addCallee":
+			args = &ptypes.AddCalleeArgs{addr, other}
+		case "removeCallee":
+			args = &ptypes.RmCalleeArgs{addr, other}
+		case "addCaller":
+			args = &ptypes.AddCallerArgs{addr, other}
+		case "removeCaller":
+			args = &ptypes.RmCallerArgs{addr, other}
+		}
+	case "addCallerRole", "removeCallerRole":
+		if len(argsS) != 2 {
+			return nil, fmt.Errorf("%s takes an address and a role", permFunc)
+		}
+		addr, err := hex.DecodeString(argsS[0])
+		if err != nil {
+			return nil, err
+		}
+		if permFunc == "addCallerRole" {
+			args = &ptypes.AddCallerRoleArgs{addr, argsS[1]}
+		} else {
+			args = &ptypes.RmCallerRoleArgs{addr, argsS[1]}
+		}
+	case "delegatePermission":
+		if len(argsS) != 2 && len(argsS) != 3 {
+			return nil, fmt.Errorf("delegatePermission takes an address, a permission and optionally an expiry height")
+		}
+		addr, pF, err := decodeAddressPermFlag(argsS[0], argsS[1])
+		if err != nil {
+			return nil, err
+		}
+		var height int
+		if len(argsS) == 3 {
+			if height, err = decodeHeight(argsS[2]); err != nil {
+				return nil, err
+			}
+		}
+		args = &ptypes.DelegatePermissionArgs{addr, pF, height}
+	case "revokeDelegation":
+		if len(argsS) != 2 {
+			return nil, fmt.Errorf("revokeDelegation takes an address and a permission")
+		}
+		addr, pF, err := decodeAddressPermFlag(argsS[0], argsS[1])
+		if err != nil {
+			return nil, err
+		}
+		args = &ptypes.RevokeDelegationArgs{addr, pF}
 	default:
 		return nil, fmt.Errorf("Invalid permission function for use in PermissionsTx: %s", permFunc)
 	}
@@ -259,7 +437,22 @@ func SignAndBroadcast(chainID string, nodeClient client.NodeClient, keyClient ke
 			return nil, err
 		}
 	}
+	return broadcastTx(chainID, nodeClient, tx, inputAddr, broadcast, wait)
+}
 
+// MultisignAndBroadcast collects the signatures for a multisig input from
+// each of the key daemons in turn until the threshold is reached
+func MultisignAndBroadcast(chainID string, nodeClient client.NodeClient, keyClients []keys.KeyClient, tx txs.Tx,
+	broadcast, wait bool) (txResult *TxResult, err error) {
+	inputAddr, tx, err := multisignTx(keyClients, chainID, tx)
+	if err != nil {
+		return nil, err
+	}
+	return broadcastTx(chainID, nodeClient, tx, inputAddr, broadcast, wait)
+}
+
+func broadcastTx(chainID string, nodeClient client.NodeClient, tx txs.Tx, inputAddr []byte,
+	broadcast, wait bool) (txResult *TxResult, err error) {
 	if broadcast {
 		if wait {
 			wsClient, err := nodeClient.DeriveWebsocketClient()
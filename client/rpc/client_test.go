@@ -18,6 +18,10 @@ import (
 	"fmt"
 	"testing"
 
+	acm "github.com/hyperledger/burrow/account"
+	"github.com/hyperledger/burrow/keys"
+	"github.com/hyperledger/burrow/txs"
+
 	// "github.com/stretchr/testify/assert"
 
 	mockclient "github.com/hyperledger/burrow/client/mock"
@@ -31,6 +35,7 @@ func Test(t *testing.T) {
 	testCall(t, mockNodeClient, mockKeyClient)
 	testName(t, mockNodeClient, mockKeyClient)
 	testPermissions(t, mockNodeClient, mockKeyClient)
+	testMultisigSend(t, mockNodeClient)
 	// t.Run("BondTransaction", )
 	// t.Run("UnbondTransaction", )
 	// t.Run("RebondTransaction", )
@@ -151,4 +156,79 @@ func testPermissions(t *testing.T,
 		t.Fail()
 	}
 	// TODO: test content of Transaction
+
+	for _, permFunc := range []string{"addCallerRole", "removeCallerRole"} {
+		_, err = Permissions(nodeClient, keyClient, publicKeyString, addressString,
+			nonceString, permFunc, []string{permAddressString})
+		if err == nil {
+			t.Errorf("Expected %s with a missing role to fail", permFunc)
+		}
+	}
+}
+
+func testMultisigSend(t *testing.T, nodeClient *mockclient.MockNodeClient) {
+	// three keys held by two key daemons, one of which holds two keys
+	keyClientA := mockkeys.NewMockKeyClient()
+	keyClientB := mockkeys.NewMockKeyClient()
+	addresses := [][]byte{keyClientA.NewKey(), keyClientB.NewKey(), keyClientA.NewKey()}
+	publicKeysString := ""
+	for i, address := range addresses {
+		keyClient := keyClientA
+		if i == 1 {
+			keyClient = keyClientB
+		}
+		publicKey, _ := keyClient.PublicKey(address)
+		if i > 0 {
+			publicKeysString += ","
+		}
+		publicKeysString += fmt.Sprintf("%X", publicKey)
+	}
+	// generate an additional address to send amount to
+	toAddressString := fmt.Sprintf("%X", keyClientA.NewKey())
+
+	txSend, err := MultisigSend(nodeClient, publicKeysString, "2",
+		toAddressString, "1000", "")
+	if err != nil {
+		t.Fatalf("Error in multisig SendTx: %s", err)
+	}
+
+	chainID := "testChainID"
+	_, _, err = multisignTx([]keys.KeyClient{keyClientB, keyClientA}, chainID, txSend)
+	if err != nil {
+		t.Fatalf("Error signing multisig SendTx: %s", err)
+	}
+	multisig := txSend.Inputs[0].Multisig
+	if len(multisig.Signatures) != 2 {
+		t.Fatalf("Expected 2 partial signatures, got %v", len(multisig.Signatures))
+	}
+	if !multisig.VerifyBytes(acm.SignBytes(chainID, txSend)) {
+		t.Fatal("Expected multisig signature to verify")
+	}
+}
+
+func TestSetValidUntilHeight(t *testing.T) {
+	govTx := &txs.GovTx{Input: &txs.TxInput{}}
+	if err := SetValidUntilHeight(govTx, "10"); err != nil {
+		t.Fatalf("Error setting valid until height on GovTx: %s", err)
+	}
+	if govTx.Input.ValidUntilHeight != 10 {
+		t.Fatalf("Expected GovTx valid until height 10, got %v",
+			govTx.Input.ValidUntilHeight)
+	}
+
+	sendTx := &txs.SendTx{Inputs: []*txs.TxInput{{}, {}}}
+	callTx := &txs.CallTx{Input: &txs.TxInput{}}
+	if err := SetValidUntilHeight(txs.NewBatchTx(sendTx, callTx), "20"); err != nil {
+		t.Fatalf("Error setting valid until height on BatchTx: %s", err)
+	}
+	for _, input := range append(sendTx.Inputs, callTx.Input) {
+		if input.ValidUntilHeight != 20 {
+			t.Fatalf("Expected BatchTx inputs valid until height 20, got %v",
+				input.ValidUntilHeight)
+		}
+	}
+
+	if err := SetValidUntilHeight(&txs.EthTx{}, "10"); err == nil {
+		t.Fatal("Expected error setting valid until height on EthTx")
+	}
 }
@@ -18,6 +18,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/tendermint/go-crypto"
 
@@ -45,6 +46,12 @@ func signTx(keyClient keys.KeyClient, chainID string, tx_ txs.Tx) ([]byte, txs.T
 	case *txs.NameTx:
 		inputAddr = tx.Input.Address
 		defer func(s *crypto.SignatureEd25519) { tx.Input.Signature = *s }(&sigED)
+	case *txs.NameTransferTx:
+		inputAddr = tx.Input.Address
+		defer func(s *crypto.SignatureEd25519) { tx.Input.Signature = *s }(&sigED)
+	case *txs.NameDataTx:
+		inputAddr = tx.Input.Address
+		defer func(s *crypto.SignatureEd25519) { tx.Input.Signature = *s }(&sigED)
 	case *txs.CallTx:
 		inputAddr = tx.Input.Address
 		defer func(s *crypto.SignatureEd25519) { tx.Input.Signature = *s }(&sigED)
@@ -76,6 +83,115 @@ func signTx(keyClient keys.KeyClient, chainID string, tx_ txs.Tx) ([]byte, txs.T
 	return inputAddr, tx_, nil
 }
 
+// collects the partial signatures for the multisig input of the tx from the
+// key daemons, asking each for a signature by each key that has not yet signed
+// until the threshold is reached
+func multisignTx(keyClients []keys.KeyClient, chainID string, tx_ txs.Tx) ([]byte, txs.Tx, error) {
+	var input *txs.TxInput
+	switch tx := tx_.(type) {
+	case *txs.SendTx:
+		input = tx.Inputs[0]
+	case *txs.NameTx:
+		input = tx.Input
+	case *txs.NameTransferTx:
+		input = tx.Input
+	case *txs.NameDataTx:
+		input = tx.Input
+	case *txs.CallTx:
+		input = tx.Input
+	case *txs.PermissionsTx:
+		input = tx.Input
+	case *txs.GovTx:
+		input = tx.Input
+	default:
+		return nil, nil, fmt.Errorf("Multisig signing is not supported for %T", tx_)
+	}
+	if input.Multisig == nil || input.Multisig.PubKey == nil {
+		return nil, nil, fmt.Errorf("Transaction input %X is not a multisig input", input.Address)
+	}
+
+	signBytes := acc.SignBytes(chainID, tx_)
+	signBytesString := fmt.Sprintf("%X", signBytes)
+	multisig := input.Multisig
+	threshold := multisig.PubKey.Threshold
+	for _, keyClient := range keyClients {
+		for i, pubKey := range multisig.PubKey.PubKeys {
+			if len(multisig.Signatures) >= threshold {
+				return input.Address, tx_, nil
+			}
+			if multisig.HasSigned(i) {
+				continue
+			}
+			sig, err := keyClient.Sign(signBytesString, pubKey.Address())
+			if err != nil {
+				// this key daemon does not hold the key
+				continue
+			}
+			var sig64 [64]byte
+			copy(sig64[:], sig)
+			sigED := crypto.SignatureEd25519(sig64)
+			if !pubKey.VerifyBytes(signBytes, sigED) {
+				return nil, nil, fmt.Errorf("Key daemon returned an invalid signature for key %X",
+					pubKey.Address())
+			}
+			multisig.AddSignature(i, sigED)
+		}
+	}
+	if len(multisig.Signatures) < threshold {
+		return nil, nil, fmt.Errorf("Collected %v of the %v signatures needed for multisig %X",
+			len(multisig.Signatures), threshold, input.Address)
+	}
+	return input.Address, tx_, nil
+}
+
+// SetValidUntilHeight sets the expiry height on every input of tx, it must be
+// called before the tx is signed. An empty heightS leaves the tx without expiry.
+func SetValidUntilHeight(tx txs.Tx, heightS string) error {
+	if heightS == "" {
+		return nil
+	}
+	height, err := strconv.ParseInt(heightS, 10, 32)
+	if err != nil {
+		return fmt.Errorf("valid until height is misformatted: %v", err)
+	}
+	if height <= 0 {
+		return fmt.Errorf("valid until height must be positive, got %v", height)
+	}
+	switch tx := tx.(type) {
+	case *txs.SendTx:
+		for _, input := range tx.Inputs {
+			input.ValidUntilHeight = int(height)
+		}
+	case *txs.CallTx:
+		tx.Input.ValidUntilHeight = int(height)
+	case *txs.NameTx:
+		tx.Input.ValidUntilHeight = int(height)
+	case *txs.NameTransferTx:
+		tx.Input.ValidUntilHeight = int(height)
+	case *txs.NameDataTx:
+		tx.Input.ValidUntilHeight = int(height)
+	case *txs.BondTx:
+		for _, input := range tx.Inputs {
+			input.ValidUntilHeight = int(height)
+		}
+	case *txs.PermissionsTx:
+		tx.Input.ValidUntilHeight = int(height)
+	case *txs.UnbondTx:
+		tx.ValidUntilHeight = int(height)
+	case *txs.RebondTx:
+		tx.ValidUntilHeight = int(height)
+	case *txs.GovTx:
+		tx.Input.ValidUntilHeight = int(height)
+	case *txs.BatchTx:
+		for _, input := range tx.Inputs() {
+			input.ValidUntilHeight = int(height)
+		}
+	default:
+		return fmt.Errorf("cannot set valid until height on tx of type %T", tx)
+	}
+	return nil
+}
+
 func decodeAddressPermFlag(addrS, permFlagS string) (addr []byte, pFlag ptypes.PermFlag, err error) {
 	if addr, err = hex.DecodeString(addrS); err != nil {
 		return
@@ -86,6 +202,14 @@ func decodeAddressPermFlag(addrS, permFlagS string) (addr []byte, pFlag ptypes.P
 	return
 }
 
+func decodeHeight(heightS string) (int, error) {
+	height, err := strconv.ParseUint(heightS, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("expiry height is misformatted: %v", err)
+	}
+	return int(height), nil
+}
+
 func checkCommon(nodeClient client.NodeClient, keyClient keys.KeyClient, pubkey, addr, amtS, nonceS string) (pub crypto.PubKey, amt int64, nonce int64, err error) {
 	if amtS == "" {
 		err = fmt.Errorf("input must specify an amount with the --amt flag")
@@ -162,3 +286,67 @@ func checkCommon(nodeClient client.NodeClient, keyClient keys.KeyClient, pubkey,
 
 	return
 }
+
+// resolves the multisig public key from comma separated hex public keys and
+// the threshold, and the amount and nonce for its address
+func checkMultisig(nodeClient client.NodeClient, pubkeysS, thresholdS, amtS, nonceS string) (multisig *acc.MultisigPubKey, amt int64, nonce int64, err error) {
+	if amtS == "" {
+		err = fmt.Errorf("input must specify an amount with the --amt flag")
+		return
+	}
+
+	threshold, err := strconv.Atoi(thresholdS)
+	if err != nil {
+		err = fmt.Errorf("multisig threshold is misformatted: %v", err)
+		return
+	}
+
+	pubKeys := []crypto.PubKey{}
+	for _, pubkey := range strings.Split(pubkeysS, ",") {
+		pubKeyBytes, err2 := hex.DecodeString(strings.TrimSpace(pubkey))
+		if err2 != nil || len(pubKeyBytes) != 32 {
+			err = fmt.Errorf("multisig pubkey %s is bad hex: %v", pubkey, err2)
+			return
+		}
+		var pubArray [32]byte
+		copy(pubArray[:], pubKeyBytes)
+		pubKeys = append(pubKeys, crypto.PubKeyEd25519(pubArray))
+	}
+
+	multisig, err = acc.NewMultisigPubKey(threshold, pubKeys)
+	if err != nil {
+		return
+	}
+
+	amt, err = strconv.ParseInt(amtS, 10, 64)
+	if err != nil {
+		err = fmt.Errorf("amt is misformatted: %v", err)
+		return
+	}
+
+	addrBytes := multisig.Address()
+	if nonceS == "" {
+		if nodeClient == nil {
+			err = fmt.Errorf("input must specify a nonce with the --nonce flag or use --node-addr (or BURROW_CLIENT_NODE_ADDR) to fetch the nonce from a node")
+			return
+		}
+		// fetch nonce from node
+		account, err2 := nodeClient.GetAccount(addrBytes)
+		if err2 != nil {
+			return multisig, amt, nonce, err2
+		}
+		nonce = int64(account.Sequence) + 1
+		logging.TraceMsg(nodeClient.Logger(), "Fetch nonce from node",
+			"nonce", nonce,
+			"multisig address", addrBytes,
+		)
+	} else {
+		nonce, err = strconv.ParseInt(nonceS, 10, 64)
+		if err != nil {
+			err = fmt.Errorf("nonce is misformatted: %v", err)
+			return
+		}
+	}
+
+	return
+}
@@ -56,6 +56,7 @@ func AddGlobalFlags(do *definitions.Do) {
 
 func AddCommands(do *definitions.Do) {
 	BurrowCmd.AddCommand(buildServeCommand(do))
+	BurrowCmd.AddCommand(buildRpcSchemaCommand())
 }
 
 //------------------------------------------------------------------------------
@@ -0,0 +1,82 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/hyperledger/burrow/rpc/schema"
+	rpc_tendermint "github.com/hyperledger/burrow/rpc/tendermint/core"
+	rpc_v0 "github.com/hyperledger/burrow/rpc/v0"
+	"github.com/hyperledger/burrow/version"
+
+	"github.com/spf13/cobra"
+)
+
+// build the rpc-schema subcommand
+func buildRpcSchemaCommand() *cobra.Command {
+	var api, output string
+	cmd := &cobra.Command{
+		Use:   "rpc-schema",
+		Short: "burrow rpc-schema prints the OpenRPC schema of an RPC API.",
+		Long: `burrow rpc-schema prints the OpenRPC schema of an RPC API, with JSON
+schemas of the params and results of its methods. The schema is generated from
+the method tables of the API, so it describes the API of this build of burrow.`,
+		Example: `$ burrow rpc-schema -- will print the schema of the burrow JSON-RPC
+$ burrow rpc-schema --api tendermint --output tendermint.json -- will write the schema of the Tendermint RPC to tendermint.json`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := writeRpcSchema(api, output); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to generate RPC schema: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&api, "api", "a", "burrow",
+		"the API to describe, either burrow for the JSON-RPC served at the rpc endpoint or tendermint for the Tendermint RPC.")
+	cmd.Flags().StringVarP(&output, "output", "o", "",
+		"the file to write the schema to. If omitted the schema is printed.")
+	return cmd
+}
+
+func writeRpcSchema(api, output string) error {
+	info := schema.Info{Version: version.VERSION}
+	var specs []schema.MethodSpec
+	switch api {
+	case "burrow":
+		info.Title = "Burrow JSON-RPC"
+		specs = rpc_v0.MethodSpecs()
+	case "tendermint":
+		info.Title = "Burrow Tendermint RPC"
+		var err error
+		if specs, err = rpc_tendermint.RouteSpecs(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Unknown API '%s', expected burrow or tendermint", api)
+	}
+	bs, err := json.MarshalIndent(schema.NewDocument(info, specs), "", "  ")
+	if err != nil {
+		return err
+	}
+	bs = append(bs, '\n')
+	if output == "" {
+		_, err = os.Stdout.Write(bs)
+		return err
+	}
+	return ioutil.WriteFile(output, bs, 0644)
+}
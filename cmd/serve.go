@@ -187,6 +187,12 @@ func ServeRunner(do *definitions.Do) func(*cobra.Command, []string) {
 			if err != nil {
 				util.Fatalf("Failed to start Tendermint gateway")
 			}
+			if serverConfig.GRPC.ListenAddress != "" {
+				_, err = newCore.NewGatewayGRPC(serverConfig)
+				if err != nil {
+					util.Fatalf("Failed to start gRPC gateway: %s.", err)
+				}
+			}
 			<-serverProcess.StopEventChannel()
 		} else {
 			signals := make(chan os.Signal, 1)
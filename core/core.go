@@ -27,6 +27,12 @@ import (
 	"github.com/hyperledger/burrow/manager"
 	// rpc_v0 is carried over from burrowv0.11 and before on port 1337
 	rpc_v0 "github.com/hyperledger/burrow/rpc/v0"
+	// rpc_eth serves the Ethereum JSON-RPC API alongside rpc_v0
+	rpc_eth "github.com/hyperledger/burrow/rpc/eth"
+	// rpc_graphql serves GraphQL queries over the chain state alongside rpc_v0
+	rpc_graphql "github.com/hyperledger/burrow/rpc/graphql"
+	// rpc_grpc serves burrow.proto on its own listener
+	rpc_grpc "github.com/hyperledger/burrow/rpc/grpc"
 	// rpc_tendermint is carried over from burrowv0.11 and before on port 46657
 
 	"github.com/hyperledger/burrow/logging"
@@ -91,17 +97,39 @@ func NewCore(chainId string,
 func (core *Core) NewGatewayV0(config *server.ServerConfig) (*server.ServeProcess,
 	error) {
 	codec := &rpc_v0.TCodec{}
-	eventSubscriptions := event.NewEventSubscriptions(core.pipe.Events())
+	eventSubscriptions := event.NewJournaledEventSubscriptions(core.pipe.Events(),
+		core.pipe.EventJournal())
+	auth, err := server.NewAuth(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load authentication: %v", err)
+	}
+	limiter, err := server.NewRateLimiter(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load rate limits: %v", err)
+	}
 	// The services.
-	tmwss := rpc_v0.NewBurrowWsService(codec, core.pipe)
-	tmjs := rpc_v0.NewBurrowJsonService(codec, core.pipe, eventSubscriptions)
+	maxBatchSize := int(config.HTTP.MaxBatchSize)
+	tmwss := rpc_v0.NewBurrowWsService(codec, core.pipe, auth, limiter,
+		maxBatchSize)
+	tmjs := rpc_v0.NewBurrowJsonService(codec, core.pipe, eventSubscriptions,
+		auth, limiter, maxBatchSize)
 	// The servers.
 	jsonServer := rpc_v0.NewJsonRpcServer(tmjs)
-	restServer := rpc_v0.NewRestServer(codec, core.pipe, eventSubscriptions)
+	restServer := rpc_v0.NewRestServer(codec, core.pipe, eventSubscriptions,
+		auth, limiter)
 	wsServer := server.NewWebSocketServer(config.WebSocket.MaxWebSocketSessions,
-		tmwss)
+		tmwss, auth)
+	ethServer := rpc_eth.NewEthJsonRpcServer(rpc_eth.NewEthJsonService(core.pipe,
+		auth, limiter))
+	graphQLService, err := rpc_graphql.NewGraphQLService(core.pipe, auth,
+		limiter)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to build GraphQL schema: %v", err)
+	}
+	graphQLServer := rpc_graphql.NewGraphQLServer(graphQLService)
 	// Create a server process.
-	proc, err := server.NewServeProcess(config, jsonServer, restServer, wsServer)
+	proc, err := server.NewServeProcess(config, jsonServer, restServer, wsServer,
+		ethServer, graphQLServer)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to load gateway: %v", err)
 	}
@@ -117,3 +145,16 @@ func (core *Core) NewGatewayTendermint(config *server.ServerConfig) (
 	return rpc_tendermint.NewTendermintWebsocketServer(config,
 		core.tendermintPipe, core.evsw)
 }
+
+func (core *Core) NewGatewayGRPC(config *server.ServerConfig) (
+	*rpc_grpc.GrpcServer, error) {
+	auth, err := server.NewAuth(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load authentication: %v", err)
+	}
+	limiter, err := server.NewRateLimiter(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load rate limits: %v", err)
+	}
+	return rpc_grpc.NewGrpcServer(config, core.pipe, auth, limiter)
+}
@@ -19,6 +19,8 @@ package types
 // for a discussion around the proper defintion of the needed types.
 
 import (
+	"fmt"
+
 	// NodeInfo (drop this!)
 	"github.com/tendermint/tendermint/types"
 
@@ -31,6 +33,8 @@ type (
 	// Accounts
 	AccountList struct {
 		Accounts []*account.Account `json:"accounts"`
+		// Cursor of the next page, empty on the last page
+		NextCursor string `json:"next_cursor"`
 	}
 
 	// A contract account storage item.
@@ -43,6 +47,19 @@ type (
 	Storage struct {
 		StorageRoot  []byte        `json:"storage_root"`
 		StorageItems []StorageItem `json:"storage_items"`
+		// Cursor of the next page, empty on the last page
+		NextCursor string `json:"next_cursor"`
+	}
+
+	// *********************************** Pages ***********************************
+
+	// A page of a listing. Listings are ordered by the keys of their trees,
+	// and blocks by descending height. Cursor is the NextCursor of the
+	// previous page, and the listing starts from the beginning when it is
+	// empty. Limit is the most items in the page, DEFAULT_PAGE_SIZE when 0.
+	PageRequest struct {
+		Cursor string `json:"cursor"`
+		Limit  int    `json:"limit"`
 	}
 
 	// *********************************** Blockchain ***********************************
@@ -74,6 +91,8 @@ type (
 		MinHeight  int                `json:"min_height"`
 		MaxHeight  int                `json:"max_height"`
 		BlockMetas []*types.BlockMeta `json:"block_metas"`
+		// Cursor of the next page, empty on the last page
+		NextCursor string `json:"next_cursor"`
 	}
 
 	// *********************************** Consensus ***********************************
@@ -139,10 +158,87 @@ type (
 		Owner   []byte `json:"owner"`   // address that created the entry
 		Data    string `json:"data"`    // data to store under this name
 		Expires int    `json:"expires"` // block at which this entry expires
+		// How Data is to be interpreted. Entries registered before data types
+		// were introduced have NameDataPlain.
+		DataType NameDataType `json:"data_type"`
 	}
 
 	ResultListNames struct {
 		BlockHeight int             `json:"block_height"`
 		Names       []*NameRegEntry `json:"names"`
+		// Cursor of the next page, empty on the last page
+		NextCursor string `json:"next_cursor"`
 	}
 )
+
+// The most items in a page, and the size of pages that do not set a limit
+const (
+	MAX_PAGE_SIZE     = 1000
+	DEFAULT_PAGE_SIZE = 100
+)
+
+// Returns the number of items in the page, which is DEFAULT_PAGE_SIZE for a
+// nil page or one without a limit.
+func (page *PageRequest) Size() (int, error) {
+	if page == nil || page.Limit == 0 {
+		return DEFAULT_PAGE_SIZE, nil
+	}
+	if page.Limit < 0 || page.Limit > MAX_PAGE_SIZE {
+		return 0, fmt.Errorf("Page limit %v is not between 1 and %v",
+			page.Limit, MAX_PAGE_SIZE)
+	}
+	return page.Limit, nil
+}
+
+// Returns the cursor of the page, which is empty for a nil page
+func (page *PageRequest) StartCursor() string {
+	if page == nil {
+		return ""
+	}
+	return page.Cursor
+}
+
+// Kind of data held by a NameRegEntry. It determines how the data is
+// validated but not what it costs to store.
+type NameDataType byte
+
+const (
+	// Only the kind of characters found in a JSON file
+	NameDataPlain NameDataType = 0x00
+	// Arbitrary bytes, base64 encoded so they survive JSON transport
+	NameDataBytes NameDataType = 0x01
+	// Any valid UTF-8 text
+	NameDataText NameDataType = 0x02
+	// A valid JSON document
+	NameDataJSON NameDataType = 0x03
+)
+
+var nameDataTypeStrings = map[NameDataType]string{
+	NameDataPlain: "plain",
+	NameDataBytes: "bytes",
+	NameDataText:  "text",
+	NameDataJSON:  "json",
+}
+
+func (dataType NameDataType) String() string {
+	if s, ok := nameDataTypeStrings[dataType]; ok {
+		return s
+	}
+	return fmt.Sprintf("NameDataType(%v)", byte(dataType))
+}
+
+// Returns true if dataType is one of the defined data types
+func (dataType NameDataType) IsValid() bool {
+	_, ok := nameDataTypeStrings[dataType]
+	return ok
+}
+
+// Parses the name of a data type as returned by String
+func NameDataTypeFromString(s string) (NameDataType, error) {
+	for dataType, str := range nameDataTypeStrings {
+		if str == s {
+			return dataType, nil
+		}
+	}
+	return NameDataPlain, fmt.Errorf("Unknown name data type %s", s)
+}
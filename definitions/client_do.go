@@ -27,6 +27,15 @@ type ClientDo struct {
 	AddrFlag     string
 	ChainidFlag  string
 
+	// Multisig inputs are signed by collecting partial signatures from
+	// several key daemons
+	MultisigPubkeysFlag   string
+	MultisigThresholdFlag string
+	MultisigSignAddrsFlag string
+
+	// Last block height the transaction may be included at
+	ValidUntilHeightFlag string
+
 	// signFlag      bool // TODO: remove; unsafe signing without monax-keys
 	BroadcastFlag bool
 	WaitFlag      bool
@@ -57,6 +66,12 @@ func NewClientDo() *ClientDo {
 	clientDo.AddrFlag = ""
 	clientDo.ChainidFlag = ""
 
+	clientDo.MultisigPubkeysFlag = ""
+	clientDo.MultisigThresholdFlag = ""
+	clientDo.MultisigSignAddrsFlag = ""
+
+	clientDo.ValidUntilHeightFlag = ""
+
 	// clientDo.signFlag = false
 	clientDo.BroadcastFlag = false
 	clientDo.WaitFlag = false
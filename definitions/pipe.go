@@ -38,6 +38,8 @@ type Pipe interface {
 	Accounts() Accounts
 	Blockchain() blockchain_types.Blockchain
 	Events() event.EventEmitter
+	// Journal of fired events to resume subscriptions from, may be nil
+	EventJournal() *event.EventJournal
 	NameReg() NameReg
 	Transactor() Transactor
 	// Hash of Genesis state
@@ -56,15 +58,18 @@ type Pipe interface {
 type Accounts interface {
 	GenPrivAccount() (*account.PrivAccount, error)
 	GenPrivAccountFromKey(privKey []byte) (*account.PrivAccount, error)
-	Accounts([]*event.FilterData) (*types.AccountList, error)
+	// Returns a page of the accounts matching the filters, in address order
+	Accounts([]*event.FilterData, *types.PageRequest) (*types.AccountList, error)
 	Account(address []byte) (*account.Account, error)
-	Storage(address []byte) (*types.Storage, error)
+	// Returns a page of the storage of an account, in key order
+	Storage(address []byte, page *types.PageRequest) (*types.Storage, error)
 	StorageAt(address, key []byte) (*types.StorageItem, error)
 }
 
 type NameReg interface {
 	Entry(key string) (*core_types.NameRegEntry, error)
-	Entries([]*event.FilterData) (*types.ResultListNames, error)
+	// Returns a page of the entries matching the filters, in name order
+	Entries([]*event.FilterData, *types.PageRequest) (*types.ResultListNames, error)
 }
 
 type Transactor interface {
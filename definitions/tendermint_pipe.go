@@ -16,6 +16,7 @@ package definitions
 
 import (
 	"github.com/hyperledger/burrow/account"
+	core_types "github.com/hyperledger/burrow/core/types"
 	rpc_tm_types "github.com/hyperledger/burrow/rpc/tendermint/core/types"
 	"github.com/hyperledger/burrow/txs"
 )
@@ -40,12 +41,23 @@ type TendermintPipe interface {
 	NetInfo() (*rpc_tm_types.ResultNetInfo, error)
 	Genesis() (*rpc_tm_types.ResultGenesis, error)
 	ChainId() (*rpc_tm_types.ResultChainId, error)
+	GetChainParams() (*rpc_tm_types.ResultGetChainParams, error)
+
+	// Permissions
+	// ListPermissionChanges returns a page of the permission change log,
+	// optionally filtered to the changes involving address and/or affecting
+	// the named permission
+	ListPermissionChanges(address []byte, permission string,
+		page *core_types.PageRequest) (*rpc_tm_types.ResultListPermissionChanges, error)
 
 	// Accounts
 	GetAccount(address []byte) (*rpc_tm_types.ResultGetAccount, error)
-	ListAccounts() (*rpc_tm_types.ResultListAccounts, error)
+	// ListAccounts and DumpStorage return a page of the accounts, in address
+	// order, and of the storage of an account, in key order
+	ListAccounts(page *core_types.PageRequest) (*rpc_tm_types.ResultListAccounts, error)
 	GetStorage(address, key []byte) (*rpc_tm_types.ResultGetStorage, error)
-	DumpStorage(address []byte) (*rpc_tm_types.ResultDumpStorage, error)
+	DumpStorage(address []byte,
+		page *core_types.PageRequest) (*rpc_tm_types.ResultDumpStorage, error)
 
 	// Call
 	Call(fromAddress, toAddress, data []byte) (*rpc_tm_types.ResultCall, error)
@@ -60,7 +72,12 @@ type TendermintPipe interface {
 
 	// Name registry
 	GetName(name string) (*rpc_tm_types.ResultGetName, error)
-	ListNames() (*rpc_tm_types.ResultListNames, error)
+	// Returns a page of the entries, in name order
+	ListNames(page *core_types.PageRequest) (*rpc_tm_types.ResultListNames, error)
+	// Returns the unexpired entries owned by owner
+	GetNamesByOwner(owner []byte) (*rpc_tm_types.ResultListNames, error)
+	// Returns the unexpired names owned by address
+	ReverseLookup(address []byte) (*rpc_tm_types.ResultReverseLookup, error)
 
 	// Memory pool
 	BroadcastTxAsync(transaction txs.Tx) (*rpc_tm_types.ResultBroadcastTx, error)
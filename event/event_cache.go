@@ -25,6 +25,8 @@ import (
 var (
 	reaperTimeout   = 5 * time.Second
 	reaperThreshold = 10 * time.Second
+	// Most events returned by a single poll of a journaled subscription
+	maxJournalPollEvents = 1000
 )
 
 type EventCache struct {
@@ -32,6 +34,11 @@ type EventCache struct {
 	events []interface{}
 	ts     time.Time
 	subId  string
+	// Journaled subscriptions read their events from the journal rather than
+	// collecting them, starting at nextSequence
+	journaled    bool
+	filter       Filter
+	nextSequence uint64
 }
 
 func newEventCache() *EventCache {
@@ -57,20 +64,55 @@ func (this *EventCache) poll() []interface{} {
 	return evts
 }
 
+// Reads the next events from the journal, moving the cursor past them
+func (this *EventCache) pollJournal(journal *EventJournal) (*PollResponse, error) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.ts = time.Now()
+	entries, nextSequence, err := journal.EntriesMatching(this.filter, this.nextSequence,
+		maxJournalPollEvents)
+	if err != nil {
+		return nil, err
+	}
+	this.nextSequence = nextSequence
+	response := &PollResponse{
+		Events:       make([]interface{}, len(entries)),
+		Sequences:    make([]uint64, len(entries)),
+		NextSequence: nextSequence,
+	}
+	for i, entry := range entries {
+		response.Events[i] = entry.Data
+		response.Sequences[i] = entry.Sequence
+	}
+	return response, nil
+}
+
 // Catches events that callers subscribe to and adds them to an array ready to be polled.
 type EventSubscriptions struct {
 	mtx          *sync.Mutex
 	eventEmitter EventEmitter
 	subs         map[string]*EventCache
 	reap         bool
+	// Journal to resume subscriptions from, may be nil
+	journal       *EventJournal
+	filterFactory *FilterFactory
 }
 
 func NewEventSubscriptions(eventEmitter EventEmitter) *EventSubscriptions {
+	return NewJournaledEventSubscriptions(eventEmitter, nil)
+}
+
+// Creates subscriptions that can also be resumed from a sequence number in
+// the journal
+func NewJournaledEventSubscriptions(eventEmitter EventEmitter,
+	journal *EventJournal) *EventSubscriptions {
 	es := &EventSubscriptions{
-		mtx:          &sync.Mutex{},
-		eventEmitter: eventEmitter,
-		subs:         make(map[string]*EventCache),
-		reap:         true,
+		mtx:           &sync.Mutex{},
+		eventEmitter:  eventEmitter,
+		subs:          make(map[string]*EventCache),
+		reap:          true,
+		journal:       journal,
+		filterFactory: NewEventFilterFactory(),
 	}
 	go reap(es)
 	return es
@@ -111,6 +153,8 @@ func (this *EventSubscriptions) Add(eventId string) (string, error) {
 			cache.events = append(cache.events, evt)
 		})
 	cache.subId = subId
+	// in case the subscription moves to the journal
+	cache.filter = &eventIdFilter{eventId}
 	this.mtx.Lock()
 	defer this.mtx.Unlock()
 	this.subs[subId] = cache
@@ -120,12 +164,93 @@ func (this *EventSubscriptions) Add(eventId string) (string, error) {
 	return subId, nil
 }
 
+// Add a subscription that reads events with eventId from the journal,
+// starting at fromSequence. Zero starts at the oldest event the journal
+// keeps. Only events fired by the application are journaled.
+func (this *EventSubscriptions) AddFromSequence(eventId string,
+	fromSequence uint64) (string, error) {
+	return this.addJournaled(&eventIdFilter{eventId}, fromSequence)
+}
+
+// Add a subscription to the journaled events matching a filter query (see
+// NewEventFilterFactory for the fields), and also having eventId if it is
+// not empty. It starts at fromSequence if that is not nil, otherwise with
+// the next event fired.
+func (this *EventSubscriptions) AddFilter(eventId, query string,
+	fromSequence *uint64) (string, error) {
+	if this.journal == nil {
+		return "", fmt.Errorf("Cannot filter events since the event journal is not enabled")
+	}
+	filter, err := newJournalFilter(this.filterFactory, eventId, query)
+	if err != nil {
+		return "", err
+	}
+	if fromSequence != nil {
+		return this.addJournaled(filter, *fromSequence)
+	}
+	return this.addJournaled(filter, this.journal.LastSequence()+1)
+}
+
+func (this *EventSubscriptions) addJournaled(filter Filter,
+	fromSequence uint64) (string, error) {
+	if this.journal == nil {
+		return "", fmt.Errorf("Cannot resume subscription from sequence %v "+
+			"since the event journal is not enabled", fromSequence)
+	}
+	if first := this.journal.FirstSequence(); fromSequence != 0 && fromSequence < first {
+		return "", ErrEventsDropped{fromSequence, first}
+	}
+	subId, errSID := GenerateSubId()
+	if errSID != nil {
+		return "", errSID
+	}
+	cache := newEventCache()
+	cache.subId = subId
+	cache.journaled = true
+	cache.filter = filter
+	cache.nextSequence = fromSequence
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.subs[subId] = cache
+	return subId, nil
+}
+
 func (this *EventSubscriptions) Poll(subId string) ([]interface{}, error) {
+	response, err := this.PollFromSequence(subId, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Events, nil
+}
+
+// Polls a subscription. If fromSequence is not nil the subscription first
+// moves to read from the journal at that sequence, so a consumer can go back
+// to the last event it processed.
+func (this *EventSubscriptions) PollFromSequence(subId string,
+	fromSequence *uint64) (*PollResponse, error) {
 	sub, ok := this.subs[subId]
 	if !ok {
 		return nil, fmt.Errorf("Subscription not active. ID: " + subId)
 	}
-	return sub.poll(), nil
+	if fromSequence != nil {
+		if this.journal == nil {
+			return nil, fmt.Errorf("Cannot poll from sequence %v since the event "+
+				"journal is not enabled", *fromSequence)
+		}
+		sub.mtx.Lock()
+		if !sub.journaled {
+			// stop collecting events now they will be read from the journal
+			this.eventEmitter.Unsubscribe(subId)
+			sub.journaled = true
+			sub.events = []interface{}{}
+		}
+		sub.nextSequence = *fromSequence
+		sub.mtx.Unlock()
+	}
+	if sub.journaled {
+		return sub.pollJournal(this.journal)
+	}
+	return &PollResponse{Events: sub.poll()}, nil
 }
 
 func (this *EventSubscriptions) Remove(subId string) error {
@@ -0,0 +1,339 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/burrow/txs"
+	. "github.com/hyperledger/burrow/word256"
+)
+
+// Filters over journaled events, matched against *JournalEntry. Fields that
+// do not apply to an event (eg. topic for a tx event) never match it.
+//
+//	event      event id, a trailing * matches any id with that prefix
+//	type       kind of event data: tx, call, log, new_block, new_block_header
+//	tx_type    type of the tx of a tx event, eg. send, call, name, permissions
+//	value      amount sent by a call event, the input of a CallTx or the value of an EthTx
+//	exception  exception of a tx or call event, exception:!= matches any
+//	caller     address calling in a call event
+//	callee     address called in a call event
+//	address    address of the contract emitting a log event
+//	topic      any of the topics of a log event, topic0 to topic3 for one
+//	height     height of a log event
+func NewEventFilterFactory() *FilterFactory {
+	ff := NewFilterFactory()
+	registerEventFilter(ff, "event", func() ConfigurableFilter {
+		return &eventStringFilter{field: "event", get: eventIdOf, prefix: true}
+	})
+	registerEventFilter(ff, "type", func() ConfigurableFilter {
+		return &eventStringFilter{field: "type", get: eventTypeOf}
+	})
+	registerEventFilter(ff, "tx_type", func() ConfigurableFilter {
+		return &eventStringFilter{field: "tx_type", get: txTypeOf}
+	})
+	registerEventFilter(ff, "exception", func() ConfigurableFilter {
+		return &eventStringFilter{field: "exception", get: exceptionOf}
+	})
+	registerEventFilter(ff, "value", func() ConfigurableFilter {
+		return &eventNumberFilter{field: "value", get: valueOf}
+	})
+	registerEventFilter(ff, "height", func() ConfigurableFilter {
+		return &eventNumberFilter{field: "height", get: heightOf}
+	})
+	registerEventFilter(ff, "caller", func() ConfigurableFilter {
+		return &eventWordFilter{field: "caller", get: callerOf}
+	})
+	registerEventFilter(ff, "callee", func() ConfigurableFilter {
+		return &eventWordFilter{field: "callee", get: calleeOf}
+	})
+	registerEventFilter(ff, "address", func() ConfigurableFilter {
+		return &eventWordFilter{field: "address", get: logAddressOf}
+	})
+	registerEventFilter(ff, "topic", func() ConfigurableFilter {
+		return &eventWordFilter{field: "topic", get: topicsOf(-1)}
+	})
+	for i := 0; i < 4; i++ {
+		field := fmt.Sprintf("topic%v", i)
+		get := topicsOf(i)
+		registerEventFilter(ff, field, func() ConfigurableFilter {
+			return &eventWordFilter{field: field, get: get}
+		})
+	}
+	return ff
+}
+
+func registerEventFilter(ff *FilterFactory, field string, newFilter func() ConfigurableFilter) {
+	ff.RegisterFilterPool(field, &sync.Pool{
+		New: func() interface{} {
+			return newFilter()
+		},
+	})
+}
+
+// Returns a filter over journaled events matching query, narrowed to the
+// events with eventId if it is not empty
+func NewJournalFilter(eventId, query string) (Filter, error) {
+	return newJournalFilter(NewEventFilterFactory(), eventId, query)
+}
+
+func newJournalFilter(ff *FilterFactory, eventId, query string) (Filter, error) {
+	fdArr, err := ParseFilterQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := ff.NewFilter(fdArr)
+	if err != nil {
+		return nil, err
+	}
+	if eventId != "" {
+		composite := &CompositeFilter{}
+		composite.SetData([]Filter{&eventIdFilter{eventId}, filter})
+		filter = composite
+	}
+	return filter, nil
+}
+
+// Matches journaled events with exactly the given event id
+type eventIdFilter struct {
+	eventId string
+}
+
+func (this *eventIdFilter) Match(v interface{}) bool {
+	entry, ok := v.(*JournalEntry)
+	return ok && entry.EventId == this.eventId
+}
+
+// Filter for string fields of events
+type eventStringFilter struct {
+	field string
+	get   func(*JournalEntry) (string, bool)
+	// whether a trailing * matches by prefix
+	prefix bool
+	value  string
+	match  func(string, string) bool
+}
+
+func (this *eventStringFilter) Configure(fd *FilterData) error {
+	match, err := GetStringFilter(fd.Op, this.field)
+	if err != nil {
+		return err
+	}
+	this.value = fd.Value
+	if this.prefix && strings.HasSuffix(fd.Value, "*") {
+		this.value = strings.ToLower(strings.TrimSuffix(fd.Value, "*"))
+		if fd.Op == "==" {
+			match = func(s0, s1 string) bool {
+				return strings.HasPrefix(strings.ToLower(s0), s1)
+			}
+		} else {
+			match = func(s0, s1 string) bool {
+				return !strings.HasPrefix(strings.ToLower(s0), s1)
+			}
+		}
+	}
+	this.match = match
+	return nil
+}
+
+func (this *eventStringFilter) Match(v interface{}) bool {
+	entry, ok := v.(*JournalEntry)
+	if !ok {
+		return false
+	}
+	s, ok := this.get(entry)
+	return ok && this.match(s, this.value)
+}
+
+// Filter for numeric fields of events
+type eventNumberFilter struct {
+	field string
+	get   func(*JournalEntry) (int64, bool)
+	value int64
+	match func(int64, int64) bool
+}
+
+func (this *eventNumberFilter) Configure(fd *FilterData) error {
+	val, err := ParseNumberValue(fd.Value)
+	if err != nil {
+		return err
+	}
+	match, err := GetRangeFilter(fd.Op, this.field)
+	if err != nil {
+		return err
+	}
+	this.match = match
+	this.value = val
+	return nil
+}
+
+func (this *eventNumberFilter) Match(v interface{}) bool {
+	entry, ok := v.(*JournalEntry)
+	if !ok {
+		return false
+	}
+	n, ok := this.get(entry)
+	return ok && this.match(n, this.value)
+}
+
+// Filter for address and topic fields of events, given as hex and compared
+// left padded to 32 bytes. With == any of the words must match, with !=
+// none of them may.
+type eventWordFilter struct {
+	field  string
+	get    func(*JournalEntry) []Word256
+	value  Word256
+	negate bool
+}
+
+func (this *eventWordFilter) Configure(fd *FilterData) error {
+	if fd.Op != "==" && fd.Op != "!=" {
+		return fmt.Errorf("Op: " + fd.Op + " is not supported for '" + this.field + "' filtering")
+	}
+	bs, err := hex.DecodeString(strings.TrimPrefix(fd.Value, "0x"))
+	if err != nil || len(bs) > Word256Length {
+		return fmt.Errorf("Value of '%s' filter must be at most %v bytes of hex",
+			this.field, Word256Length)
+	}
+	this.value = LeftPadWord256(bs)
+	this.negate = fd.Op == "!="
+	return nil
+}
+
+func (this *eventWordFilter) Match(v interface{}) bool {
+	entry, ok := v.(*JournalEntry)
+	if !ok {
+		return false
+	}
+	words := this.get(entry)
+	if words == nil {
+		return false
+	}
+	for _, word := range words {
+		if word == this.value {
+			return !this.negate
+		}
+	}
+	return this.negate
+}
+
+// Field getters
+
+func eventIdOf(entry *JournalEntry) (string, bool) {
+	return entry.EventId, true
+}
+
+func eventTypeOf(entry *JournalEntry) (string, bool) {
+	switch entry.Data.(type) {
+	case txs.EventDataTx:
+		return "tx", true
+	case txs.EventDataCall:
+		return "call", true
+	case txs.EventDataLog:
+		return "log", true
+	case txs.EventDataNewBlock:
+		return "new_block", true
+	case txs.EventDataNewBlockHeader:
+		return "new_block_header", true
+	}
+	return "", false
+}
+
+func txTypeOf(entry *JournalEntry) (string, bool) {
+	eventDataTx, ok := entry.Data.(txs.EventDataTx)
+	if !ok {
+		return "", false
+	}
+	name := txs.TxTypeName(eventDataTx.Tx)
+	return name, name != ""
+}
+
+func exceptionOf(entry *JournalEntry) (string, bool) {
+	switch data := entry.Data.(type) {
+	case txs.EventDataTx:
+		return data.Exception, true
+	case txs.EventDataCall:
+		return data.Exception, true
+	}
+	return "", false
+}
+
+func valueOf(entry *JournalEntry) (int64, bool) {
+	switch data := entry.Data.(type) {
+	case txs.EventDataCall:
+		if data.CallData != nil {
+			return data.CallData.Value, true
+		}
+	case txs.EventDataTx:
+		switch tx := data.Tx.(type) {
+		case *txs.CallTx:
+			if tx.Input != nil {
+				return tx.Input.Amount, true
+			}
+		case *txs.EthTx:
+			return int64(tx.Value), true
+		}
+	}
+	return 0, false
+}
+
+func heightOf(entry *JournalEntry) (int64, bool) {
+	if data, ok := entry.Data.(txs.EventDataLog); ok {
+		return data.Height, true
+	}
+	return 0, false
+}
+
+func callerOf(entry *JournalEntry) []Word256 {
+	if data, ok := entry.Data.(txs.EventDataCall); ok && data.CallData != nil {
+		return []Word256{LeftPadWord256(data.CallData.Caller)}
+	}
+	return nil
+}
+
+func calleeOf(entry *JournalEntry) []Word256 {
+	if data, ok := entry.Data.(txs.EventDataCall); ok && data.CallData != nil {
+		return []Word256{LeftPadWord256(data.CallData.Callee)}
+	}
+	return nil
+}
+
+func logAddressOf(entry *JournalEntry) []Word256 {
+	if data, ok := entry.Data.(txs.EventDataLog); ok {
+		return []Word256{data.Address}
+	}
+	return nil
+}
+
+// Returns a getter for the topic at index, or all topics if index is negative
+func topicsOf(index int) func(*JournalEntry) []Word256 {
+	return func(entry *JournalEntry) []Word256 {
+		data, ok := entry.Data.(txs.EventDataLog)
+		if !ok {
+			return nil
+		}
+		if index < 0 {
+			return append([]Word256{}, data.Topics...)
+		}
+		if index >= len(data.Topics) {
+			return nil
+		}
+		return []Word256{data.Topics[index]}
+	}
+}
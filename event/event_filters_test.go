@@ -0,0 +1,140 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/burrow/txs"
+	"github.com/hyperledger/burrow/word256"
+	"github.com/stretchr/testify/assert"
+	dbm "github.com/tendermint/go-db"
+)
+
+var (
+	callerAddress = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	calleeAddress = []byte{20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	transferTopic = word256.RightPadWord256([]byte("Transfer"))
+)
+
+func testEventEntries() []*JournalEntry {
+	return []*JournalEntry{
+		{1, txs.EventStringAccCall(calleeAddress), txs.EventDataCall{
+			CallData: &txs.CallData{Caller: callerAddress, Callee: calleeAddress, Value: 500},
+		}},
+		{2, txs.EventStringAccCall(calleeAddress), txs.EventDataCall{
+			CallData:  &txs.CallData{Caller: callerAddress, Callee: calleeAddress, Value: 50},
+			Exception: "Insufficient gas",
+		}},
+		{3, txs.EventStringLogEvent(calleeAddress), txs.EventDataLog{
+			Address: word256.LeftPadWord256(calleeAddress),
+			Topics:  []word256.Word256{transferTopic, word256.LeftPadWord256(callerAddress)},
+			Height:  7,
+		}},
+		{4, txs.EventStringAccInput(callerAddress), txs.EventDataTx{
+			Tx: &txs.CallTx{Input: &txs.TxInput{Address: callerAddress, Amount: 200}},
+		}},
+		{5, txs.EventStringNameReg("foo"), txs.EventDataTx{
+			Tx: &txs.NameTx{Input: &txs.TxInput{Address: callerAddress, Amount: 200}},
+		}},
+	}
+}
+
+func TestEventFilters(t *testing.T) {
+	ff := NewEventFilterFactory()
+	queries := map[string][]uint64{
+		"":                     {1, 2, 3, 4, 5},
+		"event:Acc/*":          {1, 2, 4},
+		"event:!=Acc/*":        {3, 5},
+		"type:call":            {1, 2},
+		"tx_type:name":         {5},
+		"value:>100":           {1, 4},
+		"value:10..200":        {2, 4},
+		"exception:!=":         {2},
+		"exception: type:call": {1},
+		"height:>=7":           {3},
+		"topic:" + fmt.Sprintf("%X", transferTopic.Bytes()): {3},
+		"topic1:" + fmt.Sprintf("%X", callerAddress):        {3},
+		"topic0:" + fmt.Sprintf("%X", callerAddress):        {},
+		"address:" + fmt.Sprintf("%X", calleeAddress):       {3},
+		"caller:0x" + fmt.Sprintf("%x", callerAddress):      {1, 2},
+		"callee:!=" + fmt.Sprintf("%X", callerAddress):      {1, 2},
+	}
+	for query, expected := range queries {
+		fdArr, err := ParseFilterQuery(query)
+		if !assert.NoError(t, err, query) {
+			continue
+		}
+		filter, err := ff.NewFilter(fdArr)
+		if !assert.NoError(t, err, query) {
+			continue
+		}
+		matched := []uint64{}
+		for _, entry := range testEventEntries() {
+			if filter.Match(entry) {
+				matched = append(matched, entry.Sequence)
+			}
+		}
+		assert.Equal(t, expected, matched, query)
+	}
+
+	for _, query := range []string{"nonsense:1", "value:lots", "topic:XYZ", "caller:>1", "event"} {
+		fdArr, err := ParseFilterQuery(query)
+		if err == nil {
+			_, err = ff.NewFilter(fdArr)
+		}
+		assert.Error(t, err, query)
+	}
+}
+
+func TestFilteredSubscription(t *testing.T) {
+	journal, err := NewEventJournal(dbm.NewMemDB(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eSubs := NewJournaledEventSubscriptions(newMockEventEmitter(), journal)
+	entries := testEventEntries()
+	journal.Append(1, nil, entries[0].EventId, entries[0].Data)
+
+	// without a from_sequence only events fired after subscribing are polled
+	subId, err := eSubs.AddFilter("", "type:call value:>100", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		journal.Append(1, nil, entry.EventId, entry.Data)
+	}
+	response, err := eSubs.PollFromSequence(subId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []uint64{2}, response.Sequences)
+
+	// the event id narrows the filter
+	from := uint64(0)
+	subId, err = eSubs.AddFilter(txs.EventStringAccInput(callerAddress), "value:>100", &from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err = eSubs.PollFromSequence(subId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []uint64{5}, response.Sequences)
+
+	_, err = eSubs.AddFilter("", "value:lots", nil)
+	assert.Error(t, err)
+}
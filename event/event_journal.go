@@ -0,0 +1,341 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/burrow/txs"
+	dbm "github.com/tendermint/go-db"
+	go_events "github.com/tendermint/go-events"
+	"github.com/tendermint/go-wire"
+)
+
+// Number of events the journal keeps when no size is configured
+const DefaultEventJournalSize = 10000
+
+// Most events read from the database by a single call to EntriesMatching,
+// whatever the number that match
+const maxJournalScan = 1000
+
+var journalMetaKey = []byte("journal/meta")
+
+// A fired event and the sequence number the journal gave it
+type JournalEntry struct {
+	Sequence uint64 `json:"sequence"`
+	// height of the block whose execution fired the event
+	Height int `json:"height"`
+	// hash of the tx that fired the event, nil if it was not fired by a tx
+	TxHash  []byte        `json:"tx_hash"`
+	EventId string        `json:"event_id"`
+	Data    txs.EventData `json:"data"`
+}
+
+// Returned when events a consumer asked for have already been dropped from
+// the journal to keep it within its size
+type ErrEventsDropped struct {
+	FromSequence  uint64
+	FirstSequence uint64
+}
+
+func (e ErrEventsDropped) Error() string {
+	return fmt.Sprintf("Events from sequence %v have been dropped from the journal, "+
+		"the earliest event kept has sequence %v", e.FromSequence, e.FirstSequence)
+}
+
+// Bounds of the sequence numbers held by the journal, persisted alongside
+// the events so sequences keep increasing across restarts
+type journalMeta struct {
+	First uint64
+	Last  uint64
+}
+
+// EventJournal gives every event fired through a journaling event switch a
+// monotonically increasing sequence number (starting at 1) and keeps the
+// most recent events in a database, so that consumers can resume from the
+// last sequence they saw.
+type EventJournal struct {
+	mtx  *sync.Mutex
+	db   dbm.DB
+	size uint64
+	meta journalMeta
+	// hash of the tx of the last event appended, the events of a tx are
+	// appended one after the other
+	lastTxHash []byte
+	// closed and replaced on every append
+	appended chan struct{}
+}
+
+// Opens the journal held in db, keeping at most size events (or
+// DefaultEventJournalSize if size is zero)
+func NewEventJournal(db dbm.DB, size uint64) (*EventJournal, error) {
+	if size == 0 {
+		size = DefaultEventJournalSize
+	}
+	journal := &EventJournal{
+		mtx:      &sync.Mutex{},
+		db:       db,
+		size:     size,
+		meta:     journalMeta{First: 1},
+		appended: make(chan struct{}),
+	}
+	if metaBytes := db.Get(journalMetaKey); len(metaBytes) > 0 {
+		var n int
+		var err error
+		wire.ReadBinary(&journal.meta, bytes.NewBuffer(metaBytes), len(metaBytes), &n, &err)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read event journal metadata: %v", err)
+		}
+	}
+	return journal, nil
+}
+
+// Records an event fired at height by the tx with txHash (nil if it was not
+// fired by a tx) and returns its sequence number. Once the journal holds
+// more than its size the oldest events are dropped.
+func (journal *EventJournal) Append(height int, txHash []byte, eventId string,
+	data txs.EventData) uint64 {
+	journal.mtx.Lock()
+	defer journal.mtx.Unlock()
+	sequence := journal.meta.Last + 1
+	batch := journal.db.NewBatch()
+	batch.Set(journalEntryKey(sequence), wire.BinaryBytes(&JournalEntry{
+		Sequence: sequence,
+		Height:   height,
+		TxHash:   txHash,
+		EventId:  eventId,
+		Data:     data,
+	}))
+	// the tx index points at the first event of each tx
+	if txHash != nil && !bytes.Equal(txHash, journal.lastTxHash) {
+		batch.Set(journalTxKey(txHash), wire.BinaryBytes(sequence))
+	}
+	journal.lastTxHash = txHash
+	journal.meta.Last = sequence
+	for journal.meta.Last-journal.meta.First+1 > journal.size {
+		// a tx can no longer be looked up once its first event is dropped
+		if dropped, err := journal.entry(journal.meta.First); err == nil &&
+			dropped.TxHash != nil {
+			batch.Delete(journalTxKey(dropped.TxHash))
+		}
+		batch.Delete(journalEntryKey(journal.meta.First))
+		journal.meta.First++
+	}
+	batch.Set(journalMetaKey, wire.BinaryBytes(journal.meta))
+	batch.Write()
+	close(journal.appended)
+	journal.appended = make(chan struct{})
+	return sequence
+}
+
+// Returns a channel that is closed when the next event is appended, so
+// consumers can wait for events rather than poll. To not miss an event the
+// channel must be taken before reading the entries it is meant to follow.
+func (journal *EventJournal) Appended() <-chan struct{} {
+	journal.mtx.Lock()
+	defer journal.mtx.Unlock()
+	return journal.appended
+}
+
+// Sequence of the most recent event, zero if no event has been recorded
+func (journal *EventJournal) LastSequence() uint64 {
+	journal.mtx.Lock()
+	defer journal.mtx.Unlock()
+	return journal.meta.Last
+}
+
+// Sequence of the oldest event still kept
+func (journal *EventJournal) FirstSequence() uint64 {
+	journal.mtx.Lock()
+	defer journal.mtx.Unlock()
+	return journal.meta.First
+}
+
+// Returns the events with eventId from fromSequence onwards, up to max of
+// them if max is positive, and the sequence to read from next. A
+// fromSequence of zero reads from the oldest event kept; otherwise it is an
+// error for events from fromSequence to have been dropped. At most
+// maxJournalScan events are read per call, so fewer than max events may be
+// returned while more remain to be read from the next sequence.
+func (journal *EventJournal) Entries(eventId string, fromSequence uint64,
+	max int) ([]*JournalEntry, uint64, error) {
+	return journal.EntriesMatching(&eventIdFilter{eventId}, fromSequence, max)
+}
+
+// Same as Entries but returns the events matching filter, which is passed
+// each *JournalEntry
+func (journal *EventJournal) EntriesMatching(filter Filter, fromSequence uint64,
+	max int) ([]*JournalEntry, uint64, error) {
+	// the entries are read without holding the lock so appending is not held
+	// up, entries dropped in the meantime are noticed when they are missing
+	journal.mtx.Lock()
+	first, last := journal.meta.First, journal.meta.Last
+	journal.mtx.Unlock()
+	if fromSequence == 0 {
+		fromSequence = first
+	} else if fromSequence < first {
+		return nil, fromSequence, ErrEventsDropped{fromSequence, first}
+	}
+	if last >= fromSequence+maxJournalScan {
+		last = fromSequence + maxJournalScan - 1
+	}
+	entries := []*JournalEntry{}
+	sequence := fromSequence
+	for ; sequence <= last; sequence++ {
+		if max > 0 && len(entries) == max {
+			break
+		}
+		entry, err := journal.entry(sequence)
+		if err != nil {
+			if first := journal.FirstSequence(); sequence < first {
+				return nil, fromSequence, ErrEventsDropped{fromSequence, first}
+			}
+			return nil, fromSequence, err
+		}
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, sequence, nil
+}
+
+func (journal *EventJournal) entry(sequence uint64) (*JournalEntry, error) {
+	entryBytes := journal.db.Get(journalEntryKey(sequence))
+	if len(entryBytes) == 0 {
+		return nil, fmt.Errorf("Event with sequence %v missing from journal", sequence)
+	}
+	var n int
+	var err error
+	entry := new(JournalEntry)
+	wire.ReadBinary(entry, bytes.NewBuffer(entryBytes), len(entryBytes), &n, &err)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read event with sequence %v from journal: %v",
+			sequence, err)
+	}
+	return entry, nil
+}
+
+// Returns the sequence of the first event kept that was fired at height or
+// later, or the sequence after the last event if there is none. Events are
+// appended in the order of the blocks that fired them, so the journal is
+// searched rather than scanned.
+func (journal *EventJournal) SequenceAtHeight(height int) (uint64, error) {
+	journal.mtx.Lock()
+	low, high := journal.meta.First, journal.meta.Last+1
+	journal.mtx.Unlock()
+	for low < high {
+		middle := low + (high-low)/2
+		entry, err := journal.entry(middle)
+		if err != nil {
+			if first := journal.FirstSequence(); middle < first {
+				// dropped since the search started, carry on from the new oldest
+				low = first
+				continue
+			}
+			return 0, err
+		}
+		if entry.Height < height {
+			low = middle + 1
+		} else {
+			high = middle
+		}
+	}
+	return low, nil
+}
+
+// Returns the events fired by the tx with txHash, or none if its events are
+// not (or no longer) in the journal
+func (journal *EventJournal) TxEntries(txHash []byte) ([]*JournalEntry, error) {
+	sequenceBytes := journal.db.Get(journalTxKey(txHash))
+	if len(sequenceBytes) == 0 {
+		return nil, nil
+	}
+	var sequence uint64
+	var n int
+	var err error
+	wire.ReadBinary(&sequence, bytes.NewBuffer(sequenceBytes), len(sequenceBytes), &n, &err)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read tx index of journal: %v", err)
+	}
+	last := journal.LastSequence()
+	entries := []*JournalEntry{}
+	for ; sequence <= last && len(entries) < maxJournalScan; sequence++ {
+		entry, err := journal.entry(sequence)
+		if err != nil {
+			if sequence < journal.FirstSequence() {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if !bytes.Equal(entry.TxHash, txHash) {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func journalEntryKey(sequence uint64) []byte {
+	return []byte(fmt.Sprintf("journal/event/%020d", sequence))
+}
+
+func journalTxKey(txHash []byte) []byte {
+	return []byte(fmt.Sprintf("journal/tx/%X", txHash))
+}
+
+// Wraps an EventSwitch so that every event fired through it is recorded in
+// the journal before being passed on to listeners. Events are fired by the
+// application as it commits a block, so blockHeight gives the height of the
+// block being committed.
+func NewJournalingEventSwitch(eventSwitch go_events.EventSwitch,
+	journal *EventJournal, chainId string, blockHeight func() int) go_events.EventSwitch {
+	return &journalingEventSwitch{
+		EventSwitch: eventSwitch,
+		journal:     journal,
+		chainId:     chainId,
+		blockHeight: blockHeight,
+	}
+}
+
+type journalingEventSwitch struct {
+	go_events.EventSwitch
+	journal     *EventJournal
+	chainId     string
+	blockHeight func() int
+}
+
+func (evsw *journalingEventSwitch) FireEvent(event string, data go_events.EventData) {
+	// events we cannot map are still fired, they just cannot be resumed
+	if eventData, err := mapToOurEventData(data); err == nil {
+		evsw.journal.Append(evsw.blockHeight(), eventTxHash(evsw.chainId, eventData),
+			event, eventData)
+	}
+	evsw.EventSwitch.FireEvent(event, data)
+}
+
+// Hash of the tx that fired an event, nil for events not fired by a tx
+func eventTxHash(chainId string, data txs.EventData) []byte {
+	switch data := data.(type) {
+	case txs.EventDataTx:
+		return txs.TxHash(chainId, data.Tx)
+	case txs.EventDataCall:
+		return data.TxID
+	case txs.EventDataLog:
+		return data.TxID
+	}
+	return nil
+}
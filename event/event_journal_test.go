@@ -0,0 +1,289 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"testing"
+
+	"github.com/hyperledger/burrow/txs"
+	"github.com/hyperledger/burrow/word256"
+	"github.com/stretchr/testify/assert"
+	dbm "github.com/tendermint/go-db"
+	go_events "github.com/tendermint/go-events"
+)
+
+func logEvent(height int64) txs.EventData {
+	return txs.EventDataLog{
+		Topics: []word256.Word256{word256.Uint64ToWord256(uint64(height))},
+		Data:   []byte{1, 2, 3},
+		Height: height,
+	}
+}
+
+func TestEventJournal(t *testing.T) {
+	db := dbm.NewMemDB()
+	journal, err := NewEventJournal(db, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		assert.Equal(t, uint64(2*i-1), journal.Append(1, nil, "Log/A", logEvent(i)))
+		assert.Equal(t, uint64(2*i), journal.Append(1, nil, "Log/B", logEvent(i)))
+	}
+	// only the last 4 events are kept
+	assert.Equal(t, uint64(3), journal.FirstSequence())
+	assert.Equal(t, uint64(6), journal.LastSequence())
+
+	entries, next, err := journal.Entries("Log/A", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(7), next)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, uint64(3), entries[0].Sequence)
+		assert.Equal(t, logEvent(2), entries[0].Data)
+		assert.Equal(t, uint64(5), entries[1].Sequence)
+	}
+
+	// reading stops after max events
+	entries, next, err = journal.Entries("Log/B", 4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, entries, 1)
+	assert.Equal(t, uint64(5), next)
+
+	_, _, err = journal.Entries("Log/A", 1, 0)
+	assert.Equal(t, ErrEventsDropped{1, 3}, err)
+
+	// reopening the journal carries on from where it was
+	journal, err = NewEventJournal(db, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(3), journal.FirstSequence())
+	assert.Equal(t, uint64(7), journal.Append(1, nil, "Log/A", logEvent(4)))
+}
+
+func TestJournalScanLimit(t *testing.T) {
+	journal, err := NewEventJournal(dbm.NewMemDB(), 2*maxJournalScan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxJournalScan; i++ {
+		journal.Append(1, nil, "Log/B", logEvent(1))
+	}
+	journal.Append(1, nil, "Log/A", logEvent(2))
+
+	// the scan stops before reaching the match
+	entries, next, err := journal.Entries("Log/A", 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, entries)
+	assert.Equal(t, uint64(maxJournalScan+1), next)
+
+	entries, next, err = journal.Entries("Log/A", next, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, logEvent(2), entries[0].Data)
+	}
+	assert.Equal(t, uint64(maxJournalScan+2), next)
+}
+
+// Appends an event the first time it matches
+type appendingFilter struct {
+	journal  *EventJournal
+	appended bool
+}
+
+func (filter *appendingFilter) Match(v interface{}) bool {
+	if !filter.appended {
+		filter.appended = true
+		filter.journal.Append(1, nil, "Log/A", logEvent(4))
+	}
+	return true
+}
+
+func TestJournalAppendWhileReading(t *testing.T) {
+	journal, err := NewEventJournal(dbm.NewMemDB(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(1); i <= 3; i++ {
+		journal.Append(1, nil, "Log/A", logEvent(i))
+	}
+	// appending from the filter would deadlock if the lock were held
+	entries, next, err := journal.EntriesMatching(&appendingFilter{journal: journal}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the events there when the read started are returned
+	assert.Len(t, entries, 3)
+	assert.Equal(t, uint64(4), next)
+	assert.Equal(t, uint64(4), journal.LastSequence())
+}
+
+func TestJournalAppended(t *testing.T) {
+	journal, err := NewEventJournal(dbm.NewMemDB(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appended := journal.Appended()
+	select {
+	case <-appended:
+		t.Fatal("Appended should not be closed before an event is appended")
+	default:
+	}
+	journal.Append(1, nil, "Log/A", logEvent(1))
+	select {
+	case <-appended:
+	default:
+		t.Fatal("Appended should be closed once an event is appended")
+	}
+	assert.NotEqual(t, appended, journal.Appended())
+}
+
+func TestJournalingEventSwitch(t *testing.T) {
+	journal, err := NewEventJournal(dbm.NewMemDB(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evsw := NewJournalingEventSwitch(go_events.NewEventSwitch(), journal, "test_chain",
+		func() int { return 7 })
+	evsw.Start()
+	defer evsw.Stop()
+
+	var received []txs.EventData
+	evsw.AddListenerForEvent("listener", "Log/A", func(data go_events.EventData) {
+		received = append(received, data.(txs.EventData))
+	})
+	evsw.FireEvent("Log/A", logEvent(1))
+	evsw.FireEvent("Log/B", logEvent(2))
+
+	assert.Equal(t, []txs.EventData{logEvent(1)}, received)
+	assert.Equal(t, uint64(2), journal.LastSequence())
+
+	// entries record the height and tx they were fired at
+	tx := &txs.SendTx{}
+	evsw.FireEvent("Acc/A/Input", txs.EventDataTx{Tx: tx})
+	entries, _, err := journal.Entries("Acc/A/Input", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, 7, entries[0].Height)
+		assert.Equal(t, txs.TxHash("test_chain", tx), entries[0].TxHash)
+	}
+}
+
+func TestJournalTxIndex(t *testing.T) {
+	journal, err := NewEventJournal(dbm.NewMemDB(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txA, txB := []byte{0xA}, []byte{0xB}
+	journal.Append(1, txA, "Log/A", logEvent(1))
+	journal.Append(1, txA, "Log/B", logEvent(1))
+	journal.Append(3, txB, "Log/A", logEvent(3))
+	journal.Append(3, nil, "Log/C", logEvent(3))
+
+	entries, err := journal.TxEntries(txA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, uint64(1), entries[0].Sequence)
+		assert.Equal(t, uint64(2), entries[1].Sequence)
+	}
+	entries, err = journal.TxEntries(txB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, entries, 1)
+
+	for height, expected := range map[int]uint64{0: 1, 1: 1, 2: 3, 3: 3, 4: 5} {
+		sequence, err := journal.SequenceAtHeight(height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, sequence, "height %v", height)
+	}
+
+	// a tx is no longer found once its first event is dropped
+	journal.Append(4, nil, "Log/C", logEvent(4))
+	entries, err = journal.TxEntries(txA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, entries)
+	sequence, err := journal.SequenceAtHeight(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(2), sequence)
+}
+
+func TestResumeSubscription(t *testing.T) {
+	journal, err := NewEventJournal(dbm.NewMemDB(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eSubs := NewJournaledEventSubscriptions(newMockEventEmitter(), journal)
+	for i := int64(1); i <= 3; i++ {
+		journal.Append(1, nil, "Log/A", logEvent(i))
+		journal.Append(1, nil, "Log/B", logEvent(i))
+	}
+
+	subId, err := eSubs.AddFromSequence("Log/A", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := eSubs.PollFromSequence(subId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []interface{}{logEvent(2), logEvent(3)}, response.Events)
+	assert.Equal(t, []uint64{3, 5}, response.Sequences)
+	assert.Equal(t, uint64(7), response.NextSequence)
+
+	// nothing new until another event is fired
+	response, err = eSubs.PollFromSequence(subId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, response.Events)
+	journal.Append(1, nil, "Log/A", logEvent(4))
+	response, err = eSubs.PollFromSequence(subId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []uint64{7}, response.Sequences)
+
+	// rewind to an earlier sequence
+	rewind := uint64(5)
+	response, err = eSubs.PollFromSequence(subId, &rewind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []uint64{5, 7}, response.Sequences)
+
+	// subscriptions cannot be resumed without a journal
+	_, err = NewEventSubscriptions(newMockEventEmitter()).AddFromSequence("Log/A", 1)
+	assert.Error(t, err)
+}
@@ -125,6 +125,10 @@ type EventUnsub struct {
 // EventPoll
 type PollResponse struct {
 	Events []interface{} `json:"events"`
+	// For subscriptions resumed from the journal, the sequence number of each
+	// event and the sequence to resume from after them
+	Sequences    []uint64 `json:"sequences,omitempty"`
+	NextSequence uint64   `json:"next_sequence,omitempty"`
 }
 
 // **************************************************************************************
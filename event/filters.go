@@ -139,6 +139,83 @@ func (this *FilterFactory) newSingleFilter(fd *FilterData) (ConfigurableFilter,
 	return f, nil
 }
 
+// Parses a query of space separated field:statement terms into filter data,
+// eg. "balance:>=100 code:" or "height:10..*". A statement is an operator
+// followed by a value, a value on its own (meaning ==) or a min..max range
+// where * leaves either end open.
+func ParseFilterQuery(queryString string) ([]*FilterData, error) {
+	if len(queryString) == 0 {
+		return nil, nil
+	}
+	filters := strings.Split(queryString, " ")
+	fdArr := []*FilterData{}
+	for _, f := range filters {
+		kv := strings.Split(f, ":")
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Malformed query. Missing ':' separator: " + f)
+		}
+		if kv[0] == "" {
+			return nil, fmt.Errorf("Malformed query. Field name missing: " + f)
+		}
+
+		fd, fd2, errTfd := ToFilterData(kv[0], kv[1])
+		if errTfd != nil {
+			return nil, errTfd
+		}
+		fdArr = append(fdArr, fd)
+		if fd2 != nil {
+			fdArr = append(fdArr, fd2)
+		}
+	}
+	return fdArr, nil
+}
+
+// Parse the query statement and create filter data. Two filter data in case of a range param.
+func ToFilterData(field, stmt string) (*FilterData, *FilterData, error) {
+	// In case statement is empty
+	if stmt == "" {
+		return &FilterData{field, "==", ""}, nil, nil
+	}
+	// Simple routine based on string splitting. TODO add quoted range query.
+	if stmt[0] == '>' || stmt[0] == '<' || stmt[0] == '=' || stmt[0] == '!' {
+		// This means a normal operator. If one character then stop, otherwise
+		// peek at next and check if it's a "=".
+
+		if len(stmt) == 1 {
+			return &FilterData{field, stmt[0:1], ""}, nil, nil
+		} else if stmt[1] == '=' {
+			return &FilterData{field, stmt[:2], stmt[2:]}, nil, nil
+		} else {
+			return &FilterData{field, stmt[0:1], stmt[1:]}, nil, nil
+		}
+	} else {
+		// Either we have a range query here or a malformed query.
+		rng := strings.Split(stmt, "..")
+		// This is for when there is no op, but the value is not empty.
+		if len(rng) == 1 {
+			return &FilterData{field, "==", stmt}, nil, nil
+		}
+		// The rest.
+		if len(rng) != 2 || rng[0] == "" || rng[1] == "" {
+			return nil, nil, fmt.Errorf("Malformed query statement: " + stmt)
+		}
+		var min string
+		var max string
+		if rng[0] == "*" {
+			min = "min"
+		} else {
+			min = rng[0]
+		}
+		if rng[1] == "*" {
+			max = "max"
+		} else {
+			max = rng[1]
+		}
+		return &FilterData{field, ">=", min}, &FilterData{field, "<=", max}, nil
+	}
+	return nil, nil, nil
+}
+
 // Some standard value parsing functions.
 
 func ParseNumberValue(value string) (int64, error) {
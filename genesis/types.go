@@ -20,6 +20,7 @@ import (
 	"time"
 
 	ptypes "github.com/hyperledger/burrow/permission/types"
+	"github.com/hyperledger/burrow/txs"
 
 	"github.com/tendermint/go-crypto"
 	"github.com/tendermint/go-wire"
@@ -67,6 +68,8 @@ type GenesisPrivateValidator struct {
 
 type GenesisParams struct {
 	GlobalPermissions *ptypes.AccountPermissions `json:"global_permissions"`
+	// Chain parameters until governance changes them, the defaults when nil
+	ChainParams *txs.ChainParams `json:"chain_params"`
 }
 
 //------------------------------------------------------------
@@ -76,23 +76,28 @@ func (this *accounts) GenPrivAccountFromKey(privKey []byte) (
 	return pa, nil
 }
 
-// Get all accounts.
-func (this *accounts) Accounts(fda []*event.FilterData) (
-	*core_types.AccountList, error) {
+// Get a page of the accounts matching the filters, in address order.
+func (this *accounts) Accounts(fda []*event.FilterData,
+	page *core_types.PageRequest) (*core_types.AccountList, error) {
 	accounts := make([]*account.Account, 0)
 	state := this.burrowMint.GetState()
 	filter, err := this.filterFactory.NewFilter(fda)
 	if err != nil {
 		return nil, fmt.Errorf("Error in query: " + err.Error())
 	}
-	state.GetAccounts().Iterate(func(key, value []byte) bool {
-		acc := account.DecodeAccount(value)
-		if filter.Match(acc) {
+	nextCursor, err := iteratePage(state.GetAccounts(), page,
+		func(key, value []byte) bool {
+			acc := account.DecodeAccount(value)
+			if !filter.Match(acc) {
+				return false
+			}
 			accounts = append(accounts, acc)
-		}
-		return false
-	})
-	return &core_types.AccountList{accounts}, nil
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &core_types.AccountList{accounts, nextCursor}, nil
 }
 
 // Get an account.
@@ -124,24 +129,30 @@ func (this *accounts) StorageAt(address, key []byte) (*core_types.StorageItem,
 	return &core_types.StorageItem{key, value}, nil
 }
 
-// Get the storage of the account with address 'address'.
-func (this *accounts) Storage(address []byte) (*core_types.Storage, error) {
+// Get a page of the storage of the account with address 'address', in key
+// order.
+func (this *accounts) Storage(address []byte,
+	page *core_types.PageRequest) (*core_types.Storage, error) {
 
 	state := this.burrowMint.GetState()
 	account := state.GetAccount(address)
 	storageItems := make([]core_types.StorageItem, 0)
 	if account == nil {
-		return &core_types.Storage{nil, storageItems}, nil
+		return &core_types.Storage{nil, storageItems, ""}, nil
 	}
 	storageRoot := account.StorageRoot
 	storageTree := state.LoadStorage(storageRoot)
 
-	storageTree.Iterate(func(key, value []byte) bool {
-		storageItems = append(storageItems, core_types.StorageItem{
-			key, value})
-		return false
-	})
-	return &core_types.Storage{storageRoot, storageItems}, nil
+	nextCursor, err := iteratePage(storageTree, page,
+		func(key, value []byte) bool {
+			storageItems = append(storageItems, core_types.StorageItem{
+				key, value})
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &core_types.Storage{storageRoot, storageItems, nextCursor}, nil
 }
 
 // Create a new account.
@@ -55,6 +55,14 @@ type BurrowMint struct {
 // burrow/manager/types.Application
 var _ manager_types.Application = (*BurrowMint)(nil)
 
+// Compiler check to ensure BurrowMint successfully implements
+// burrow/manager/types.BlockchainAware
+var _ manager_types.BlockchainAware = (*BurrowMint)(nil)
+
+// Tendermint only calls InitChain, BeginBlock and EndBlock through the local
+// ABCI client when BurrowMint implements abci.BlockchainAware
+var _ abci.BlockchainAware = (*BurrowMint)(nil)
+
 // NOTE: [ben] also automatically implements abci.Application,
 // undesired but unharmful
 // var _ abci.Application = (*BurrowMint)(nil)
@@ -176,6 +184,41 @@ func (app *BurrowMint) Commit() (res abci.Result) {
 	return abci.NewResultOK(appHash, "Success")
 }
 
+// Implements manager/types.BlockchainAware
+// The genesis validators are already recorded in the state by
+// MakeGenesisState, so there is nothing to initialise here.
+func (app *BurrowMint) InitChain(validators []*abci.Validator) {
+	logging.TraceMsg(app.logger, "Initialising chain",
+		"validators", len(validators))
+}
+
+// Implements manager/types.BlockchainAware
+func (app *BurrowMint) BeginBlock(hash []byte, header *abci.Header) {
+}
+
+// Implements manager/types.BlockchainAware
+// EndBlock puts into effect the governance proposals for the next block,
+// releases the bonds of validators whose unbonding period is over and returns
+// to Tendermint the changes in voting power caused by the BondTx, UnbondTx,
+// RebondTx and DupeoutTx delivered in this block.
+// A power of zero removes the validator from the validator set.
+func (app *BurrowMint) EndBlock(height uint64) (res abci.ResponseEndBlock) {
+	sm.ApplyGovProposals(app.cache, int(height)+1)
+	sm.ReleaseValidators(app.cache, int(height))
+
+	for _, valInfo := range sm.ValidatorPowerChanges(app.cache) {
+		logging.InfoMsg(app.logger, "Updating validator voting power",
+			"height", height,
+			"address", fmt.Sprintf("%X", valInfo.Address),
+			"power", valInfo.Power())
+		res.Diffs = append(res.Diffs, &abci.Validator{
+			PubKey: valInfo.PubKey.Bytes(),
+			Power:  uint64(valInfo.Power()),
+		})
+	}
+	return res
+}
+
 func (app *BurrowMint) Query(query []byte) (res abci.Result) {
 	return abci.NewResultOK(nil, "Success")
 }
@@ -15,9 +15,21 @@
 package burrowmint
 
 import (
+	"sync"
 	"testing"
 
+	"github.com/hyperledger/burrow/genesis"
+	"github.com/hyperledger/burrow/logging/loggers"
+	sm "github.com/hyperledger/burrow/manager/burrow-mint/state"
+	"github.com/hyperledger/burrow/txs"
+
 	assert "github.com/stretchr/testify/assert"
+	require "github.com/stretchr/testify/require"
+	abcicli "github.com/tendermint/abci/client"
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+	dbm "github.com/tendermint/go-db"
+	tendermint_events "github.com/tendermint/go-events"
 )
 
 func TestCompatibleConsensus(t *testing.T) {
@@ -28,3 +40,36 @@ func TestCompatibleConsensus(t *testing.T) {
 		assert.Nil(t, AssertCompatibleConsensus(listedConsensus))
 	}
 }
+
+// Tendermint calls the BlockchainAware methods through the local ABCI client,
+// which skips them unless they match its interface
+func TestBlockchainAwareOverABCI(t *testing.T) {
+	pubKey := crypto.GenPrivKeyEd25519().PubKey()
+	genDoc := &genesis.GenesisDoc{
+		ChainID: "abci_test",
+		Validators: []genesis.GenesisValidator{
+			{PubKey: pubKey, Amount: 10, Name: "validator"},
+		},
+	}
+	app := NewBurrowMint(sm.MakeGenesisState(dbm.NewMemDB(), genDoc),
+		tendermint_events.NewEventSwitch(), loggers.NewNoopInfoTraceLogger())
+	client := abcicli.NewLocalClient(new(sync.Mutex), app)
+
+	// the validator unbonds and a proposal takes effect at the next block
+	valInfo := app.cache.GetValidatorInfo(pubKey.Address())
+	require.NotNil(t, valInfo)
+	valInfo.UnbondHeight = 1
+	app.cache.UpdateValidatorInfo(valInfo)
+	params := txs.DefaultChainParams()
+	params.UnbondingPeriodBlocks = 7
+	app.cache.SetGovProposals(2, []*txs.GovProposal{{Height: 1, Params: params}})
+
+	require.NoError(t, client.InitChainSync(nil))
+	require.NoError(t, client.BeginBlockSync([]byte("hash"), &abci.Header{Height: 1}))
+	res, err := client.EndBlockSync(1)
+	require.NoError(t, err)
+	require.Len(t, res.Diffs, 1)
+	assert.Equal(t, pubKey.Bytes(), res.Diffs[0].PubKey)
+	assert.Equal(t, uint64(0), res.Diffs[0].Power)
+	assert.Equal(t, 7, app.cache.GetChainParams().UnbondingPeriodBlocks)
+}
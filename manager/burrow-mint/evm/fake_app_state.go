@@ -17,13 +17,22 @@ package vm
 import (
 	"fmt"
 
+	core_types "github.com/hyperledger/burrow/core/types"
 	"github.com/hyperledger/burrow/manager/burrow-mint/evm/sha3"
+	ptypes "github.com/hyperledger/burrow/permission/types"
+	"github.com/hyperledger/burrow/txs"
 	. "github.com/hyperledger/burrow/word256"
 )
 
 type FakeAppState struct {
 	accounts map[string]*Account
 	storage  map[string]Word256
+	roles    map[string]*ptypes.BasePermissions
+	callACLs map[string]*ptypes.CallACL
+	grants   map[string]*ptypes.PermissionGrants
+	changes  []*ptypes.PermissionChange
+	names    map[string]*core_types.NameRegEntry
+	height   int
 }
 
 func (fas *FakeAppState) GetAccount(addr Word256) *Account {
@@ -83,6 +92,75 @@ func (fas *FakeAppState) SetStorage(addr Word256, key Word256, value Word256) {
 	fas.storage[addr.String()+key.String()] = value
 }
 
+func (fas *FakeAppState) GetRolePermissions(role string) *ptypes.BasePermissions {
+	return fas.roles[ptypes.PadRole(role)]
+}
+
+func (fas *FakeAppState) SetRolePermissions(role string, perms *ptypes.BasePermissions) {
+	if fas.roles == nil {
+		fas.roles = make(map[string]*ptypes.BasePermissions)
+	}
+	if perms == nil {
+		delete(fas.roles, ptypes.PadRole(role))
+		return
+	}
+	fas.roles[ptypes.PadRole(role)] = perms
+}
+
+func (fas *FakeAppState) GetCallACL(addr Word256) *ptypes.CallACL {
+	return fas.callACLs[addr.String()]
+}
+
+func (fas *FakeAppState) SetCallACL(addr Word256, acl *ptypes.CallACL) {
+	if fas.callACLs == nil {
+		fas.callACLs = make(map[string]*ptypes.CallACL)
+	}
+	if acl.IsEmpty() {
+		delete(fas.callACLs, addr.String())
+		return
+	}
+	fas.callACLs[addr.String()] = acl
+}
+
+func (fas *FakeAppState) GetPermissionGrants(addr Word256) *ptypes.PermissionGrants {
+	return fas.grants[addr.String()]
+}
+
+func (fas *FakeAppState) SetPermissionGrants(addr Word256, grants *ptypes.PermissionGrants) {
+	if fas.grants == nil {
+		fas.grants = make(map[string]*ptypes.PermissionGrants)
+	}
+	if grants.IsEmpty() {
+		delete(fas.grants, addr.String())
+		return
+	}
+	fas.grants[addr.String()] = grants
+}
+
+func (fas *FakeAppState) BlockHeight() int {
+	return fas.height
+}
+
+func (fas *FakeAppState) AppendPermissionChange(change *ptypes.PermissionChange) {
+	change.Index = len(fas.changes)
+	fas.changes = append(fas.changes, change)
+}
+
+func (fas *FakeAppState) GetNameRegEntry(name string) *core_types.NameRegEntry {
+	return fas.names[name]
+}
+
+func (fas *FakeAppState) UpdateNameRegEntry(entry *core_types.NameRegEntry) {
+	if fas.names == nil {
+		fas.names = make(map[string]*core_types.NameRegEntry)
+	}
+	fas.names[entry.Name] = entry
+}
+
+func (fas *FakeAppState) GetChainParams() *txs.ChainParams {
+	return txs.DefaultChainParams()
+}
+
 // Creates a 20 byte address and bumps the nonce.
 func createAddress(creator *Account) Word256 {
 	nonce := creator.Nonce
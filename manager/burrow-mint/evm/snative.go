@@ -15,11 +15,15 @@
 package vm
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 
 	"github.com/hyperledger/burrow/common/sanity"
+	core_types "github.com/hyperledger/burrow/core/types"
 	"github.com/hyperledger/burrow/manager/burrow-mint/evm/sha3"
 	ptypes "github.com/hyperledger/burrow/permission/types"
+	"github.com/hyperledger/burrow/txs"
 	. "github.com/hyperledger/burrow/word256"
 
 	"strings"
@@ -182,6 +186,259 @@ func SNativeContracts() map[string]*SNativeContractDescription {
 				ret("result", permFlagTypeName),
 				ptypes.SetGlobal,
 				setGlobal},
+
+			&SNativeFunctionDescription{`
+			* @notice Sets permission flags granted to every account holding a role. Makes them explicitly set (on or off) for the role.
+			* @param _role role name
+			* @param _permission the permissions flags to set for the role
+			* @param _set whether to set or unset the permissions flags at the role level
+			* @return result the permissions flags set on (and granted by) the role after the call
+			`,
+				"setRolePermission",
+				[]abi.Arg{
+					arg("_role", roleTypeName),
+					arg("_permission", permFlagTypeName),
+					arg("_set", abi.BoolTypeName)},
+				ret("result", permFlagTypeName),
+				ptypes.SetGlobal,
+				setRolePermission},
+
+			&SNativeFunctionDescription{`
+			* @notice Unsets permission flags for a role. Causes them to fall through to global permissions for accounts holding the role.
+			* @param _role role name
+			* @param _permission the permissions flags to unset for the role
+			* @return result the permissions flags set on (and granted by) the role after the call
+			`,
+				"unsetRolePermission",
+				[]abi.Arg{
+					arg("_role", roleTypeName),
+					arg("_permission", permFlagTypeName)},
+				ret("result", permFlagTypeName),
+				ptypes.SetGlobal,
+				unsetRolePermission},
+
+			&SNativeFunctionDescription{`
+			* @notice Indicates whether a role grants a subset of permissions
+			* @param _role role name
+			* @param _permission the permissions flags (mask) to check whether granted by the role
+			* @return result whether the role grants the passed permissions flags
+			`,
+				"hasRolePermission",
+				[]abi.Arg{
+					arg("_role", roleTypeName),
+					arg("_permission", permFlagTypeName)},
+				ret("result", abi.BoolTypeName),
+				ptypes.HasBase,
+				hasRolePermission},
+
+			&SNativeFunctionDescription{`
+			* @notice Restricts an account to calling only the contracts it has been allowed to call, and allows it to call a contract
+			* @param _account account address
+			* @param _callee contract address
+			* @return result whether the contract was added to the allowed callees
+			`,
+				"addCallee",
+				[]abi.Arg{
+					arg("_account", abi.AddressTypeName),
+					arg("_callee", abi.AddressTypeName)},
+				ret("result", abi.BoolTypeName),
+				ptypes.SetBase,
+				addCallee},
+
+			&SNativeFunctionDescription{`
+			* @notice Removes a contract from those an account is allowed to call. Removing the last leaves the account allowed to call no contracts.
+			* @param _account account address
+			* @param _callee contract address
+			* @return result whether the contract was removed from the allowed callees
+			`,
+				"removeCallee",
+				[]abi.Arg{
+					arg("_account", abi.AddressTypeName),
+					arg("_callee", abi.AddressTypeName)},
+				ret("result", abi.BoolTypeName),
+				ptypes.SetBase,
+				removeCallee},
+
+			&SNativeFunctionDescription{`
+			* @notice Restricts a contract to being called only by the accounts and role holders it has been allowed to be called by, and allows an account to call it
+			* @param _contract contract address
+			* @param _caller account address
+			* @return result whether the account was added to the allowed callers
+			`,
+				"addCaller",
+				[]abi.Arg{
+					arg("_contract", abi.AddressTypeName),
+					arg("_caller", abi.AddressTypeName)},
+				ret("result", abi.BoolTypeName),
+				ptypes.SetBase,
+				addCaller},
+
+			&SNativeFunctionDescription{`
+			* @notice Removes an account from those allowed to call a contract. Removing the last caller and caller role leaves the contract callable by no account.
+			* @param _contract contract address
+			* @param _caller account address
+			* @return result whether the account was removed from the allowed callers
+			`,
+				"removeCaller",
+				[]abi.Arg{
+					arg("_contract", abi.AddressTypeName),
+					arg("_caller", abi.AddressTypeName)},
+				ret("result", abi.BoolTypeName),
+				ptypes.SetBase,
+				removeCaller},
+
+			&SNativeFunctionDescription{`
+			* @notice Restricts a contract to being called only by the accounts and role holders it has been allowed to be called by, and allows holders of a role to call it
+			* @param _contract contract address
+			* @param _role role name
+			* @return result whether the role was added to the allowed caller roles
+			`,
+				"addCallerRole",
+				[]abi.Arg{
+					arg("_contract", abi.AddressTypeName),
+					arg("_role", roleTypeName)},
+				ret("result", abi.BoolTypeName),
+				ptypes.SetBase,
+				addCallerRole},
+
+			&SNativeFunctionDescription{`
+			* @notice Removes a role from those whose holders are allowed to call a contract. Removing the last caller and caller role leaves the contract callable by no account.
+			* @param _contract contract address
+			* @param _role role name
+			* @return result whether the role was removed from the allowed caller roles
+			`,
+				"removeCallerRole",
+				[]abi.Arg{
+					arg("_contract", abi.AddressTypeName),
+					arg("_role", roleTypeName)},
+				ret("result", abi.BoolTypeName),
+				ptypes.SetBase,
+				removeCallerRole},
+
+			&SNativeFunctionDescription{`
+			* @notice Sets the permission flags for an account until a block height, after which they are treated as unset
+			* @param _account account address
+			* @param _permission the base permissions flags to set for the account
+			* @param _set whether to set or unset the permissions flags at the account level
+			* @param _height last block height at which the permissions flags are set
+			* @return result the effective permissions flags on the account after the call
+			`,
+				"setBaseUntil",
+				[]abi.Arg{
+					arg("_account", abi.AddressTypeName),
+					arg("_permission", permFlagTypeName),
+					arg("_set", abi.BoolTypeName),
+					arg("_height", abi.Uint64TypeName),
+				},
+				ret("result", permFlagTypeName),
+				ptypes.SetBase,
+				setBaseUntil},
+
+			&SNativeFunctionDescription{`
+			* @notice Adds a role to an account until a block height, after which the account no longer holds it
+			* @param _account account address
+			* @param _role role name
+			* @param _height last block height at which the account holds the role
+			* @return result whether role was added or its expiry changed
+			`,
+				"addRoleUntil",
+				[]abi.Arg{
+					arg("_account", abi.AddressTypeName),
+					arg("_role", roleTypeName),
+					arg("_height", abi.Uint64TypeName),
+				},
+				ret("result", abi.BoolTypeName),
+				ptypes.AddRole,
+				addRoleUntil},
+
+			&SNativeFunctionDescription{`
+			* @notice Lets another account exercise one of the caller's own permissions for as long as the caller has it
+			* @param _delegate account address
+			* @param _permission the single permission flag to delegate
+			* @param _height last block height at which the delegation is valid, or 0 for no expiry
+			* @return result whether the delegation is new (rather than replacing an existing one)
+			`,
+				"delegatePermission",
+				[]abi.Arg{
+					arg("_delegate", abi.AddressTypeName),
+					arg("_permission", permFlagTypeName),
+					arg("_height", abi.Uint64TypeName),
+				},
+				ret("result", abi.BoolTypeName),
+				ptypes.SetBase,
+				delegatePermission},
+
+			&SNativeFunctionDescription{`
+			* @notice Revokes a permission the caller delegated to another account
+			* @param _delegate account address
+			* @param _permission the permission flag delegated
+			* @return result whether a delegation was revoked
+			`,
+				"revokeDelegation",
+				[]abi.Arg{
+					arg("_delegate", abi.AddressTypeName),
+					arg("_permission", permFlagTypeName)},
+				ret("result", abi.BoolTypeName),
+				ptypes.SetBase,
+				revokeDelegation},
+		),
+
+		NewSNativeContract(`
+		* Interface for the name registry.
+		* @dev This interface describes the functions exposed by the name registry in burrow. Names and data are right-padded bytes32.
+		`,
+			"NameReg",
+			&SNativeFunctionDescription{`
+			* @notice Gets the data registered under a name
+			* @param _name name
+			* @return result the data registered under the name (decoded if it is base64 encoded bytes), or empty if the name is not registered
+			`,
+				"resolve",
+				[]abi.Arg{
+					arg("_name", abi.Bytes32TypeName)},
+				ret("result", abi.BytesTypeName),
+				ptypes.Name,
+				resolveName},
+
+			&SNativeFunctionDescription{`
+			* @notice Gets the owner of a name
+			* @param _name name
+			* @return result the address owning the name, or zero if the name is not registered
+			`,
+				"owner",
+				[]abi.Arg{
+					arg("_name", abi.Bytes32TypeName)},
+				ret("result", abi.AddressTypeName),
+				ptypes.Name,
+				nameOwner},
+
+			&SNativeFunctionDescription{`
+			* @notice Gets the block height at which a name expires
+			* @param _name name
+			* @return result the expiry height of the name, or zero if the name is not registered
+			`,
+				"expires",
+				[]abi.Arg{
+					arg("_name", abi.Bytes32TypeName)},
+				ret("result", abi.Uint64TypeName),
+				ptypes.Name,
+				nameExpires},
+
+			&SNativeFunctionDescription{`
+			* @notice Registers data under a name owned by the caller, or updates a name the caller controls. The cost of each block is charged to the caller's balance.
+			* @param _name name
+			* @param _data data to register under the name
+			* @param _blocks number of blocks to pay for
+			* @return result the expiry height of the name after the call
+			`,
+				"register",
+				[]abi.Arg{
+					arg("_name", abi.Bytes32TypeName),
+					arg("_data", abi.Bytes32TypeName),
+					arg("_blocks", abi.Uint64TypeName)},
+				ret("result", abi.Uint64TypeName),
+				ptypes.Name,
+				registerName},
 		),
 	}
 
@@ -358,6 +615,19 @@ func hasBase(appState AppState, caller *Account, args []byte, gas *int64) (outpu
 
 func setBase(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
 	addr, permNum, permVal := returnThreeArgs(args)
+	return setBaseUntilHeight(appState, caller, "setBase", addr, permNum, permVal, 0)
+}
+
+func setBaseUntil(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	addr, permNum, permVal, height := returnFourArgs(args)
+	return setBaseUntilHeight(appState, caller, "setBaseUntil", addr, permNum, permVal,
+		int(Uint64FromWord256(height)))
+}
+
+// Sets the permissions flags for an account, valid until validUntilHeight if
+// it is not zero
+func setBaseUntilHeight(appState AppState, caller *Account, function string,
+	addr, permNum, permVal Word256, validUntilHeight int) (output []byte, err error) {
 	vmAcc := appState.GetAccount(addr)
 	if vmAcc == nil {
 		return nil, fmt.Errorf("Unknown account %X", addr)
@@ -366,12 +636,27 @@ func setBase(appState AppState, caller *Account, args []byte, gas *int64) (outpu
 	if !ValidPermN(permN) {
 		return nil, ptypes.ErrInvalidPermission(permN)
 	}
+	if ptypes.GrantExpiredAt(validUntilHeight, appState.BlockHeight()) {
+		return nil, fmt.Errorf("Expiry height %v has already passed", validUntilHeight)
+	}
 	permV := !permVal.IsZero()
+	oldV := ptypes.PermValueOf(&vmAcc.Permissions.Base, permN)
 	if err = vmAcc.Permissions.Base.Set(permN, permV); err != nil {
 		return nil, err
 	}
 	appState.UpdateAccount(vmAcc)
-	dbg.Printf("snative.setBasePerm(0x%X, %b, %v)\n", addr.Postfix(20), permN, permV)
+	updatePermissionGrants(appState, addr, func(grants *ptypes.PermissionGrants) {
+		grants.SetBaseExpiry(permN, validUntilHeight)
+	})
+	recordPermissionChange(appState, caller, &ptypes.PermissionChange{
+		Target:           addr.Postfix(20),
+		Function:         function,
+		Permission:       permN,
+		OldValue:         oldV,
+		NewValue:         ptypes.PermValueFromBool(permV),
+		ValidUntilHeight: validUntilHeight,
+	})
+	dbg.Printf("snative.%s(0x%X, %b, %v, %v)\n", function, addr.Postfix(20), permN, permV, validUntilHeight)
 	return effectivePermBytes(vmAcc.Permissions.Base, globalPerms(appState)), nil
 }
 
@@ -385,10 +670,21 @@ func unsetBase(appState AppState, caller *Account, args []byte, gas *int64) (out
 	if !ValidPermN(permN) {
 		return nil, ptypes.ErrInvalidPermission(permN)
 	}
+	oldV := ptypes.PermValueOf(&vmAcc.Permissions.Base, permN)
 	if err = vmAcc.Permissions.Base.Unset(permN); err != nil {
 		return nil, err
 	}
 	appState.UpdateAccount(vmAcc)
+	updatePermissionGrants(appState, addr, func(grants *ptypes.PermissionGrants) {
+		grants.SetBaseExpiry(permN, 0)
+	})
+	recordPermissionChange(appState, caller, &ptypes.PermissionChange{
+		Target:     addr.Postfix(20),
+		Function:   "unsetBase",
+		Permission: permN,
+		OldValue:   oldV,
+		NewValue:   ptypes.PermValueUnset,
+	})
 	dbg.Printf("snative.unsetBasePerm(0x%X, %b)\n", addr.Postfix(20), permN)
 	return effectivePermBytes(vmAcc.Permissions.Base, globalPerms(appState)), nil
 }
@@ -404,10 +700,18 @@ func setGlobal(appState AppState, caller *Account, args []byte, gas *int64) (out
 		return nil, ptypes.ErrInvalidPermission(permN)
 	}
 	permV := !permVal.IsZero()
+	oldV := ptypes.PermValueOf(&vmAcc.Permissions.Base, permN)
 	if err = vmAcc.Permissions.Base.Set(permN, permV); err != nil {
 		return nil, err
 	}
 	appState.UpdateAccount(vmAcc)
+	recordPermissionChange(appState, caller, &ptypes.PermissionChange{
+		Target:     ptypes.GlobalPermissionsAddress,
+		Function:   "setGlobal",
+		Permission: permN,
+		OldValue:   oldV,
+		NewValue:   ptypes.PermValueFromBool(permV),
+	})
 	dbg.Printf("snative.setGlobalPerm(%b, %v)\n", permN, permV)
 	return permBytes(vmAcc.Permissions.Base.ResultantPerms()), nil
 }
@@ -419,21 +723,56 @@ func hasRole(appState AppState, caller *Account, args []byte, gas *int64) (outpu
 		return nil, fmt.Errorf("Unknown account %X", addr)
 	}
 	roleS := string(role.Bytes())
-	permInt := byteFromBool(vmAcc.Permissions.HasRole(roleS))
+	permInt := byteFromBool(vmAcc.Permissions.HasRoleAt(roleS, appState.GetPermissionGrants(addr),
+		appState.BlockHeight()))
 	dbg.Printf("snative.hasRole(0x%X, %s) = %v\n", addr.Postfix(20), roleS, permInt > 0)
 	return LeftPadWord256([]byte{permInt}).Bytes(), nil
 }
 
 func addRole(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
 	addr, role := returnTwoArgs(args)
+	return addRoleUntilHeight(appState, caller, "addRole", addr, role, 0)
+}
+
+func addRoleUntil(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	addr, role, height := returnThreeArgs(args)
+	return addRoleUntilHeight(appState, caller, "addRoleUntil", addr, role, int(Uint64FromWord256(height)))
+}
+
+// Adds a role to an account, valid until validUntilHeight if it is not zero.
+// The expiry of a held role is replaced, so without one a role held until some
+// height is made permanent. A role held without an expiry is left as it is.
+func addRoleUntilHeight(appState AppState, caller *Account, function string,
+	addr, role Word256, validUntilHeight int) (output []byte, err error) {
 	vmAcc := appState.GetAccount(addr)
 	if vmAcc == nil {
 		return nil, fmt.Errorf("Unknown account %X", addr)
 	}
+	height := appState.BlockHeight()
+	if ptypes.GrantExpiredAt(validUntilHeight, height) {
+		return nil, fmt.Errorf("Expiry height %v has already passed", validUntilHeight)
+	}
 	roleS := string(role.Bytes())
-	permInt := byteFromBool(vmAcc.Permissions.AddRole(roleS))
+	grants := appState.GetPermissionGrants(addr)
+	held := vmAcc.Permissions.HasRoleAt(roleS, grants, height)
+	vmAcc.Permissions.AddRole(roleS)
+	added := !held || validUntilHeight != 0 || grants.RoleExpiry(roleS) != 0
+	permInt := byteFromBool(added)
 	appState.UpdateAccount(vmAcc)
-	dbg.Printf("snative.addRole(0x%X, %s) = %v\n", addr.Postfix(20), roleS, permInt > 0)
+	if added {
+		updatePermissionGrants(appState, addr, func(grants *ptypes.PermissionGrants) {
+			grants.SetRoleExpiry(roleS, validUntilHeight)
+		})
+		recordPermissionChange(appState, caller, &ptypes.PermissionChange{
+			Target:           addr.Postfix(20),
+			Function:         function,
+			Role:             roleS,
+			OldValue:         ptypes.PermValueFromBool(held),
+			NewValue:         ptypes.PermValueTrue,
+			ValidUntilHeight: validUntilHeight,
+		})
+	}
+	dbg.Printf("snative.%s(0x%X, %s, %v) = %v\n", function, addr.Postfix(20), roleS, validUntilHeight, permInt > 0)
 	return LeftPadWord256([]byte{permInt}).Bytes(), nil
 }
 
@@ -444,15 +783,414 @@ func removeRole(appState AppState, caller *Account, args []byte, gas *int64) (ou
 		return nil, fmt.Errorf("Unknown account %X", addr)
 	}
 	roleS := string(role.Bytes())
-	permInt := byteFromBool(vmAcc.Permissions.RmRole(roleS))
+	removed := vmAcc.Permissions.RmRole(roleS)
+	permInt := byteFromBool(removed)
 	appState.UpdateAccount(vmAcc)
+	if removed {
+		updatePermissionGrants(appState, addr, func(grants *ptypes.PermissionGrants) {
+			grants.SetRoleExpiry(roleS, 0)
+		})
+		recordPermissionChange(appState, caller, &ptypes.PermissionChange{
+			Target:   addr.Postfix(20),
+			Function: "removeRole",
+			Role:     roleS,
+			OldValue: ptypes.PermValueTrue,
+			NewValue: ptypes.PermValueFalse,
+		})
+	}
 	dbg.Printf("snative.rmRole(0x%X, %s) = %v\n", addr.Postfix(20), roleS, permInt > 0)
 	return LeftPadWord256([]byte{permInt}).Bytes(), nil
 }
 
+func setRolePermission(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	role, permNum, permVal := returnThreeArgs(args)
+	permN := ptypes.PermFlag(Uint64FromWord256(permNum))
+	if !ValidPermN(permN) {
+		return nil, ptypes.ErrInvalidPermission(permN)
+	}
+	roleS := string(role.Bytes())
+	rolePerms := ptypes.ZeroBasePermissions
+	if perms := appState.GetRolePermissions(roleS); perms != nil {
+		rolePerms = *perms
+	}
+	permV := !permVal.IsZero()
+	oldV := ptypes.PermValueOf(&rolePerms, permN)
+	if err = rolePerms.Set(permN, permV); err != nil {
+		return nil, err
+	}
+	appState.SetRolePermissions(roleS, &rolePerms)
+	recordPermissionChange(appState, caller, &ptypes.PermissionChange{
+		Function:   "setRolePermission",
+		Permission: permN,
+		Role:       roleS,
+		OldValue:   oldV,
+		NewValue:   ptypes.PermValueFromBool(permV),
+	})
+	dbg.Printf("snative.setRolePermission(%s, %b, %v)\n", roleS, permN, permV)
+	return permBytes(rolePerms.ResultantPerms()), nil
+}
+
+func unsetRolePermission(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	role, permNum := returnTwoArgs(args)
+	permN := ptypes.PermFlag(Uint64FromWord256(permNum))
+	if !ValidPermN(permN) {
+		return nil, ptypes.ErrInvalidPermission(permN)
+	}
+	roleS := string(role.Bytes())
+	perms := appState.GetRolePermissions(roleS)
+	if perms == nil {
+		return nil, fmt.Errorf("Unknown role %s", roleS)
+	}
+	rolePerms := *perms
+	oldV := ptypes.PermValueOf(&rolePerms, permN)
+	if err = rolePerms.Unset(permN); err != nil {
+		return nil, err
+	}
+	if rolePerms.SetBit == 0 {
+		appState.SetRolePermissions(roleS, nil)
+	} else {
+		appState.SetRolePermissions(roleS, &rolePerms)
+	}
+	recordPermissionChange(appState, caller, &ptypes.PermissionChange{
+		Function:   "unsetRolePermission",
+		Permission: permN,
+		Role:       roleS,
+		OldValue:   oldV,
+		NewValue:   ptypes.PermValueUnset,
+	})
+	dbg.Printf("snative.unsetRolePermission(%s, %b)\n", roleS, permN)
+	return permBytes(rolePerms.ResultantPerms()), nil
+}
+
+func hasRolePermission(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	role, permNum := returnTwoArgs(args)
+	permN := ptypes.PermFlag(Uint64FromWord256(permNum))
+	if !ValidPermN(permN) {
+		return nil, ptypes.ErrInvalidPermission(permN)
+	}
+	roleS := string(role.Bytes())
+	var permInt byte
+	if perms := appState.GetRolePermissions(roleS); perms != nil {
+		v, _ := perms.Get(permN)
+		permInt = byteFromBool(v)
+	}
+	dbg.Printf("snative.hasRolePermission(%s, %b) = %v\n", roleS, permN, permInt > 0)
+	return LeftPadWord256([]byte{permInt}).Bytes(), nil
+}
+
+func addCallee(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	addr, callee := returnTwoArgs(args)
+	dbg.Printf("snative.addCallee(0x%X, 0x%X)\n", addr.Postfix(20), callee.Postfix(20))
+	return updateCallACL(appState, caller, &ptypes.PermissionChange{
+		Target:   addr.Postfix(20),
+		Function: "addCallee",
+		Subject:  callee.Postfix(20),
+		OldValue: ptypes.PermValueFalse,
+		NewValue: ptypes.PermValueTrue,
+	}, func(acl *ptypes.CallACL) bool {
+		return acl.AddCallee(callee.Postfix(20))
+	})
+}
+
+func removeCallee(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	addr, callee := returnTwoArgs(args)
+	dbg.Printf("snative.removeCallee(0x%X, 0x%X)\n", addr.Postfix(20), callee.Postfix(20))
+	return updateCallACL(appState, caller, &ptypes.PermissionChange{
+		Target:   addr.Postfix(20),
+		Function: "removeCallee",
+		Subject:  callee.Postfix(20),
+		OldValue: ptypes.PermValueTrue,
+		NewValue: ptypes.PermValueFalse,
+	}, func(acl *ptypes.CallACL) bool {
+		return acl.RmCallee(callee.Postfix(20))
+	})
+}
+
+func addCaller(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	addr, aclCaller := returnTwoArgs(args)
+	dbg.Printf("snative.addCaller(0x%X, 0x%X)\n", addr.Postfix(20), aclCaller.Postfix(20))
+	return updateCallACL(appState, caller, &ptypes.PermissionChange{
+		Target:   addr.Postfix(20),
+		Function: "addCaller",
+		Subject:  aclCaller.Postfix(20),
+		OldValue: ptypes.PermValueFalse,
+		NewValue: ptypes.PermValueTrue,
+	}, func(acl *ptypes.CallACL) bool {
+		return acl.AddCaller(aclCaller.Postfix(20))
+	})
+}
+
+func removeCaller(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	addr, aclCaller := returnTwoArgs(args)
+	dbg.Printf("snative.removeCaller(0x%X, 0x%X)\n", addr.Postfix(20), aclCaller.Postfix(20))
+	return updateCallACL(appState, caller, &ptypes.PermissionChange{
+		Target:   addr.Postfix(20),
+		Function: "removeCaller",
+		Subject:  aclCaller.Postfix(20),
+		OldValue: ptypes.PermValueTrue,
+		NewValue: ptypes.PermValueFalse,
+	}, func(acl *ptypes.CallACL) bool {
+		return acl.RmCaller(aclCaller.Postfix(20))
+	})
+}
+
+func addCallerRole(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	addr, role := returnTwoArgs(args)
+	roleS := string(role.Bytes())
+	dbg.Printf("snative.addCallerRole(0x%X, %s)\n", addr.Postfix(20), roleS)
+	return updateCallACL(appState, caller, &ptypes.PermissionChange{
+		Target:   addr.Postfix(20),
+		Function: "addCallerRole",
+		Role:     roleS,
+		OldValue: ptypes.PermValueFalse,
+		NewValue: ptypes.PermValueTrue,
+	}, func(acl *ptypes.CallACL) bool {
+		return acl.AddCallerRole(roleS)
+	})
+}
+
+func removeCallerRole(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	addr, role := returnTwoArgs(args)
+	roleS := string(role.Bytes())
+	dbg.Printf("snative.removeCallerRole(0x%X, %s)\n", addr.Postfix(20), roleS)
+	return updateCallACL(appState, caller, &ptypes.PermissionChange{
+		Target:   addr.Postfix(20),
+		Function: "removeCallerRole",
+		Role:     roleS,
+		OldValue: ptypes.PermValueTrue,
+		NewValue: ptypes.PermValueFalse,
+	}, func(acl *ptypes.CallACL) bool {
+		return acl.RmCallerRole(roleS)
+	})
+}
+
+func delegatePermission(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	delegate, permNum, height := returnThreeArgs(args)
+	if appState.GetAccount(delegate) == nil {
+		return nil, fmt.Errorf("Unknown account %X", delegate)
+	}
+	permN := ptypes.PermFlag(Uint64FromWord256(permNum))
+	if !ptypes.IsSinglePermFlag(permN) {
+		return nil, fmt.Errorf("Only a single permission can be delegated, not %b", permN)
+	}
+	validUntilHeight := int(Uint64FromWord256(height))
+	if ptypes.GrantExpiredAt(validUntilHeight, appState.BlockHeight()) {
+		return nil, fmt.Errorf("Expiry height %v has already passed", validUntilHeight)
+	}
+	// delegated permissions cannot be delegated again
+	if !hasOwnPermission(appState, caller, permN) {
+		return nil, fmt.Errorf("Account %X cannot delegate permission %s it does not have",
+			caller.Address.Postfix(20), ptypes.PermFlagToString(permN))
+	}
+	added := false
+	updatePermissionGrants(appState, delegate, func(grants *ptypes.PermissionGrants) {
+		added = grants.Delegate(caller.Address.Postfix(20), permN, validUntilHeight)
+	})
+	recordPermissionChange(appState, caller, &ptypes.PermissionChange{
+		Target:           delegate.Postfix(20),
+		Function:         "delegatePermission",
+		Permission:       permN,
+		OldValue:         ptypes.PermValueFromBool(!added),
+		NewValue:         ptypes.PermValueTrue,
+		ValidUntilHeight: validUntilHeight,
+	})
+	dbg.Printf("snative.delegatePermission(0x%X, %b, %v) = %v\n", delegate.Postfix(20), permN,
+		validUntilHeight, added)
+	return LeftPadWord256([]byte{byteFromBool(added)}).Bytes(), nil
+}
+
+func revokeDelegation(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	delegate, permNum := returnTwoArgs(args)
+	permN := ptypes.PermFlag(Uint64FromWord256(permNum))
+	revoked := false
+	updatePermissionGrants(appState, delegate, func(grants *ptypes.PermissionGrants) {
+		revoked = grants.RevokeDelegation(caller.Address.Postfix(20), permN)
+	})
+	if revoked {
+		recordPermissionChange(appState, caller, &ptypes.PermissionChange{
+			Target:     delegate.Postfix(20),
+			Function:   "revokeDelegation",
+			Permission: permN,
+			OldValue:   ptypes.PermValueTrue,
+			NewValue:   ptypes.PermValueFalse,
+		})
+	}
+	dbg.Printf("snative.revokeDelegation(0x%X, %b) = %v\n", delegate.Postfix(20), permN, revoked)
+	return LeftPadWord256([]byte{byteFromBool(revoked)}).Bytes(), nil
+}
+
+//------------------------------------------------------------------------------------------------
+// Name registry
+
+func resolveName(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	name := nameFromWord256(LeftPadWord256(args))
+	entry := activeNameRegEntry(appState, name)
+	if entry == nil {
+		return abiEncodeBytes(nil), nil
+	}
+	data := []byte(entry.Data)
+	// contracts get the bytes themselves rather than their encoding
+	if entry.DataType == core_types.NameDataBytes {
+		if data, err = base64.StdEncoding.DecodeString(entry.Data); err != nil {
+			return nil, err
+		}
+	}
+	dbg.Printf("snative.resolve(%s) = %X\n", name, data)
+	return abiEncodeBytes(data), nil
+}
+
+// Encodes data as the ABI encodes a single dynamic bytes value: the offset of
+// the value, its length and then the data right-padded to a whole number of
+// words
+func abiEncodeBytes(data []byte) []byte {
+	words := (len(data) + Word256Length - 1) / Word256Length
+	output := make([]byte, 2*Word256Length, (2+words)*Word256Length)
+	copy(output, Uint64ToWord256(Word256Length).Bytes())
+	copy(output[Word256Length:], Uint64ToWord256(uint64(len(data))).Bytes())
+	output = append(output, data...)
+	return append(output, make([]byte, cap(output)-len(output))...)
+}
+
+func nameOwner(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	name := nameFromWord256(LeftPadWord256(args))
+	entry := activeNameRegEntry(appState, name)
+	if entry == nil {
+		return Zero256.Bytes(), nil
+	}
+	dbg.Printf("snative.owner(%s) = %X\n", name, entry.Owner)
+	return LeftPadWord256(entry.Owner).Bytes(), nil
+}
+
+func nameExpires(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	name := nameFromWord256(LeftPadWord256(args))
+	entry := activeNameRegEntry(appState, name)
+	if entry == nil {
+		return Zero256.Bytes(), nil
+	}
+	dbg.Printf("snative.expires(%s) = %v\n", name, entry.Expires)
+	return Uint64ToWord256(uint64(entry.Expires)).Bytes(), nil
+}
+
+// Follows the rules for a NameTx: the caller may register a name that is not
+// registered (and not beneath a name it does not own) or has expired, and
+// update a name it owns or controls through a name above it
+func registerName(appState AppState, caller *Account, args []byte, gas *int64) (output []byte, err error) {
+	nameWord, dataWord, blocksWord := returnThreeArgs(args)
+	name := nameFromWord256(nameWord)
+	data := nameFromWord256(dataWord)
+	blocks := int64(Uint64FromWord256(blocksWord))
+	if err := txs.ValidateNameRegEntry(name, data, core_types.NameDataPlain); err != nil {
+		return nil, err
+	}
+
+	params := appState.GetChainParams()
+	costPerBlock := params.NameCostPerBlock(txs.NameBaseCost(name, data))
+	// checked before multiplying so a large _blocks cannot overflow the cost
+	if blocks < 0 || blocks > caller.Balance/costPerBlock {
+		return nil, ErrInsufficientBalance
+	}
+	cost := blocks * costPerBlock
+
+	lastBlockHeight := appState.BlockHeight() - 1
+	address := caller.Address.Postfix(20)
+	entry := activeNameRegEntry(appState, name)
+	if entry != nil {
+		if !bytes.Equal(entry.Owner, address) &&
+			!txs.OwnsNameAncestor(appState, name, address, lastBlockHeight) &&
+			!HasPermission(appState, caller, ptypes.NameAdmin) {
+			return nil, fmt.Errorf("Account %X does not control name %s", address, name)
+		}
+		// since the size of the data may have changed
+		// we use the total amount of "credit"
+		oldCredit := int64(entry.Expires-lastBlockHeight) * txs.NameBaseCost(entry.Name, entry.Data)
+		expiresIn := int((oldCredit + cost) / costPerBlock)
+		if expiresIn < params.MinNameRegistrationPeriod {
+			return nil, fmt.Errorf("Names must be registered for at least %d blocks",
+				params.MinNameRegistrationPeriod)
+		}
+		entry = &core_types.NameRegEntry{
+			Name:    name,
+			Owner:   entry.Owner,
+			Data:    data,
+			Expires: lastBlockHeight + expiresIn,
+		}
+	} else {
+		if blocks < int64(params.MinNameRegistrationPeriod) {
+			return nil, fmt.Errorf("Names must be registered for at least %d blocks",
+				params.MinNameRegistrationPeriod)
+		}
+		if ancestor := txs.ActiveNameAncestor(appState, name, lastBlockHeight); ancestor != nil &&
+			!txs.OwnsNameAncestor(appState, name, address, lastBlockHeight) &&
+			!HasPermission(appState, caller, ptypes.NameAdmin) {
+			return nil, fmt.Errorf("Account %X cannot register %s beneath %s which it does not own",
+				address, name, ancestor.Name)
+		}
+		entry = &core_types.NameRegEntry{
+			Name:    name,
+			Owner:   address,
+			Data:    data,
+			Expires: lastBlockHeight + int(blocks),
+		}
+	}
+
+	caller.Balance -= cost
+	appState.UpdateAccount(caller)
+	appState.UpdateNameRegEntry(entry)
+	dbg.Printf("snative.register(%s, %s, %v) = %v\n", name, data, blocks, entry.Expires)
+	return Uint64ToWord256(uint64(entry.Expires)).Bytes(), nil
+}
+
+// Returns the entry registered under name if it has not expired
+func activeNameRegEntry(appState AppState, name string) *core_types.NameRegEntry {
+	entry := appState.GetNameRegEntry(name)
+	if entry == nil || entry.Expires <= appState.BlockHeight()-1 {
+		return nil
+	}
+	return entry
+}
+
+// Names and data are passed as right-padded bytes32
+func nameFromWord256(word Word256) string {
+	return string(bytes.TrimRight(word.Bytes(), "\x00"))
+}
+
 //------------------------------------------------------------------------------------------------
 // Errors and utility funcs
 
+// Applies update to a copy of the permission grants held by addr and sets them
+func updatePermissionGrants(appState AppState, addr Word256, update func(grants *ptypes.PermissionGrants)) {
+	grants := appState.GetPermissionGrants(addr).Copy()
+	existed := grants != nil
+	if !existed {
+		grants = &ptypes.PermissionGrants{}
+	}
+	update(grants)
+	// avoid writing to the app state when there were and are no grants
+	if existed || !grants.IsEmpty() {
+		appState.SetPermissionGrants(addr, grants)
+	}
+}
+
+// Applies update to a copy of the call ACL of the change's target, storing it
+// and recording the change if it changed. Returns whether it changed.
+func updateCallACL(appState AppState, caller *Account, change *ptypes.PermissionChange,
+	update func(acl *ptypes.CallACL) bool) (output []byte, err error) {
+	addr := LeftPadWord256(change.Target)
+	if appState.GetAccount(addr) == nil {
+		return nil, fmt.Errorf("Unknown account %X", addr)
+	}
+	acl := appState.GetCallACL(addr).Copy()
+	if acl == nil {
+		acl = &ptypes.CallACL{}
+	}
+	changed := update(acl)
+	if changed {
+		appState.SetCallACL(addr, acl)
+		recordPermissionChange(appState, caller, change)
+	}
+	return LeftPadWord256([]byte{byteFromBool(changed)}).Bytes(), nil
+}
+
 type ErrInvalidPermission struct {
 	Address Word256
 	SNative string
@@ -470,6 +1208,12 @@ func ValidPermN(n ptypes.PermFlag) bool {
 	return true
 }
 
+// Records a change made by the caller in the permission change log
+func recordPermissionChange(appState AppState, caller *Account, change *ptypes.PermissionChange) {
+	change.Actor = caller.Address.Postfix(20)
+	appState.AppendPermissionChange(change)
+}
+
 // Get the global BasePermissions
 func globalPerms(appState AppState) ptypes.BasePermissions {
 	vmAcc := appState.GetAccount(ptypes.GlobalPermissionsAddress256)
@@ -505,6 +1249,15 @@ func returnThreeArgs(args []byte) (a Word256, b Word256, c Word256) {
 	return
 }
 
+// CONTRACT: length has already been checked
+func returnFourArgs(args []byte) (a Word256, b Word256, c Word256, d Word256) {
+	copy(a[:], args[:32])
+	copy(b[:], args[32:64])
+	copy(c[:], args[64:96])
+	copy(d[:], args[96:128])
+	return
+}
+
 func byteFromBool(b bool) byte {
 	if b {
 		return 0x1
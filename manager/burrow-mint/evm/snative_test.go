@@ -20,10 +20,12 @@ import (
 
 	"strings"
 
+	core_types "github.com/hyperledger/burrow/core/types"
 	"github.com/hyperledger/burrow/manager/burrow-mint/evm/abi"
 	. "github.com/hyperledger/burrow/manager/burrow-mint/evm/opcodes"
 	"github.com/hyperledger/burrow/manager/burrow-mint/evm/sha3"
 	ptypes "github.com/hyperledger/burrow/permission/types"
+	"github.com/hyperledger/burrow/txs"
 	. "github.com/hyperledger/burrow/word256"
 	"github.com/stretchr/testify/assert"
 )
@@ -41,6 +43,27 @@ ac4ab3fb hasRole(address,bytes32)
 dbd4a8ea setBase(address,uint64,bool)
 c4bc7b70 setGlobal(uint64,bool)
 b7d4dc0d unsetBase(address,uint64)
+a0601bdf setRolePermission(bytes32,uint64,bool)
+58c90a5f unsetRolePermission(bytes32,uint64)
+0fc131c6 hasRolePermission(bytes32,uint64)
+054b9046 addCallee(address,address)
+cd5f696b removeCallee(address,address)
+4346c2b4 addCaller(address,address)
+0cb37521 removeCaller(address,address)
+10b2dfa4 addCallerRole(address,bytes32)
+04da36a7 removeCallerRole(address,bytes32)
+8c2efff3 setBaseUntil(address,uint64,bool,uint64)
+dfeb3b97 addRoleUntil(address,bytes32,uint64)
+40a0badb delegatePermission(address,uint64,uint64)
+7cfcac04 revokeDelegation(address,uint64)
+`
+
+// Keep this updated to drive TestNameRegContractSignatures
+const compiledNameRegSigs = `
+5c23bdf5 resolve(bytes32)
+02571be3 owner(bytes32)
+44a60bbb expires(bytes32)
+c0e64821 register(bytes32,bytes32,uint64)
 `
 
 func TestPermissionsContractSignatures(t *testing.T) {
@@ -48,7 +71,7 @@ func TestPermissionsContractSignatures(t *testing.T) {
 
 	nFuncs := len(contract.functions)
 
-	sigMap := idToSignatureMap()
+	sigMap := idToSignatureMap(compiledSigs)
 
 	assert.Len(t, sigMap, nFuncs,
 		"Permissions contract defines %s functions so we need %s "+
@@ -60,6 +83,23 @@ func TestPermissionsContractSignatures(t *testing.T) {
 	}
 }
 
+func TestNameRegContractSignatures(t *testing.T) {
+	contract := SNativeContracts()["NameReg"]
+
+	nFuncs := len(contract.functions)
+
+	sigMap := idToSignatureMap(compiledNameRegSigs)
+
+	assert.Len(t, sigMap, nFuncs,
+		"NameReg contract defines %s functions so we need %s "+
+			"signatures in compiledNameRegSigs",
+		nFuncs, nFuncs)
+
+	for funcID, signature := range sigMap {
+		assertFunctionIDSignature(t, contract, funcID, signature)
+	}
+}
+
 func TestSNativeContractDescription_Dispatch(t *testing.T) {
 	contract := SNativeContracts()["Permissions"]
 	state := newAppState()
@@ -94,6 +134,139 @@ func TestSNativeContractDescription_Dispatch(t *testing.T) {
 	assert.Equal(t, retValue, LeftPadBytes([]byte{1}, 32))
 }
 
+func TestSNativeRolePermissions(t *testing.T) {
+	contract := SNativeContracts()["Permissions"]
+	state := newAppState()
+	caller := &Account{
+		Address:     addr(1, 1, 1),
+		Permissions: allAccountPermissions(),
+	}
+	grantee := &Account{
+		Address: addr(2, 2, 2),
+	}
+	grantee.Permissions.AddRole("deployer")
+	state.UpdateAccount(grantee)
+	role := RightPadWord256([]byte("deployer"))
+	gas := int64(1000)
+
+	assert.False(t, HasPermission(state, grantee, ptypes.CreateContract))
+
+	function, err := contract.FunctionByName("setRolePermission")
+	if err != nil {
+		t.Fatalf("Could not get function: %s", err)
+	}
+	funcID := function.ID()
+	retValue, err := contract.Dispatch(state, caller, Bytecode(funcID[:],
+		role, permFlagToWord256(ptypes.CreateContract), LeftPadWord256([]byte{1})), &gas)
+	assert.NoError(t, err)
+	assert.Equal(t, permFlagToWord256(ptypes.CreateContract).Bytes(), retValue)
+	assert.True(t, HasPermission(state, grantee, ptypes.CreateContract))
+
+	function, err = contract.FunctionByName("unsetRolePermission")
+	if err != nil {
+		t.Fatalf("Could not get function: %s", err)
+	}
+	funcID = function.ID()
+	_, err = contract.Dispatch(state, caller, Bytecode(funcID[:],
+		role, permFlagToWord256(ptypes.CreateContract)), &gas)
+	assert.NoError(t, err)
+	assert.Nil(t, state.GetRolePermissions("deployer"))
+	assert.False(t, HasPermission(state, grantee, ptypes.CreateContract))
+}
+
+func TestSNativeNameReg(t *testing.T) {
+	contract := SNativeContracts()["NameReg"]
+	state := newAppState()
+	state.height = 10
+	caller := &Account{
+		Address:     addr(1, 1, 1),
+		Balance:     100000,
+		Permissions: allAccountPermissions(),
+	}
+	other := &Account{
+		Address: addr(2, 2, 2),
+		Balance: 100000,
+	}
+	other.Permissions.Base.Set(ptypes.Name, true)
+	state.UpdateAccount(caller)
+	state.UpdateAccount(other)
+	name := RightPadWord256([]byte("service"))
+	data := RightPadWord256([]byte("tcp://10.0.0.1:46656"))
+	gas := int64(1000)
+
+	call := func(caller *Account, funcName string, args ...interface{}) ([]byte, error) {
+		function, err := contract.FunctionByName(funcName)
+		if err != nil {
+			t.Fatalf("Could not get function: %s", err)
+		}
+		funcID := function.ID()
+		return contract.Dispatch(state, caller, Bytecode(append([]interface{}{funcID[:]}, args...)...), &gas)
+	}
+
+	// Names resolve to their data ABI encoded as bytes, and unregistered
+	// names to empty bytes
+	abiBytes := func(data string) []byte {
+		padded := RightPadBytes([]byte(data), (len(data)+31)/32*32)
+		return Bytecode(Uint64ToWord256(32), Uint64ToWord256(uint64(len(data))), padded)
+	}
+	retValue, err := call(caller, "resolve", name)
+	assert.NoError(t, err)
+	assert.Equal(t, abiBytes(""), retValue)
+
+	// Registering for less than the minimum period fails
+	_, err = call(caller, "register", name, data, Uint64ToWord256(1))
+	assert.Error(t, err)
+
+	blocks := int64(20)
+	retValue, err = call(caller, "register", name, data, Uint64ToWord256(uint64(blocks)))
+	assert.NoError(t, err)
+	assert.Equal(t, Uint64ToWord256(uint64(9+blocks)).Bytes(), retValue)
+	cost := blocks * txs.NameCostPerBlock(txs.NameBaseCost("service", "tcp://10.0.0.1:46656"))
+	assert.Equal(t, 100000-cost, caller.Balance)
+
+	retValue, err = call(caller, "resolve", name)
+	assert.NoError(t, err)
+	assert.Equal(t, abiBytes("tcp://10.0.0.1:46656"), retValue)
+	retValue, err = call(caller, "owner", name)
+	assert.NoError(t, err)
+	assert.Equal(t, caller.Address.Postfix(20), retValue[12:])
+	retValue, err = call(caller, "expires", name)
+	assert.NoError(t, err)
+	assert.Equal(t, Uint64ToWord256(uint64(9+blocks)).Bytes(), retValue)
+
+	// Data longer than a word is resolved whole
+	longName := RightPadWord256([]byte("long"))
+	for _, length := range []int{32, 33, 100} {
+		state.UpdateNameRegEntry(&core_types.NameRegEntry{
+			Name:    "long",
+			Owner:   other.Address.Postfix(20),
+			Data:    strings.Repeat("a", length),
+			Expires: 50,
+		})
+		retValue, err = call(caller, "resolve", longName)
+		assert.NoError(t, err)
+		assert.Equal(t, abiBytes(strings.Repeat("a", length)), retValue)
+	}
+
+	// Another account cannot take over the name or register beneath it
+	_, err = call(other, "register", name, data, Uint64ToWord256(uint64(blocks)))
+	assert.Error(t, err)
+	_, err = call(other, "register", RightPadWord256([]byte("service/child")), data,
+		Uint64ToWord256(uint64(blocks)))
+	assert.Error(t, err)
+
+	// Once the name expires it resolves to empty bytes and can be taken
+	state.height = 100
+	retValue, err = call(caller, "resolve", name)
+	assert.NoError(t, err)
+	assert.Equal(t, abiBytes(""), retValue)
+	_, err = call(other, "register", name, data, Uint64ToWord256(uint64(blocks)))
+	assert.NoError(t, err)
+	retValue, err = call(caller, "owner", name)
+	assert.NoError(t, err)
+	assert.Equal(t, other.Address.Postfix(20), retValue[12:])
+}
+
 func TestSNativeContractDescription_Address(t *testing.T) {
 	contract := NewSNativeContract("A comment",
 		"CoolButVeryLongNamedContractOfDoom")
@@ -143,9 +316,9 @@ func allAccountPermissions() ptypes.AccountPermissions {
 
 // turns the solidity compiler function summary into a map to drive signature
 // test
-func idToSignatureMap() map[string]string {
+func idToSignatureMap(sigs string) map[string]string {
 	sigMap := make(map[string]string)
-	lines := strings.Split(compiledSigs, "\n")
+	lines := strings.Split(sigs, "\n")
 	for _, line := range lines {
 		trimmed := strings.Trim(line, " \t")
 		if trimmed != "" {
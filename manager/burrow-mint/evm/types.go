@@ -17,7 +17,9 @@ package vm
 import (
 	"fmt"
 
+	core_types "github.com/hyperledger/burrow/core/types"
 	ptypes "github.com/hyperledger/burrow/permission/types"
+	"github.com/hyperledger/burrow/txs"
 	. "github.com/hyperledger/burrow/word256"
 )
 
@@ -55,6 +57,30 @@ type AppState interface {
 	GetStorage(Word256, Word256) Word256
 	SetStorage(Word256, Word256, Word256) // Setting to Zero is deleting.
 
+	// Roles
+	GetRolePermissions(role string) *ptypes.BasePermissions
+	SetRolePermissions(role string, perms *ptypes.BasePermissions) // Setting nil is deleting.
+
+	// Call ACLs
+	GetCallACL(addr Word256) *ptypes.CallACL
+	SetCallACL(addr Word256, acl *ptypes.CallACL) // Setting nil is deleting.
+
+	// Permission grants
+	GetPermissionGrants(addr Word256) *ptypes.PermissionGrants
+	SetPermissionGrants(addr Word256, grants *ptypes.PermissionGrants) // Setting nil is deleting.
+
+	// Height of the block being executed
+	BlockHeight() int
+
+	// Permission change log
+	AppendPermissionChange(change *ptypes.PermissionChange)
+
+	// Name registry
+	GetNameRegEntry(name string) *core_types.NameRegEntry
+	UpdateNameRegEntry(entry *core_types.NameRegEntry)
+
+	// Chain parameters in effect for the block being executed
+	GetChainParams() *txs.ChainParams
 }
 
 type Params struct {
@@ -105,20 +105,64 @@ func (vm *VM) SetFireable(evc events.Fireable) {
 // we do not convey if a permission is not set
 // (unlike in state/execution, where we guarantee HasPermission is called
 // on known permissions and panics else)
-// If the perm is not defined in the acc nor set by default in GlobalPermissions,
-// prints a log warning and returns false.
+// If the perm is not defined in the acc, its roles, nor set by default in
+// GlobalPermissions, prints a log warning and returns false.
+// Expired base permissions and roles are treated as unset, and a perm the acc
+// lacks is granted if delegated to it by an account that has it.
 func HasPermission(appState AppState, acc *Account, perm ptypes.PermFlag) bool {
+	if hasOwnPermission(appState, acc, perm) {
+		return true
+	}
+	if appState == nil {
+		return false
+	}
+	height := appState.BlockHeight()
+	for _, delegator := range appState.GetPermissionGrants(acc.Address).Delegators(perm, height) {
+		// delegated permissions cannot be delegated again
+		delegatorAcc := appState.GetAccount(LeftPadWord256(delegator))
+		if delegatorAcc != nil && hasOwnPermission(appState, delegatorAcc, perm) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOwnPermission(appState AppState, acc *Account, perm ptypes.PermFlag) bool {
+	var grants *ptypes.PermissionGrants
+	var height int
+	if appState != nil {
+		grants = appState.GetPermissionGrants(acc.Address)
+		height = appState.BlockHeight()
+	}
 	v, err := acc.Permissions.Base.Get(perm)
+	if err == nil && grants.BaseExpired(perm, height) {
+		err = ptypes.ErrValueNotSet(perm)
+	}
+	if _, ok := err.(ptypes.ErrValueNotSet); ok && appState != nil && len(acc.Permissions.Roles) > 0 {
+		v, err = acc.Permissions.GetFromRoles(perm, appState, grants, height)
+	}
 	if _, ok := err.(ptypes.ErrValueNotSet); ok {
 		if appState == nil {
 			log.Warn(fmt.Sprintf("\n\n***** Unknown permission %b! ********\n\n", perm))
 			return false
 		}
-		return HasPermission(nil, appState.GetAccount(ptypes.GlobalPermissionsAddress256), perm)
+		return hasOwnPermission(nil, appState.GetAccount(ptypes.GlobalPermissionsAddress256), perm)
 	}
 	return v
 }
 
+// Checks the call ACLs of both the caller and the callee. Native contracts,
+// including the SNatives, are part of the VM rather than accounts so they are
+// not restricted by call ACLs; the SNatives check permissions of their own.
+func callAllowedByACLs(appState AppState, caller *Account, callee Word256) bool {
+	if RegisteredNativeContract(callee) {
+		return true
+	}
+	return appState.GetCallACL(caller.Address).AllowsCallee(callee.Postfix(20)) &&
+		appState.GetCallACL(callee).AllowsCaller(caller.Address.Postfix(20), &caller.Permissions,
+			appState.GetPermissionGrants(caller.Address), appState.BlockHeight())
+}
+
 func (vm *VM) fireCallEvent(exception *string, output *[]byte, caller, callee *Account, input []byte, value int64, gas *int64) {
 	// fire the post call event (including exception if applicable)
 	if vm.evc != nil {
@@ -763,6 +807,7 @@ func (vm *VM) call(caller, callee *Account, code, input []byte, value int64, gas
 					topics,
 					data,
 					vm.params.BlockHeight,
+					vm.txid,
 				}
 				vm.evc.FireEvent(eventID, log)
 			}
@@ -804,6 +849,9 @@ func (vm *VM) call(caller, callee *Account, code, input []byte, value int64, gas
 			}
 			gasLimit := stack.Pop64()
 			addr := stack.Pop()
+			if !callAllowedByACLs(vm.appState, callee, addr) {
+				return nil, ErrPermission{fmt.Sprintf("call %X", addr.Postfix(20))}
+			}
 			// NOTE: for DELEGATECALL value is preserved from the original
 			// caller, as such it is not stored on stack as an argument
 			// for DELEGATECALL and should not be popped.  Instead previous
@@ -74,7 +74,9 @@ func (this *namereg) Entry(key string) (*core_types.NameRegEntry, error) {
 	return entry, nil
 }
 
-func (this *namereg) Entries(filters []*event.FilterData) (*core_types.ResultListNames, error) {
+// Get a page of the entries matching the filters, in name order.
+func (this *namereg) Entries(filters []*event.FilterData,
+	page *core_types.PageRequest) (*core_types.ResultListNames, error) {
 	var blockHeight int
 	var names []*core_types.NameRegEntry
 	state := this.burrowMint.GetState()
@@ -83,14 +85,19 @@ func (this *namereg) Entries(filters []*event.FilterData) (*core_types.ResultLis
 	if err != nil {
 		return nil, fmt.Errorf("Error in query: " + err.Error())
 	}
-	state.GetNames().Iterate(func(key, value []byte) bool {
-		nre := sm.DecodeNameRegEntry(value)
-		if filter.Match(nre) {
+	nextCursor, err := iteratePage(state.GetNames(), page,
+		func(key, value []byte) bool {
+			nre := sm.DecodeNameRegEntry(value)
+			if !filter.Match(nre) {
+				return false
+			}
 			names = append(names, nre)
-		}
-		return false
-	})
-	return &core_types.ResultListNames{blockHeight, names}, nil
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &core_types.ResultListNames{blockHeight, names, nextCursor}, nil
 }
 
 type ResultListNames struct {
@@ -0,0 +1,80 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burrowmint
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	core_types "github.com/hyperledger/burrow/core/types"
+
+	merkle "github.com/tendermint/go-merkle"
+)
+
+// The most items scanned for a page of a filtered listing, so that a filter
+// matching few items can not make one page scan a whole tree. A page may
+// then hold fewer items than its limit, or none, and still have a next page.
+const maxPageScan = 10000
+
+// Iterates over a page of the items of tree in key order, passing them to
+// match. The page starts at the key of its cursor, which is hex encoded, and
+// ends once limit items have matched or maxPageScan have been scanned.
+// Returns the cursor of the next page, which is empty after the last item.
+//
+// Pages are found by index rather than by iterating from the start of the
+// tree, so each page costs the same however deep into the tree it is.
+func iteratePage(tree merkle.Tree, page *core_types.PageRequest,
+	match func(key, value []byte) bool) (string, error) {
+	return iteratePrefixPage(tree, nil, page, match)
+}
+
+// Iterates over a page of the items of tree whose keys start with prefix, as
+// iteratePage does over all of them
+func iteratePrefixPage(tree merkle.Tree, prefix []byte,
+	page *core_types.PageRequest,
+	match func(key, value []byte) bool) (string, error) {
+	limit, err := page.Size()
+	if err != nil {
+		return "", err
+	}
+	start := prefix
+	if cursor := page.StartCursor(); cursor != "" {
+		if start, err = hex.DecodeString(cursor); err != nil {
+			return "", fmt.Errorf("Invalid cursor %s: %v", cursor, err)
+		}
+		if !bytes.HasPrefix(start, prefix) {
+			return "", fmt.Errorf("Invalid cursor %s: it is not in the listing", cursor)
+		}
+	}
+	// the index of the key, or of the first key after it when it has since
+	// been removed
+	index, _, _ := tree.Get(start)
+	matched := 0
+	for scanned := 0; index < tree.Size(); index++ {
+		key, value := tree.GetByIndex(index)
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+		if matched == limit || scanned == maxPageScan {
+			return fmt.Sprintf("%X", key), nil
+		}
+		if match(key, value) {
+			matched++
+		}
+		scanned++
+	}
+	return "", nil
+}
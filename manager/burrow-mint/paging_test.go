@@ -0,0 +1,128 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package burrowmint
+
+import (
+	"testing"
+
+	core_types "github.com/hyperledger/burrow/core/types"
+
+	assert "github.com/stretchr/testify/assert"
+	dbm "github.com/tendermint/go-db"
+	merkle "github.com/tendermint/go-merkle"
+)
+
+func newTestTree(keys ...string) merkle.Tree {
+	tree := merkle.NewIAVLTree(0, dbm.NewMemDB())
+	for _, key := range keys {
+		tree.Set([]byte(key), []byte(key))
+	}
+	return tree
+}
+
+// Collects the pages of tree, returning the keys of each
+func collectPages(t *testing.T, tree merkle.Tree, limit int,
+	match func(key string) bool) [][]string {
+	pages := [][]string{}
+	page := &core_types.PageRequest{Limit: limit}
+	for {
+		keys := []string{}
+		next, err := iteratePage(tree, page, func(key, value []byte) bool {
+			if !match(string(key)) {
+				return false
+			}
+			keys = append(keys, string(key))
+			return true
+		})
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		pages = append(pages, keys)
+		if next == "" {
+			return pages
+		}
+		page.Cursor = next
+	}
+}
+
+func TestIteratePage(t *testing.T) {
+	tree := newTestTree("e", "a", "c", "b", "d")
+	all := func(string) bool { return true }
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+		collectPages(t, tree, 2, all))
+	assert.Equal(t, [][]string{{"a", "b", "c", "d", "e"}},
+		collectPages(t, tree, 0, all))
+	// pages hold the items that match
+	assert.Equal(t, [][]string{{"a", "c"}, {"e"}},
+		collectPages(t, tree, 2, func(key string) bool { return key != "b" && key != "d" }))
+	assert.Equal(t, [][]string{{}}, collectPages(t, newTestTree(), 2, all))
+}
+
+func TestIteratePageRemovedCursor(t *testing.T) {
+	tree := newTestTree("a", "b", "c", "d")
+	var keys []string
+	next, err := iteratePage(tree, &core_types.PageRequest{Limit: 2},
+		func(key, value []byte) bool { return true })
+	assert.NoError(t, err)
+	// the next page starts after the cursor even once its key is removed
+	tree.Remove([]byte("c"))
+	_, err = iteratePage(tree, &core_types.PageRequest{Cursor: next, Limit: 2},
+		func(key, value []byte) bool {
+			keys = append(keys, string(key))
+			return true
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d"}, keys)
+}
+
+func TestIteratePrefixPage(t *testing.T) {
+	tree := newTestTree("a1", "b1", "b2", "b3", "c1")
+	var keys []string
+	collect := func(key, value []byte) bool {
+		keys = append(keys, string(key))
+		return true
+	}
+	next, err := iteratePrefixPage(tree, []byte("b"),
+		&core_types.PageRequest{Limit: 2}, collect)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b1", "b2"}, keys)
+	keys = nil
+	next, err = iteratePrefixPage(tree, []byte("b"),
+		&core_types.PageRequest{Cursor: next, Limit: 2}, collect)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b3"}, keys)
+	assert.Equal(t, "", next)
+
+	// a cursor from another listing is refused
+	_, err = iteratePrefixPage(tree, []byte("b"),
+		&core_types.PageRequest{Cursor: "6331"}, collect)
+	assert.Error(t, err)
+	keys = nil
+	_, err = iteratePrefixPage(tree, []byte("d"), &core_types.PageRequest{}, collect)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestIteratePageInvalid(t *testing.T) {
+	tree := newTestTree("a")
+	match := func(key, value []byte) bool { return true }
+	_, err := iteratePage(tree, &core_types.PageRequest{Cursor: "xyz"}, match)
+	assert.Error(t, err)
+	_, err = iteratePage(tree, &core_types.PageRequest{Limit: -1}, match)
+	assert.Error(t, err)
+	_, err = iteratePage(tree,
+		&core_types.PageRequest{Limit: core_types.MAX_PAGE_SIZE + 1}, match)
+	assert.Error(t, err)
+}
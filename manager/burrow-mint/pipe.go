@@ -39,6 +39,7 @@ import (
 	vm "github.com/hyperledger/burrow/manager/burrow-mint/evm"
 	"github.com/hyperledger/burrow/manager/burrow-mint/state"
 	manager_types "github.com/hyperledger/burrow/manager/types"
+	ptypes "github.com/hyperledger/burrow/permission/types"
 	rpc_tm_types "github.com/hyperledger/burrow/rpc/tendermint/core/types"
 	"github.com/hyperledger/burrow/txs"
 	"github.com/hyperledger/burrow/word256"
@@ -52,6 +53,7 @@ type burrowMintPipe struct {
 	blockchain      blockchain_types.Blockchain
 	consensusEngine consensus_types.ConsensusEngine
 	events          edb_event.EventEmitter
+	eventJournal    *edb_event.EventJournal
 	namereg         *namereg
 	transactor      *transactor
 	// Genesis cache
@@ -81,11 +83,22 @@ func NewBurrowMintPipe(moduleConfig *config.ModuleConfig,
 		"chainId", startedState.ChainID,
 		"lastBlockHeight", startedState.LastBlockHeight,
 		"lastBlockHash", startedState.LastBlockHash)
+	// journal the events fired by the application so subscriptions can resume
+	eventJournal, err := startEventJournal(moduleConfig.DataDir,
+		moduleConfig.Config.GetString("db_backend"),
+		uint64(moduleConfig.Config.GetInt("event_journal_size")))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to start event journal: %v", err)
+	}
+	// the events are fired as the application commits a block, once it has
+	// moved the last block height of the state on to the block's height
+	journalingEventSwitch := edb_event.NewJournalingEventSwitch(eventSwitch, eventJournal,
+		startedState.ChainID, func() int { return startedState.LastBlockHeight })
 	// start the application
-	burrowMint := NewBurrowMint(startedState, eventSwitch, logger)
+	burrowMint := NewBurrowMint(startedState, journalingEventSwitch, logger)
 
 	// initialise the components of the pipe
-	events := edb_event.NewEvents(eventSwitch, logger)
+	events := edb_event.NewEvents(journalingEventSwitch, logger)
 	accounts := newAccounts(burrowMint)
 	namereg := newNameReg(burrowMint)
 
@@ -94,6 +107,7 @@ func NewBurrowMintPipe(moduleConfig *config.ModuleConfig,
 		burrowMint:      burrowMint,
 		accounts:        accounts,
 		events:          events,
+		eventJournal:    eventJournal,
 		namereg:         namereg,
 		// We need to set transactor later since we are introducing a mutual dependency
 		// NOTE: this will be cleaned up when the RPC is unified
@@ -117,6 +131,8 @@ func NewBurrowMintPipe(moduleConfig *config.ModuleConfig,
 	// like a reasonably minimal and flexible way of providing transactor with the
 	// broadcast function it needs, without making it explicitly
 	// aware of/depend on Pipe.
+	// The transactor fires the events of simulated calls on the unwrapped
+	// switch so that they are not journaled alongside those of executed txs
 	transactor := newTransactor(moduleConfig.ChainId, eventSwitch, burrowMint,
 		events,
 		func(tx txs.Tx) error {
@@ -128,6 +144,21 @@ func NewBurrowMintPipe(moduleConfig *config.ModuleConfig,
 	return pipe, nil
 }
 
+//------------------------------------------------------------------------------
+// Start event journal
+
+// Opens the event journal in its own database in the data directory, which
+// keeps sequence numbers increasing across restarts
+func startEventJournal(dataDir, backend string, size uint64) (*edb_event.EventJournal,
+	error) {
+	if backend != db.MemDBBackendStr &&
+		backend != db.LevelDBBackendStr {
+		return nil, fmt.Errorf("Database backend %s is not supported by %s",
+			backend, GetBurrowMintVersion)
+	}
+	return edb_event.NewEventJournal(db.NewDB("events", backend, dataDir), size)
+}
+
 //------------------------------------------------------------------------------
 // Start state
 
@@ -193,6 +224,10 @@ func (pipe *burrowMintPipe) Events() edb_event.EventEmitter {
 	return pipe.events
 }
 
+func (pipe *burrowMintPipe) EventJournal() *edb_event.EventJournal {
+	return pipe.eventJournal
+}
+
 func (pipe *burrowMintPipe) NameReg() definitions.NameReg {
 	return pipe.namereg
 }
@@ -351,16 +386,21 @@ func (pipe *burrowMintPipe) GetAccount(address []byte) (*rpc_tm_types.ResultGetA
 	return &rpc_tm_types.ResultGetAccount{Account: account}, nil
 }
 
-func (pipe *burrowMintPipe) ListAccounts() (*rpc_tm_types.ResultListAccounts, error) {
+func (pipe *burrowMintPipe) ListAccounts(page *core_types.PageRequest) (
+	*rpc_tm_types.ResultListAccounts, error) {
 	var blockHeight int
 	var accounts []*account.Account
 	state := pipe.burrowMint.GetState()
 	blockHeight = state.LastBlockHeight
-	state.GetAccounts().Iterate(func(key []byte, value []byte) bool {
-		accounts = append(accounts, account.DecodeAccount(value))
-		return false
-	})
-	return &rpc_tm_types.ResultListAccounts{blockHeight, accounts}, nil
+	nextCursor, err := iteratePage(state.GetAccounts(), page,
+		func(key []byte, value []byte) bool {
+			accounts = append(accounts, account.DecodeAccount(value))
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_tm_types.ResultListAccounts{blockHeight, accounts, nextCursor}, nil
 }
 
 func (pipe *burrowMintPipe) GetStorage(address, key []byte) (*rpc_tm_types.ResultGetStorage,
@@ -383,8 +423,8 @@ func (pipe *burrowMintPipe) GetStorage(address, key []byte) (*rpc_tm_types.Resul
 	return &rpc_tm_types.ResultGetStorage{key, value}, nil
 }
 
-func (pipe *burrowMintPipe) DumpStorage(address []byte) (*rpc_tm_types.ResultDumpStorage,
-	error) {
+func (pipe *burrowMintPipe) DumpStorage(address []byte,
+	page *core_types.PageRequest) (*rpc_tm_types.ResultDumpStorage, error) {
 	state := pipe.burrowMint.GetState()
 	account := state.GetAccount(address)
 	if account == nil {
@@ -393,12 +433,17 @@ func (pipe *burrowMintPipe) DumpStorage(address []byte) (*rpc_tm_types.ResultDum
 	storageRoot := account.StorageRoot
 	storageTree := state.LoadStorage(storageRoot)
 	storageItems := []rpc_tm_types.StorageItem{}
-	storageTree.Iterate(func(key []byte, value []byte) bool {
-		storageItems = append(storageItems, rpc_tm_types.StorageItem{key,
-			value})
-		return false
-	})
-	return &rpc_tm_types.ResultDumpStorage{storageRoot, storageItems}, nil
+	nextCursor, err := iteratePage(storageTree, page,
+		func(key []byte, value []byte) bool {
+			storageItems = append(storageItems, rpc_tm_types.StorageItem{key,
+				value})
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_tm_types.ResultDumpStorage{storageRoot, storageItems,
+		nextCursor}, nil
 }
 
 // Call
@@ -506,6 +551,83 @@ func (pipe *burrowMintPipe) SignTransaction(tx txs.Tx,
 	return &rpc_tm_types.ResultSignTx{tx}, nil
 }
 
+// Returns the chain parameters and global permissions currently in effect
+// along with the governance proposals waiting to be applied.
+func (pipe *burrowMintPipe) GetChainParams() (*rpc_tm_types.ResultGetChainParams, error) {
+	currentState := pipe.burrowMint.GetState()
+	globalPermsAcc := currentState.GetAccount(ptypes.GlobalPermissionsAddress)
+	if globalPermsAcc == nil {
+		return nil, fmt.Errorf("Global permissions account not found")
+	}
+	proposals := []*txs.GovProposal{}
+	currentState.GetAllGovProposals().Iterate(func(key []byte, value []byte) bool {
+		proposals = append(proposals, state.DecodeGovProposals(value)...)
+		return false
+	})
+	return &rpc_tm_types.ResultGetChainParams{
+		BlockHeight:       currentState.LastBlockHeight,
+		Params:            currentState.GetChainParams(),
+		GlobalPermissions: globalPermsAcc.Permissions.Base,
+		PendingProposals:  proposals,
+	}, nil
+}
+
+// Returns a page of the permission change log in the order the changes were
+// made. An empty address or permission places no restriction on the changes
+// returned. The changes involving an address are found through the index of
+// the log by address rather than by scanning the log.
+func (pipe *burrowMintPipe) ListPermissionChanges(address []byte, permission string,
+	page *core_types.PageRequest) (*rpc_tm_types.ResultListPermissionChanges, error) {
+	var permFlag ptypes.PermFlag
+	if permission != "" {
+		var err error
+		if permFlag, err = ptypes.PermStringToFlag(permission); err != nil {
+			return nil, err
+		}
+	}
+	if len(address) > 0 && len(address) != 20 {
+		return nil, fmt.Errorf("Address %X is not 20 bytes", address)
+	}
+	currentState := pipe.burrowMint.GetState()
+	changes := []*ptypes.PermissionChange{}
+	var decodeErr error
+	match := func(change *ptypes.PermissionChange, err error) bool {
+		if err != nil {
+			decodeErr = err
+			return false
+		}
+		if change == nil || (permFlag != 0 && !change.Affects(permFlag)) {
+			return false
+		}
+		changes = append(changes, change)
+		return true
+	}
+	var nextCursor string
+	var err error
+	if len(address) == 0 {
+		nextCursor, err = iteratePage(currentState.GetAllPermissionChanges(), page,
+			func(key, value []byte) bool {
+				return match(state.DecodePermissionChange(value))
+			})
+	} else {
+		nextCursor, err = iteratePrefixPage(currentState.GetPermissionChangesByAddress(),
+			address, page, func(key, value []byte) bool {
+				return match(currentState.GetPermissionChange(value))
+			})
+	}
+	if err == nil {
+		err = decodeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_tm_types.ResultListPermissionChanges{
+		BlockHeight: currentState.LastBlockHeight,
+		Changes:     changes,
+		NextCursor:  nextCursor,
+	}, nil
+}
+
 // Name registry
 func (pipe *burrowMintPipe) GetName(name string) (*rpc_tm_types.ResultGetName, error) {
 	currentState := pipe.burrowMint.GetState()
@@ -516,16 +638,54 @@ func (pipe *burrowMintPipe) GetName(name string) (*rpc_tm_types.ResultGetName, e
 	return &rpc_tm_types.ResultGetName{entry}, nil
 }
 
-func (pipe *burrowMintPipe) ListNames() (*rpc_tm_types.ResultListNames, error) {
+func (pipe *burrowMintPipe) ListNames(page *core_types.PageRequest) (
+	*rpc_tm_types.ResultListNames, error) {
 	var blockHeight int
 	var names []*core_types.NameRegEntry
 	currentState := pipe.burrowMint.GetState()
 	blockHeight = currentState.LastBlockHeight
-	currentState.GetNames().Iterate(func(key []byte, value []byte) bool {
-		names = append(names, state.DecodeNameRegEntry(value))
-		return false
-	})
-	return &rpc_tm_types.ResultListNames{blockHeight, names}, nil
+	nextCursor, err := iteratePage(currentState.GetNames(), page,
+		func(key []byte, value []byte) bool {
+			names = append(names, state.DecodeNameRegEntry(value))
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	return &rpc_tm_types.ResultListNames{blockHeight, names, nextCursor}, nil
+}
+
+func (pipe *burrowMintPipe) GetNamesByOwner(owner []byte) (*rpc_tm_types.ResultListNames, error) {
+	currentState := pipe.burrowMint.GetState()
+	return &rpc_tm_types.ResultListNames{
+		BlockHeight: currentState.LastBlockHeight,
+		Names:       ownedNameEntries(currentState, owner),
+	}, nil
+}
+
+func (pipe *burrowMintPipe) ReverseLookup(address []byte) (*rpc_tm_types.ResultReverseLookup, error) {
+	currentState := pipe.burrowMint.GetState()
+	names := []string{}
+	for _, entry := range ownedNameEntries(currentState, address) {
+		names = append(names, entry.Name)
+	}
+	return &rpc_tm_types.ResultReverseLookup{
+		BlockHeight: currentState.LastBlockHeight,
+		Address:     address,
+		Names:       names,
+	}, nil
+}
+
+// Returns the unexpired entries owned by owner, sorted by name
+func ownedNameEntries(currentState *state.State, owner []byte) []*core_types.NameRegEntry {
+	entries := []*core_types.NameRegEntry{}
+	for _, name := range currentState.GetNamesByOwner(owner) {
+		entry := currentState.GetNameRegEntry(name)
+		if entry != nil && entry.Expires > currentState.LastBlockHeight {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
 }
 
 func (pipe *burrowMintPipe) broadcastTx(tx txs.Tx,
@@ -642,12 +802,28 @@ func (pipe *burrowMintPipe) GetBlock(height int) (*rpc_tm_types.ResultGetBlock,
 func (pipe *burrowMintPipe) ListValidators() (*rpc_tm_types.ResultListValidators, error) {
 	validators := pipe.consensusEngine.ListValidators()
 	consensusState := pipe.consensusEngine.ConsensusState()
-	// TODO: when we reintroduce support for bonding and unbonding update this
-	// to reflect the mutable bonding state
+	// the bonded validators are those in Tendermint's validator set, which
+	// follows the bonding state through BurrowMint.EndBlock; unbonding
+	// validators are only known to the application state
+	unbondingValidators := []consensus_types.Validator{}
+	pipe.burrowMint.GetState().GetValidatorInfos().Iterate(func(key []byte, value []byte) bool {
+		valInfo := state.DecodeValidatorInfo(value)
+		if valInfo.IsUnbonding() {
+			unbondingValidators = append(unbondingValidators,
+				&consensus_types.TendermintValidator{
+					Validator: &tm_types.Validator{
+						Address:     valInfo.Address,
+						PubKey:      valInfo.PubKey,
+						VotingPower: valInfo.VotingPower,
+					},
+				})
+		}
+		return false
+	})
 	return &rpc_tm_types.ResultListValidators{
 		BlockHeight:         consensusState.Height,
 		BondedValidators:    validators,
-		UnbondingValidators: nil,
+		UnbondingValidators: unbondingValidators,
 	}, nil
 }
 
@@ -22,6 +22,8 @@ import (
 	acm "github.com/hyperledger/burrow/account"
 	"github.com/hyperledger/burrow/common/sanity"
 	core_types "github.com/hyperledger/burrow/core/types"
+	ptypes "github.com/hyperledger/burrow/permission/types"
+	"github.com/hyperledger/burrow/txs"
 	. "github.com/hyperledger/burrow/word256"
 
 	dbm "github.com/tendermint/go-db"
@@ -36,20 +38,32 @@ func makeStorage(db dbm.DB, root []byte) merkle.Tree {
 
 // The blockcache helps prevent unnecessary IAVLTree updates and garbage generation.
 type BlockCache struct {
-	db       dbm.DB
-	backend  *State
-	accounts map[string]accountInfo
-	storages map[Tuple256]storageInfo
-	names    map[string]nameInfo
+	db          dbm.DB
+	backend     *State
+	accounts    map[string]accountInfo
+	storages    map[Tuple256]storageInfo
+	names       map[string]nameInfo
+	validators  map[string]validatorInfo
+	proposals   map[int]govProposalsInfo
+	roles       map[string]roleInfo
+	callACLs    map[string]callACLInfo
+	permGrants  map[string]permGrantsInfo
+	permChanges []*ptypes.PermissionChange
+	params      *txs.ChainParams
 }
 
 func NewBlockCache(backend *State) *BlockCache {
 	return &BlockCache{
-		db:       backend.DB,
-		backend:  backend,
-		accounts: make(map[string]accountInfo),
-		storages: make(map[Tuple256]storageInfo),
-		names:    make(map[string]nameInfo),
+		db:         backend.DB,
+		backend:    backend,
+		accounts:   make(map[string]accountInfo),
+		storages:   make(map[Tuple256]storageInfo),
+		names:      make(map[string]nameInfo),
+		validators: make(map[string]validatorInfo),
+		proposals:  make(map[int]govProposalsInfo),
+		roles:      make(map[string]roleInfo),
+		callACLs:   make(map[string]callACLInfo),
+		permGrants: make(map[string]permGrantsInfo),
 	}
 }
 
@@ -57,6 +71,62 @@ func (cache *BlockCache) State() *State {
 	return cache.backend
 }
 
+// Copy returns a BlockCache over the same backend holding copies of the
+// cached entries, so changes made through the copy do not affect this cache.
+// The storage trees are shared as they are only written to on Sync().
+func (cache *BlockCache) Copy() *BlockCache {
+	cacheCopy := NewBlockCache(cache.backend)
+	for addrStr, accInfo := range cache.accounts {
+		acc, storage, removed, dirty := accInfo.unpack()
+		if acc != nil {
+			permissions := acc.Permissions.Clone()
+			acc = acc.Copy()
+			acc.Permissions = permissions
+		}
+		cacheCopy.accounts[addrStr] = accountInfo{acc, storage, removed, dirty}
+	}
+	for key, stjInfo := range cache.storages {
+		cacheCopy.storages[key] = stjInfo
+	}
+	for name, nInfo := range cache.names {
+		entry, removed, dirty := nInfo.unpack()
+		if entry != nil {
+			entryCopy := *entry
+			entry = &entryCopy
+		}
+		cacheCopy.names[name] = nameInfo{entry, removed, dirty}
+	}
+	for addrStr, vInfo := range cache.validators {
+		valInfo, dirty := vInfo.unpack()
+		cacheCopy.validators[addrStr] = validatorInfo{valInfo.Copy(), dirty}
+	}
+	for height, gInfo := range cache.proposals {
+		cacheCopy.proposals[height] = gInfo
+	}
+	for role, rInfo := range cache.roles {
+		perms, dirty := rInfo.unpack()
+		if perms != nil {
+			permsCopy := *perms
+			perms = &permsCopy
+		}
+		cacheCopy.roles[role] = roleInfo{perms, dirty}
+	}
+	for addrStr, aInfo := range cache.callACLs {
+		acl, dirty := aInfo.unpack()
+		cacheCopy.callACLs[addrStr] = callACLInfo{acl.Copy(), dirty}
+	}
+	for addrStr, gInfo := range cache.permGrants {
+		grants, dirty := gInfo.unpack()
+		cacheCopy.permGrants[addrStr] = permGrantsInfo{grants.Copy(), dirty}
+	}
+	cacheCopy.permChanges = make([]*ptypes.PermissionChange, len(cache.permChanges))
+	copy(cacheCopy.permChanges, cache.permChanges)
+	if cache.params != nil {
+		cacheCopy.params = cache.params.Copy()
+	}
+	return cacheCopy
+}
+
 //-------------------------------------
 // BlockCache.account
 
@@ -164,6 +234,148 @@ func (cache *BlockCache) RemoveNameRegEntry(name string) {
 
 // BlockCache.names
 //-------------------------------------
+// BlockCache.validators
+
+func (cache *BlockCache) GetValidatorInfo(addr []byte) *ValidatorInfo {
+	valInfo, _ := cache.validators[string(addr)].unpack()
+	if valInfo != nil {
+		return valInfo
+	} else {
+		valInfo = cache.backend.GetValidatorInfo(addr)
+		if valInfo != nil {
+			cache.validators[string(addr)] = validatorInfo{valInfo, false}
+		}
+		return valInfo
+	}
+}
+
+func (cache *BlockCache) UpdateValidatorInfo(valInfo *ValidatorInfo) {
+	cache.validators[string(valInfo.Address)] = validatorInfo{valInfo, true}
+}
+
+// BlockCache.validators
+//-------------------------------------
+// BlockCache.params
+
+func (cache *BlockCache) GetChainParams() *txs.ChainParams {
+	if cache.params != nil {
+		return cache.params
+	}
+	return cache.backend.GetChainParams()
+}
+
+func (cache *BlockCache) UpdateChainParams(params *txs.ChainParams) {
+	cache.params = params
+}
+
+// BlockCache.params
+//-------------------------------------
+// BlockCache.proposals
+
+func (cache *BlockCache) GetGovProposals(height int) []*txs.GovProposal {
+	proposals, _ := cache.proposals[height].unpack()
+	if proposals != nil {
+		return proposals
+	} else {
+		proposals = cache.backend.GetGovProposals(height)
+		cache.proposals[height] = govProposalsInfo{proposals, false}
+		return proposals
+	}
+}
+
+// NOTE: Set an empty list to remove the proposals for the height.
+func (cache *BlockCache) SetGovProposals(height int, proposals []*txs.GovProposal) {
+	if proposals == nil {
+		proposals = []*txs.GovProposal{}
+	}
+	cache.proposals[height] = govProposalsInfo{proposals, true}
+}
+
+// BlockCache.proposals
+//-------------------------------------
+// BlockCache.roles
+
+func (cache *BlockCache) GetRolePermissions(role string) *ptypes.BasePermissions {
+	role = ptypes.PadRole(role)
+	if rInfo, ok := cache.roles[role]; ok {
+		perms, _ := rInfo.unpack()
+		return perms
+	}
+	perms := cache.backend.GetRolePermissions(role)
+	cache.roles[role] = roleInfo{perms, false}
+	return perms
+}
+
+// NOTE: Set nil to remove the role definition.
+func (cache *BlockCache) SetRolePermissions(role string, perms *ptypes.BasePermissions) {
+	if perms != nil && perms.SetBit == 0 {
+		perms = nil
+	}
+	cache.roles[ptypes.PadRole(role)] = roleInfo{perms, true}
+}
+
+// BlockCache.roles
+//-------------------------------------
+// BlockCache.callACLs
+
+func (cache *BlockCache) GetCallACL(addr []byte) *ptypes.CallACL {
+	if aInfo, ok := cache.callACLs[string(addr)]; ok {
+		acl, _ := aInfo.unpack()
+		return acl
+	}
+	acl := cache.backend.GetCallACL(addr)
+	cache.callACLs[string(addr)] = callACLInfo{acl, false}
+	return acl
+}
+
+// NOTE: Set nil or an empty ACL to remove it.
+func (cache *BlockCache) SetCallACL(addr []byte, acl *ptypes.CallACL) {
+	if acl.IsEmpty() {
+		acl = nil
+	}
+	cache.callACLs[string(addr)] = callACLInfo{acl, true}
+}
+
+// BlockCache.callACLs
+//-------------------------------------
+// BlockCache.permGrants
+
+func (cache *BlockCache) GetPermissionGrants(addr []byte) *ptypes.PermissionGrants {
+	if gInfo, ok := cache.permGrants[string(addr)]; ok {
+		grants, _ := gInfo.unpack()
+		return grants
+	}
+	grants := cache.backend.GetPermissionGrants(addr)
+	cache.permGrants[string(addr)] = permGrantsInfo{grants, false}
+	return grants
+}
+
+// NOTE: Set nil or empty grants to remove them.
+func (cache *BlockCache) SetPermissionGrants(addr []byte, grants *ptypes.PermissionGrants) {
+	if grants.IsEmpty() {
+		grants = nil
+	}
+	cache.permGrants[string(addr)] = permGrantsInfo{grants, true}
+}
+
+// Height of the block being executed, against which grant expiries are checked
+func (cache *BlockCache) BlockHeight() int {
+	return cache.backend.LastBlockHeight + 1
+}
+
+// BlockCache.permGrants
+//-------------------------------------
+// BlockCache.permChanges
+
+// Records the change as made in the current block. It is appended to the
+// permission change log on Sync().
+func (cache *BlockCache) AppendPermissionChange(change *ptypes.PermissionChange) {
+	change.Height = cache.BlockHeight()
+	cache.permChanges = append(cache.permChanges, change)
+}
+
+// BlockCache.permChanges
+//-------------------------------------
 
 // CONTRACT the updates are in deterministic order.
 func (cache *BlockCache) Sync() {
@@ -269,6 +481,96 @@ func (cache *BlockCache) Sync() {
 		}
 	}
 
+	// Determine order for validators
+	valAddrStrs := []string{}
+	for addrStr := range cache.validators {
+		valAddrStrs = append(valAddrStrs, addrStr)
+	}
+	sort.Strings(valAddrStrs)
+
+	// Update validators.
+	for _, addrStr := range valAddrStrs {
+		valInfo, dirty := cache.validators[addrStr].unpack()
+		if dirty {
+			cache.backend.SetValidatorInfo(valInfo)
+			cache.validators[addrStr] = validatorInfo{valInfo, false}
+		}
+	}
+
+	// Determine order for proposals
+	heights := []int{}
+	for height := range cache.proposals {
+		heights = append(heights, height)
+	}
+	sort.Ints(heights)
+
+	// Update or delete proposals.
+	for _, height := range heights {
+		proposals, dirty := cache.proposals[height].unpack()
+		if dirty {
+			cache.backend.SetGovProposals(height, proposals)
+			cache.proposals[height] = govProposalsInfo{proposals, false}
+		}
+	}
+
+	// Determine order for roles
+	roleStrs := []string{}
+	for role := range cache.roles {
+		roleStrs = append(roleStrs, role)
+	}
+	sort.Strings(roleStrs)
+
+	// Update or delete roles.
+	for _, role := range roleStrs {
+		perms, dirty := cache.roles[role].unpack()
+		if dirty {
+			cache.backend.SetRolePermissions(role, perms)
+			cache.roles[role] = roleInfo{perms, false}
+		}
+	}
+
+	// Determine order for call ACLs
+	aclAddrStrs := []string{}
+	for addrStr := range cache.callACLs {
+		aclAddrStrs = append(aclAddrStrs, addrStr)
+	}
+	sort.Strings(aclAddrStrs)
+
+	// Update or delete call ACLs.
+	for _, addrStr := range aclAddrStrs {
+		acl, dirty := cache.callACLs[addrStr].unpack()
+		if dirty {
+			cache.backend.SetCallACL([]byte(addrStr), acl)
+			cache.callACLs[addrStr] = callACLInfo{acl, false}
+		}
+	}
+
+	// Determine order for permission grants
+	grantsAddrStrs := []string{}
+	for addrStr := range cache.permGrants {
+		grantsAddrStrs = append(grantsAddrStrs, addrStr)
+	}
+	sort.Strings(grantsAddrStrs)
+
+	// Update or delete permission grants.
+	for _, addrStr := range grantsAddrStrs {
+		grants, dirty := cache.permGrants[addrStr].unpack()
+		if dirty {
+			cache.backend.SetPermissionGrants([]byte(addrStr), grants)
+			cache.permGrants[addrStr] = permGrantsInfo{grants, false}
+		}
+	}
+
+	// Append permission changes in the order they were made.
+	for _, change := range cache.permChanges {
+		cache.backend.AppendPermissionChange(change)
+	}
+	cache.permChanges = nil
+
+	if cache.params != nil {
+		cache.backend.SetChainParams(cache.params)
+		cache.params = nil
+	}
 }
 
 //-----------------------------------------------------------------------------
@@ -302,3 +604,48 @@ type nameInfo struct {
 func (nInfo nameInfo) unpack() (*core_types.NameRegEntry, bool, bool) {
 	return nInfo.name, nInfo.removed, nInfo.dirty
 }
+
+type validatorInfo struct {
+	validator *ValidatorInfo
+	dirty     bool
+}
+
+func (vInfo validatorInfo) unpack() (*ValidatorInfo, bool) {
+	return vInfo.validator, vInfo.dirty
+}
+
+type govProposalsInfo struct {
+	proposals []*txs.GovProposal
+	dirty     bool
+}
+
+func (gInfo govProposalsInfo) unpack() ([]*txs.GovProposal, bool) {
+	return gInfo.proposals, gInfo.dirty
+}
+
+type roleInfo struct {
+	perms *ptypes.BasePermissions
+	dirty bool
+}
+
+func (rInfo roleInfo) unpack() (*ptypes.BasePermissions, bool) {
+	return rInfo.perms, rInfo.dirty
+}
+
+type callACLInfo struct {
+	acl   *ptypes.CallACL
+	dirty bool
+}
+
+func (aInfo callACLInfo) unpack() (*ptypes.CallACL, bool) {
+	return aInfo.acl, aInfo.dirty
+}
+
+type permGrantsInfo struct {
+	grants *ptypes.PermissionGrants
+	dirty  bool
+}
+
+func (gInfo permGrantsInfo) unpack() (*ptypes.PermissionGrants, bool) {
+	return gInfo.grants, gInfo.dirty
+}
@@ -17,6 +17,7 @@ package state
 import (
 	acm "github.com/hyperledger/burrow/account"
 	"github.com/hyperledger/burrow/manager/burrow-mint/evm"
+	ptypes "github.com/hyperledger/burrow/permission/types"
 	. "github.com/hyperledger/burrow/word256"
 )
 
@@ -24,6 +25,16 @@ type AccountGetter interface {
 	GetAccount(addr []byte) *acm.Account
 }
 
+// Accounts and the role definitions, call ACLs and grants needed to resolve
+// their permissions at the height of the block being executed
+type PermissionsGetter interface {
+	AccountGetter
+	ptypes.RoleGetter
+	GetCallACL(addr []byte) *ptypes.CallACL
+	GetPermissionGrants(addr []byte) *ptypes.PermissionGrants
+	BlockHeight() int
+}
+
 type VMAccountState interface {
 	GetAccount(addr Word256) *vm.Account
 	UpdateAccount(acc *vm.Account)
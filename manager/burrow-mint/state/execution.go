@@ -163,7 +163,7 @@ func execBlock(s *State, block *txs.Block, blockPartsHeader txs.PartSetHeader) e
 // acm.PubKey.(type) != nil, (it must be known),
 // or it must be specified in the TxInput.  If redeclared,
 // the TxInput is modified and input.PubKey set to nil.
-func getInputs(state AccountGetter, ins []*txs.TxInput) (map[string]*acm.Account, error) {
+func getInputs(state PermissionsGetter, ins []*txs.TxInput) (map[string]*acm.Account, error) {
 	accounts := map[string]*acm.Account{}
 	for _, in := range ins {
 		// Account shouldn't be duplicated
@@ -174,6 +174,9 @@ func getInputs(state AccountGetter, ins []*txs.TxInput) (map[string]*acm.Account
 		if acc == nil {
 			return nil, txs.ErrTxInvalidAddress
 		}
+		if !hasInputPermission(state, acc) {
+			return nil, fmt.Errorf("Account %X does not have Input permission", in.Address)
+		}
 		// PubKey should be present in either "account" or "in"
 		if err := checkInputPubKey(acc, in); err != nil {
 			return nil, err
@@ -183,7 +186,7 @@ func getInputs(state AccountGetter, ins []*txs.TxInput) (map[string]*acm.Account
 	return accounts, nil
 }
 
-func getOrMakeOutputs(state AccountGetter, accounts map[string]*acm.Account, outs []*txs.TxOutput) (map[string]*acm.Account, error) {
+func getOrMakeOutputs(state PermissionsGetter, accounts map[string]*acm.Account, outs []*txs.TxOutput) (map[string]*acm.Account, error) {
 	if accounts == nil {
 		accounts = make(map[string]*acm.Account)
 	}
@@ -223,7 +226,19 @@ func getOrMakeOutputs(state AccountGetter, accounts map[string]*acm.Account, out
 // transaction acting on behalf of that account we will be given a public key that we can check matches the address.
 // If it does then we will associate the public key with the stub account already registered in the system once and
 // for all time.
+// Multisig accounts never have a public key associated with them; the
+// MultisigPubKey accompanies every input and must hash to the address.
 func checkInputPubKey(acc *acm.Account, in *txs.TxInput) error {
+	if in.Multisig != nil {
+		if acc.PubKey != nil || in.Multisig.PubKey == nil {
+			return txs.ErrTxInvalidPubKey
+		}
+		if !bytes.Equal(in.Multisig.PubKey.Address(), acc.Address) {
+			return txs.ErrTxInvalidPubKey
+		}
+		in.PubKey = nil
+		return nil
+	}
 	if acc.PubKey == nil {
 		if in.PubKey == nil {
 			return txs.ErrTxUnknownPubKey
@@ -260,7 +275,11 @@ func validateInput(acc *acm.Account, signBytes []byte, in *txs.TxInput) (err err
 		return err
 	}
 	// Check signatures
-	if !acc.PubKey.VerifyBytes(signBytes, in.Signature) {
+	if in.Multisig != nil {
+		if !in.Multisig.VerifyBytes(signBytes) {
+			return txs.ErrTxInvalidSignature
+		}
+	} else if !acc.PubKey.VerifyBytes(signBytes, in.Signature) {
 		return txs.ErrTxInvalidSignature
 	}
 	// Check sequences
@@ -317,11 +336,24 @@ func adjustByOutputs(accounts map[string]*acm.Account, outs []*txs.TxOutput) {
 // If the tx is invalid, an error will be returned.
 // Unlike ExecBlock(), state will not be altered.
 func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable) (err error) {
+	return execTx(blockCache, tx, acm.SignBytes(blockCache.State().ChainID, tx), runCall, evc, false)
+}
+
+// Inputs are checked against signBytes, which are those of the tx itself
+// except for the txs of a BatchTx which are signed as part of the batch.
+// In a batch a failed call is an error so that the whole batch reverts.
+func execTx(blockCache *BlockCache, tx txs.Tx, signBytes []byte, runCall bool,
+	evc events.Fireable, inBatch bool) (err error) {
 
 	// TODO: do something with fees
 	fees := int64(0)
 	_s := blockCache.State() // hack to access validators and block height
 
+	// Reject txs that have expired by the height of the block being built
+	if err := txs.ValidateHeight(tx, _s.LastBlockHeight+1); err != nil {
+		return err
+	}
+
 	// Exec tx
 	switch tx := tx.(type) {
 	case *txs.SendTx:
@@ -342,7 +374,6 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			return err
 		}
 
-		signBytes := acm.SignBytes(_s.ChainID, tx)
 		inTotal, err := validateInputs(accounts, signBytes, tx.Inputs)
 		if err != nil {
 			return err
@@ -377,7 +408,7 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 		return nil
 
 	case *txs.CallTx:
-		var inAcc, outAcc *acm.Account
+		var inAcc *acm.Account
 
 		// Validate input
 		inAcc = blockCache.GetAccount(tx.Input.Address)
@@ -385,16 +416,8 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			log.Info(fmt.Sprintf("Can't find in account %X", tx.Input.Address))
 			return txs.ErrTxInvalidAddress
 		}
-
-		createContract := len(tx.Address) == 0
-		if createContract {
-			if !hasCreateContractPermission(blockCache, inAcc) {
-				return fmt.Errorf("Account %X does not have CreateContract permission", tx.Input.Address)
-			}
-		} else {
-			if !hasCallPermission(blockCache, inAcc) {
-				return fmt.Errorf("Account %X does not have Call permission", tx.Input.Address)
-			}
+		if err := checkCallPermissions(blockCache, inAcc, tx.Address); err != nil {
+			return err
 		}
 
 		// pubKey should be present in either "inAcc" or "tx.Input"
@@ -402,7 +425,6 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			log.Info(fmt.Sprintf("Can't find pubkey for %X", tx.Input.Address))
 			return err
 		}
-		signBytes := acm.SignBytes(_s.ChainID, tx)
 		err := validateInput(inAcc, signBytes, tx.Input)
 		if err != nil {
 			log.Info(fmt.Sprintf("validateInput failed on %X: %v", tx.Input.Address, err))
@@ -413,136 +435,60 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			return txs.ErrTxInsufficientFunds
 		}
 
-		if !createContract {
-			// Validate output
-			if len(tx.Address) != 20 {
-				log.Info(fmt.Sprintf("Destination address is not 20 bytes %X", tx.Address))
-				return txs.ErrTxInvalidAddress
-			}
-			// check if its a native contract
-			if vm.RegisteredNativeContract(LeftPadWord256(tx.Address)) {
-				return fmt.Errorf("NativeContracts can not be called using CallTx. Use a contract or the appropriate tx type (eg. PermissionsTx, NameTx)")
-			}
+		return execCall(blockCache, tx, inAcc, tx.Address, tx.Data, tx.GasLimit,
+			tx.Fee, tx.Input.Amount-tx.Fee, runCall, evc, inBatch)
 
-			// Output account may be nil if we are still in mempool and contract was created in same block as this tx
-			// but that's fine, because the account will be created properly when the create tx runs in the block
-			// and then this won't return nil. otherwise, we take their fee
-			outAcc = blockCache.GetAccount(tx.Address)
+	case *txs.EthTx:
+		sender, err := tx.Sender()
+		if err != nil {
+			return err
+		}
+		ethChainID, err := _s.EthChainID()
+		if err != nil {
+			return err
+		}
+		if tx.ChainID() != ethChainID {
+			return fmt.Errorf("EthTx is signed for chain id %v but this chain "+
+				"has id %v", tx.ChainID(), ethChainID)
 		}
 
-		log.Info(fmt.Sprintf("Out account: %v", outAcc))
-
-		// Good!
-		value := tx.Input.Amount - tx.Fee
-
-		inAcc.Sequence += 1
-		inAcc.Balance -= tx.Fee
-		blockCache.UpdateAccount(inAcc)
-
-		// The logic in runCall MUST NOT return.
-		if runCall {
-
-			// VM call variables
-			var (
-				gas     int64       = tx.GasLimit
-				err     error       = nil
-				caller  *vm.Account = toVMAccount(inAcc)
-				callee  *vm.Account = nil // initialized below
-				code    []byte      = nil
-				ret     []byte      = nil
-				txCache             = NewTxCache(blockCache)
-				params              = vm.Params{
-					BlockHeight: int64(_s.LastBlockHeight),
-					BlockHash:   LeftPadWord256(_s.LastBlockHash),
-					BlockTime:   _s.LastBlockTime.Unix(),
-					GasLimit:    _s.GetGasLimit(),
-				}
-			)
-
-			if !createContract && (outAcc == nil || len(outAcc.Code) == 0) {
-				// if you call an account that doesn't exist
-				// or an account with no code then we take fees (sorry pal)
-				// NOTE: it's fine to create a contract and call it within one
-				// block (nonce will prevent re-ordering of those txs)
-				// but to create with one contract and call with another
-				// you have to wait a block to avoid a re-ordering attack
-				// that will take your fees
-				if outAcc == nil {
-					log.Info(fmt.Sprintf("%X tries to call %X but it does not exist.",
-						inAcc.Address, tx.Address))
-				} else {
-					log.Info(fmt.Sprintf("%X tries to call %X but code is blank.",
-						inAcc.Address, tx.Address))
-				}
-				err = txs.ErrTxInvalidAddress
-				goto CALL_COMPLETE
-			}
-
-			// get or create callee
-			if createContract {
-				// We already checked for permission
-				callee = txCache.CreateAccount(caller)
-				log.Info(fmt.Sprintf("Created new contract %X", callee.Address))
-				code = tx.Data
-			} else {
-				callee = toVMAccount(outAcc)
-				log.Info(fmt.Sprintf("Calling contract %X with code %X", callee.Address, callee.Code))
-				code = callee.Code
-			}
-			log.Info(fmt.Sprintf("Code for this contract: %X", code))
-
-			// Run VM call and sync txCache to blockCache.
-			{ // Capture scope for goto.
-				// Write caller/callee to txCache.
-				txCache.UpdateAccount(caller)
-				txCache.UpdateAccount(callee)
-				vmach := vm.NewVM(txCache, params, caller.Address, txs.TxHash(_s.ChainID, tx))
-				vmach.SetFireable(evc)
-				// NOTE: Call() transfers the value from caller to callee iff call succeeds.
-				ret, err = vmach.Call(caller, callee, code, tx.Data, value, &gas)
-				if err != nil {
-					// Failure. Charge the gas fee. The 'value' was otherwise not transferred.
-					log.Info(fmt.Sprintf("Error on execution: %v", err))
-					goto CALL_COMPLETE
-				}
-
-				log.Info("Successful execution")
-				if createContract {
-					callee.Code = ret
-				}
-				txCache.Sync()
+		// Validate input, which is the account of the signer
+		inAcc := blockCache.GetAccount(sender)
+		if inAcc == nil {
+			log.Info(fmt.Sprintf("Can't find in account %X", sender))
+			return txs.ErrTxInvalidAddress
+		}
+		if err := checkCallPermissions(blockCache, inAcc, tx.To); err != nil {
+			return err
+		}
+		if inAcc.Sequence+1 != tx.Sequence() {
+			return txs.ErrTxInvalidSequence{
+				Got:      tx.Sequence(),
+				Expected: inAcc.Sequence + 1,
 			}
+		}
+		if inAcc.Balance < int64(tx.Value) {
+			return txs.ErrTxInsufficientFunds
+		}
 
-		CALL_COMPLETE: // err may or may not be nil.
-
-			// Create a receipt from the ret and whether errored.
-			log.Notice("VM call complete", "caller", caller, "callee", callee, "return", ret, "err", err)
+		return execCall(blockCache, tx, inAcc, tx.To, tx.Data,
+			int64(tx.GasLimit), 0, int64(tx.Value), runCall, evc, inBatch)
 
-			// Fire Events for sender and receiver
-			// a separate event will be fired from vm for each additional call
-			if evc != nil {
-				exception := ""
-				if err != nil {
-					exception = err.Error()
-				}
-				evc.FireEvent(txs.EventStringAccInput(tx.Input.Address), txs.EventDataTx{tx, ret, exception})
-				evc.FireEvent(txs.EventStringAccOutput(tx.Address), txs.EventDataTx{tx, ret, exception})
-			}
-		} else {
-			// The mempool does not call txs until
-			// the proposer determines the order of txs.
-			// So mempool will skip the actual .Call(),
-			// and only deduct from the caller's balance.
-			inAcc.Balance -= value
-			if createContract {
-				inAcc.Sequence += 1 // XXX ?!
-			}
-			blockCache.UpdateAccount(inAcc)
+	case *txs.NameTx:
+		// a NameTx registers plain data
+		nameTx := &txs.NameDataTx{
+			Input:    tx.Input,
+			Name:     tx.Name,
+			Data:     tx.Data,
+			Fee:      tx.Fee,
+			DataType: core_types.NameDataPlain,
 		}
+		return execNameTx(blockCache, tx, nameTx, signBytes, evc)
 
-		return nil
+	case *txs.NameDataTx:
+		return execNameTx(blockCache, tx, tx, signBytes, evc)
 
-	case *txs.NameTx:
+	case *txs.NameTransferTx:
 		var inAcc *acm.Account
 
 		// Validate input
@@ -551,6 +497,9 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			log.Info(fmt.Sprintf("Can't find in account %X", tx.Input.Address))
 			return txs.ErrTxInvalidAddress
 		}
+		if !hasInputPermission(blockCache, inAcc) {
+			return fmt.Errorf("Account %X does not have Input permission", tx.Input.Address)
+		}
 		// check permission
 		if !hasNamePermission(blockCache, inAcc) {
 			return fmt.Errorf("Account %X does not have Name permission", tx.Input.Address)
@@ -560,287 +509,214 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			log.Info(fmt.Sprintf("Can't find pubkey for %X", tx.Input.Address))
 			return err
 		}
-		signBytes := acm.SignBytes(_s.ChainID, tx)
 		err := validateInput(inAcc, signBytes, tx.Input)
 		if err != nil {
 			log.Info(fmt.Sprintf("validateInput failed on %X: %v", tx.Input.Address, err))
 			return err
 		}
-		// fee is in addition to the amount which is used to determine the TTL
-		if tx.Input.Amount < tx.Fee {
-			log.Info(fmt.Sprintf("Sender did not send enough to cover the fee %X", tx.Input.Address))
-			return txs.ErrTxInsufficientFunds
+		if len(tx.NewOwner) != 20 {
+			return txs.ErrTxInvalidAddress
+		}
+		// the input only pays the fee, so that nothing more is taken from it
+		if tx.Input.Amount != tx.Fee {
+			return txs.ErrTxInvalidAmount
 		}
 
-		// validate the input strings
-		if err := tx.ValidateStrings(); err != nil {
-			return err
+		lastBlockHeight := _s.LastBlockHeight
+
+		// only unexpired entries can be transferred, by their owner, the
+		// owner of a name above them or a name admin
+		entry := blockCache.GetNameRegEntry(tx.Name)
+		if entry == nil || entry.Expires <= lastBlockHeight {
+			return fmt.Errorf("Name %s is not registered", tx.Name)
+		}
+		if !bytes.Equal(entry.Owner, tx.Input.Address) &&
+			!txs.OwnsNameAncestor(blockCache, entry.Name, tx.Input.Address, lastBlockHeight) &&
+			!hasNameAdminPermission(blockCache, inAcc) {
+			log.Info(fmt.Sprintf("Sender %X is trying to transfer a name (%s) for which he is not owner", tx.Input.Address, tx.Name))
+			return txs.ErrTxPermissionDenied
 		}
 
-		value := tx.Input.Amount - tx.Fee
+		log.Info("Transferring namereg entry", "name", entry.Name, "owner", fmt.Sprintf("%X", entry.Owner),
+			"newOwner", fmt.Sprintf("%X", tx.NewOwner))
+		entry.Owner = tx.NewOwner
+		blockCache.UpdateNameRegEntry(entry)
 
-		// let's say cost of a name for one block is len(data) + 32
-		costPerBlock := txs.NameCostPerBlock(txs.NameBaseCost(tx.Name, tx.Data))
-		expiresIn := int(value / costPerBlock)
-		lastBlockHeight := _s.LastBlockHeight
+		// Good!
+		inAcc.Sequence += 1
+		inAcc.Balance -= tx.Fee
+		blockCache.UpdateAccount(inAcc)
 
-		log.Info("New NameTx", "value", value, "costPerBlock", costPerBlock, "expiresIn", expiresIn, "lastBlock", lastBlockHeight)
+		if evc != nil {
+			evc.FireEvent(txs.EventStringAccInput(tx.Input.Address), txs.EventDataTx{tx, nil, ""})
+			evc.FireEvent(txs.EventStringNameReg(tx.Name), txs.EventDataTx{tx, nil, ""})
+		}
 
-		// check if the name exists
-		entry := blockCache.GetNameRegEntry(tx.Name)
+		return nil
 
-		if entry != nil {
-			var expired bool
+	case *txs.BondTx:
+		valInfo := blockCache.GetValidatorInfo(tx.PubKey.Address())
+		if valInfo != nil {
+			// TODO: In the future, check that the validator wasn't destroyed,
+			// add funds, merge UnbondTo outputs, and unbond validator.
+			return errors.New("Adding coins to existing validators not yet supported")
+		}
 
-			// if the entry already exists, and hasn't expired, we must be owner
-			if entry.Expires > lastBlockHeight {
-				// ensure we are owner
-				if bytes.Compare(entry.Owner, tx.Input.Address) != 0 {
-					log.Info(fmt.Sprintf("Sender %X is trying to update a name (%s) for which he is not owner", tx.Input.Address, tx.Name))
-					return txs.ErrTxPermissionDenied
-				}
-			} else {
-				expired = true
-			}
+		accounts, err := getInputs(blockCache, tx.Inputs)
+		if err != nil {
+			return err
+		}
 
-			// no value and empty data means delete the entry
-			if value == 0 && len(tx.Data) == 0 {
-				// maybe we reward you for telling us we can delete this crap
-				// (owners if not expired, anyone if expired)
-				log.Info("Removing namereg entry", "name", entry.Name)
-				blockCache.RemoveNameRegEntry(entry.Name)
-			} else {
-				// update the entry by bumping the expiry
-				// and changing the data
-				if expired {
-					if expiresIn < txs.MinNameRegistrationPeriod {
-						return errors.New(fmt.Sprintf("Names must be registered for at least %d blocks", txs.MinNameRegistrationPeriod))
-					}
-					entry.Expires = lastBlockHeight + expiresIn
-					entry.Owner = tx.Input.Address
-					log.Info("An old namereg entry has expired and been reclaimed", "name", entry.Name, "expiresIn", expiresIn, "owner", entry.Owner)
-				} else {
-					// since the size of the data may have changed
-					// we use the total amount of "credit"
-					oldCredit := int64(entry.Expires-lastBlockHeight) * txs.NameBaseCost(entry.Name, entry.Data)
-					credit := oldCredit + value
-					expiresIn = int(credit / costPerBlock)
-					if expiresIn < txs.MinNameRegistrationPeriod {
-						return errors.New(fmt.Sprintf("Names must be registered for at least %d blocks", txs.MinNameRegistrationPeriod))
-					}
-					entry.Expires = lastBlockHeight + expiresIn
-					log.Info("Updated namereg entry", "name", entry.Name, "expiresIn", expiresIn, "oldCredit", oldCredit, "value", value, "credit", credit)
-				}
-				entry.Data = tx.Data
-				blockCache.UpdateNameRegEntry(entry)
-			}
-		} else {
-			if expiresIn < txs.MinNameRegistrationPeriod {
-				return errors.New(fmt.Sprintf("Names must be registered for at least %d blocks", txs.MinNameRegistrationPeriod))
+		// add outputs to accounts map
+		// if any outputs don't exist, all inputs must have CreateAccount perm
+		// though outputs aren't created until unbonding/release time
+		canCreate := hasCreateAccountPermission(blockCache, accounts)
+		for _, out := range tx.UnbondTo {
+			acc := blockCache.GetAccount(out.Address)
+			if acc == nil && !canCreate {
+				return fmt.Errorf("At least one input does not have permission to create accounts")
 			}
-			// entry does not exist, so create it
-			entry = &core_types.NameRegEntry{
-				Name:    tx.Name,
-				Owner:   tx.Input.Address,
-				Data:    tx.Data,
-				Expires: lastBlockHeight + expiresIn,
+		}
+
+		bondAcc := blockCache.GetAccount(tx.PubKey.Address())
+		if !hasBondPermission(blockCache, bondAcc) {
+			return fmt.Errorf("The bonder does not have permission to bond")
+		}
+
+		if !hasBondOrSendPermission(blockCache, accounts) {
+			return fmt.Errorf("At least one input lacks permission to bond")
+		}
+
+		inTotal, err := validateInputs(accounts, signBytes, tx.Inputs)
+		if err != nil {
+			return err
+		}
+		if !tx.PubKey.VerifyBytes(signBytes, tx.Signature) {
+			return txs.ErrTxInvalidSignature
+		}
+		outTotal, err := validateOutputs(tx.UnbondTo)
+		if err != nil {
+			return err
+		}
+		if outTotal > inTotal {
+			return txs.ErrTxInsufficientFunds
+		}
+		if minBondAmount := blockCache.GetChainParams().MinBondAmount; outTotal < minBondAmount {
+			return fmt.Errorf("Bond amount %v is below the minimum bond amount %v", outTotal, minBondAmount)
+		}
+		fee := inTotal - outTotal
+		fees += fee
+
+		// Good! Adjust accounts
+		adjustByInputs(accounts, tx.Inputs)
+		for _, acc := range accounts {
+			blockCache.UpdateAccount(acc)
+		}
+		// Add ValidatorInfo, the validator joins the validator set at the end of the block
+		blockCache.UpdateValidatorInfo(&ValidatorInfo{
+			Address:         tx.PubKey.Address(),
+			PubKey:          tx.PubKey,
+			UnbondTo:        tx.UnbondTo,
+			FirstBondHeight: _s.LastBlockHeight + 1,
+			FirstBondAmount: outTotal,
+			BondHeight:      _s.LastBlockHeight + 1,
+			VotingPower:     outTotal,
+		})
+		if evc != nil {
+			for _, i := range tx.Inputs {
+				evc.FireEvent(txs.EventStringAccInput(i.Address), txs.EventDataTx{tx, nil, ""})
 			}
-			log.Info("Creating namereg entry", "name", entry.Name, "expiresIn", expiresIn)
-			blockCache.UpdateNameRegEntry(entry)
+			evc.FireEvent(txs.EventStringBond(), txs.EventDataTx{tx, nil, ""})
 		}
+		return nil
 
-		// TODO: something with the value sent?
+	case *txs.UnbondTx:
+		// The validator must be active
+		valInfo := blockCache.GetValidatorInfo(tx.Address)
+		if valInfo == nil || !valInfo.IsBonded() {
+			return txs.ErrTxInvalidAddress
+		}
 
-		// Good!
-		inAcc.Sequence += 1
-		inAcc.Balance -= value
-		blockCache.UpdateAccount(inAcc)
+		// Verify the signature
+		if !valInfo.PubKey.VerifyBytes(signBytes, tx.Signature) {
+			return txs.ErrTxInvalidSignature
+		}
 
-		// TODO: maybe we want to take funds on error and allow txs in that don't do anythingi?
+		// tx.Height must not predate the current bond, so that an UnbondTx
+		// cannot be replayed against a validator that has since rebonded
+		if tx.Height < valInfo.BondHeight || tx.Height > _s.LastBlockHeight+1 {
+			return errors.New("Invalid unbond height")
+		}
 
+		// Good!
+		unbondValidator(blockCache, valInfo)
 		if evc != nil {
-			evc.FireEvent(txs.EventStringAccInput(tx.Input.Address), txs.EventDataTx{tx, nil, ""})
-			evc.FireEvent(txs.EventStringNameReg(tx.Name), txs.EventDataTx{tx, nil, ""})
+			evc.FireEvent(txs.EventStringUnbond(), txs.EventDataTx{tx, nil, ""})
+		}
+		return nil
+
+	case *txs.RebondTx:
+		// The validator must be inactive
+		valInfo := blockCache.GetValidatorInfo(tx.Address)
+		if valInfo == nil || !valInfo.IsUnbonding() {
+			return txs.ErrTxInvalidAddress
+		}
+
+		// Verify the signature
+		if !valInfo.PubKey.VerifyBytes(signBytes, tx.Signature) {
+			return txs.ErrTxInvalidSignature
+		}
+
+		// tx.Height must be in a suitable range
+		minRebondHeight := _s.LastBlockHeight - (validatorTimeoutBlocks / 2)
+		maxRebondHeight := _s.LastBlockHeight + 2
+		if !((minRebondHeight <= tx.Height) && (tx.Height <= maxRebondHeight)) {
+			return fmt.Errorf("Rebond height not in range.  Expected %v <= %v <= %v",
+				minRebondHeight, tx.Height, maxRebondHeight)
 		}
 
+		// Good!
+		rebondValidator(blockCache, valInfo)
+		if evc != nil {
+			evc.FireEvent(txs.EventStringRebond(), txs.EventDataTx{tx, nil, ""})
+		}
 		return nil
 
-		// Consensus related Txs inactivated for now
-		// TODO!
-		/*
-			case *txs.BondTx:
-						valInfo := blockCache.State().GetValidatorInfo(tx.PubKey.Address())
-						if valInfo != nil {
-							// TODO: In the future, check that the validator wasn't destroyed,
-							// add funds, merge UnbondTo outputs, and unbond validator.
-							return errors.New("Adding coins to existing validators not yet supported")
-						}
-
-						accounts, err := getInputs(blockCache, tx.Inputs)
-						if err != nil {
-							return err
-						}
-
-						// add outputs to accounts map
-						// if any outputs don't exist, all inputs must have CreateAccount perm
-						// though outputs aren't created until unbonding/release time
-						canCreate := hasCreateAccountPermission(blockCache, accounts)
-						for _, out := range tx.UnbondTo {
-							acc := blockCache.GetAccount(out.Address)
-							if acc == nil && !canCreate {
-								return fmt.Errorf("At least one input does not have permission to create accounts")
-							}
-						}
-
-						bondAcc := blockCache.GetAccount(tx.PubKey.Address())
-						if !hasBondPermission(blockCache, bondAcc) {
-							return fmt.Errorf("The bonder does not have permission to bond")
-						}
-
-						if !hasBondOrSendPermission(blockCache, accounts) {
-							return fmt.Errorf("At least one input lacks permission to bond")
-						}
-
-						signBytes := acm.SignBytes(_s.ChainID, tx)
-						inTotal, err := validateInputs(accounts, signBytes, tx.Inputs)
-						if err != nil {
-							return err
-						}
-						if !tx.PubKey.VerifyBytes(signBytes, tx.Signature) {
-							return txs.ErrTxInvalidSignature
-						}
-						outTotal, err := validateOutputs(tx.UnbondTo)
-						if err != nil {
-							return err
-						}
-						if outTotal > inTotal {
-							return txs.ErrTxInsufficientFunds
-						}
-						fee := inTotal - outTotal
-						fees += fee
-
-						// Good! Adjust accounts
-						adjustByInputs(accounts, tx.Inputs)
-						for _, acc := range accounts {
-							blockCache.UpdateAccount(acc)
-						}
-						// Add ValidatorInfo
-						_s.SetValidatorInfo(&txs.ValidatorInfo{
-							Address:         tx.PubKey.Address(),
-							PubKey:          tx.PubKey,
-							UnbondTo:        tx.UnbondTo,
-							FirstBondHeight: _s.LastBlockHeight + 1,
-							FirstBondAmount: outTotal,
-						})
-						// Add Validator
-						added := _s.BondedValidators.Add(&txs.Validator{
-							Address:     tx.PubKey.Address(),
-							PubKey:      tx.PubKey,
-							BondHeight:  _s.LastBlockHeight + 1,
-							VotingPower: outTotal,
-							Accum:       0,
-						})
-						if !added {
-							PanicCrisis("Failed to add validator")
-						}
-						if evc != nil {
-							// TODO: fire for all inputs
-							evc.FireEvent(txs.EventStringBond(), txs.EventDataTx{tx, nil, ""})
-						}
-						return nil
-
-					case *txs.UnbondTx:
-						// The validator must be active
-						_, val := _s.BondedValidators.GetByAddress(tx.Address)
-						if val == nil {
-							return txs.ErrTxInvalidAddress
-						}
-
-						// Verify the signature
-						signBytes := acm.SignBytes(_s.ChainID, tx)
-						if !val.PubKey.VerifyBytes(signBytes, tx.Signature) {
-							return txs.ErrTxInvalidSignature
-						}
-
-						// tx.Height must be greater than val.LastCommitHeight
-						if tx.Height <= val.LastCommitHeight {
-							return errors.New("Invalid unbond height")
-						}
-
-						// Good!
-						_s.unbondValidator(val)
-						if evc != nil {
-							evc.FireEvent(txs.EventStringUnbond(), txs.EventDataTx{tx, nil, ""})
-						}
-						return nil
-
-					case *txs.RebondTx:
-						// The validator must be inactive
-						_, val := _s.UnbondingValidators.GetByAddress(tx.Address)
-						if val == nil {
-							return txs.ErrTxInvalidAddress
-						}
-
-						// Verify the signature
-						signBytes := acm.SignBytes(_s.ChainID, tx)
-						if !val.PubKey.VerifyBytes(signBytes, tx.Signature) {
-							return txs.ErrTxInvalidSignature
-						}
-
-						// tx.Height must be in a suitable range
-						minRebondHeight := _s.LastBlockHeight - (validatorTimeoutBlocks / 2)
-						maxRebondHeight := _s.LastBlockHeight + 2
-						if !((minRebondHeight <= tx.Height) && (tx.Height <= maxRebondHeight)) {
-							return errors.New(Fmt("Rebond height not in range.  Expected %v <= %v <= %v",
-								minRebondHeight, tx.Height, maxRebondHeight))
-						}
-
-						// Good!
-						_s.rebondValidator(val)
-						if evc != nil {
-							evc.FireEvent(txs.EventStringRebond(), txs.EventDataTx{tx, nil, ""})
-						}
-						return nil
-
-					case *txs.DupeoutTx:
-						// Verify the signatures
-						_, accused := _s.BondedValidators.GetByAddress(tx.Address)
-						if accused == nil {
-							_, accused = _s.UnbondingValidators.GetByAddress(tx.Address)
-							if accused == nil {
-								return txs.ErrTxInvalidAddress
-							}
-						}
-						voteASignBytes := acm.SignBytes(_s.ChainID, &tx.VoteA)
-						voteBSignBytes := acm.SignBytes(_s.ChainID, &tx.VoteB)
-						if !accused.PubKey.VerifyBytes(voteASignBytes, tx.VoteA.Signature) ||
-							!accused.PubKey.VerifyBytes(voteBSignBytes, tx.VoteB.Signature) {
-							return txs.ErrTxInvalidSignature
-						}
-
-						// Verify equivocation
-						// TODO: in the future, just require one vote from a previous height that
-						// doesn't exist on this chain.
-						if tx.VoteA.Height != tx.VoteB.Height {
-							return errors.New("DupeoutTx heights don't match")
-						}
-						if tx.VoteA.Round != tx.VoteB.Round {
-							return errors.New("DupeoutTx rounds don't match")
-						}
-						if tx.VoteA.Type != tx.VoteB.Type {
-							return errors.New("DupeoutTx types don't match")
-						}
-						if bytes.Equal(tx.VoteA.BlockHash, tx.VoteB.BlockHash) {
-							return errors.New("DupeoutTx blockhashes shouldn't match")
-						}
-
-						// Good! (Bad validator!)
-						_s.destroyValidator(accused)
-						if evc != nil {
-							evc.FireEvent(txs.EventStringDupeout(), txs.EventDataTx{tx, nil, ""})
-						}
-						return nil
-		*/
+	case *txs.DupeoutTx:
+		// Verify the signatures
+		accused := blockCache.GetValidatorInfo(tx.Address)
+		if accused == nil || !(accused.IsBonded() || accused.IsUnbonding()) {
+			return txs.ErrTxInvalidAddress
+		}
+		voteASignBytes := acm.SignBytes(_s.ChainID, &tx.VoteA)
+		voteBSignBytes := acm.SignBytes(_s.ChainID, &tx.VoteB)
+		if !accused.PubKey.VerifyBytes(voteASignBytes, tx.VoteA.Signature) ||
+			!accused.PubKey.VerifyBytes(voteBSignBytes, tx.VoteB.Signature) {
+			return txs.ErrTxInvalidSignature
+		}
+
+		// Verify equivocation
+		// TODO: in the future, just require one vote from a previous height that
+		// doesn't exist on this chain.
+		if tx.VoteA.Height != tx.VoteB.Height {
+			return errors.New("DupeoutTx heights don't match")
+		}
+		if tx.VoteA.Round != tx.VoteB.Round {
+			return errors.New("DupeoutTx rounds don't match")
+		}
+		if tx.VoteA.Type != tx.VoteB.Type {
+			return errors.New("DupeoutTx types don't match")
+		}
+		if tx.VoteA.BlockID.Equals(tx.VoteB.BlockID) {
+			return errors.New("DupeoutTx blockhashes shouldn't match")
+		}
+
+		// Good! (Bad validator!)
+		destroyValidator(blockCache, accused)
+		if evc != nil {
+			evc.FireEvent(txs.EventStringDupeout(), txs.EventDataTx{tx, nil, ""})
+		}
+		return nil
 
 	case *txs.PermissionsTx:
 		var inAcc *acm.Account
@@ -851,6 +727,9 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			log.Debug(fmt.Sprintf("Can't find in account %X", tx.Input.Address))
 			return txs.ErrTxInvalidAddress
 		}
+		if !hasInputPermission(blockCache, inAcc) {
+			return fmt.Errorf("Account %X does not have Input permission", tx.Input.Address)
+		}
 
 		permFlag := tx.PermArgs.PermFlag()
 		// check permission
@@ -863,7 +742,6 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			log.Debug(fmt.Sprintf("Can't find pubkey for %X", tx.Input.Address))
 			return err
 		}
-		signBytes := acm.SignBytes(_s.ChainID, tx)
 		err := validateInput(inAcc, signBytes, tx.Input)
 		if err != nil {
 			log.Debug(fmt.Sprintf("validateInput failed on %X: %v", tx.Input.Address, err))
@@ -875,6 +753,11 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 		log.Debug("New PermissionsTx", "function", ptypes.PermFlagToString(permFlag), "args", tx.PermArgs)
 
 		var permAcc *acm.Account
+		// every successful PermissionsTx is recorded in the permission change log
+		change := &ptypes.PermissionChange{
+			Actor:  tx.Input.Address,
+			TxHash: txs.TxHash(_s.ChainID, tx),
+		}
 		switch args := tx.PermArgs.(type) {
 		case *ptypes.HasBaseArgs:
 			// this one doesn't make sense from txs
@@ -883,16 +766,49 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			if permAcc = blockCache.GetAccount(args.Address); permAcc == nil {
 				return fmt.Errorf("Trying to update permissions for unknown account %X", args.Address)
 			}
-			err = permAcc.Permissions.Base.Set(args.Permission, args.Value)
+			change.Target, change.Function, change.Permission = args.Address, "setBase", args.Permission
+			change.OldValue = ptypes.PermValueOf(&permAcc.Permissions.Base, args.Permission)
+			change.NewValue = ptypes.PermValueFromBool(args.Value)
+			if err = permAcc.Permissions.Base.Set(args.Permission, args.Value); err == nil {
+				updatePermissionGrants(blockCache, args.Address, func(grants *ptypes.PermissionGrants) {
+					grants.SetBaseExpiry(args.Permission, 0)
+				})
+			}
+		case *ptypes.SetBaseUntilArgs:
+			if permAcc = blockCache.GetAccount(args.Address); permAcc == nil {
+				return fmt.Errorf("Trying to update permissions for unknown account %X", args.Address)
+			}
+			if ptypes.GrantExpiredAt(args.ValidUntilHeight, blockCache.BlockHeight()) {
+				return fmt.Errorf("Expiry height %v has already passed", args.ValidUntilHeight)
+			}
+			change.Target, change.Function, change.Permission = args.Address, "setBaseUntil", args.Permission
+			change.OldValue = ptypes.PermValueOf(&permAcc.Permissions.Base, args.Permission)
+			change.NewValue = ptypes.PermValueFromBool(args.Value)
+			change.ValidUntilHeight = args.ValidUntilHeight
+			if err = permAcc.Permissions.Base.Set(args.Permission, args.Value); err == nil {
+				updatePermissionGrants(blockCache, args.Address, func(grants *ptypes.PermissionGrants) {
+					grants.SetBaseExpiry(args.Permission, args.ValidUntilHeight)
+				})
+			}
 		case *ptypes.UnsetBaseArgs:
 			if permAcc = blockCache.GetAccount(args.Address); permAcc == nil {
 				return fmt.Errorf("Trying to update permissions for unknown account %X", args.Address)
 			}
-			err = permAcc.Permissions.Base.Unset(args.Permission)
+			change.Target, change.Function, change.Permission = args.Address, "unsetBase", args.Permission
+			change.OldValue = ptypes.PermValueOf(&permAcc.Permissions.Base, args.Permission)
+			change.NewValue = ptypes.PermValueUnset
+			if err = permAcc.Permissions.Base.Unset(args.Permission); err == nil {
+				updatePermissionGrants(blockCache, args.Address, func(grants *ptypes.PermissionGrants) {
+					grants.SetBaseExpiry(args.Permission, 0)
+				})
+			}
 		case *ptypes.SetGlobalArgs:
 			if permAcc = blockCache.GetAccount(ptypes.GlobalPermissionsAddress); permAcc == nil {
 				sanity.PanicSanity("can't find global permissions account")
 			}
+			change.Target, change.Function, change.Permission = ptypes.GlobalPermissionsAddress, "setGlobal", args.Permission
+			change.OldValue = ptypes.PermValueOf(&permAcc.Permissions.Base, args.Permission)
+			change.NewValue = ptypes.PermValueFromBool(args.Value)
 			err = permAcc.Permissions.Base.Set(args.Permission, args.Value)
 		case *ptypes.HasRoleArgs:
 			return fmt.Errorf("HasRole is for contracts, not humans. Just look at the blockchain")
@@ -900,9 +816,35 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			if permAcc = blockCache.GetAccount(args.Address); permAcc == nil {
 				return fmt.Errorf("Trying to update roles for unknown account %X", args.Address)
 			}
-			if !permAcc.Permissions.AddRole(args.Role) {
+			// an expired role may be added again, and one held until some
+			// height is made permanent
+			grants := blockCache.GetPermissionGrants(args.Address)
+			held := permAcc.Permissions.HasRoleAt(args.Role, grants, blockCache.BlockHeight())
+			if held && grants.RoleExpiry(args.Role) == 0 {
 				return fmt.Errorf("Role (%s) already exists for account %X", args.Role, args.Address)
 			}
+			permAcc.Permissions.AddRole(args.Role)
+			updatePermissionGrants(blockCache, args.Address, func(grants *ptypes.PermissionGrants) {
+				grants.SetRoleExpiry(args.Role, 0)
+			})
+			change.Target, change.Function, change.Role = args.Address, "addRole", ptypes.PadRole(args.Role)
+			change.OldValue, change.NewValue = ptypes.PermValueFromBool(held), ptypes.PermValueTrue
+		case *ptypes.AddRoleUntilArgs:
+			if permAcc = blockCache.GetAccount(args.Address); permAcc == nil {
+				return fmt.Errorf("Trying to update roles for unknown account %X", args.Address)
+			}
+			height := blockCache.BlockHeight()
+			if ptypes.GrantExpiredAt(args.ValidUntilHeight, height) {
+				return fmt.Errorf("Expiry height %v has already passed", args.ValidUntilHeight)
+			}
+			held := permAcc.Permissions.HasRoleAt(args.Role, blockCache.GetPermissionGrants(args.Address), height)
+			permAcc.Permissions.AddRole(args.Role)
+			updatePermissionGrants(blockCache, args.Address, func(grants *ptypes.PermissionGrants) {
+				grants.SetRoleExpiry(args.Role, args.ValidUntilHeight)
+			})
+			change.Target, change.Function, change.Role = args.Address, "addRoleUntil", ptypes.PadRole(args.Role)
+			change.OldValue, change.NewValue = ptypes.PermValueFromBool(held), ptypes.PermValueTrue
+			change.ValidUntilHeight = args.ValidUntilHeight
 		case *ptypes.RmRoleArgs:
 			if permAcc = blockCache.GetAccount(args.Address); permAcc == nil {
 				return fmt.Errorf("Trying to update roles for unknown account %X", args.Address)
@@ -910,6 +852,132 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 			if !permAcc.Permissions.RmRole(args.Role) {
 				return fmt.Errorf("Role (%s) does not exist for account %X", args.Role, args.Address)
 			}
+			updatePermissionGrants(blockCache, args.Address, func(grants *ptypes.PermissionGrants) {
+				grants.SetRoleExpiry(args.Role, 0)
+			})
+			change.Target, change.Function, change.Role = args.Address, "removeRole", ptypes.PadRole(args.Role)
+			change.OldValue, change.NewValue = ptypes.PermValueTrue, ptypes.PermValueFalse
+		case *ptypes.SetRolePermissionArgs:
+			rolePerms := ptypes.ZeroBasePermissions
+			if perms := blockCache.GetRolePermissions(args.Role); perms != nil {
+				rolePerms = *perms
+			}
+			change.Function, change.Permission, change.Role = "setRolePermission", args.Permission, ptypes.PadRole(args.Role)
+			change.OldValue = ptypes.PermValueOf(&rolePerms, args.Permission)
+			change.NewValue = ptypes.PermValueFromBool(args.Value)
+			if err = rolePerms.Set(args.Permission, args.Value); err == nil {
+				blockCache.SetRolePermissions(args.Role, &rolePerms)
+			}
+		case *ptypes.UnsetRolePermissionArgs:
+			perms := blockCache.GetRolePermissions(args.Role)
+			if perms == nil {
+				return fmt.Errorf("Role (%s) has not been defined", args.Role)
+			}
+			rolePerms := *perms
+			change.Function, change.Permission, change.Role = "unsetRolePermission", args.Permission, ptypes.PadRole(args.Role)
+			change.OldValue = ptypes.PermValueOf(&rolePerms, args.Permission)
+			change.NewValue = ptypes.PermValueUnset
+			if err = rolePerms.Unset(args.Permission); err == nil {
+				blockCache.SetRolePermissions(args.Role, &rolePerms)
+			}
+		case *ptypes.AddCalleeArgs:
+			var acl *ptypes.CallACL
+			if acl, err = callACLForUpdate(blockCache, args.Address); err != nil {
+				return err
+			}
+			if !acl.AddCallee(args.Callee) {
+				return fmt.Errorf("Callee %X already allowed for account %X", args.Callee, args.Address)
+			}
+			blockCache.SetCallACL(args.Address, acl)
+			change.Target, change.Function, change.Subject = args.Address, "addCallee", args.Callee
+			change.OldValue, change.NewValue = ptypes.PermValueFalse, ptypes.PermValueTrue
+		case *ptypes.RmCalleeArgs:
+			var acl *ptypes.CallACL
+			if acl, err = callACLForUpdate(blockCache, args.Address); err != nil {
+				return err
+			}
+			if !acl.RmCallee(args.Callee) {
+				return fmt.Errorf("Callee %X is not allowed for account %X", args.Callee, args.Address)
+			}
+			blockCache.SetCallACL(args.Address, acl)
+			change.Target, change.Function, change.Subject = args.Address, "removeCallee", args.Callee
+			change.OldValue, change.NewValue = ptypes.PermValueTrue, ptypes.PermValueFalse
+		case *ptypes.AddCallerArgs:
+			var acl *ptypes.CallACL
+			if acl, err = callACLForUpdate(blockCache, args.Address); err != nil {
+				return err
+			}
+			if !acl.AddCaller(args.Caller) {
+				return fmt.Errorf("Caller %X already allowed for account %X", args.Caller, args.Address)
+			}
+			blockCache.SetCallACL(args.Address, acl)
+			change.Target, change.Function, change.Subject = args.Address, "addCaller", args.Caller
+			change.OldValue, change.NewValue = ptypes.PermValueFalse, ptypes.PermValueTrue
+		case *ptypes.RmCallerArgs:
+			var acl *ptypes.CallACL
+			if acl, err = callACLForUpdate(blockCache, args.Address); err != nil {
+				return err
+			}
+			if !acl.RmCaller(args.Caller) {
+				return fmt.Errorf("Caller %X is not allowed for account %X", args.Caller, args.Address)
+			}
+			blockCache.SetCallACL(args.Address, acl)
+			change.Target, change.Function, change.Subject = args.Address, "removeCaller", args.Caller
+			change.OldValue, change.NewValue = ptypes.PermValueTrue, ptypes.PermValueFalse
+		case *ptypes.AddCallerRoleArgs:
+			var acl *ptypes.CallACL
+			if acl, err = callACLForUpdate(blockCache, args.Address); err != nil {
+				return err
+			}
+			if !acl.AddCallerRole(args.Role) {
+				return fmt.Errorf("Caller role (%s) already allowed for account %X", args.Role, args.Address)
+			}
+			blockCache.SetCallACL(args.Address, acl)
+			change.Target, change.Function, change.Role = args.Address, "addCallerRole", ptypes.PadRole(args.Role)
+			change.OldValue, change.NewValue = ptypes.PermValueFalse, ptypes.PermValueTrue
+		case *ptypes.RmCallerRoleArgs:
+			var acl *ptypes.CallACL
+			if acl, err = callACLForUpdate(blockCache, args.Address); err != nil {
+				return err
+			}
+			if !acl.RmCallerRole(args.Role) {
+				return fmt.Errorf("Caller role (%s) is not allowed for account %X", args.Role, args.Address)
+			}
+			blockCache.SetCallACL(args.Address, acl)
+			change.Target, change.Function, change.Role = args.Address, "removeCallerRole", ptypes.PadRole(args.Role)
+			change.OldValue, change.NewValue = ptypes.PermValueTrue, ptypes.PermValueFalse
+		case *ptypes.DelegatePermissionArgs:
+			if blockCache.GetAccount(args.Delegate) == nil {
+				return fmt.Errorf("Trying to delegate permissions to unknown account %X", args.Delegate)
+			}
+			if !ptypes.IsSinglePermFlag(args.Permission) {
+				return fmt.Errorf("Only a single permission can be delegated, not %b", args.Permission)
+			}
+			if ptypes.GrantExpiredAt(args.ValidUntilHeight, blockCache.BlockHeight()) {
+				return fmt.Errorf("Expiry height %v has already passed", args.ValidUntilHeight)
+			}
+			if !hasOwnPermission(blockCache, inAcc, args.Permission) {
+				return fmt.Errorf("Account %X cannot delegate permission %s it does not have",
+					tx.Input.Address, ptypes.PermFlagToString(args.Permission))
+			}
+			added := false
+			updatePermissionGrants(blockCache, args.Delegate, func(grants *ptypes.PermissionGrants) {
+				added = grants.Delegate(tx.Input.Address, args.Permission, args.ValidUntilHeight)
+			})
+			change.Target, change.Function, change.Permission = args.Delegate, "delegatePermission", args.Permission
+			change.OldValue, change.NewValue = ptypes.PermValueFromBool(!added), ptypes.PermValueTrue
+			change.ValidUntilHeight = args.ValidUntilHeight
+		case *ptypes.RevokeDelegationArgs:
+			revoked := false
+			updatePermissionGrants(blockCache, args.Delegate, func(grants *ptypes.PermissionGrants) {
+				revoked = grants.RevokeDelegation(tx.Input.Address, args.Permission)
+			})
+			if !revoked {
+				return fmt.Errorf("Permission %s has not been delegated to account %X",
+					ptypes.PermFlagToString(args.Permission), args.Delegate)
+			}
+			change.Target, change.Function, change.Permission = args.Delegate, "revokeDelegation", args.Permission
+			change.OldValue, change.NewValue = ptypes.PermValueTrue, ptypes.PermValueFalse
 		default:
 			sanity.PanicSanity(fmt.Sprintf("invalid permission function: %s", ptypes.PermFlagToString(permFlag)))
 		}
@@ -926,6 +994,7 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 		if permAcc != nil {
 			blockCache.UpdateAccount(permAcc)
 		}
+		blockCache.AppendPermissionChange(change)
 
 		if evc != nil {
 			evc.FireEvent(txs.EventStringAccInput(tx.Input.Address), txs.EventDataTx{tx, nil, ""})
@@ -934,6 +1003,112 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 
 		return nil
 
+	case *txs.GovTx:
+		var inAcc *acm.Account
+
+		// Validate input
+		inAcc = blockCache.GetAccount(tx.Input.Address)
+		if inAcc == nil {
+			log.Debug(fmt.Sprintf("Can't find in account %X", tx.Input.Address))
+			return txs.ErrTxInvalidAddress
+		}
+		if !hasInputPermission(blockCache, inAcc) {
+			return fmt.Errorf("Account %X does not have Input permission", tx.Input.Address)
+		}
+		if !hasGovernancePermission(blockCache, inAcc) {
+			return fmt.Errorf("Account %X does not have governance permission", tx.Input.Address)
+		}
+
+		// pubKey should be present in either "inAcc" or "tx.Input"
+		if err := checkInputPubKey(inAcc, tx.Input); err != nil {
+			log.Debug(fmt.Sprintf("Can't find pubkey for %X", tx.Input.Address))
+			return err
+		}
+		err := validateInput(inAcc, signBytes, tx.Input)
+		if err != nil {
+			log.Debug(fmt.Sprintf("validateInput failed on %X: %v", tx.Input.Address, err))
+			return err
+		}
+
+		// changes can only be scheduled for a block that is yet to be run
+		if tx.Height <= _s.LastBlockHeight+1 {
+			return fmt.Errorf("GovTx height %v must be after the current block height %v",
+				tx.Height, _s.LastBlockHeight+1)
+		}
+		if tx.Params == nil && tx.GlobalPermissions == nil {
+			return fmt.Errorf("GovTx must change the chain parameters or the global permissions")
+		}
+		if tx.Params != nil {
+			if err := tx.Params.ValidateBasic(); err != nil {
+				return err
+			}
+		}
+
+		value := tx.Input.Amount
+
+		log.Debug("New GovTx", "height", tx.Height, "params", tx.Params,
+			"global_permissions", tx.GlobalPermissions)
+
+		proposals := blockCache.GetGovProposals(tx.Height)
+		// copy so the cached list is not shared with the backend
+		newProposals := make([]*txs.GovProposal, len(proposals), len(proposals)+1)
+		copy(newProposals, proposals)
+		newProposals = append(newProposals, &txs.GovProposal{
+			Proposer:          tx.Input.Address,
+			TxHash:            txs.TxHash(_s.ChainID, tx),
+			Height:            tx.Height,
+			Params:            tx.Params,
+			GlobalPermissions: tx.GlobalPermissions,
+		})
+		blockCache.SetGovProposals(tx.Height, newProposals)
+
+		// Good!
+		inAcc.Sequence += 1
+		inAcc.Balance -= value
+		blockCache.UpdateAccount(inAcc)
+
+		if evc != nil {
+			evc.FireEvent(txs.EventStringAccInput(tx.Input.Address), txs.EventDataTx{tx, nil, ""})
+			evc.FireEvent(txs.EventStringGov(), txs.EventDataTx{tx, nil, ""})
+		}
+
+		return nil
+
+	case *txs.BatchTx:
+		if len(tx.Txs) == 0 || len(tx.Txs) > txs.MaxBatchTxs {
+			return fmt.Errorf("BatchTx must contain between 1 and %v txs, got %v",
+				txs.MaxBatchTxs, len(tx.Txs))
+		}
+
+		// Run the batch against a copy of the cache and hold back its events
+		// until we know that every tx succeeded
+		batchCache := blockCache.Copy()
+		var batchEvc *events.EventCache
+		var innerEvc events.Fireable
+		if evc != nil {
+			batchEvc = events.NewEventCache(evc)
+			innerEvc = batchEvc
+		}
+		for i, innerTx := range tx.Txs {
+			switch innerTx.(type) {
+			case *txs.SendTx, *txs.CallTx, *txs.NameTx, *txs.NameTransferTx, *txs.NameDataTx,
+				*txs.PermissionsTx:
+			default:
+				return fmt.Errorf("BatchTx can not contain %T", innerTx)
+			}
+			if err := execTx(batchCache, innerTx, signBytes, runCall, innerEvc, true); err != nil {
+				log.Info("BatchTx reverted", "tx", i, "error", err)
+				return fmt.Errorf("BatchTx reverted, tx %v failed: %v", i, err)
+			}
+		}
+
+		// Good! Keep the changes made by the batch
+		*blockCache = *batchCache
+		if batchEvc != nil {
+			batchEvc.Flush()
+		}
+		return nil
+
 	default:
 		// binary decoding should not let this happen
 		sanity.PanicSanity("Unknown Tx type")
@@ -941,28 +1116,379 @@ func ExecTx(blockCache *BlockCache, tx txs.Tx, runCall bool, evc events.Fireable
 	}
 }
 
+// Executes tx, a NameTx or NameDataTx whose fields nameTx holds. Its events
+// carry tx itself.
+func execNameTx(blockCache *BlockCache, tx txs.Tx, nameTx *txs.NameDataTx, signBytes []byte,
+	evc events.Fireable) error {
+	var inAcc *acm.Account
+
+	// Validate input
+	inAcc = blockCache.GetAccount(nameTx.Input.Address)
+	if inAcc == nil {
+		log.Info(fmt.Sprintf("Can't find in account %X", nameTx.Input.Address))
+		return txs.ErrTxInvalidAddress
+	}
+	if !hasInputPermission(blockCache, inAcc) {
+		return fmt.Errorf("Account %X does not have Input permission", nameTx.Input.Address)
+	}
+	// check permission
+	if !hasNamePermission(blockCache, inAcc) {
+		return fmt.Errorf("Account %X does not have Name permission", nameTx.Input.Address)
+	}
+	// pubKey should be present in either "inAcc" or "nameTx.Input"
+	if err := checkInputPubKey(inAcc, nameTx.Input); err != nil {
+		log.Info(fmt.Sprintf("Can't find pubkey for %X", nameTx.Input.Address))
+		return err
+	}
+	err := validateInput(inAcc, signBytes, nameTx.Input)
+	if err != nil {
+		log.Info(fmt.Sprintf("validateInput failed on %X: %v", nameTx.Input.Address, err))
+		return err
+	}
+	// fee is in addition to the amount which is used to determine the TTL
+	if nameTx.Input.Amount < nameTx.Fee {
+		log.Info(fmt.Sprintf("Sender did not send enough to cover the fee %X", nameTx.Input.Address))
+		return txs.ErrTxInsufficientFunds
+	}
+
+	// validate the input strings
+	if err := nameTx.ValidateStrings(); err != nil {
+		return err
+	}
+
+	value := nameTx.Input.Amount - nameTx.Fee
+
+	// let's say cost of a name for one block is len(data) + 32
+	params := blockCache.GetChainParams()
+	costPerBlock := params.NameCostPerBlock(txs.NameBaseCost(nameTx.Name, nameTx.Data))
+	expiresIn := int(value / costPerBlock)
+	lastBlockHeight := blockCache.State().LastBlockHeight
+
+	log.Info("New NameTx", "value", value, "costPerBlock", costPerBlock, "expiresIn", expiresIn, "lastBlock", lastBlockHeight)
+
+	// check if the name exists
+	entry := blockCache.GetNameRegEntry(nameTx.Name)
+
+	if entry != nil {
+		var expired bool
+
+		// if the entry already exists, and hasn't expired, we must be owner,
+		// own a name above it or be a name admin
+		if entry.Expires > lastBlockHeight {
+			// ensure we are owner
+			if bytes.Compare(entry.Owner, nameTx.Input.Address) != 0 {
+				if txs.OwnsNameAncestor(blockCache, entry.Name, nameTx.Input.Address, lastBlockHeight) {
+					log.Info("Owner of parent name overriding namereg entry", "name", entry.Name,
+						"owner", fmt.Sprintf("%X", entry.Owner), "parentOwner", fmt.Sprintf("%X", nameTx.Input.Address))
+				} else if hasNameAdminPermission(blockCache, inAcc) {
+					log.Info("Name admin overriding namereg entry", "name", entry.Name,
+						"owner", fmt.Sprintf("%X", entry.Owner), "admin", fmt.Sprintf("%X", nameTx.Input.Address))
+				} else {
+					log.Info(fmt.Sprintf("Sender %X is trying to update a name (%s) for which he is not owner", nameTx.Input.Address, nameTx.Name))
+					return txs.ErrTxPermissionDenied
+				}
+			}
+		} else {
+			expired = true
+			if err := checkNameAncestors(blockCache, inAcc, nameTx.Name, lastBlockHeight); err != nil {
+				return err
+			}
+		}
+
+		// no value and empty data means delete the entry
+		if value == 0 && len(nameTx.Data) == 0 {
+			// maybe we reward you for telling us we can delete this crap
+			// (owners if not expired, anyone if expired)
+			log.Info("Removing namereg entry", "name", entry.Name)
+			blockCache.RemoveNameRegEntry(entry.Name)
+		} else {
+			// update the entry by bumping the expiry
+			// and changing the data
+			if expired {
+				if expiresIn < params.MinNameRegistrationPeriod {
+					return errors.New(fmt.Sprintf("Names must be registered for at least %d blocks", params.MinNameRegistrationPeriod))
+				}
+				entry.Expires = lastBlockHeight + expiresIn
+				entry.Owner = nameTx.Input.Address
+				log.Info("An old namereg entry has expired and been reclaimed", "name", entry.Name, "expiresIn", expiresIn, "owner", entry.Owner)
+			} else {
+				// since the size of the data may have changed
+				// we use the total amount of "credit"
+				oldCredit := int64(entry.Expires-lastBlockHeight) * txs.NameBaseCost(entry.Name, entry.Data)
+				credit := oldCredit + value
+				expiresIn = int(credit / costPerBlock)
+				if expiresIn < params.MinNameRegistrationPeriod {
+					return errors.New(fmt.Sprintf("Names must be registered for at least %d blocks", params.MinNameRegistrationPeriod))
+				}
+				entry.Expires = lastBlockHeight + expiresIn
+				log.Info("Updated namereg entry", "name", entry.Name, "expiresIn", expiresIn, "oldCredit", oldCredit, "value", value, "credit", credit)
+			}
+			entry.Data = nameTx.Data
+			entry.DataType = nameTx.DataType
+			blockCache.UpdateNameRegEntry(entry)
+		}
+	} else {
+		if expiresIn < params.MinNameRegistrationPeriod {
+			return errors.New(fmt.Sprintf("Names must be registered for at least %d blocks", params.MinNameRegistrationPeriod))
+		}
+		if err := checkNameAncestors(blockCache, inAcc, nameTx.Name, lastBlockHeight); err != nil {
+			return err
+		}
+		// entry does not exist, so create it
+		entry = &core_types.NameRegEntry{
+			Name:     nameTx.Name,
+			Owner:    nameTx.Input.Address,
+			Data:     nameTx.Data,
+			Expires:  lastBlockHeight + expiresIn,
+			DataType: nameTx.DataType,
+		}
+		log.Info("Creating namereg entry", "name", entry.Name, "expiresIn", expiresIn)
+		blockCache.UpdateNameRegEntry(entry)
+	}
+
+	// TODO: something with the value sent?
+
+	// Good!
+	inAcc.Sequence += 1
+	inAcc.Balance -= value
+	blockCache.UpdateAccount(inAcc)
+
+	// TODO: maybe we want to take funds on error and allow txs in that don't do anythingi?
+
+	if evc != nil {
+		evc.FireEvent(txs.EventStringAccInput(nameTx.Input.Address), txs.EventDataTx{tx, nil, ""})
+		evc.FireEvent(txs.EventStringNameReg(nameTx.Name), txs.EventDataTx{tx, nil, ""})
+	}
+
+	return nil
+}
+
+// Checks that acc may call address, or create a contract when address is
+// empty
+func checkCallPermissions(state PermissionsGetter, acc *acm.Account, address []byte) error {
+	if !hasInputPermission(state, acc) {
+		return fmt.Errorf("Account %X does not have Input permission", acc.Address)
+	}
+	if len(address) == 0 {
+		if !hasCreateContractPermission(state, acc) {
+			return fmt.Errorf("Account %X does not have CreateContract permission", acc.Address)
+		}
+		return nil
+	}
+	if !hasCallPermission(state, acc) {
+		return fmt.Errorf("Account %X does not have Call permission", acc.Address)
+	}
+	if !callAllowedByACLs(state, acc, address) {
+		return fmt.Errorf("Account %X is not allowed to call %X by the call ACLs", acc.Address, address)
+	}
+	return nil
+}
+
+// Runs the call of a CallTx or EthTx from inAcc, which has been validated and
+// may pay the fee and value, to address or to a new contract when address is
+// empty.
+func execCall(blockCache *BlockCache, tx txs.Tx, inAcc *acm.Account, address, data []byte,
+	gasLimit, fee, value int64, runCall bool, evc events.Fireable, inBatch bool) error {
+
+	var outAcc *acm.Account
+	_s := blockCache.State() // hack to access block height
+	createContract := len(address) == 0
+	if !createContract {
+		// Validate output
+		if len(address) != 20 {
+			log.Info(fmt.Sprintf("Destination address is not 20 bytes %X", address))
+			return txs.ErrTxInvalidAddress
+		}
+		// check if its a native contract
+		if vm.RegisteredNativeContract(LeftPadWord256(address)) {
+			return fmt.Errorf("NativeContracts can not be called using CallTx. Use a contract or the appropriate tx type (eg. PermissionsTx, NameTx)")
+		}
+
+		// Output account may be nil if we are still in mempool and contract was created in same block as this tx
+		// but that's fine, because the account will be created properly when the create tx runs in the block
+		// and then this won't return nil. otherwise, we take their fee
+		outAcc = blockCache.GetAccount(address)
+	}
+
+	log.Info(fmt.Sprintf("Out account: %v", outAcc))
+
+	// Good!
+	inAcc.Sequence += 1
+	inAcc.Balance -= fee
+	blockCache.UpdateAccount(inAcc)
+
+	// The logic in runCall MUST NOT return.
+	if runCall {
+
+		// VM call variables
+		var (
+			gas     int64       = gasLimit
+			err     error       = nil
+			caller  *vm.Account = toVMAccount(inAcc)
+			callee  *vm.Account = nil // initialized below
+			code    []byte      = nil
+			ret     []byte      = nil
+			txCache             = NewTxCache(blockCache)
+			params              = vm.Params{
+				BlockHeight: int64(_s.LastBlockHeight),
+				BlockHash:   LeftPadWord256(_s.LastBlockHash),
+				BlockTime:   _s.LastBlockTime.Unix(),
+				GasLimit:    _s.GetGasLimit(),
+			}
+		)
+
+		if !createContract && (outAcc == nil || len(outAcc.Code) == 0) {
+			// if you call an account that doesn't exist
+			// or an account with no code then we take fees (sorry pal)
+			// NOTE: it's fine to create a contract and call it within one
+			// block (nonce will prevent re-ordering of those txs)
+			// but to create with one contract and call with another
+			// you have to wait a block to avoid a re-ordering attack
+			// that will take your fees
+			if outAcc == nil {
+				log.Info(fmt.Sprintf("%X tries to call %X but it does not exist.",
+					inAcc.Address, address))
+			} else {
+				log.Info(fmt.Sprintf("%X tries to call %X but code is blank.",
+					inAcc.Address, address))
+			}
+			err = txs.ErrTxInvalidAddress
+			goto CALL_COMPLETE
+		}
+
+		// get or create callee
+		if createContract {
+			// We already checked for permission
+			callee = txCache.CreateAccount(caller)
+			log.Info(fmt.Sprintf("Created new contract %X", callee.Address))
+			code = data
+		} else {
+			callee = toVMAccount(outAcc)
+			log.Info(fmt.Sprintf("Calling contract %X with code %X", callee.Address, callee.Code))
+			code = callee.Code
+		}
+		log.Info(fmt.Sprintf("Code for this contract: %X", code))
+
+		// Run VM call and sync txCache to blockCache.
+		{ // Capture scope for goto.
+			// Write caller/callee to txCache.
+			txCache.UpdateAccount(caller)
+			txCache.UpdateAccount(callee)
+			txHash := txs.TxHash(_s.ChainID, tx)
+			txCache.SetTxHash(txHash)
+			vmach := vm.NewVM(txCache, params, caller.Address, txHash)
+			vmach.SetFireable(evc)
+			// NOTE: Call() transfers the value from caller to callee iff call succeeds.
+			ret, err = vmach.Call(caller, callee, code, data, value, &gas)
+			if err != nil {
+				// Failure. Charge the gas fee. The 'value' was otherwise not transferred.
+				log.Info(fmt.Sprintf("Error on execution: %v", err))
+				goto CALL_COMPLETE
+			}
+
+			log.Info("Successful execution")
+			if createContract {
+				callee.Code = ret
+			}
+			txCache.Sync()
+		}
+
+	CALL_COMPLETE: // err may or may not be nil.
+
+		// Create a receipt from the ret and whether errored.
+		log.Notice("VM call complete", "caller", caller, "callee", callee, "return", ret, "err", err)
+
+		// Fire Events for sender and receiver
+		// a separate event will be fired from vm for each additional call
+		if evc != nil {
+			exception := ""
+			if err != nil {
+				exception = err.Error()
+			}
+			evc.FireEvent(txs.EventStringAccInput(inAcc.Address), txs.EventDataTx{tx, ret, exception})
+			evc.FireEvent(txs.EventStringAccOutput(address), txs.EventDataTx{tx, ret, exception})
+		}
+		if inBatch && err != nil {
+			return fmt.Errorf("Call to %X failed: %v", address, err)
+		}
+	} else {
+		// The mempool does not call txs until
+		// the proposer determines the order of txs.
+		// So mempool will skip the actual .Call(),
+		// and only deduct from the caller's balance.
+		inAcc.Balance -= value
+		if createContract {
+			inAcc.Sequence += 1 // XXX ?!
+		}
+		blockCache.UpdateAccount(inAcc)
+	}
+
+	return nil
+}
+
 //---------------------------------------------------------------
 
-// Get permission on an account or fall back to global value
-func HasPermission(state AccountGetter, acc *acm.Account, perm ptypes.PermFlag) bool {
+// Get permission on an account, falling back to the permissions granted by
+// its roles and then to the global value. Base permissions and roles that have
+// expired are treated as unset. If the account does not have the permission it
+// may still exercise it when delegated by an account that does.
+func HasPermission(state PermissionsGetter, acc *acm.Account, perm ptypes.PermFlag) bool {
+	if hasOwnPermission(state, acc, perm) {
+		return true
+	}
+	if state == nil || acc == nil {
+		return false
+	}
+	height := state.BlockHeight()
+	for _, delegator := range state.GetPermissionGrants(acc.Address).Delegators(perm, height) {
+		// delegated permissions cannot be delegated again
+		delegatorAcc := state.GetAccount(delegator)
+		if delegatorAcc != nil && hasOwnPermission(state, delegatorAcc, perm) {
+			log.Info("Account has permission delegated", "address", fmt.Sprintf("%X", acc.Address),
+				"delegator", fmt.Sprintf("%X", delegator), "perm", ptypes.PermFlagToString(perm))
+			return true
+		}
+	}
+	return false
+}
+
+func hasOwnPermission(state PermissionsGetter, acc *acm.Account, perm ptypes.PermFlag) bool {
 	if perm > ptypes.AllPermFlags {
 		sanity.PanicSanity("Checking an unknown permission in state should never happen")
 	}
 
+	permString := ptypes.PermFlagToString(perm)
+
 	if acc == nil {
-		// TODO
-		// this needs to fall back to global or do some other specific things
 		// eg. a bondAcc may be nil and so can only bond if global bonding is true
+		if state == nil {
+			sanity.PanicSanity("Global permissions account should never be nil")
+		}
+		log.Info("Account does not exist. Querying GlobalPermissionsAddress", "perm", permString)
+		return hasOwnPermission(nil, state.GetAccount(ptypes.GlobalPermissionsAddress), perm)
 	}
-	permString := ptypes.PermFlagToString(perm)
 
+	var grants *ptypes.PermissionGrants
+	var height int
+	if state != nil {
+		grants = state.GetPermissionGrants(acc.Address)
+		height = state.BlockHeight()
+	}
 	v, err := acc.Permissions.Base.Get(perm)
+	if err == nil && grants.BaseExpired(perm, height) {
+		log.Info("Permission for account has expired", "address", fmt.Sprintf("%X", acc.Address), "perm", permString)
+		err = ptypes.ErrValueNotSet(perm)
+	}
+	if _, ok := err.(ptypes.ErrValueNotSet); ok && state != nil && len(acc.Permissions.Roles) > 0 {
+		log.Info("Permission for account is not set. Querying its roles", "perm", permString)
+		v, err = acc.Permissions.GetFromRoles(perm, state, grants, height)
+	}
 	if _, ok := err.(ptypes.ErrValueNotSet); ok {
 		if state == nil {
 			sanity.PanicSanity("All known global permissions should be set!")
 		}
 		log.Info("Permission for account is not set. Querying GlobalPermissionsAddress", "perm", permString)
-		return HasPermission(nil, state.GetAccount(ptypes.GlobalPermissionsAddress), perm)
+		return hasOwnPermission(nil, state.GetAccount(ptypes.GlobalPermissionsAddress), perm)
 	} else if v {
 		log.Info("Account has permission", "address", fmt.Sprintf("%X", acc.Address), "perm", permString)
 	} else {
@@ -972,7 +1498,7 @@ func HasPermission(state AccountGetter, acc *acm.Account, perm ptypes.PermFlag)
 }
 
 // TODO: for debug log the failed accounts
-func hasSendPermission(state AccountGetter, accs map[string]*acm.Account) bool {
+func hasSendPermission(state PermissionsGetter, accs map[string]*acm.Account) bool {
 	for _, acc := range accs {
 		if !HasPermission(state, acc, ptypes.Send) {
 			return false
@@ -981,19 +1507,43 @@ func hasSendPermission(state AccountGetter, accs map[string]*acm.Account) bool {
 	return true
 }
 
-func hasNamePermission(state AccountGetter, acc *acm.Account) bool {
+func hasInputPermission(state PermissionsGetter, acc *acm.Account) bool {
+	return HasPermission(state, acc, ptypes.Input)
+}
+
+func hasNamePermission(state PermissionsGetter, acc *acm.Account) bool {
 	return HasPermission(state, acc, ptypes.Name)
 }
 
-func hasCallPermission(state AccountGetter, acc *acm.Account) bool {
+func hasNameAdminPermission(state PermissionsGetter, acc *acm.Account) bool {
+	return HasPermission(state, acc, ptypes.NameAdmin)
+}
+
+func hasCallPermission(state PermissionsGetter, acc *acm.Account) bool {
 	return HasPermission(state, acc, ptypes.Call)
 }
 
-func hasCreateContractPermission(state AccountGetter, acc *acm.Account) bool {
+// Checks the call ACLs of both the caller and the callee
+func callAllowedByACLs(state PermissionsGetter, acc *acm.Account, callee []byte) bool {
+	if !state.GetCallACL(acc.Address).AllowsCallee(callee) {
+		log.Info("Callee not allowed by caller's call ACL", "address", fmt.Sprintf("%X", acc.Address),
+			"callee", fmt.Sprintf("%X", callee))
+		return false
+	}
+	if !state.GetCallACL(callee).AllowsCaller(acc.Address, &acc.Permissions,
+		state.GetPermissionGrants(acc.Address), state.BlockHeight()) {
+		log.Info("Caller not allowed by callee's call ACL", "address", fmt.Sprintf("%X", acc.Address),
+			"callee", fmt.Sprintf("%X", callee))
+		return false
+	}
+	return true
+}
+
+func hasCreateContractPermission(state PermissionsGetter, acc *acm.Account) bool {
 	return HasPermission(state, acc, ptypes.CreateContract)
 }
 
-func hasCreateAccountPermission(state AccountGetter, accs map[string]*acm.Account) bool {
+func hasCreateAccountPermission(state PermissionsGetter, accs map[string]*acm.Account) bool {
 	for _, acc := range accs {
 		if !HasPermission(state, acc, ptypes.CreateAccount) {
 			return false
@@ -1002,11 +1552,15 @@ func hasCreateAccountPermission(state AccountGetter, accs map[string]*acm.Accoun
 	return true
 }
 
-func hasBondPermission(state AccountGetter, acc *acm.Account) bool {
+func hasBondPermission(state PermissionsGetter, acc *acm.Account) bool {
 	return HasPermission(state, acc, ptypes.Bond)
 }
 
-func hasBondOrSendPermission(state AccountGetter, accs map[string]*acm.Account) bool {
+func hasGovernancePermission(state PermissionsGetter, acc *acm.Account) bool {
+	return HasPermission(state, acc, ptypes.Governance)
+}
+
+func hasBondOrSendPermission(state PermissionsGetter, accs map[string]*acm.Account) bool {
 	for _, acc := range accs {
 		if !HasPermission(state, acc, ptypes.Bond) {
 			if !HasPermission(state, acc, ptypes.Send) {
@@ -1017,6 +1571,44 @@ func hasBondOrSendPermission(state AccountGetter, accs map[string]*acm.Account)
 	return true
 }
 
+// Applies update to a copy of the permission grants held by addr and sets them
+func updatePermissionGrants(blockCache *BlockCache, addr []byte, update func(grants *ptypes.PermissionGrants)) {
+	grants := blockCache.GetPermissionGrants(addr).Copy()
+	existed := grants != nil
+	if !existed {
+		grants = &ptypes.PermissionGrants{}
+	}
+	update(grants)
+	// avoid writing to the cache when there were and are no grants
+	if existed || !grants.IsEmpty() {
+		blockCache.SetPermissionGrants(addr, grants)
+	}
+}
+
+// Returns a copy of the call ACL for addr that can be modified and then set
+func callACLForUpdate(blockCache *BlockCache, addr []byte) (*ptypes.CallACL, error) {
+	if blockCache.GetAccount(addr) == nil {
+		return nil, fmt.Errorf("Trying to update call ACL for unknown account %X", addr)
+	}
+	if acl := blockCache.GetCallACL(addr); acl != nil {
+		return acl.Copy(), nil
+	}
+	return &ptypes.CallACL{}, nil
+}
+
+// Names beneath an unexpired name can only be registered by the owner of a
+// name above them or by a name admin
+func checkNameAncestors(blockCache *BlockCache, acc *acm.Account, name string, lastBlockHeight int) error {
+	ancestor := txs.ActiveNameAncestor(blockCache, name, lastBlockHeight)
+	if ancestor == nil || txs.OwnsNameAncestor(blockCache, name, acc.Address, lastBlockHeight) ||
+		hasNameAdminPermission(blockCache, acc) {
+		return nil
+	}
+	log.Info(fmt.Sprintf("Sender %X is trying to register a name (%s) beneath %s which it does not own",
+		acc.Address, name, ancestor.Name))
+	return txs.ErrTxPermissionDenied
+}
+
 //-----------------------------------------------------------------------------
 
 type InvalidTxError struct {
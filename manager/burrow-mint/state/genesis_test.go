@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/burrow/common/random"
 	genesis "github.com/hyperledger/burrow/genesis"
 	ptypes "github.com/hyperledger/burrow/permission/types"
+	"github.com/hyperledger/burrow/txs"
 
 	tdb "github.com/tendermint/go-db"
 	"github.com/tendermint/tendermint/types"
@@ -104,6 +105,22 @@ func TestGenesisMakeState(t *testing.T) {
 	if v != (send1 > 0) {
 		t.Fatalf("Incorrect permission for send. Got %v, expected %v\n", v, send1 > 0)
 	}
+	if *st.GetChainParams() != *txs.DefaultChainParams() {
+		t.Fatalf("Incorrect chain params. Got %v, expected the defaults\n",
+			st.GetChainParams())
+	}
+}
+
+func TestGenesisChainParams(t *testing.T) {
+	genDoc := genesis.GenesisDocFromJSON([]byte(g1))
+	params := txs.DefaultChainParams()
+	params.MinNameRegistrationPeriod = 1
+	genDoc.Params = &genesis.GenesisParams{ChainParams: params}
+	st := MakeGenesisState(tdb.NewMemDB(), genDoc)
+	if st.GetChainParams().MinNameRegistrationPeriod != 1 {
+		t.Fatalf("Incorrect name registration period. Got %v, expected 1\n",
+			st.GetChainParams().MinNameRegistrationPeriod)
+	}
 }
 
 //-------------------------------------------------------
@@ -0,0 +1,79 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/hyperledger/burrow/common/sanity"
+	ptypes "github.com/hyperledger/burrow/permission/types"
+	"github.com/hyperledger/burrow/txs"
+
+	"github.com/tendermint/go-wire"
+)
+
+// Proposals are keyed by the big-endian height so that the tree iterates them
+// in the order they take effect.
+func govProposalsKey(height int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(height))
+	return key
+}
+
+func EncodeGovProposals(proposals []*txs.GovProposal) []byte {
+	w := new(bytes.Buffer)
+	var n int
+	var err error
+	wire.WriteBinary(proposals, w, &n, &err)
+	return w.Bytes()
+}
+
+func DecodeGovProposals(proposalsBytes []byte) []*txs.GovProposal {
+	var n int
+	var err error
+	proposals := wire.ReadBinary([]*txs.GovProposal{}, bytes.NewBuffer(proposalsBytes),
+		txs.MaxDataLength, &n, &err)
+	return proposals.([]*txs.GovProposal)
+}
+
+// ApplyGovProposals puts into effect the proposals accepted for the given
+// height, in the order their GovTxs were run. A later proposal for the same
+// height overrides an earlier one.
+func ApplyGovProposals(blockCache *BlockCache, height int) {
+	proposals := blockCache.GetGovProposals(height)
+	if len(proposals) == 0 {
+		return
+	}
+	for _, proposal := range proposals {
+		log.Notice("Applying governance proposal", "proposer", proposal.Proposer,
+			"height", height, "params", proposal.Params,
+			"global_permissions", proposal.GlobalPermissions)
+		if proposal.Params != nil {
+			blockCache.UpdateChainParams(proposal.Params.Copy())
+		}
+		if proposal.GlobalPermissions != nil {
+			permsAcc := blockCache.GetAccount(ptypes.GlobalPermissionsAddress)
+			if permsAcc == nil {
+				sanity.PanicSanity("can't find global permissions account")
+			}
+			// XXX: make sure the set bits are all true
+			// Without it the HasPermission() functions will fail
+			permsAcc.Permissions.Base = ptypes.GlobalBasePermissions(*proposal.GlobalPermissions)
+			blockCache.UpdateAccount(permsAcc)
+		}
+	}
+	blockCache.SetGovProposals(height, nil)
+}
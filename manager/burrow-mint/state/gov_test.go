@@ -0,0 +1,85 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"testing"
+
+	ptypes "github.com/hyperledger/burrow/permission/types"
+	"github.com/hyperledger/burrow/txs"
+
+	dbm "github.com/tendermint/go-db"
+)
+
+func TestGovTxApplyProposals(t *testing.T) {
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
+	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
+	st := MakeGenesisState(stateDB, &genDoc)
+	blockCache := NewBlockCache(st)
+
+	params := txs.DefaultChainParams()
+	params.MinBondAmount = 100
+	height := st.LastBlockHeight + 2
+
+	//------------------------------
+	// governance permission is required
+	govTx, _ := txs.NewGovTx(blockCache, user[0].PubKey, height, params, nil)
+	govTx.Sign(chainID, user[0])
+	if err := ExecTx(blockCache, govTx, true, nil); err == nil {
+		t.Fatal("Expected error proposing without the governance permission")
+	}
+
+	govAcc := blockCache.GetAccount(user[0].Address)
+	govAcc.Permissions.Base.Set(ptypes.Governance, true)
+	blockCache.UpdateAccount(govAcc)
+
+	//------------------------------
+	// proposals can't take effect in the current block
+	govTx, _ = txs.NewGovTx(blockCache, user[0].PubKey, st.LastBlockHeight+1, params, nil)
+	govTx.Sign(chainID, user[0])
+	if err := ExecTx(blockCache, govTx, true, nil); err == nil {
+		t.Fatal("Expected error proposing a change for the current block")
+	}
+
+	//------------------------------
+	// proposals are held until their height
+	globalPerms := ptypes.ZeroBasePermissions
+	globalPerms.Set(ptypes.Send, true)
+	govTx, _ = txs.NewGovTx(blockCache, user[0].PubKey, height, params, &globalPerms)
+	govTx.Sign(chainID, user[0])
+	if err := ExecTx(blockCache, govTx, true, nil); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	blockCache.Sync()
+	if proposals := st.GetGovProposals(height); len(proposals) != 1 {
+		t.Fatalf("Expected a single proposal at height %v, got %v", height, proposals)
+	}
+	if st.GetChainParams().MinBondAmount == params.MinBondAmount {
+		t.Fatal("Chain parameters should not change before the proposal height")
+	}
+
+	ApplyGovProposals(blockCache, height)
+	blockCache.Sync()
+	if minBond := st.GetChainParams().MinBondAmount; minBond != params.MinBondAmount {
+		t.Fatalf("Expected MinBondAmount %v, got %v", params.MinBondAmount, minBond)
+	}
+	if !HasPermission(nil, st.GetAccount(ptypes.GlobalPermissionsAddress), ptypes.Send) ||
+		HasPermission(nil, st.GetAccount(ptypes.GlobalPermissionsAddress), ptypes.Call) {
+		t.Fatal("Expected global permissions to be replaced by the proposal")
+	}
+	if proposals := st.GetGovProposals(height); len(proposals) != 0 {
+		t.Fatalf("Expected applied proposals to be removed, got %v", proposals)
+	}
+}
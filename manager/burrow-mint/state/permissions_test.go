@@ -32,6 +32,7 @@ import (
 	"github.com/tendermint/go-crypto"
 	dbm "github.com/tendermint/go-db"
 	"github.com/tendermint/go-events"
+	"github.com/tendermint/go-merkle"
 	"github.com/tendermint/tendermint/config/tendermint_test"
 )
 
@@ -258,6 +259,111 @@ func TestName(t *testing.T) {
 	}
 }
 
+func TestInputPermission(t *testing.T) {
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
+	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
+	genDoc.Accounts[0].Permissions.Base.Set(ptypes.Send, true)
+	genDoc.Accounts[1].Permissions.Base.Set(ptypes.Send, true)
+	genDoc.Accounts[1].Permissions.Base.Set(ptypes.Input, false)
+	st := MakeGenesisState(stateDB, &genDoc)
+	blockCache := NewBlockCache(st)
+
+	// Input is granted globally unless the genesis denies it
+	tx := txs.NewSendTx()
+	if err := tx.AddInput(blockCache, user[0].PubKey, 5); err != nil {
+		t.Fatal(err)
+	}
+	tx.AddOutput(user[2].Address, 5)
+	tx.SignInput(chainID, 0, user[0])
+	if err := ExecTx(blockCache, tx, true, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// send tx from an account denied Input should fail
+	tx = txs.NewSendTx()
+	if err := tx.AddInput(blockCache, user[1].PubKey, 5); err != nil {
+		t.Fatal(err)
+	}
+	tx.AddOutput(user[2].Address, 5)
+	tx.SignInput(chainID, 0, user[1])
+	if err := ExecTx(blockCache, tx, true, nil); err == nil {
+		t.Fatal("Expected error")
+	} else {
+		fmt.Println(err)
+	}
+
+	// as should any other tx it signs
+	snativeArgs := &ptypes.SetBaseArgs{user[1].Address, ptypes.Input, true}
+	acc := blockCache.GetAccount(user[1].Address)
+	acc.Permissions.Base.Set(ptypes.SetBase, true)
+	blockCache.UpdateAccount(acc)
+	permTx, _ := txs.NewPermissionsTx(blockCache, user[1].PubKey, snativeArgs)
+	permTx.Sign(chainID, user[1])
+	if err := ExecTx(blockCache, permTx, true, nil); err == nil {
+		t.Fatal("Expected error")
+	} else {
+		fmt.Println(err)
+	}
+}
+
+func TestNameAdminPermission(t *testing.T) {
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
+	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
+	genDoc.Accounts[0].Permissions.Base.Set(ptypes.Name, true)
+	genDoc.Accounts[1].Permissions.Base.Set(ptypes.Name, true)
+	genDoc.Accounts[2].Permissions.Base.Set(ptypes.Name, true)
+	genDoc.Accounts[2].Permissions.Base.Set(ptypes.NameAdmin, true)
+	st := MakeGenesisState(stateDB, &genDoc)
+	blockCache := NewBlockCache(st)
+
+	tx, err := txs.NewNameTx(blockCache, user[0].PubKey, "somename", "somedata", 10000, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sign(chainID, user[0])
+	if err := ExecTx(blockCache, tx, true, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// updating someone else's name should fail
+	tx, err = txs.NewNameTx(blockCache, user[1].PubKey, "somename", "otherdata", 10000, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sign(chainID, user[1])
+	if err := ExecTx(blockCache, tx, true, nil); err == nil {
+		t.Fatal("Expected error")
+	} else {
+		fmt.Println(err)
+	}
+
+	// but a name admin may override it
+	tx, err = txs.NewNameTx(blockCache, user[2].PubKey, "somename", "admindata", 10000, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sign(chainID, user[2])
+	if err := ExecTx(blockCache, tx, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if entry := blockCache.GetNameRegEntry("somename"); entry == nil || entry.Data != "admindata" {
+		t.Fatalf("expected name admin to override the entry, got %v", entry)
+	}
+
+	// and delete it
+	tx, err = txs.NewNameTx(blockCache, user[2].PubKey, "somename", "", 100, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sign(chainID, user[2])
+	if err := ExecTx(blockCache, tx, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if entry := blockCache.GetNameRegEntry("somename"); entry != nil {
+		t.Fatalf("expected name admin to delete the entry, got %v", entry)
+	}
+}
+
 func TestCallFails(t *testing.T) {
 	stateDB := dbm.NewDB("state", dbBackend, dbDir)
 	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
@@ -603,9 +709,8 @@ func TestCreatePermission(t *testing.T) {
 	}
 }
 
-/* TODO
 func TestBondPermission(t *testing.T) {
-	stateDB := dbm.NewDB("state",dbBackend,dbDir)
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
 	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
 	st := MakeGenesisState(stateDB, &genDoc)
 	blockCache := NewBlockCache(st)
@@ -726,7 +831,6 @@ func TestBondPermission(t *testing.T) {
 		t.Fatal("Expected error")
 	}
 }
-*/
 
 func TestCreateAccountPermission(t *testing.T) {
 	stateDB := dbm.NewDB("state", dbBackend, dbDir)
@@ -1075,6 +1179,166 @@ func TestSNativeTx(t *testing.T) {
 	}
 }
 
+func TestRolePermissions(t *testing.T) {
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
+	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
+	genDoc.Accounts[3].Permissions.AddRole("deployer")
+	st := MakeGenesisState(stateDB, &genDoc)
+	blockCache := NewBlockCache(st)
+
+	acc := blockCache.GetAccount(user[3].Address)
+	if HasPermission(blockCache, acc, ptypes.CreateContract) {
+		t.Fatal("expected no permission before the role is defined")
+	}
+
+	fmt.Println("\n#### SetRolePermission")
+	var snativeArgs ptypes.PermArgs = &ptypes.SetRolePermissionArgs{"deployer", ptypes.CreateContract, true}
+	testSNativeTxExpectFail(t, blockCache, snativeArgs)
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetGlobal, snativeArgs)
+	acc = blockCache.GetAccount(user[3].Address)
+	if !HasPermission(blockCache, acc, ptypes.CreateContract) {
+		t.Fatal("expected permission to be granted by role")
+	}
+	if HasPermission(blockCache, acc, ptypes.Call) {
+		t.Fatal("expected permission not granted by role to fall through to global")
+	}
+	if HasPermission(blockCache, blockCache.GetAccount(user[2].Address), ptypes.CreateContract) {
+		t.Fatal("expected no permission for account without the role")
+	}
+
+	// account permissions take precedence over those of its roles
+	acc.Permissions.Base.Set(ptypes.CreateContract, false)
+	blockCache.UpdateAccount(acc)
+	if HasPermission(blockCache, acc, ptypes.CreateContract) {
+		t.Fatal("expected account permission to override role")
+	}
+	acc.Permissions.Base.Unset(ptypes.CreateContract)
+	blockCache.UpdateAccount(acc)
+
+	fmt.Println("\n#### UnsetRolePermission")
+	snativeArgs = &ptypes.UnsetRolePermissionArgs{"deployer", ptypes.CreateContract}
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetGlobal, snativeArgs)
+	if blockCache.GetRolePermissions("deployer") != nil {
+		t.Fatal("expected role without permissions set to be removed")
+	}
+	if HasPermission(blockCache, acc, ptypes.CreateContract) {
+		t.Fatal("expected permission to fall through to global")
+	}
+}
+
+func TestCallACLs(t *testing.T) {
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
+	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
+	genDoc.Accounts[1].Permissions.Base.Set(ptypes.Call, true)
+	genDoc.Accounts[2].Permissions.Base.Set(ptypes.Call, true)
+	st := MakeGenesisState(stateDB, &genDoc)
+	blockCache := NewBlockCache(st)
+
+	// create two simple contracts
+	simpleContractAddrs := make([][]byte, 2)
+	for i := range simpleContractAddrs {
+		simpleContractAddrs[i] = NewContractAddress(user[0].Address, 100+i)
+		st.UpdateAccount(&acm.Account{
+			Address:     simpleContractAddrs[i],
+			Balance:     0,
+			Code:        []byte{0x60},
+			Sequence:    0,
+			StorageRoot: Zero256.Bytes(),
+			Permissions: ptypes.ZeroAccountPermissions,
+		})
+	}
+
+	fmt.Println("\n#### AddCallee")
+	// user 1 may only call the first contract
+	var snativeArgs ptypes.PermArgs = &ptypes.AddCalleeArgs{user[1].Address, simpleContractAddrs[0]}
+	testSNativeTxExpectFail(t, blockCache, snativeArgs)
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, snativeArgs)
+	testCallTx(t, true, blockCache, user[1], simpleContractAddrs[0])
+	testCallTx(t, false, blockCache, user[1], simpleContractAddrs[1])
+	testCallTx(t, true, blockCache, user[2], simpleContractAddrs[1])
+
+	fmt.Println("\n#### AddCallerRole")
+	// the second contract may only be called by holders of the role
+	snativeArgs = &ptypes.AddCallerRoleArgs{simpleContractAddrs[1], "compliance"}
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, snativeArgs)
+	testCallTx(t, false, blockCache, user[2], simpleContractAddrs[1])
+	snativeArgs = &ptypes.AddRoleArgs{user[2].Address, "compliance"}
+	testSNativeTxExpectPass(t, blockCache, ptypes.AddRole, snativeArgs)
+	testCallTx(t, true, blockCache, user[2], simpleContractAddrs[1])
+
+	fmt.Println("\n#### RmCallee")
+	// removing the last callee leaves user 1 allowed to call no contracts
+	snativeArgs = &ptypes.RmCalleeArgs{user[1].Address, simpleContractAddrs[0]}
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, snativeArgs)
+	if acl := blockCache.GetCallACL(user[1].Address); acl == nil || !acl.RestrictCallees {
+		t.Fatalf("expected call ACL restricting callees to be kept, got %v", acl)
+	}
+	testCallTx(t, false, blockCache, user[1], simpleContractAddrs[0])
+	snativeArgs = &ptypes.AddCalleeArgs{user[1].Address, simpleContractAddrs[1]}
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, snativeArgs)
+	// still refused by the second contract's ACL
+	testCallTx(t, false, blockCache, user[1], simpleContractAddrs[1])
+
+	fmt.Println("\n#### AddCaller")
+	snativeArgs = &ptypes.AddCallerArgs{simpleContractAddrs[1], user[1].Address}
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, snativeArgs)
+	testCallTx(t, true, blockCache, user[1], simpleContractAddrs[1])
+
+	//----------------------------------------------------------
+	// contract calling the second contract
+	fmt.Println("\n##### CALL TO CONTRACT RESTRICTED BY CALL ACL (FAIL)")
+	callerContractAddr := NewContractAddress(user[0].Address, 102)
+	callerAcc := &acm.Account{
+		Address:     callerContractAddr,
+		Balance:     10000,
+		Code:        callContractCode(simpleContractAddrs[1]),
+		Sequence:    0,
+		StorageRoot: Zero256.Bytes(),
+		Permissions: ptypes.ZeroAccountPermissions,
+	}
+	callerAcc.Permissions.Base.Set(ptypes.Call, true)
+	blockCache.UpdateAccount(callerAcc)
+
+	tx, _ := txs.NewCallTx(blockCache, user[2].PubKey, callerContractAddr, nil, 100, 10000, 100)
+	tx.Sign(chainID, user[2])
+	_, exception := execTxWaitEvent(t, blockCache, tx, txs.EventStringAccCall(callerContractAddr))
+	if exception == "" {
+		t.Fatal("Expected exception")
+	}
+
+	fmt.Println("\n##### CALL TO CONTRACT RESTRICTED BY CALL ACL (PASS)")
+	snativeArgs = &ptypes.AddCallerArgs{simpleContractAddrs[1], callerContractAddr}
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, snativeArgs)
+	tx, _ = txs.NewCallTx(blockCache, user[2].PubKey, callerContractAddr, nil, 100, 10000, 100)
+	tx.Sign(chainID, user[2])
+	_, exception = execTxWaitEvent(t, blockCache, tx, txs.EventStringAccCall(callerContractAddr))
+	if exception != "" {
+		t.Fatal("Unexpected exception", exception)
+	}
+
+	fmt.Println("\n##### CALL TO NATIVE CONTRACT FROM CONTRACT RESTRICTED BY CALL ACL (PASS)")
+	// native contracts are not restricted by call ACLs
+	nativeCallerAddr := NewContractAddress(user[0].Address, 103)
+	nativeCallerAcc := &acm.Account{
+		Address:     nativeCallerAddr,
+		Balance:     10000,
+		Code:        callContractCode(LeftPadWord256([]byte{2}).Postfix(20)), // sha256
+		Sequence:    0,
+		StorageRoot: Zero256.Bytes(),
+		Permissions: ptypes.ZeroAccountPermissions,
+	}
+	nativeCallerAcc.Permissions.Base.Set(ptypes.Call, true)
+	blockCache.UpdateAccount(nativeCallerAcc)
+	snativeArgs = &ptypes.AddCalleeArgs{nativeCallerAddr, simpleContractAddrs[1]}
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, snativeArgs)
+	tx, _ = txs.NewCallTx(blockCache, user[2].PubKey, nativeCallerAddr, nil, 100, 10000, 100)
+	tx.Sign(chainID, user[2])
+	_, exception = execTxWaitEvent(t, blockCache, tx, txs.EventStringAccCall(nativeCallerAddr))
+	if exception != "" {
+		t.Fatal("Unexpected exception", exception)
+	}
+}
+
 //-------------------------------------------------------------------------------------
 // helpers
 
@@ -1116,6 +1380,270 @@ func execTxWaitEvent(t *testing.T, blockCache *BlockCache, tx txs.Tx, eventid st
 	}
 }
 
+func TestPermissionChanges(t *testing.T) {
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
+	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
+	st := MakeGenesisState(stateDB, &genDoc)
+	blockCache := NewBlockCache(st)
+
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, &ptypes.SetBaseArgs{user[1].Address, ptypes.Send, true})
+	testSNativeTxExpectPass(t, blockCache, ptypes.AddRole, &ptypes.AddRoleArgs{user[2].Address, "auditor"})
+	testSNativeTxExpectPass(t, blockCache, ptypes.UnsetBase, &ptypes.UnsetBaseArgs{user[1].Address, ptypes.Send})
+	// failed txs are not recorded
+	testSNativeTxExpectFail(t, blockCache, &ptypes.SetBaseArgs{user[3].Address, ptypes.Call, true})
+	if st.NumPermissionChanges() != 0 {
+		t.Fatal("expected changes to be held in the block cache until sync")
+	}
+	blockCache.Sync()
+
+	changes := permissionChanges(t, st, st.GetAllPermissionChanges())
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 permission changes, got %v", len(changes))
+	}
+	for i, change := range changes {
+		if change.Index != i {
+			t.Errorf("expected change %v to have index %v, got %v", change, i, change.Index)
+		}
+		if change.Height != st.LastBlockHeight+1 {
+			t.Errorf("expected change %v to be made at height %v", change, st.LastBlockHeight+1)
+		}
+		if !bytes.Equal(change.Actor, user[0].Address) {
+			t.Errorf("expected change %v to be made by %X", change, user[0].Address)
+		}
+		if len(change.TxHash) == 0 {
+			t.Errorf("expected change %v to record its tx hash", change)
+		}
+	}
+	if c := changes[0]; c.Function != "setBase" || !bytes.Equal(c.Target, user[1].Address) ||
+		c.Permission != ptypes.Send || c.OldValue != ptypes.PermValueUnset || c.NewValue != ptypes.PermValueTrue {
+		t.Errorf("unexpected change %v", c)
+	}
+	if c := changes[1]; c.Function != "addRole" || c.Role != ptypes.PadRole("auditor") ||
+		c.OldValue != ptypes.PermValueFalse || c.NewValue != ptypes.PermValueTrue {
+		t.Errorf("unexpected change %v", c)
+	}
+	if c := changes[2]; c.Function != "unsetBase" ||
+		c.OldValue != ptypes.PermValueTrue || c.NewValue != ptypes.PermValueUnset {
+		t.Errorf("unexpected change %v", c)
+	}
+
+	// the changes involving an account are indexed by its address
+	byTarget := permissionChanges(t, st, st.GetPermissionChangesByAddress(), user[1].Address)
+	if len(byTarget) != 2 || byTarget[0].Index != 0 || byTarget[1].Index != 2 {
+		t.Errorf("expected changes 0 and 2 to involve %X, got %v", user[1].Address, byTarget)
+	}
+	byActor := permissionChanges(t, st, st.GetPermissionChangesByAddress(), user[0].Address)
+	if len(byActor) != 3 {
+		t.Errorf("expected 3 changes made by %X, got %v", user[0].Address, len(byActor))
+	}
+	if byNone := permissionChanges(t, st, st.GetPermissionChangesByAddress(),
+		user[3].Address); len(byNone) != 0 {
+		t.Errorf("expected no changes involving %X, got %v", user[3].Address, byNone)
+	}
+
+	var bySend, byAddRole []*ptypes.PermissionChange
+	for _, change := range changes {
+		if change.Affects(ptypes.Send) {
+			bySend = append(bySend, change)
+		}
+		if change.Affects(ptypes.AddRole) {
+			byAddRole = append(byAddRole, change)
+		}
+	}
+	if len(bySend) != 2 {
+		t.Errorf("expected 2 changes affecting send, got %v", len(bySend))
+	}
+	// role changes affect the permission they are made with
+	if len(byAddRole) != 1 || byAddRole[0].Function != "addRole" {
+		t.Errorf("expected the role change to affect add_role, got %v", byAddRole)
+	}
+}
+
+// Returns the changes of the permission change log that tree holds, which is
+// the log or, given an address, its index by address
+func permissionChanges(t *testing.T, st *State, tree merkle.Tree,
+	address ...[]byte) []*ptypes.PermissionChange {
+	changes := []*ptypes.PermissionChange{}
+	tree.Iterate(func(key, value []byte) bool {
+		var change *ptypes.PermissionChange
+		var err error
+		if len(address) == 0 {
+			change, err = DecodePermissionChange(value)
+		} else if bytes.HasPrefix(key, address[0]) {
+			change, err = st.GetPermissionChange(value)
+		} else {
+			return false
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		changes = append(changes, change)
+		return false
+	})
+	return changes
+}
+
+func TestPermissionExpiry(t *testing.T) {
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
+	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
+	st := MakeGenesisState(stateDB, &genDoc)
+	st.LastBlockHeight = 10
+	blockCache := NewBlockCache(st)
+
+	fmt.Println("\n#### SetBaseUntil")
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, &ptypes.SetBaseUntilArgs{user[1].Address, ptypes.Send, true, 12})
+	// expiry heights that have already passed are rejected
+	testSNativeTxExpectFail(t, blockCache, &ptypes.SetBaseUntilArgs{user[3].Address, ptypes.Send, true, 10})
+
+	fmt.Println("\n#### AddRoleUntil")
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetGlobal, &ptypes.SetRolePermissionArgs{"deployer", ptypes.CreateContract, true})
+	testSNativeTxExpectPass(t, blockCache, ptypes.AddRole, &ptypes.AddRoleUntilArgs{user[2].Address, "deployer", 12})
+
+	acc1 := blockCache.GetAccount(user[1].Address)
+	acc2 := blockCache.GetAccount(user[2].Address)
+	if !HasPermission(blockCache, acc1, ptypes.Send) {
+		t.Fatal("expected permission before it expires")
+	}
+	if !HasPermission(blockCache, acc2, ptypes.CreateContract) {
+		t.Fatal("expected role permission before the role expires")
+	}
+
+	// the grants are valid up to and including their expiry height
+	st.LastBlockHeight = 11
+	if !HasPermission(blockCache, acc1, ptypes.Send) {
+		t.Fatal("expected permission at its expiry height")
+	}
+
+	st.LastBlockHeight = 12
+	if HasPermission(blockCache, acc1, ptypes.Send) {
+		t.Fatal("expected expired permission to fall through to global")
+	}
+	if HasPermission(blockCache, acc2, ptypes.CreateContract) {
+		t.Fatal("expected expired role to grant no permissions")
+	}
+	if acc2.Permissions.HasRoleAt("deployer", blockCache.GetPermissionGrants(user[2].Address), blockCache.BlockHeight()) {
+		t.Fatal("expected expired role not to be held")
+	}
+
+	// setting without an expiry renews the grants indefinitely
+	fmt.Println("\n#### SetBase and AddRole after expiry")
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, &ptypes.SetBaseArgs{user[1].Address, ptypes.Send, true})
+	testSNativeTxExpectPass(t, blockCache, ptypes.AddRole, &ptypes.AddRoleArgs{user[2].Address, "deployer"})
+	st.LastBlockHeight = 100
+	if !HasPermission(blockCache, blockCache.GetAccount(user[1].Address), ptypes.Send) {
+		t.Fatal("expected permission set without expiry to be held")
+	}
+	if !HasPermission(blockCache, blockCache.GetAccount(user[2].Address), ptypes.CreateContract) {
+		t.Fatal("expected role added without expiry to grant permissions")
+	}
+	if grants := blockCache.GetPermissionGrants(user[1].Address); !grants.IsEmpty() {
+		t.Fatalf("expected expiry to be cleared, got %v", grants)
+	}
+
+	// adding a role held until some height without an expiry makes it permanent
+	fmt.Println("\n#### AddRole of a role held until some height")
+	testSNativeTxExpectPass(t, blockCache, ptypes.AddRole, &ptypes.AddRoleUntilArgs{user[3].Address, "auditor", 150})
+	testSNativeTxExpectPass(t, blockCache, ptypes.AddRole, &ptypes.AddRoleArgs{user[3].Address, "auditor"})
+	if expiry := blockCache.GetPermissionGrants(user[3].Address).RoleExpiry("auditor"); expiry != 0 {
+		t.Fatalf("expected role expiry to be cleared, got %v", expiry)
+	}
+	st.LastBlockHeight = 200
+	if !blockCache.GetAccount(user[3].Address).Permissions.HasRoleAt("auditor",
+		blockCache.GetPermissionGrants(user[3].Address), blockCache.BlockHeight()) {
+		t.Fatal("expected role to be held after its former expiry")
+	}
+	// a role held without an expiry already exists
+	testSNativeTxExpectFail(t, blockCache, &ptypes.AddRoleArgs{user[3].Address, "auditor"})
+
+	// and the same through the SNative contract
+	doug := &acm.Account{
+		Address:     DougAddress,
+		StorageRoot: Zero256.Bytes(),
+		Permissions: ptypes.ZeroAccountPermissions,
+	}
+	doug.Permissions.Base.Set(ptypes.Call, true)
+	blockCache.UpdateAccount(doug)
+	snativeAddress, pF, data := snativeRoleTestInputCALL("addRole", user[4], "auditor")
+	untilData := append(permNameToFuncID("addRoleUntil"), data[4:]...)
+	untilData = append(untilData, LeftPadBytes([]byte{250}, 32)...)
+	testSNativeCALLExpectPass(t, blockCache, doug, pF, snativeAddress, untilData, func(ret []byte) error { return nil })
+	if expiry := blockCache.GetPermissionGrants(user[4].Address).RoleExpiry("auditor"); expiry != 250 {
+		t.Fatalf("expected role expiry 250, got %v", expiry)
+	}
+	expectAdded := func(added bool) func(ret []byte) error {
+		return func(ret []byte) error {
+			if !IsZeros(ret[:31]) || (ret[31] == 1) != added {
+				return fmt.Errorf("Expected %v. Got %X", added, ret)
+			}
+			return nil
+		}
+	}
+	testSNativeCALLExpectPass(t, blockCache, doug, pF, snativeAddress, data, expectAdded(true))
+	if expiry := blockCache.GetPermissionGrants(user[4].Address).RoleExpiry("auditor"); expiry != 0 {
+		t.Fatalf("expected role expiry to be cleared, got %v", expiry)
+	}
+	testSNativeCALLExpectPass(t, blockCache, doug, pF, snativeAddress, data, expectAdded(false))
+}
+
+func TestDelegatedPermission(t *testing.T) {
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
+	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
+	genDoc.Accounts[0].Permissions.Base.Set(ptypes.CreateContract, true)
+	st := MakeGenesisState(stateDB, &genDoc)
+	blockCache := NewBlockCache(st)
+
+	fmt.Println("\n#### DelegatePermission")
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, &ptypes.DelegatePermissionArgs{user[1].Address, ptypes.CreateContract, 0})
+	// only single permissions held by the delegator can be delegated
+	testSNativeTxExpectFail(t, blockCache, &ptypes.DelegatePermissionArgs{user[1].Address, ptypes.Call | ptypes.CreateContract, 0})
+	testSNativeTxExpectFail(t, blockCache, &ptypes.DelegatePermissionArgs{user[1].Address, ptypes.Bond, 0})
+
+	acc1 := blockCache.GetAccount(user[1].Address)
+	if !HasPermission(blockCache, acc1, ptypes.CreateContract) {
+		t.Fatal("expected delegated permission")
+	}
+	if HasPermission(blockCache, acc1, ptypes.Call) {
+		t.Fatal("expected permission not delegated to fall through to global")
+	}
+
+	// delegated permissions cannot be delegated again
+	blockCache.SetPermissionGrants(user[2].Address, &ptypes.PermissionGrants{
+		Delegations: []ptypes.Delegation{{Delegator: user[1].Address, Permission: ptypes.CreateContract}},
+	})
+	if HasPermission(blockCache, blockCache.GetAccount(user[2].Address), ptypes.CreateContract) {
+		t.Fatal("expected permission delegated by a delegate not to be held")
+	}
+
+	// the delegate only has the permission while the delegator does
+	acc0 := blockCache.GetAccount(user[0].Address)
+	acc0.Permissions.Base.Set(ptypes.CreateContract, false)
+	blockCache.UpdateAccount(acc0)
+	if HasPermission(blockCache, acc1, ptypes.CreateContract) {
+		t.Fatal("expected delegated permission to be lost with the delegator's")
+	}
+	acc0.Permissions.Base.Set(ptypes.CreateContract, true)
+	blockCache.UpdateAccount(acc0)
+
+	fmt.Println("\n#### RevokeDelegation")
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, &ptypes.RevokeDelegationArgs{user[1].Address, ptypes.CreateContract})
+	if HasPermission(blockCache, acc1, ptypes.CreateContract) {
+		t.Fatal("expected revoked permission not to be held")
+	}
+	// nothing left to revoke
+	testSNativeTxExpectFail(t, blockCache, &ptypes.RevokeDelegationArgs{user[1].Address, ptypes.CreateContract})
+
+	fmt.Println("\n#### DelegatePermission with expiry")
+	height := blockCache.BlockHeight()
+	testSNativeTxExpectPass(t, blockCache, ptypes.SetBase, &ptypes.DelegatePermissionArgs{user[1].Address, ptypes.CreateContract, height})
+	if !HasPermission(blockCache, acc1, ptypes.CreateContract) {
+		t.Fatal("expected delegated permission before it expires")
+	}
+	st.LastBlockHeight = height
+	if HasPermission(blockCache, acc1, ptypes.CreateContract) {
+		t.Fatal("expected expired delegation not to grant permission")
+	}
+}
+
 // give a contract perms for an snative, call it, it calls the snative, but shouldn't have permission
 func testSNativeCALLExpectFail(t *testing.T, blockCache *BlockCache, doug *acm.Account, snativeAddress, data []byte) {
 	testSNativeCALL(t, false, blockCache, doug, 0, snativeAddress, data, nil)
@@ -1186,6 +1714,21 @@ func testSNativeTx(t *testing.T, expectPass bool, blockCache *BlockCache, perm p
 	}
 }
 
+func testCallTx(t *testing.T, expectPass bool, blockCache *BlockCache, caller *acm.PrivAccount, addr []byte) {
+	tx, _ := txs.NewCallTx(blockCache, caller.PubKey, addr, nil, 100, 100, 100)
+	tx.Sign(chainID, caller)
+	err := ExecTx(blockCache, tx, true, nil)
+	if expectPass {
+		if err != nil {
+			t.Fatal("Unexpected exception", err)
+		}
+	} else {
+		if err == nil {
+			t.Fatal("Expected exception")
+		}
+	}
+}
+
 func boolToWord256(v bool) Word256 {
 	var vint byte
 	if v {
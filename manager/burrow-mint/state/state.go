@@ -16,9 +16,11 @@ package state
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"strconv"
 	"time"
 
 	acm "github.com/hyperledger/burrow/account"
@@ -38,11 +40,30 @@ import (
 
 var (
 	stateKey                     = []byte("stateKey")
-	minBondAmount                = int64(1)           // TODO adjust
-	defaultAccountsCacheCapacity = 1000               // TODO adjust
-	unbondingPeriodBlocks        = int(60 * 24 * 365) // TODO probably better to make it time based.
-	validatorTimeoutBlocks       = int(10)            // TODO adjust
-	maxLoadStateElementSize      = 0                  // no max
+	defaultAccountsCacheCapacity = 1000    // TODO adjust
+	validatorTimeoutBlocks       = int(10) // TODO adjust
+	maxLoadStateElementSize      = 0       // no max
+)
+
+// Layouts of the saved state. States saved before the layout was versioned
+// are stateLayoutV0, which starts with the chain id and holds only the
+// accounts and name registry trees. Later layouts start with
+// stateLayoutMarker, which can not start a go-wire string, and their version.
+// States of an older layout are loaded with empty trees and the default chain
+// params for what they lack, and saved in the latest layout.
+//
+// Trees and chain params that stateLayoutV0 lacks are left out of State.Hash
+// while they are empty or the defaults, so chains started before them keep
+// their app hash until they are first used.
+const (
+	stateLayoutV0 = iota
+	// adds the validator infos, chain params, gov proposals, roles, call ACLs,
+	// permission changes and grants, the name owners and the index of the
+	// permission changes by address
+	stateLayoutV1
+
+	stateLayout       = stateLayoutV1
+	stateLayoutMarker = byte(0xff)
 )
 
 //-----------------------------------------------------------------------------
@@ -61,6 +82,17 @@ type State struct {
 	accounts       merkle.Tree // Shouldn't be accessed directly.
 	validatorInfos merkle.Tree // Shouldn't be accessed directly.
 	nameReg        merkle.Tree // Shouldn't be accessed directly.
+	govProposals   merkle.Tree // Shouldn't be accessed directly.
+	roles          merkle.Tree // Shouldn't be accessed directly.
+	callACLs       merkle.Tree // Shouldn't be accessed directly.
+	permChanges    merkle.Tree // Shouldn't be accessed directly.
+	permGrants     merkle.Tree // Shouldn't be accessed directly.
+	nameOwners     merkle.Tree // Shouldn't be accessed directly.
+	params         *txs.ChainParams
+	ethChainID     uint64 // derived from the genesis doc on first use
+
+	// index of permChanges by address, see GetPermissionChangesByAddress
+	permChangesByAddress merkle.Tree // Shouldn't be accessed directly.
 
 	evc events.Fireable // typically an events.EventCache
 }
@@ -72,6 +104,15 @@ func LoadState(db dbm.DB) *State {
 		return nil
 	} else {
 		r, n, err := bytes.NewReader(buf), new(int), new(error)
+		layout := stateLayoutV0
+		if buf[0] == stateLayoutMarker {
+			wire.ReadByte(r, n, err)
+			layout = int(wire.ReadUvarint(r, n, err))
+			if layout > stateLayout {
+				util.Fatalf("The state has layout %v but this version of burrow "+
+					"can only read up to layout %v\n", layout, stateLayout)
+			}
+		}
 		s.ChainID = wire.ReadString(r, maxLoadStateElementSize, n, err)
 		s.LastBlockHeight = wire.ReadVarint(r, n, err)
 		s.LastBlockHash = wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
@@ -83,12 +124,42 @@ func LoadState(db dbm.DB) *State {
 		accountsHash := wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
 		s.accounts = merkle.NewIAVLTree(defaultAccountsCacheCapacity, db)
 		s.accounts.Load(accountsHash)
-		//validatorInfosHash := wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
-		//s.validatorInfos = merkle.NewIAVLTree(wire.BasicCodec, types.ValidatorInfoCodec, 0, db)
-		//s.validatorInfos.Load(validatorInfosHash)
 		nameRegHash := wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
 		s.nameReg = merkle.NewIAVLTree(0, db)
 		s.nameReg.Load(nameRegHash)
+		// the trees that stateLayoutV0 does not have start empty, which an
+		// empty hash loads
+		var validatorInfosHash, govProposalsHash, rolesHash, callACLsHash,
+			permChangesHash, permGrantsHash, nameOwnersHash,
+			permChangesByAddressHash []byte
+		s.params = txs.DefaultChainParams()
+		if layout >= stateLayoutV1 {
+			validatorInfosHash = wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
+			s.params = wire.ReadBinary(&txs.ChainParams{}, r, maxLoadStateElementSize, n, err).(*txs.ChainParams)
+			govProposalsHash = wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
+			rolesHash = wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
+			callACLsHash = wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
+			permChangesHash = wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
+			permGrantsHash = wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
+			nameOwnersHash = wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
+			permChangesByAddressHash = wire.ReadByteSlice(r, maxLoadStateElementSize, n, err)
+		}
+		s.validatorInfos = merkle.NewIAVLTree(0, db)
+		s.validatorInfos.Load(validatorInfosHash)
+		s.govProposals = merkle.NewIAVLTree(0, db)
+		s.govProposals.Load(govProposalsHash)
+		s.roles = merkle.NewIAVLTree(0, db)
+		s.roles.Load(rolesHash)
+		s.callACLs = merkle.NewIAVLTree(0, db)
+		s.callACLs.Load(callACLsHash)
+		s.permChanges = merkle.NewIAVLTree(0, db)
+		s.permChanges.Load(permChangesHash)
+		s.permGrants = merkle.NewIAVLTree(0, db)
+		s.permGrants.Load(permGrantsHash)
+		s.nameOwners = merkle.NewIAVLTree(0, db)
+		s.nameOwners.Load(nameOwnersHash)
+		s.permChangesByAddress = merkle.NewIAVLTree(0, db)
+		s.permChangesByAddress.Load(permChangesByAddressHash)
 		if *err != nil {
 			// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
 			util.Fatalf("Data has been corrupted or its spec has changed: %v\n", *err)
@@ -100,9 +171,18 @@ func LoadState(db dbm.DB) *State {
 
 func (s *State) Save() {
 	s.accounts.Save()
-	//s.validatorInfos.Save()
+	s.validatorInfos.Save()
 	s.nameReg.Save()
+	s.govProposals.Save()
+	s.roles.Save()
+	s.callACLs.Save()
+	s.permChanges.Save()
+	s.permGrants.Save()
+	s.nameOwners.Save()
+	s.permChangesByAddress.Save()
 	buf, n, err := new(bytes.Buffer), new(int), new(error)
+	wire.WriteByte(stateLayoutMarker, buf, n, err)
+	wire.WriteUvarint(uint(stateLayout), buf, n, err)
 	wire.WriteString(s.ChainID, buf, n, err)
 	wire.WriteVarint(s.LastBlockHeight, buf, n, err)
 	wire.WriteByteSlice(s.LastBlockHash, buf, n, err)
@@ -112,8 +192,16 @@ func (s *State) Save() {
 	// wire.WriteBinary(s.LastBondedValidators, buf, n, err)
 	// wire.WriteBinary(s.UnbondingValidators, buf, n, err)
 	wire.WriteByteSlice(s.accounts.Hash(), buf, n, err)
-	//wire.WriteByteSlice(s.validatorInfos.Hash(), buf, n, err)
 	wire.WriteByteSlice(s.nameReg.Hash(), buf, n, err)
+	wire.WriteByteSlice(s.validatorInfos.Hash(), buf, n, err)
+	wire.WriteBinary(s.params, buf, n, err)
+	wire.WriteByteSlice(s.govProposals.Hash(), buf, n, err)
+	wire.WriteByteSlice(s.roles.Hash(), buf, n, err)
+	wire.WriteByteSlice(s.callACLs.Hash(), buf, n, err)
+	wire.WriteByteSlice(s.permChanges.Hash(), buf, n, err)
+	wire.WriteByteSlice(s.permGrants.Hash(), buf, n, err)
+	wire.WriteByteSlice(s.nameOwners.Hash(), buf, n, err)
+	wire.WriteByteSlice(s.permChangesByAddress.Hash(), buf, n, err)
 	if *err != nil {
 		// TODO: [Silas] Do something better than this, really serialising ought to
 		// be error-free
@@ -137,22 +225,52 @@ func (s *State) Copy() *State {
 		// BondedValidators:     s.BondedValidators.Copy(),     // TODO remove need for Copy() here.
 		// LastBondedValidators: s.LastBondedValidators.Copy(), // That is, make updates to the validator set
 		// UnbondingValidators: s.UnbondingValidators.Copy(), // copy the valSet lazily.
-		accounts: s.accounts.Copy(),
-		//validatorInfos:       s.validatorInfos.Copy(),
-		nameReg: s.nameReg.Copy(),
-		evc:     nil,
+		accounts:       s.accounts.Copy(),
+		validatorInfos: s.validatorInfos.Copy(),
+		nameReg:        s.nameReg.Copy(),
+		govProposals:   s.govProposals.Copy(),
+		roles:          s.roles.Copy(),
+		callACLs:       s.callACLs.Copy(),
+		permChanges:    s.permChanges.Copy(),
+		permGrants:     s.permGrants.Copy(),
+		nameOwners:     s.nameOwners.Copy(),
+		params:         s.params.Copy(),
+		ethChainID:     s.ethChainID,
+		evc:            nil,
+
+		permChangesByAddress: s.permChangesByAddress.Copy(),
 	}
 }
 
-// Returns a hash that represents the state data, excluding Last*
+// Returns a hash that represents the state data, excluding Last*. Trees
+// added since stateLayoutV0 are only hashed once they are not empty, and the
+// chain params once they are not the defaults.
 func (s *State) Hash() []byte {
-	return merkle.SimpleHashFromMap(map[string]interface{}{
+	hashables := map[string]interface{}{
 		//"BondedValidators":    s.BondedValidators,
 		//"UnbondingValidators": s.UnbondingValidators,
-		"Accounts": s.accounts,
-		//"ValidatorInfos":      s.validatorInfos,
+		"Accounts":     s.accounts,
 		"NameRegistry": s.nameReg,
-	})
+	}
+	for name, tree := range map[string]merkle.Tree{
+		"ValidatorInfos": s.validatorInfos,
+		"GovProposals":   s.govProposals,
+		"Roles":          s.roles,
+		"CallACLs":       s.callACLs,
+		"PermChanges":    s.permChanges,
+		"PermGrants":     s.permGrants,
+		"NameOwners":     s.nameOwners,
+
+		"PermChangesByAddress": s.permChangesByAddress,
+	} {
+		if tree.Size() > 0 {
+			hashables[name] = tree
+		}
+	}
+	if *s.params != *txs.DefaultChainParams() {
+		hashables["ChainParams"] = s.params
+	}
+	return merkle.SimpleHashFromMap(hashables)
 }
 
 /* //XXX Done by tendermint core
@@ -185,6 +303,24 @@ func (s *State) SetDB(db dbm.DB) {
 	s.DB = db
 }
 
+// Returns the Ethereum chain id EthTxs must be signed for, which unless the
+// chain id is numeric is derived from the genesis hash, see txs.EthChainID
+func (s *State) EthChainID() (uint64, error) {
+	if s.ethChainID != 0 {
+		return s.ethChainID, nil
+	}
+	var genesisHash []byte
+	if _, err := strconv.ParseUint(s.ChainID, 10, 64); err != nil {
+		genDoc, err := s.GetGenesisDoc()
+		if err != nil {
+			return 0, err
+		}
+		genesisHash = MakeGenesisState(dbm.NewMemDB(), genDoc).Hash()
+	}
+	s.ethChainID = txs.EthChainID(s.ChainID, genesisHash)
+	return s.ethChainID, nil
+}
+
 //-------------------------------------
 // State.params
 
@@ -192,6 +328,16 @@ func (s *State) GetGasLimit() int64 {
 	return 1000000 // TODO
 }
 
+// The returned ChainParams are a copy, so mutating them
+// has no side effects.
+func (s *State) GetChainParams() *txs.ChainParams {
+	return s.params.Copy()
+}
+
+func (s *State) SetChainParams(params *txs.ChainParams) {
+	s.params = params.Copy()
+}
+
 // State.params
 //-------------------------------------
 // State.accounts
@@ -234,112 +380,259 @@ func (s *State) SetAccounts(accounts merkle.Tree) {
 //-------------------------------------
 // State.validators
 
-// XXX: now handled by tendermint core
-
-/*
+// NOTE: the validator set itself is held by tendermint core; the state keeps
+// the bonding information from which the changes to that set are derived
+// (see BurrowMint.EndBlock).
 
-// The returned ValidatorInfo is a copy, so mutating it
-// has no side effects.
-func (s *State) GetValidatorInfo(address []byte) *types.ValidatorInfo {
-	_, valInfo := s.validatorInfos.Get(address)
-	if valInfo == nil {
+// Returns nil if no validator has ever bonded with the given address.
+func (s *State) GetValidatorInfo(address []byte) *ValidatorInfo {
+	_, valInfoBytes, _ := s.validatorInfos.Get(address)
+	if valInfoBytes == nil {
 		return nil
 	}
-	return valInfo.(*types.ValidatorInfo).Copy()
+	return DecodeValidatorInfo(valInfoBytes)
 }
 
 // Returns false if new, true if updated.
 // The valInfo is copied before setting, so mutating it
 // afterwards has no side effects.
-func (s *State) SetValidatorInfo(valInfo *types.ValidatorInfo) (updated bool) {
-	return s.validatorInfos.Set(valInfo.Address, valInfo.Copy())
+func (s *State) SetValidatorInfo(valInfo *ValidatorInfo) (updated bool) {
+	return s.validatorInfos.Set(valInfo.Address, EncodeValidatorInfo(valInfo))
 }
 
 func (s *State) GetValidatorInfos() merkle.Tree {
 	return s.validatorInfos.Copy()
 }
 
-func (s *State) unbondValidator(val *types.Validator) {
-	// Move validator to UnbondingValidators
-	val, removed := s.BondedValidators.Remove(val.Address)
-	if !removed {
-		PanicCrisis("Couldn't remove validator for unbonding")
-	}
-	val.UnbondHeight = s.LastBlockHeight + 1
-	added := s.UnbondingValidators.Add(val)
-	if !added {
-		PanicCrisis("Couldn't add validator for unbonding")
+// Set the validator infos tree
+func (s *State) SetValidatorInfos(validatorInfos merkle.Tree) {
+	s.validatorInfos = validatorInfos
+}
+
+// State.validators
+//-------------------------------------
+// State.govProposals
+
+// Returns the proposals accepted to take effect at the given height.
+func (s *State) GetGovProposals(height int) []*txs.GovProposal {
+	_, proposalsBytes, _ := s.govProposals.Get(govProposalsKey(height))
+	if proposalsBytes == nil {
+		return nil
 	}
+	return DecodeGovProposals(proposalsBytes)
 }
 
-func (s *State) rebondValidator(val *types.Validator) {
-	// Move validator to BondingValidators
-	val, removed := s.UnbondingValidators.Remove(val.Address)
-	if !removed {
-		PanicCrisis("Couldn't remove validator for rebonding")
+// An empty list of proposals removes the entry for the height.
+func (s *State) SetGovProposals(height int, proposals []*txs.GovProposal) {
+	if len(proposals) == 0 {
+		s.govProposals.Remove(govProposalsKey(height))
+		return
 	}
-	val.BondHeight = s.LastBlockHeight + 1
-	added := s.BondedValidators.Add(val)
-	if !added {
-		PanicCrisis("Couldn't add validator for rebonding")
+	s.govProposals.Set(govProposalsKey(height), EncodeGovProposals(proposals))
+}
+
+func (s *State) GetAllGovProposals() merkle.Tree {
+	return s.govProposals.Copy()
+}
+
+// Set the gov proposals tree
+func (s *State) SetAllGovProposals(govProposals merkle.Tree) {
+	s.govProposals = govProposals
+}
+
+// State.govProposals
+//-------------------------------------
+// State.roles
+
+// Returns the base permissions granted by the role, or nil if the role has
+// not been defined
+func (s *State) GetRolePermissions(role string) *ptypes.BasePermissions {
+	_, permsBytes, _ := s.roles.Get([]byte(ptypes.PadRole(role)))
+	if permsBytes == nil {
+		return nil
 	}
+	return DecodeRolePermissions(permsBytes)
 }
 
-func (s *State) releaseValidator(val *types.Validator) {
-	// Update validatorInfo
-	valInfo := s.GetValidatorInfo(val.Address)
-	if valInfo == nil {
-		PanicSanity("Couldn't find validatorInfo for release")
+// Setting nil or permissions with no set bits removes the role definition
+func (s *State) SetRolePermissions(role string, perms *ptypes.BasePermissions) {
+	if perms == nil || perms.SetBit == 0 {
+		s.roles.Remove([]byte(ptypes.PadRole(role)))
+		return
 	}
-	valInfo.ReleasedHeight = s.LastBlockHeight + 1
-	s.SetValidatorInfo(valInfo)
+	s.roles.Set([]byte(ptypes.PadRole(role)), wire.BinaryBytes(perms))
+}
 
-	// Send coins back to UnbondTo outputs
-	accounts, err := getOrMakeOutputs(s, nil, valInfo.UnbondTo)
-	if err != nil {
-		PanicSanity("Couldn't get or make unbondTo accounts")
+func DecodeRolePermissions(permsBytes []byte) *ptypes.BasePermissions {
+	var n int
+	var err error
+	perms := wire.ReadBinary(&ptypes.BasePermissions{}, bytes.NewBuffer(permsBytes),
+		txs.MaxDataLength, &n, &err)
+	return perms.(*ptypes.BasePermissions)
+}
+
+func (s *State) GetRoles() merkle.Tree {
+	return s.roles.Copy()
+}
+
+// Set the roles tree
+func (s *State) SetRoles(roles merkle.Tree) {
+	s.roles = roles
+}
+
+// State.roles
+//-------------------------------------
+// State.callACLs
+
+// Returns the call ACL held against the address, or nil if there is none
+func (s *State) GetCallACL(addr []byte) *ptypes.CallACL {
+	_, aclBytes, _ := s.callACLs.Get(addr)
+	if aclBytes == nil {
+		return nil
 	}
-	adjustByOutputs(accounts, valInfo.UnbondTo)
-	for _, acc := range accounts {
-		s.UpdateAccount(acc)
+	return DecodeCallACL(aclBytes)
+}
+
+// Setting nil or an empty ACL removes it
+func (s *State) SetCallACL(addr []byte, acl *ptypes.CallACL) {
+	if acl.IsEmpty() {
+		s.callACLs.Remove(addr)
+		return
 	}
+	s.callACLs.Set(addr, wire.BinaryBytes(acl))
+}
+
+func DecodeCallACL(aclBytes []byte) *ptypes.CallACL {
+	var n int
+	var err error
+	acl := wire.ReadBinary(&ptypes.CallACL{}, bytes.NewBuffer(aclBytes),
+		txs.MaxDataLength, &n, &err)
+	return acl.(*ptypes.CallACL)
+}
+
+func (s *State) GetCallACLs() merkle.Tree {
+	return s.callACLs.Copy()
+}
+
+// Set the call ACLs tree
+func (s *State) SetCallACLs(callACLs merkle.Tree) {
+	s.callACLs = callACLs
+}
 
-	// Remove validator from UnbondingValidators
-	_, removed := s.UnbondingValidators.Remove(val.Address)
-	if !removed {
-		PanicCrisis("Couldn't remove validator for release")
+// State.callACLs
+//-------------------------------------
+// State.permGrants
+
+// Returns the expiries and delegations held by the address, or nil if there
+// are none
+func (s *State) GetPermissionGrants(addr []byte) *ptypes.PermissionGrants {
+	_, grantsBytes, _ := s.permGrants.Get(addr)
+	if grantsBytes == nil {
+		return nil
 	}
+	return DecodePermissionGrants(grantsBytes)
 }
 
-func (s *State) destroyValidator(val *types.Validator) {
-	// Update validatorInfo
-	valInfo := s.GetValidatorInfo(val.Address)
-	if valInfo == nil {
-		PanicSanity("Couldn't find validatorInfo for release")
+// Setting nil or empty grants removes them
+func (s *State) SetPermissionGrants(addr []byte, grants *ptypes.PermissionGrants) {
+	if grants.IsEmpty() {
+		s.permGrants.Remove(addr)
+		return
 	}
-	valInfo.DestroyedHeight = s.LastBlockHeight + 1
-	valInfo.DestroyedAmount = val.VotingPower
-	s.SetValidatorInfo(valInfo)
+	s.permGrants.Set(addr, wire.BinaryBytes(grants))
+}
 
-	// Remove validator
-	_, removed := s.BondedValidators.Remove(val.Address)
-	if !removed {
-		_, removed := s.UnbondingValidators.Remove(val.Address)
-		if !removed {
-			PanicCrisis("Couldn't remove validator for destruction")
+func DecodePermissionGrants(grantsBytes []byte) *ptypes.PermissionGrants {
+	var n int
+	var err error
+	grants := wire.ReadBinary(&ptypes.PermissionGrants{}, bytes.NewBuffer(grantsBytes),
+		txs.MaxDataLength, &n, &err)
+	return grants.(*ptypes.PermissionGrants)
+}
+
+func (s *State) GetAllPermissionGrants() merkle.Tree {
+	return s.permGrants.Copy()
+}
+
+// Set the permission grants tree
+func (s *State) SetAllPermissionGrants(permGrants merkle.Tree) {
+	s.permGrants = permGrants
+}
+
+// State.permGrants
+//-------------------------------------
+// State.permChanges
+
+// Returns the number of entries in the permission change log
+func (s *State) NumPermissionChanges() int {
+	return s.permChanges.Size()
+}
+
+// Appends the change to the permission change log, setting its index, and
+// indexes it under each account it involves
+func (s *State) AppendPermissionChange(change *ptypes.PermissionChange) {
+	change.Index = s.permChanges.Size()
+	key := permChangeKey(change.Index)
+	s.permChanges.Set(key, wire.BinaryBytes(change))
+	for _, address := range [][]byte{change.Actor, change.Target, change.Subject} {
+		if len(address) > 0 {
+			// an address appearing twice in the change is indexed once
+			s.permChangesByAddress.Set(permChangeAddressKey(address, change), key)
 		}
 	}
+}
 
+// Returns the permission change log, keyed by the big endian index of each
+// change so that it iterates in the order changes were made
+func (s *State) GetAllPermissionChanges() merkle.Tree {
+	return s.permChanges
 }
 
-// Set the validator infos tree
-func (s *State) SetValidatorInfos(validatorInfos merkle.Tree) {
-	s.validatorInfos = validatorInfos
+// Returns the index of the permission change log by the accounts each change
+// involves. Its keys are an address followed by the big endian height and
+// index of a change, so the changes involving an address are those keyed by
+// it and iterate in the order they were made. Its values are the keys of the
+// changes in the log.
+func (s *State) GetPermissionChangesByAddress() merkle.Tree {
+	return s.permChangesByAddress
 }
 
-*/
+// Returns the change with the given key in the log, or nil
+func (s *State) GetPermissionChange(key []byte) (*ptypes.PermissionChange, error) {
+	_, changeBytes, exists := s.permChanges.Get(key)
+	if !exists {
+		return nil, nil
+	}
+	return DecodePermissionChange(changeBytes)
+}
 
-// State.validators
+func DecodePermissionChange(changeBytes []byte) (*ptypes.PermissionChange, error) {
+	var n int
+	var err error
+	change := wire.ReadBinary(&ptypes.PermissionChange{}, bytes.NewBuffer(changeBytes),
+		0, &n, &err)
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode permission change: %v", err)
+	}
+	return change.(*ptypes.PermissionChange), nil
+}
+
+// Keys are big endian so the log iterates in the order changes were made
+func permChangeKey(index int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(index))
+	return key
+}
+
+func permChangeAddressKey(address []byte, change *ptypes.PermissionChange) []byte {
+	key := make([]byte, len(address)+16)
+	copy(key, address)
+	binary.BigEndian.PutUint64(key[len(address):], uint64(change.Height))
+	binary.BigEndian.PutUint64(key[len(address)+8:], uint64(change.Index))
+	return key
+}
+
+// State.permChanges
 //-------------------------------------
 // State.storage
 
@@ -370,6 +663,12 @@ func DecodeNameRegEntry(entryBytes []byte) *core_types.NameRegEntry {
 }
 
 func (s *State) UpdateNameRegEntry(entry *core_types.NameRegEntry) bool {
+	if oldEntry := s.GetNameRegEntry(entry.Name); oldEntry == nil {
+		s.addOwnedName(entry.Owner, entry.Name)
+	} else if !bytes.Equal(oldEntry.Owner, entry.Owner) {
+		s.removeOwnedName(oldEntry.Owner, entry.Name)
+		s.addOwnedName(entry.Owner, entry.Name)
+	}
 	w := new(bytes.Buffer)
 	var n int
 	var err error
@@ -378,6 +677,9 @@ func (s *State) UpdateNameRegEntry(entry *core_types.NameRegEntry) bool {
 }
 
 func (s *State) RemoveNameRegEntry(name string) bool {
+	if oldEntry := s.GetNameRegEntry(name); oldEntry != nil {
+		s.removeOwnedName(oldEntry.Owner, name)
+	}
 	_, removed := s.nameReg.Remove([]byte(name))
 	return removed
 }
@@ -395,8 +697,35 @@ func NameRegEncoder(o interface{}, w io.Writer, n *int, err *error) {
 	wire.WriteBinary(o.(*core_types.NameRegEntry), w, n, err)
 }
 
+// Layout of entries written before data types were introduced, which end
+// after Expires
+type legacyNameRegEntry struct {
+	Name    string
+	Owner   []byte
+	Data    string
+	Expires int
+}
+
 func NameRegDecoder(r io.Reader, n *int, err *error) interface{} {
-	return wire.ReadBinary(&core_types.NameRegEntry{}, r, txs.MaxDataLength, n, err)
+	legacy := wire.ReadBinary(&legacyNameRegEntry{}, r, txs.MaxDataLength, n, err).(*legacyNameRegEntry)
+	entry := &core_types.NameRegEntry{
+		Name:    legacy.Name,
+		Owner:   legacy.Owner,
+		Data:    legacy.Data,
+		Expires: legacy.Expires,
+	}
+	if *err != nil {
+		return entry
+	}
+	// legacy entries have no data type and hold plain data
+	var dataTypeErr error
+	dataType := wire.ReadByte(r, n, &dataTypeErr)
+	if dataTypeErr == nil {
+		entry.DataType = core_types.NameDataType(dataType)
+	} else if dataTypeErr != io.EOF {
+		*err = dataTypeErr
+	}
+	return entry
 }
 
 var NameRegCodec = wire.Codec{
@@ -406,6 +735,42 @@ var NameRegCodec = wire.Codec{
 
 // State.nameReg
 //-------------------------------------
+// State.nameOwners
+
+// Returns the names registered to owner in sorted order, whether or not
+// they have expired
+func (s *State) GetNamesByOwner(owner []byte) []string {
+	names := []string{}
+	// the index of the first key owned by owner
+	index, _, _ := s.nameOwners.Get(owner)
+	for ; index < s.nameOwners.Size(); index++ {
+		key, _ := s.nameOwners.GetByIndex(index)
+		if !bytes.HasPrefix(key, owner) {
+			break
+		}
+		names = append(names, string(key[len(owner):]))
+	}
+	return names
+}
+
+// Names are indexed by the key owner‖name with an empty value, so that a
+// change of owner touches a single key however many names they own
+func nameOwnerKey(owner []byte, name string) []byte {
+	key := make([]byte, len(owner), len(owner)+len(name))
+	copy(key, owner)
+	return append(key, name...)
+}
+
+func (s *State) addOwnedName(owner []byte, name string) {
+	s.nameOwners.Set(nameOwnerKey(owner, name), []byte{})
+}
+
+func (s *State) removeOwnedName(owner []byte, name string) {
+	s.nameOwners.Remove(nameOwnerKey(owner, name))
+}
+
+// State.nameOwners
+//-------------------------------------
 
 // Implements events.Eventable. Typically uses events.EventCache
 func (s *State) SetFireable(evc events.Fireable) {
@@ -455,6 +820,14 @@ func MakeGenesisState(db dbm.DB, genDoc *genesis.GenesisDoc) *State {
 		accounts.Set(acc.Address, acm.EncodeAccount(acc))
 	}
 
+	params := txs.DefaultChainParams()
+	if genDoc.Params != nil && genDoc.Params.ChainParams != nil {
+		if err := genDoc.Params.ChainParams.ValidateBasic(); err != nil {
+			util.Fatalf("The genesis file has invalid chain params: %v", err)
+		}
+		params = genDoc.Params.ChainParams.Copy()
+	}
+
 	// global permissions are saved as the 0 address
 	// so they are included in the accounts tree
 	globalPerms := ptypes.DefaultAccountPermissions
@@ -462,7 +835,7 @@ func MakeGenesisState(db dbm.DB, genDoc *genesis.GenesisDoc) *State {
 		globalPerms = *genDoc.Params.GlobalPermissions
 		// XXX: make sure the set bits are all true
 		// Without it the HasPermission() functions will fail
-		globalPerms.Base.SetBit = ptypes.AllPermFlags
+		globalPerms.Base = ptypes.GlobalBasePermissions(globalPerms.Base)
 	}
 
 	permsAcc := &acm.Account{
@@ -474,38 +847,30 @@ func MakeGenesisState(db dbm.DB, genDoc *genesis.GenesisDoc) *State {
 	}
 	accounts.Set(permsAcc.Address, acm.EncodeAccount(permsAcc))
 
-	// Make validatorInfos state tree && validators slice
-	/*
-		validatorInfos := merkle.NewIAVLTree(wire.BasicCodec, types.ValidatorInfoCodec, 0, db)
-		validators := make([]*types.Validator, len(genDoc.Validators))
-		for i, val := range genDoc.Validators {
-			pubKey := val.PubKey
-			address := pubKey.Address()
-
-			// Make ValidatorInfo
-			valInfo := &types.ValidatorInfo{
-				Address:         address,
-				PubKey:          pubKey,
-				UnbondTo:        make([]*types.TxOutput, len(val.UnbondTo)),
-				FirstBondHeight: 0,
-				FirstBondAmount: val.Amount,
-			}
-			for i, unbondTo := range val.UnbondTo {
-				valInfo.UnbondTo[i] = &types.TxOutput{
-					Address: unbondTo.Address,
-					Amount:  unbondTo.Amount,
-				}
-			}
-			validatorInfos.Set(address, valInfo)
-
-			// Make validator
-			validators[i] = &types.Validator{
-				Address:     address,
-				PubKey:      pubKey,
-				VotingPower: val.Amount,
+	// Make validatorInfos state tree
+	validatorInfos := merkle.NewIAVLTree(0, db)
+	for _, val := range genDoc.Validators {
+		pubKey := val.PubKey
+		address := pubKey.Address()
+
+		// Make ValidatorInfo
+		valInfo := &ValidatorInfo{
+			Address:         address,
+			PubKey:          pubKey,
+			UnbondTo:        make([]*txs.TxOutput, len(val.UnbondTo)),
+			FirstBondHeight: 0,
+			FirstBondAmount: val.Amount,
+			BondHeight:      0,
+			VotingPower:     val.Amount,
+		}
+		for i, unbondTo := range val.UnbondTo {
+			valInfo.UnbondTo[i] = &txs.TxOutput{
+				Address: unbondTo.Address,
+				Amount:  unbondTo.Amount,
 			}
 		}
-	*/
+		validatorInfos.Set(address, EncodeValidatorInfo(valInfo))
+	}
 
 	// Make namereg tree
 	nameReg := merkle.NewIAVLTree(0, db)
@@ -513,9 +878,37 @@ func MakeGenesisState(db dbm.DB, genDoc *genesis.GenesisDoc) *State {
 
 	// IAVLTrees must be persisted before copy operations.
 	accounts.Save()
-	//validatorInfos.Save()
+	validatorInfos.Save()
 	nameReg.Save()
 
+	// Make gov proposals tree
+	govProposals := merkle.NewIAVLTree(0, db)
+	govProposals.Save()
+
+	// Make roles tree
+	roles := merkle.NewIAVLTree(0, db)
+	roles.Save()
+
+	// Make call ACLs tree
+	callACLs := merkle.NewIAVLTree(0, db)
+	callACLs.Save()
+
+	// Make permission change log tree
+	permChanges := merkle.NewIAVLTree(0, db)
+	permChanges.Save()
+
+	// Make permission grants tree
+	permGrants := merkle.NewIAVLTree(0, db)
+	permGrants.Save()
+
+	// Make reverse name registry index
+	nameOwners := merkle.NewIAVLTree(0, db)
+	nameOwners.Save()
+
+	// Make index of the permission change log by address
+	permChangesByAddress := merkle.NewIAVLTree(0, db)
+	permChangesByAddress.Save()
+
 	return &State{
 		DB:              db,
 		ChainID:         genDoc.ChainID,
@@ -526,8 +919,17 @@ func MakeGenesisState(db dbm.DB, genDoc *genesis.GenesisDoc) *State {
 		//BondedValidators:     types.NewValidatorSet(validators),
 		//LastBondedValidators: types.NewValidatorSet(nil),
 		//UnbondingValidators:  types.NewValidatorSet(nil),
-		accounts: accounts,
-		//validatorInfos:       validatorInfos,
-		nameReg: nameReg,
+		accounts:       accounts,
+		validatorInfos: validatorInfos,
+		nameReg:        nameReg,
+		govProposals:   govProposals,
+		roles:          roles,
+		callACLs:       callACLs,
+		permChanges:    permChanges,
+		permGrants:     permGrants,
+		nameOwners:     nameOwners,
+		params:         params,
+
+		permChangesByAddress: permChangesByAddress,
 	}
 }
@@ -19,11 +19,18 @@ import (
 	"encoding/hex"
 	"testing"
 
+	acm "github.com/hyperledger/burrow/account"
 	core_types "github.com/hyperledger/burrow/core/types"
 	evm "github.com/hyperledger/burrow/manager/burrow-mint/evm"
+	ptypes "github.com/hyperledger/burrow/permission/types"
 	"github.com/hyperledger/burrow/txs"
 	"github.com/hyperledger/burrow/word256"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/go-crypto"
+	"github.com/tendermint/go-merkle"
+	"github.com/tendermint/go-wire"
 	"github.com/tendermint/tendermint/config/tendermint_test"
 )
 
@@ -192,6 +199,64 @@ func TestGenesisSaveLoad(t *testing.T) {
 }
 */
 
+func TestLoadStateLayoutV0(t *testing.T) {
+	s0, privAccounts, _ := RandGenesisState(2, true, 1000, 1, true, 1000)
+	s0.LastBlockHeight = 5
+
+	// save the state as burrow did before the state layout was versioned
+	buf, n, err := new(bytes.Buffer), new(int), new(error)
+	wire.WriteString(s0.ChainID, buf, n, err)
+	wire.WriteVarint(s0.LastBlockHeight, buf, n, err)
+	wire.WriteByteSlice(s0.LastBlockHash, buf, n, err)
+	wire.WriteBinary(s0.LastBlockParts, buf, n, err)
+	wire.WriteTime(s0.LastBlockTime, buf, n, err)
+	wire.WriteByteSlice(s0.accounts.Hash(), buf, n, err)
+	wire.WriteByteSlice(s0.nameReg.Hash(), buf, n, err)
+	if *err != nil {
+		t.Fatal(*err)
+	}
+	s0.DB.Set(stateKey, buf.Bytes())
+
+	s1 := LoadState(s0.DB)
+	if s1.LastBlockHeight != 5 || s1.validatorInfos.Size() != 0 {
+		t.Fatalf("Expected height 5 and no validator infos, got %v and %v",
+			s1.LastBlockHeight, s1.validatorInfos.Size())
+	}
+	if acc := s1.GetAccount(privAccounts[0].PubKey.Address()); acc == nil {
+		t.Fatal("Expected the accounts of the state to be loaded")
+	}
+	assert.Equal(t, txs.DefaultChainParams(), s1.GetChainParams())
+	// the app hash is the one the state had before it was versioned
+	hash := merkle.SimpleHashFromMap(map[string]interface{}{
+		"Accounts":     s0.accounts,
+		"NameRegistry": s0.nameReg,
+	})
+	assert.Equal(t, hash, s1.Hash())
+
+	// it is saved in the latest layout
+	s1.Save()
+	if s1.DB.Get(stateKey)[0] != stateLayoutMarker {
+		t.Fatal("Expected the state to be saved with its layout")
+	}
+	s2 := LoadState(s1.DB)
+	assert.Equal(t, hash, s2.Hash())
+	assert.Equal(t, s1.LastBlockHeight, s2.LastBlockHeight)
+
+	// until the new trees are used
+	s2.SetChainParams(&txs.ChainParams{
+		MinBondAmount:             1,
+		MinNameRegistrationPeriod: 1,
+		NameByteCostMultiplier:    1,
+		NameBlockCostMultiplier:   1,
+	})
+	s2.Save()
+	s3 := LoadState(s2.DB)
+	if bytes.Equal(hash, s3.Hash()) {
+		t.Fatal("Expected the app hash to include changed chain params")
+	}
+	assert.Equal(t, s2.GetChainParams(), s3.GetChainParams())
+}
+
 func TestTxSequence(t *testing.T) {
 
 	state, privAccounts, _ := RandGenesisState(3, true, 1000, 1, true, 1000)
@@ -235,6 +300,221 @@ func TestTxSequence(t *testing.T) {
 	}
 }
 
+func TestMultisigSendTx(t *testing.T) {
+	state, privAccounts, _ := RandGenesisState(3, true, 1000, 1, true, 1000)
+	multisig, err := acm.NewMultisigPubKey(2, []crypto.PubKey{privAccounts[0].PubKey,
+		privAccounts[1].PubKey, privAccounts[2].PubKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fund the multisig account
+	tx := txs.NewSendTx()
+	tx.AddInput(state, privAccounts[0].PubKey, 100)
+	tx.AddOutput(multisig.Address(), 100)
+	tx.SignInput(state.ChainID, 0, privAccounts[0])
+	if err := execTxWithState(state, tx, true); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	// a single signature is below the threshold
+	tx = txs.NewSendTx()
+	tx.AddMultisigInputWithNonce(multisig, 10, 1)
+	tx.AddOutput(privAccounts[1].PubKey.Address(), 10)
+	if err := tx.SignMultisigInput(state.ChainID, 0, 2, privAccounts[2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := execTxWithState(state, tx, true); err != txs.ErrTxInvalidSignature {
+		t.Fatalf("Expected invalid signature error, got %v", err)
+	}
+
+	// the signature of a key that is not part of the multisig is refused
+	if err := tx.SignMultisigInput(state.ChainID, 0, 0, privAccounts[1]); err == nil {
+		t.Fatal("Expected error signing with a key at the wrong index")
+	}
+
+	// two signatures meet the threshold
+	if err := tx.SignMultisigInput(state.ChainID, 0, 0, privAccounts[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := execTxWithState(state, tx, true); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	multisigAcc := state.GetAccount(multisig.Address())
+	if multisigAcc.Balance != 90 || multisigAcc.Sequence != 1 || multisigAcc.PubKey != nil {
+		t.Fatalf("Unexpected multisig account after send: %v", multisigAcc)
+	}
+}
+
+func TestValidUntilHeight(t *testing.T) {
+	state, privAccounts, _ := RandGenesisState(2, true, 1000, 1, true, 1000)
+	state.LastBlockHeight = 10
+
+	tx := txs.NewSendTx()
+	tx.AddInput(state, privAccounts[0].PubKey, 10)
+	tx.AddOutput(privAccounts[1].PubKey.Address(), 10)
+	tx.Inputs[0].ValidUntilHeight = 10
+	tx.SignInput(state.ChainID, 0, privAccounts[0])
+	if err := execTxWithState(state, tx, true); err != txs.ErrTxExpired {
+		t.Fatalf("Expected expired tx error, got %v", err)
+	}
+
+	// the expiry is covered by the signature
+	tx.Inputs[0].ValidUntilHeight = 11
+	if err := execTxWithState(state, tx, true); err != txs.ErrTxInvalidSignature {
+		t.Fatalf("Expected invalid signature error, got %v", err)
+	}
+
+	tx.SignInput(state.ChainID, 0, privAccounts[0])
+	if err := execTxWithState(state, tx, true); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+}
+
+func TestBatchTx(t *testing.T) {
+	state, privAccounts, _ := RandGenesisState(3, false, 1000, 1, true, 1000)
+	acc0 := state.GetAccount(privAccounts[0].PubKey.Address())
+	acc0.Permissions.Base.Set(ptypes.AddRole, true)
+	state.UpdateAccount(acc0)
+	acc1 := state.GetAccount(privAccounts[1].PubKey.Address())
+	newAddr := []byte("batch_new_account_01")
+
+	//------------------------------
+	// create, grant a role to and fund an account from two inputs
+	sendTx0 := txs.NewSendTx()
+	sendTx0.AddInputWithNonce(privAccounts[0].PubKey, 10, acc0.Sequence+1)
+	sendTx0.AddOutput(newAddr, 10)
+	permsTx := txs.NewPermissionsTxWithNonce(privAccounts[0].PubKey,
+		&ptypes.AddRoleArgs{Address: newAddr, Role: "treasurer"}, acc0.Sequence+2)
+	sendTx1 := txs.NewSendTx()
+	sendTx1.AddInputWithNonce(privAccounts[1].PubKey, 5, acc1.Sequence+1)
+	sendTx1.AddOutput(newAddr, 5)
+	batchTx := txs.NewBatchTx(sendTx0, permsTx, sendTx1)
+	batchTx.SignInputs(state.ChainID, privAccounts[0])
+	batchTx.SignInputs(state.ChainID, privAccounts[1])
+
+	// inner txs are only valid as part of the batch
+	if err := execTxWithState(state.Copy(), sendTx0, true); err != txs.ErrTxInvalidSignature {
+		t.Fatalf("Expected invalid signature error running an inner tx alone, got %v", err)
+	}
+
+	if err := execTxWithState(state, batchTx, true); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	newAcc := state.GetAccount(newAddr)
+	if newAcc == nil || newAcc.Balance != 15 || !newAcc.Permissions.HasRole("treasurer") {
+		t.Fatalf("Expected new account with balance 15 and role treasurer, got %v", newAcc)
+	}
+
+	//------------------------------
+	// the receipt of a batch gives the contracts created by its txs
+	acc1 = state.GetAccount(privAccounts[1].PubKey.Address())
+	sendTx1 = txs.NewSendTx()
+	sendTx1.AddInputWithNonce(privAccounts[1].PubKey, 5, acc1.Sequence+1)
+	sendTx1.AddOutput(newAddr, 5)
+	callTx := txs.NewCallTxWithNonce(privAccounts[1].PubKey, nil,
+		[]byte{0x60, 0x00, 0x60, 0x00, 0xf3}, 10, 10000, 0, acc1.Sequence+2)
+	batchTx = txs.NewBatchTx(sendTx1, callTx)
+	batchTx.SignInputs(state.ChainID, privAccounts[1])
+	if err := execTxWithState(state, batchTx, true); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	receipt := txs.GenerateReceipt(state.ChainID, batchTx)
+	if len(receipt.Receipts) != 2 || receipt.Receipts[0].CreatesContract != 0 ||
+		receipt.Receipts[1].CreatesContract != 1 {
+		t.Fatalf("Expected a receipt for each tx in the batch, got %v", receipt.Receipts)
+	}
+	if !bytes.Equal(receipt.Receipts[0].TxHash, txs.TxHash(state.ChainID, sendTx1)) {
+		t.Fatalf("Expected the receipt of the send tx, got %v", receipt.Receipts[0])
+	}
+	contractAddr := receipt.Receipts[1].ContractAddr
+	if contract := state.GetAccount(contractAddr); contract == nil || contract.Balance != 10 {
+		t.Fatalf("Expected contract at %X with balance 10, got %v", contractAddr, contract)
+	}
+
+	//------------------------------
+	// if any tx fails the whole batch is reverted
+	acc0 = state.GetAccount(privAccounts[0].PubKey.Address())
+	acc1 = state.GetAccount(privAccounts[1].PubKey.Address())
+	sendTx0 = txs.NewSendTx()
+	sendTx0.AddInputWithNonce(privAccounts[0].PubKey, 10, acc0.Sequence+1)
+	sendTx0.AddOutput(newAddr, 10)
+	sendTx1 = txs.NewSendTx()
+	sendTx1.AddInputWithNonce(privAccounts[1].PubKey, acc1.Balance+1, acc1.Sequence+1)
+	sendTx1.AddOutput(newAddr, acc1.Balance+1)
+	batchTx = txs.NewBatchTx(sendTx0, sendTx1)
+	batchTx.SignInputs(state.ChainID, privAccounts[0])
+	batchTx.SignInputs(state.ChainID, privAccounts[1])
+	if err := execTxWithState(state, batchTx, true); err == nil {
+		t.Fatal("Expected error from a batch with an insufficient input")
+	}
+	if newAcc0 := state.GetAccount(acc0.Address); newAcc0.Balance != acc0.Balance ||
+		newAcc0.Sequence != acc0.Sequence {
+		t.Fatalf("Expected first tx of reverted batch to have no effect, got %v", newAcc0)
+	}
+	if newAcc = state.GetAccount(newAddr); newAcc.Balance != 15 {
+		t.Fatalf("Expected balance of 15 after reverted batch, got %v", newAcc.Balance)
+	}
+}
+
+func TestEthTx(t *testing.T) {
+	state, _, _ := RandGenesisState(1, true, 1000, 1, true, 1000)
+	state.ChainID = "1"
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x46}, 32))
+	sender := &acm.Account{
+		Address:     txs.EthAddress(key.PubKey()),
+		Balance:     1000,
+		Permissions: ptypes.DefaultAccountPermissions,
+	}
+	state.UpdateAccount(sender)
+
+	// create a contract with empty code, sending it some value
+	tx := &txs.EthTx{
+		GasLimit: 10000,
+		Value:    10,
+		Data:     []byte{0x60, 0x00, 0x60, 0x00, 0xf3}, // return nothing
+	}
+	if err := tx.Sign(1, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := execTxWithState(state, tx, true); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	sender = state.GetAccount(sender.Address)
+	if sender.Sequence != 1 || sender.Balance != 990 || sender.PubKey != nil {
+		t.Fatalf("Expected sender with sequence 1, balance 990 and no pubkey, got %v", sender)
+	}
+	receipt := txs.GenerateReceipt(state.ChainID, tx)
+	contract := state.GetAccount(receipt.ContractAddr)
+	if contract == nil || contract.Balance != 10 {
+		t.Fatalf("Expected contract at %X with balance 10, got %v", receipt.ContractAddr, contract)
+	}
+
+	// replays are rejected by the nonce
+	if _, ok := execTxWithState(state, tx, true).(txs.ErrTxInvalidSequence); !ok {
+		t.Fatal("Expected invalid sequence error replaying a tx")
+	}
+
+	// as are txs signed for other chains
+	tx.Nonce = 1
+	if err := tx.Sign(2, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := execTxWithState(state, tx, true); err == nil {
+		t.Fatal("Expected error from a tx signed for another chain id")
+	}
+
+	// the sender needs the same permissions as for a CallTx
+	sender.Permissions.Base.Set(ptypes.CreateContract, false)
+	state.UpdateAccount(sender)
+	if err := tx.Sign(1, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := execTxWithState(state, tx, true); err == nil {
+		t.Fatal("Expected error creating a contract without permission")
+	}
+}
+
 func TestNameTxs(t *testing.T) {
 	state, privAccounts, _ := RandGenesisState(3, true, 1000, 1, true, 1000)
 
@@ -398,6 +678,156 @@ func TestNameTxs(t *testing.T) {
 	}
 }
 
+func TestHierarchicalNameTxs(t *testing.T) {
+	state, privAccounts, _ := RandGenesisState(3, true, 1000, 1, true, 1000)
+
+	data := "some data"
+	fee := int64(10)
+	amt := fee + int64(txs.MinNameRegistrationPeriod)*txs.NameCostPerBlock(txs.NameBaseCost("", data))
+	execNameTx := func(i int, name string) error {
+		tx, _ := txs.NewNameTx(state, privAccounts[i].PubKey, name, data, amt, fee)
+		tx.Sign(state.ChainID, privAccounts[i])
+		return execTxWithState(state, tx, true)
+	}
+	execNameTransferTx := func(i int, name string, newOwner []byte) error {
+		tx, _ := txs.NewNameTransferTx(state, privAccounts[i].PubKey, name, newOwner, fee)
+		tx.Sign(state.ChainID, privAccounts[i])
+		return execTxWithState(state, tx, true)
+	}
+
+	for _, name := range []string{"/foo", "foo/", "foo//bar"} {
+		if err := execNameTx(0, name); err == nil {
+			t.Fatalf("Expected invalid name error from %s", name)
+		}
+	}
+
+	if err := execNameTx(0, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	// only the owner of foo can register names beneath it
+	if err := execNameTx(1, "foo/bar"); err == nil {
+		t.Fatal("Expected error registering beneath a name owned by another account")
+	}
+	if err := execNameTx(0, "foo/bar"); err != nil {
+		t.Fatal(err)
+	}
+
+	// transfer foo/bar, after which its new owner controls the names beneath it
+	if err := execNameTransferTx(2, "foo/bar", privAccounts[2].Address); err == nil {
+		t.Fatal("Expected error transferring a name owned by another account")
+	}
+	// a transfer only costs its fee, which its input must pay exactly
+	tx, _ := txs.NewNameTransferTx(state, privAccounts[0].PubKey, "foo/bar", privAccounts[1].Address, fee)
+	tx.Input.Amount = fee + 1
+	tx.Sign(state.ChainID, privAccounts[0])
+	if err := execTxWithState(state, tx, true); err != txs.ErrTxInvalidAmount {
+		t.Fatalf("Expected ErrTxInvalidAmount paying more than the fee, got %v", err)
+	}
+	balance := state.GetAccount(privAccounts[0].Address).Balance
+	if err := execNameTransferTx(0, "foo/bar", privAccounts[1].Address); err != nil {
+		t.Fatal(err)
+	}
+	if newBalance := state.GetAccount(privAccounts[0].Address).Balance; newBalance != balance-fee {
+		t.Fatalf("Expected balance %v after the transfer, got %v", balance-fee, newBalance)
+	}
+	if entry := state.GetNameRegEntry("foo/bar"); !bytes.Equal(entry.Owner, privAccounts[1].Address) {
+		t.Fatalf("Expected foo/bar to be owned by %X, got %X", privAccounts[1].Address, entry.Owner)
+	}
+	if err := execNameTx(1, "foo/bar/baz"); err != nil {
+		t.Fatal(err)
+	}
+	// the owner of foo still controls everything beneath it
+	if err := execNameTx(0, "foo/bar/baz"); err != nil {
+		t.Fatal(err)
+	}
+	if entry := state.GetNameRegEntry("foo/bar/baz"); !bytes.Equal(entry.Owner, privAccounts[1].Address) {
+		t.Fatalf("Expected update by owner of foo to leave foo/bar/baz owned by %X", privAccounts[1].Address)
+	}
+	if err := execNameTx(2, "foo/bar/baz"); err == nil {
+		t.Fatal("Expected error updating a name owned by another account")
+	}
+
+	// reverse index
+	assert.Equal(t, []string{"foo"}, state.GetNamesByOwner(privAccounts[0].Address))
+	assert.Equal(t, []string{"foo/bar", "foo/bar/baz"}, state.GetNamesByOwner(privAccounts[1].Address))
+	assert.Empty(t, state.GetNamesByOwner(privAccounts[2].Address))
+
+	// once foo and foo/bar expire anyone can register beneath them
+	state.LastBlockHeight = state.GetNameRegEntry("foo/bar/baz").Expires
+	if err := execNameTx(2, "foo/bar/qux"); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"foo/bar/qux"}, state.GetNamesByOwner(privAccounts[2].Address))
+}
+
+func TestTypedNameTxs(t *testing.T) {
+	state, privAccounts, _ := RandGenesisState(1, true, 1000, 1, true, 1000)
+
+	fee := int64(10)
+	execNameTx := func(name, data string, dataType core_types.NameDataType) error {
+		amt := fee + int64(txs.MinNameRegistrationPeriod)*txs.NameCostPerBlock(txs.NameBaseCost(name, data))
+		tx, _ := txs.NewNameDataTx(state, privAccounts[0].PubKey, name, data, dataType, amt, fee)
+		tx.Sign(state.ChainID, privAccounts[0])
+		return execTxWithState(state, tx, true)
+	}
+
+	data := `{"endpoint":"https://example.com/?a=b","label":"wörld"}`
+	if err := execNameTx("service", data, core_types.NameDataPlain); err == nil {
+		t.Fatal("Expected invalid characters error for plain data")
+	}
+	if err := execNameTx("service", data, core_types.NameDataJSON); err != nil {
+		t.Fatal(err)
+	}
+	entry := state.GetNameRegEntry("service")
+	assert.Equal(t, data, entry.Data)
+	assert.Equal(t, core_types.NameDataJSON, entry.DataType)
+	// the price depends only on the size of the data
+	assert.Equal(t, txs.MinNameRegistrationPeriod, entry.Expires-state.LastBlockHeight)
+
+	if err := execNameTx("service", "{", core_types.NameDataJSON); err == nil {
+		t.Fatal("Expected invalid JSON error")
+	}
+	if err := execNameTx("service", "3q2+7w==", core_types.NameDataBytes); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, core_types.NameDataBytes, state.GetNameRegEntry("service").DataType)
+
+	// a NameTx registers plain data
+	amt := fee + int64(txs.MinNameRegistrationPeriod)*txs.NameCostPerBlock(txs.NameBaseCost("service", "plain"))
+	tx, _ := txs.NewNameTx(state, privAccounts[0].PubKey, "service", "plain", amt, fee)
+	tx.Sign(state.ChainID, privAccounts[0])
+	if err := execTxWithState(state, tx, true); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, core_types.NameDataPlain, state.GetNameRegEntry("service").DataType)
+}
+
+func TestDecodeLegacyNameRegEntry(t *testing.T) {
+	legacy := &legacyNameRegEntry{
+		Name:    "legacy",
+		Owner:   []byte("owner"),
+		Data:    "some data",
+		Expires: 10,
+	}
+	entry := DecodeNameRegEntry(wire.BinaryBytes(legacy))
+	assert.Equal(t, &core_types.NameRegEntry{
+		Name:     "legacy",
+		Owner:    []byte("owner"),
+		Data:     "some data",
+		Expires:  10,
+		DataType: core_types.NameDataPlain,
+	}, entry)
+
+	typed := &core_types.NameRegEntry{
+		Name:     "typed",
+		Owner:    []byte("owner"),
+		Data:     "3q2+7w==",
+		Expires:  10,
+		DataType: core_types.NameDataBytes,
+	}
+	assert.Equal(t, typed, DecodeNameRegEntry(wire.BinaryBytes(typed)))
+}
+
 // Test creating a contract from futher down the call stack
 /*
 contract Factory {
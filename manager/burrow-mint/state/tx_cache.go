@@ -19,6 +19,7 @@ import (
 
 	acm "github.com/hyperledger/burrow/account"
 	"github.com/hyperledger/burrow/common/sanity"
+	core_types "github.com/hyperledger/burrow/core/types"
 	"github.com/hyperledger/burrow/manager/burrow-mint/evm"
 	ptypes "github.com/hyperledger/burrow/permission/types" // for GlobalPermissionAddress ...
 	"github.com/hyperledger/burrow/txs"
@@ -31,6 +32,12 @@ type TxCache struct {
 	backend  *BlockCache
 	accounts map[Word256]vmAccountInfo
 	storages map[Tuple256]Word256
+	roles    map[string]*ptypes.BasePermissions
+	callACLs map[Word256]*ptypes.CallACL
+	grants   map[Word256]*ptypes.PermissionGrants
+	names    map[string]*core_types.NameRegEntry
+	changes  []*ptypes.PermissionChange
+	txHash   []byte
 }
 
 var _ vm.AppState = &TxCache{}
@@ -40,6 +47,10 @@ func NewTxCache(backend *BlockCache) *TxCache {
 		backend:  backend,
 		accounts: make(map[Word256]vmAccountInfo),
 		storages: make(map[Tuple256]Word256),
+		roles:    make(map[string]*ptypes.BasePermissions),
+		callACLs: make(map[Word256]*ptypes.CallACL),
+		grants:   make(map[Word256]*ptypes.PermissionGrants),
+		names:    make(map[string]*core_types.NameRegEntry),
 	}
 }
 
@@ -135,6 +146,106 @@ func (cache *TxCache) SetStorage(addr Word256, key Word256, value Word256) {
 
 // TxCache.storage
 //-------------------------------------
+// TxCache.roles
+
+func (cache *TxCache) GetRolePermissions(role string) *ptypes.BasePermissions {
+	role = ptypes.PadRole(role)
+	if perms, ok := cache.roles[role]; ok {
+		return perms
+	}
+	if perms := cache.backend.GetRolePermissions(role); perms != nil {
+		// Copy so changes are not seen by the backend until Sync()
+		permsCopy := *perms
+		return &permsCopy
+	}
+	return nil
+}
+
+// NOTE: Set nil to remove the role definition.
+func (cache *TxCache) SetRolePermissions(role string, perms *ptypes.BasePermissions) {
+	cache.roles[ptypes.PadRole(role)] = perms
+}
+
+// TxCache.roles
+//-------------------------------------
+// TxCache.callACLs
+
+func (cache *TxCache) GetCallACL(addr Word256) *ptypes.CallACL {
+	if acl, ok := cache.callACLs[addr]; ok {
+		return acl
+	}
+	// Copy so changes are not seen by the backend until Sync()
+	return cache.backend.GetCallACL(addr.Postfix(20)).Copy()
+}
+
+// NOTE: Set nil to remove the call ACL.
+func (cache *TxCache) SetCallACL(addr Word256, acl *ptypes.CallACL) {
+	cache.callACLs[addr] = acl
+}
+
+// TxCache.callACLs
+//-------------------------------------
+// TxCache.grants
+
+func (cache *TxCache) GetPermissionGrants(addr Word256) *ptypes.PermissionGrants {
+	if grants, ok := cache.grants[addr]; ok {
+		return grants
+	}
+	// Copy so changes are not seen by the backend until Sync()
+	return cache.backend.GetPermissionGrants(addr.Postfix(20)).Copy()
+}
+
+// NOTE: Set nil to remove the grants.
+func (cache *TxCache) SetPermissionGrants(addr Word256, grants *ptypes.PermissionGrants) {
+	cache.grants[addr] = grants
+}
+
+func (cache *TxCache) BlockHeight() int {
+	return cache.backend.BlockHeight()
+}
+
+// TxCache.grants
+//-------------------------------------
+// TxCache.permChanges
+
+func (cache *TxCache) AppendPermissionChange(change *ptypes.PermissionChange) {
+	change.TxHash = cache.txHash
+	cache.changes = append(cache.changes, change)
+}
+
+// Sets the hash of the tx being run against the cache, it is recorded with
+// any permission changes made
+func (cache *TxCache) SetTxHash(txHash []byte) {
+	cache.txHash = txHash
+}
+
+// TxCache.permChanges
+//-------------------------------------
+// TxCache.names
+
+func (cache *TxCache) GetNameRegEntry(name string) *core_types.NameRegEntry {
+	if entry, ok := cache.names[name]; ok {
+		return entry
+	}
+	entry := cache.backend.GetNameRegEntry(name)
+	if entry == nil {
+		return nil
+	}
+	// Copy so changes are not seen by the backend until Sync()
+	entryCopy := *entry
+	return &entryCopy
+}
+
+func (cache *TxCache) UpdateNameRegEntry(entry *core_types.NameRegEntry) {
+	cache.names[entry.Name] = entry
+}
+
+func (cache *TxCache) GetChainParams() *txs.ChainParams {
+	return cache.backend.GetChainParams()
+}
+
+// TxCache.names
+//-------------------------------------
 
 // These updates do not have to be in deterministic order,
 // the backend is responsible for ordering updates.
@@ -145,6 +256,31 @@ func (cache *TxCache) Sync() {
 		cache.backend.SetStorage(addr, key, value)
 	}
 
+	// Remove or update roles
+	for role, perms := range cache.roles {
+		cache.backend.SetRolePermissions(role, perms)
+	}
+
+	// Remove or update call ACLs
+	for addr, acl := range cache.callACLs {
+		cache.backend.SetCallACL(addr.Postfix(20), acl)
+	}
+
+	// Remove or update permission grants
+	for addr, grants := range cache.grants {
+		cache.backend.SetPermissionGrants(addr.Postfix(20), grants)
+	}
+
+	// Update name registry entries
+	for _, entry := range cache.names {
+		cache.backend.UpdateNameRegEntry(entry)
+	}
+
+	// Append permission changes in the order they were made
+	for _, change := range cache.changes {
+		cache.backend.AppendPermissionChange(change)
+	}
+
 	// Remove or update accounts
 	for addr, accInfo := range cache.accounts {
 		acc, removed := accInfo.unpack()
@@ -0,0 +1,201 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hyperledger/burrow/common/sanity"
+	"github.com/hyperledger/burrow/txs"
+
+	"github.com/tendermint/go-crypto"
+	"github.com/tendermint/go-wire"
+)
+
+// ValidatorInfo holds the bonding history of a validator. The voting power
+// tendermint core should assign to the validator is derived from it by Power().
+type ValidatorInfo struct {
+	Address         []byte          `json:"address"`
+	PubKey          crypto.PubKey   `json:"pub_key"`
+	UnbondTo        []*txs.TxOutput `json:"unbond_to"`
+	FirstBondHeight int             `json:"first_bond_height"`
+	FirstBondAmount int64           `json:"first_bond_amount"`
+	BondHeight      int             `json:"bond_height"`
+	UnbondHeight    int             `json:"unbond_height"`
+	ReleasedHeight  int             `json:"released_height"`
+	DestroyedHeight int             `json:"destroyed_height"`
+	DestroyedAmount int64           `json:"destroyed_amount"`
+	VotingPower     int64           `json:"voting_power"`
+}
+
+func (valInfo *ValidatorInfo) Copy() *ValidatorInfo {
+	valInfoCopy := *valInfo
+	return &valInfoCopy
+}
+
+// A validator is bonded from its BondHeight until it unbonds, is released,
+// or is destroyed for equivocating.
+func (valInfo *ValidatorInfo) IsBonded() bool {
+	return valInfo.UnbondHeight == 0 && valInfo.ReleasedHeight == 0 &&
+		valInfo.DestroyedHeight == 0
+}
+
+// A validator is unbonding after an UnbondTx until its bond is released.
+func (valInfo *ValidatorInfo) IsUnbonding() bool {
+	return valInfo.UnbondHeight != 0 && valInfo.ReleasedHeight == 0 &&
+		valInfo.DestroyedHeight == 0
+}
+
+// Returns the voting power of the validator in consensus, which is zero
+// for any validator that is not bonded.
+func (valInfo *ValidatorInfo) Power() int64 {
+	if !valInfo.IsBonded() {
+		return 0
+	}
+	return valInfo.VotingPower
+}
+
+func (valInfo *ValidatorInfo) String() string {
+	if valInfo == nil {
+		return "nil-ValidatorInfo"
+	}
+	return fmt.Sprintf("ValidatorInfo{%X:%v P:%v B:%v U:%v R:%v D:%v}", valInfo.Address,
+		valInfo.PubKey, valInfo.VotingPower, valInfo.BondHeight, valInfo.UnbondHeight,
+		valInfo.ReleasedHeight, valInfo.DestroyedHeight)
+}
+
+func ValidatorInfoEncoder(o interface{}, w io.Writer, n *int, err *error) {
+	wire.WriteBinary(o.(*ValidatorInfo), w, n, err)
+}
+
+func ValidatorInfoDecoder(r io.Reader, n *int, err *error) interface{} {
+	return wire.ReadBinary(&ValidatorInfo{}, r, 0, n, err)
+}
+
+var ValidatorInfoCodec = wire.Codec{
+	Encode: ValidatorInfoEncoder,
+	Decode: ValidatorInfoDecoder,
+}
+
+func EncodeValidatorInfo(valInfo *ValidatorInfo) []byte {
+	w := new(bytes.Buffer)
+	var n int
+	var err error
+	ValidatorInfoEncoder(valInfo, w, &n, &err)
+	return w.Bytes()
+}
+
+func DecodeValidatorInfo(valInfoBytes []byte) *ValidatorInfo {
+	var n int
+	var err error
+	valInfo := ValidatorInfoDecoder(bytes.NewBuffer(valInfoBytes), &n, &err)
+	return valInfo.(*ValidatorInfo)
+}
+
+//-----------------------------------------------------------------------------
+// Validator lifecycle
+
+func unbondValidator(blockCache *BlockCache, valInfo *ValidatorInfo) {
+	if !valInfo.IsBonded() {
+		sanity.PanicCrisis("Couldn't unbond validator that is not bonded")
+	}
+	valInfo.UnbondHeight = blockCache.State().LastBlockHeight + 1
+	blockCache.UpdateValidatorInfo(valInfo)
+}
+
+func rebondValidator(blockCache *BlockCache, valInfo *ValidatorInfo) {
+	if !valInfo.IsUnbonding() {
+		sanity.PanicCrisis("Couldn't rebond validator that is not unbonding")
+	}
+	valInfo.BondHeight = blockCache.State().LastBlockHeight + 1
+	valInfo.UnbondHeight = 0
+	blockCache.UpdateValidatorInfo(valInfo)
+}
+
+func releaseValidator(blockCache *BlockCache, valInfo *ValidatorInfo) {
+	if !valInfo.IsUnbonding() {
+		sanity.PanicCrisis("Couldn't release validator that is not unbonding")
+	}
+	valInfo.ReleasedHeight = blockCache.State().LastBlockHeight + 1
+	blockCache.UpdateValidatorInfo(valInfo)
+
+	// Send coins back to UnbondTo outputs
+	accounts, err := getOrMakeOutputs(blockCache, nil, valInfo.UnbondTo)
+	if err != nil {
+		sanity.PanicSanity("Couldn't get or make unbondTo accounts")
+	}
+	adjustByOutputs(accounts, valInfo.UnbondTo)
+	for _, acc := range accounts {
+		blockCache.UpdateAccount(acc)
+	}
+}
+
+func destroyValidator(blockCache *BlockCache, valInfo *ValidatorInfo) {
+	if valInfo.DestroyedHeight != 0 || valInfo.ReleasedHeight != 0 {
+		sanity.PanicCrisis("Couldn't destroy validator that has already left")
+	}
+	valInfo.DestroyedHeight = blockCache.State().LastBlockHeight + 1
+	valInfo.DestroyedAmount = valInfo.VotingPower
+	blockCache.UpdateValidatorInfo(valInfo)
+}
+
+// ReleaseValidators returns the bonded coins of any validator whose unbonding
+// period, as set in the chain parameters, is over at the given height to its
+// UnbondTo outputs.
+func ReleaseValidators(blockCache *BlockCache, height int) {
+	unbondingPeriodBlocks := blockCache.GetChainParams().UnbondingPeriodBlocks
+	toRelease := [][]byte{}
+	blockCache.State().validatorInfos.Iterate(func(key, value []byte) bool {
+		valInfo := blockCache.GetValidatorInfo(key)
+		if valInfo.IsUnbonding() && valInfo.UnbondHeight+unbondingPeriodBlocks < height {
+			toRelease = append(toRelease, key)
+		}
+		return false
+	})
+	for _, address := range toRelease {
+		valInfo := blockCache.GetValidatorInfo(address)
+		log.Notice("Releasing validator", "validator", valInfo, "height", height)
+		releaseValidator(blockCache, valInfo)
+	}
+}
+
+// ValidatorPowerChanges returns, in address order, the validators whose
+// voting power was changed by the transactions run against the BlockCache
+// since it was last synced.
+func ValidatorPowerChanges(blockCache *BlockCache) []*ValidatorInfo {
+	addrStrs := []string{}
+	for addrStr, info := range blockCache.validators {
+		if info.dirty {
+			addrStrs = append(addrStrs, addrStr)
+		}
+	}
+	sort.Strings(addrStrs)
+
+	changed := []*ValidatorInfo{}
+	for _, addrStr := range addrStrs {
+		valInfo, _ := blockCache.validators[addrStr].unpack()
+		var oldPower int64
+		if oldValInfo := blockCache.State().GetValidatorInfo([]byte(addrStr)); oldValInfo != nil {
+			oldPower = oldValInfo.Power()
+		}
+		if valInfo.Power() != oldPower {
+			changed = append(changed, valInfo)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,110 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"testing"
+
+	ptypes "github.com/hyperledger/burrow/permission/types"
+	"github.com/hyperledger/burrow/txs"
+
+	dbm "github.com/tendermint/go-db"
+)
+
+func TestGenesisValidatorInfos(t *testing.T) {
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
+	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
+	st := MakeGenesisState(stateDB, &genDoc)
+
+	valInfo := st.GetValidatorInfo(user[0].Address)
+	if valInfo == nil {
+		t.Fatal("Expected genesis validator to be in state")
+	}
+	if !valInfo.IsBonded() || valInfo.Power() != genDoc.Validators[0].Amount {
+		t.Fatalf("Expected genesis validator to be bonded with power %v, got %v",
+			genDoc.Validators[0].Amount, valInfo)
+	}
+}
+
+func TestBondUnbondReleaseValidator(t *testing.T) {
+	stateDB := dbm.NewDB("state", dbBackend, dbDir)
+	genDoc := newBaseGenDoc(PermsAllFalse, PermsAllFalse)
+	st := MakeGenesisState(stateDB, &genDoc)
+	blockCache := NewBlockCache(st)
+	bondAcc := blockCache.GetAccount(user[1].Address)
+	bondAcc.Permissions.Base.Set(ptypes.Bond, true)
+	blockCache.UpdateAccount(bondAcc)
+	balance := bondAcc.Balance
+
+	//------------------------------
+	// bonding gives the validator voting power
+	bondTx, _ := txs.NewBondTx(user[1].PubKey)
+	if err := bondTx.AddInput(blockCache, user[1].PubKey, 5); err != nil {
+		t.Fatal(err)
+	}
+	bondTx.AddOutput(user[1].Address, 5)
+	bondTx.SignInput(chainID, 0, user[1])
+	bondTx.SignBond(chainID, user[1])
+	if err := ExecTx(blockCache, bondTx, true, nil); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	changes := ValidatorPowerChanges(blockCache)
+	if len(changes) != 1 || !bytes.Equal(changes[0].Address, user[1].Address) ||
+		changes[0].Power() != 5 {
+		t.Fatalf("Expected a single power change to 5 for the bonded validator, got %v", changes)
+	}
+	blockCache.Sync()
+	if changes = ValidatorPowerChanges(blockCache); len(changes) != 0 {
+		t.Fatalf("Expected no power changes after sync, got %v", changes)
+	}
+
+	//------------------------------
+	// unbonding removes the voting power
+	unbondTx := txs.NewUnbondTx(user[1].Address, st.LastBlockHeight+1)
+	unbondTx.Sign(chainID, user[1])
+	if err := ExecTx(blockCache, unbondTx, true, nil); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	changes = ValidatorPowerChanges(blockCache)
+	if len(changes) != 1 || changes[0].Power() != 0 {
+		t.Fatalf("Expected a single power change to 0 for the unbonded validator, got %v", changes)
+	}
+	blockCache.Sync()
+
+	// unbonding twice is not allowed
+	if err := ExecTx(blockCache, unbondTx, true, nil); err == nil {
+		t.Fatal("Expected error unbonding an unbonding validator")
+	}
+
+	//------------------------------
+	// the bond is returned once the unbonding period is over
+	valInfo := blockCache.GetValidatorInfo(user[1].Address)
+	unbondingPeriodBlocks := blockCache.GetChainParams().UnbondingPeriodBlocks
+	ReleaseValidators(blockCache, valInfo.UnbondHeight+unbondingPeriodBlocks)
+	if valInfo = blockCache.GetValidatorInfo(user[1].Address); !valInfo.IsUnbonding() {
+		t.Fatal("Validator should not be released before the end of the unbonding period")
+	}
+	ReleaseValidators(blockCache, valInfo.UnbondHeight+unbondingPeriodBlocks+1)
+	if valInfo = blockCache.GetValidatorInfo(user[1].Address); valInfo.ReleasedHeight == 0 {
+		t.Fatal("Expected validator to be released")
+	}
+	if changes = ValidatorPowerChanges(blockCache); len(changes) != 0 {
+		t.Fatalf("Expected no power changes on release, got %v", changes)
+	}
+	if acc := blockCache.GetAccount(user[1].Address); acc.Balance != balance {
+		t.Fatalf("Expected bond to be returned, balance %v, got %v", balance, acc.Balance)
+	}
+}
@@ -138,17 +138,9 @@ func (this *transactor) BroadcastTx(tx txs.Tx) (*txs.Receipt, error) {
 		return nil, fmt.Errorf("Error broadcasting transaction: %v", err)
 	}
 
-	txHash := txs.TxHash(this.chainID, tx)
-	var createsContract uint8
-	var contractAddr []byte
-	// check if creates new contract
-	if callTx, ok := tx.(*txs.CallTx); ok {
-		if len(callTx.Address) == 0 {
-			createsContract = 1
-			contractAddr = state.NewContractAddress(callTx.Input.Address, callTx.Input.Sequence)
-		}
-	}
-	return &txs.Receipt{txHash, createsContract, contractAddr}, nil
+	// the receipt of a BatchTx also holds those of its txs
+	receipt := txs.GenerateReceipt(this.chainID, tx)
+	return &receipt, nil
 }
 
 // Orders calls to BroadcastTx using lock (waits for response from core before releasing)
@@ -383,6 +375,14 @@ func (this *transactor) SignTx(tx txs.Tx, privAccounts []*account.PrivAccount) (
 		nameTx := tx.(*txs.NameTx)
 		nameTx.Input.PubKey = privAccounts[0].PubKey
 		nameTx.Input.Signature = privAccounts[0].Sign(this.chainID, nameTx)
+	case *txs.NameTransferTx:
+		nameTransferTx := tx.(*txs.NameTransferTx)
+		nameTransferTx.Input.PubKey = privAccounts[0].PubKey
+		nameTransferTx.Input.Signature = privAccounts[0].Sign(this.chainID, nameTransferTx)
+	case *txs.NameDataTx:
+		nameDataTx := tx.(*txs.NameDataTx)
+		nameDataTx.Input.PubKey = privAccounts[0].PubKey
+		nameDataTx.Input.Signature = privAccounts[0].Sign(this.chainID, nameDataTx)
 	case *txs.SendTx:
 		sendTx := tx.(*txs.SendTx)
 		for i, input := range sendTx.Inputs {
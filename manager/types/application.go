@@ -77,20 +77,21 @@ type Application interface {
 	Query(query []byte) abci_types.Result
 }
 
-// Tendermint has a separate interface for reintroduction of blocks
+// Tendermint has a separate interface for reintroduction of blocks.
+// It must match abci_types.BlockchainAware, as Tendermint only calls these
+// methods on applications that implement that interface.
 type BlockchainAware interface {
 
 	// Initialise the blockchain
 	// validators: genesis validators from tendermint core
 	InitChain(validators []*abci_types.Validator)
 
-	// Signals the beginning of a block;
-	// NOTE: [ben] currently not supported by tendermint
-	BeginBlock(height uint64)
+	// Signals the beginning of a block
+	BeginBlock(hash []byte, header *abci_types.Header)
 
 	// Signals the end of a blockchain
-	// validators: changed validators from app to Tendermint
-	// NOTE: [ben] currently not supported by tendermint
-	// not yet well defined what the change set contains.
-	EndBlock(height uint64) (validators []*abci_types.Validator)
+	// Diffs: changed validators from app to Tendermint, where each
+	// validator carries its new voting power; a power of zero removes the
+	// validator from the validator set.
+	EndBlock(height uint64) abci_types.ResponseEndBlock
 }
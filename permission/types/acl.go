@@ -0,0 +1,172 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"fmt"
+)
+
+//---------------------------------------------------------------------------------------------
+
+// Call access control list held against an account. It narrows the Call
+// permission: once RestrictCallees is set the account may only call the
+// contracts in Callees, and once RestrictCallers is set the account (as a
+// contract) may only be called by the accounts in Callers or by holders of one
+// of CallerRoles. Adding an entry to a list sets its flag, which stays set when
+// the entries are removed, so removing the last entry allows no calls rather
+// than lifting the restriction.
+type CallACL struct {
+	Callees         [][]byte `json:"callees"`
+	Callers         [][]byte `json:"callers"`
+	CallerRoles     []string `json:"caller_roles"`
+	RestrictCallees bool     `json:"restrict_callees"`
+	RestrictCallers bool     `json:"restrict_callers"`
+}
+
+// Returns true if an account holding the ACL may call callee
+func (acl *CallACL) AllowsCallee(callee []byte) bool {
+	if acl == nil || !acl.RestrictCallees {
+		return true
+	}
+	return indexOfAddress(acl.Callees, callee) >= 0
+}
+
+// Returns true if a contract holding the ACL may be called by caller, which
+// has the given permissions and grants, at height
+func (acl *CallACL) AllowsCaller(caller []byte, callerPerms *AccountPermissions,
+	callerGrants *PermissionGrants, height int) bool {
+	if acl == nil || !acl.RestrictCallers {
+		return true
+	}
+	if indexOfAddress(acl.Callers, caller) >= 0 {
+		return true
+	}
+	if callerPerms != nil {
+		for _, role := range acl.CallerRoles {
+			if callerPerms.HasRoleAt(role, callerGrants, height) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns true if the ACL places no restrictions
+func (acl *CallACL) IsEmpty() bool {
+	return acl == nil || (!acl.RestrictCallees && !acl.RestrictCallers)
+}
+
+// Returns true if the callee is added, and false if it already exists
+func (acl *CallACL) AddCallee(callee []byte) bool {
+	if indexOfAddress(acl.Callees, callee) >= 0 {
+		return false
+	}
+	acl.Callees = append(acl.Callees, callee)
+	acl.RestrictCallees = true
+	return true
+}
+
+// Returns true if the callee is removed, and false if it is not found
+func (acl *CallACL) RmCallee(callee []byte) bool {
+	i := indexOfAddress(acl.Callees, callee)
+	if i < 0 {
+		return false
+	}
+	acl.Callees = append(acl.Callees[:i:i], acl.Callees[i+1:]...)
+	return true
+}
+
+// Returns true if the caller is added, and false if it already exists
+func (acl *CallACL) AddCaller(caller []byte) bool {
+	if indexOfAddress(acl.Callers, caller) >= 0 {
+		return false
+	}
+	acl.Callers = append(acl.Callers, caller)
+	acl.RestrictCallers = true
+	return true
+}
+
+// Returns true if the caller is removed, and false if it is not found
+func (acl *CallACL) RmCaller(caller []byte) bool {
+	i := indexOfAddress(acl.Callers, caller)
+	if i < 0 {
+		return false
+	}
+	acl.Callers = append(acl.Callers[:i:i], acl.Callers[i+1:]...)
+	return true
+}
+
+// Returns true if the role is added, and false if it already exists
+func (acl *CallACL) AddCallerRole(role string) bool {
+	role = PadRole(role)
+	for _, r := range acl.CallerRoles {
+		if r == role {
+			return false
+		}
+	}
+	acl.CallerRoles = append(acl.CallerRoles, role)
+	acl.RestrictCallers = true
+	return true
+}
+
+// Returns true if the role is removed, and false if it is not found
+func (acl *CallACL) RmCallerRole(role string) bool {
+	role = PadRole(role)
+	for i, r := range acl.CallerRoles {
+		if r == role {
+			acl.CallerRoles = append(acl.CallerRoles[:i:i], acl.CallerRoles[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Copy returns a copy of the ACL that can be modified independently
+func (acl *CallACL) Copy() *CallACL {
+	if acl == nil {
+		return nil
+	}
+	aclCopy := &CallACL{
+		Callees:         make([][]byte, len(acl.Callees)),
+		Callers:         make([][]byte, len(acl.Callers)),
+		CallerRoles:     make([]string, len(acl.CallerRoles)),
+		RestrictCallees: acl.RestrictCallees,
+		RestrictCallers: acl.RestrictCallers,
+	}
+	// addresses are never modified in place so copying the slices suffices
+	copy(aclCopy.Callees, acl.Callees)
+	copy(aclCopy.Callers, acl.Callers)
+	copy(aclCopy.CallerRoles, acl.CallerRoles)
+	return aclCopy
+}
+
+func (acl *CallACL) String() string {
+	if acl == nil {
+		return "CallACL{}"
+	}
+	return fmt.Sprintf("CallACL{Callees: %X, Callers: %X, CallerRoles: %v, "+
+		"RestrictCallees: %v, RestrictCallers: %v}", acl.Callees, acl.Callers,
+		acl.CallerRoles, acl.RestrictCallees, acl.RestrictCallers)
+}
+
+func indexOfAddress(addresses [][]byte, address []byte) int {
+	for i, a := range addresses {
+		if bytes.Equal(a, address) {
+			return i
+		}
+	}
+	return -1
+}
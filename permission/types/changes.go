@@ -0,0 +1,127 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"fmt"
+)
+
+//---------------------------------------------------------------------------------------------
+
+// Value of a permission flag, or of membership of a role or call ACL, before
+// or after a change
+type PermValue uint8
+
+const (
+	PermValueUnset PermValue = iota
+	PermValueFalse
+	PermValueTrue
+)
+
+func (v PermValue) String() string {
+	switch v {
+	case PermValueUnset:
+		return "unset"
+	case PermValueFalse:
+		return "false"
+	case PermValueTrue:
+		return "true"
+	default:
+		return "#-UNKNOWN-#"
+	}
+}
+
+func PermValueFromBool(b bool) PermValue {
+	if b {
+		return PermValueTrue
+	}
+	return PermValueFalse
+}
+
+// Returns the value of the permission in perms
+func PermValueOf(perms *BasePermissions, ty PermFlag) PermValue {
+	if perms == nil {
+		return PermValueUnset
+	}
+	v, err := perms.Get(ty)
+	if err != nil {
+		return PermValueUnset
+	}
+	return PermValueFromBool(v)
+}
+
+// An entry in the permission change log. Changes are made through a
+// PermissionsTx or an SNative call and the log is only ever appended to.
+type PermissionChange struct {
+	// Position in the log, assigned when the change is appended
+	Index int `json:"index"`
+	// Height of the block the change was made in and hash of the tx making it
+	Height int    `json:"height"`
+	TxHash []byte `json:"tx_hash"`
+	// Account sending the PermissionsTx or contract calling the SNative
+	Actor []byte `json:"actor"`
+	// Account whose permissions, roles or call ACL changed (the global
+	// permissions address for setGlobal), nil for role definitions
+	Target []byte `json:"target"`
+	// PermissionsTx or SNative function making the change, eg. setBase
+	Function string `json:"function"`
+	// Permission flag changed, zero for role membership and call ACL changes
+	Permission PermFlag `json:"permission"`
+	// Role granted, revoked or defined, if any
+	Role string `json:"role"`
+	// Callee or caller added to or removed from a call ACL, if any
+	Subject  []byte    `json:"subject"`
+	OldValue PermValue `json:"old_value"`
+	NewValue PermValue `json:"new_value"`
+	// Height until which the new value is valid, zero if it does not expire
+	ValidUntilHeight int `json:"valid_until_height"`
+}
+
+// Returns true if the account made the change or was changed by it
+func (change *PermissionChange) Involves(address []byte) bool {
+	return bytes.Equal(change.Actor, address) || bytes.Equal(change.Target, address) ||
+		(len(change.Subject) > 0 && bytes.Equal(change.Subject, address))
+}
+
+// Returns true if the change is to a permission overlapping the flags in ty.
+// Changes to role membership and call ACLs change no permission flag, so they
+// affect the permission they are made with and Call respectively.
+func (change *PermissionChange) Affects(ty PermFlag) bool {
+	if change.Permission != 0 {
+		return change.Permission&ty != 0
+	}
+	return functionPermFlags[change.Function]&ty != 0
+}
+
+// The permissions affected by the functions that change no permission flag
+var functionPermFlags = map[string]PermFlag{
+	"addRole":          AddRole,
+	"addRoleUntil":     AddRole,
+	"removeRole":       RmRole,
+	"addCallee":        Call,
+	"removeCallee":     Call,
+	"addCaller":        Call,
+	"removeCaller":     Call,
+	"addCallerRole":    Call,
+	"removeCallerRole": Call,
+}
+
+func (change *PermissionChange) String() string {
+	return fmt.Sprintf("PermissionChange{%v H:%v %X %s(%X, %s, %s) %v -> %v by %X}",
+		change.Index, change.Height, change.TxHash, change.Function, change.Target,
+		PermFlagToString(change.Permission), change.Role, change.OldValue, change.NewValue,
+		change.Actor)
+}
@@ -0,0 +1,219 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"fmt"
+)
+
+//---------------------------------------------------------------------------------------------
+
+// Grants held by an account that either lapse or were made by another
+// account: the heights until which its base permissions and roles remain valid,
+// and the permissions other accounts have delegated to it.
+type PermissionGrants struct {
+	Expiries    []PermExpiry `json:"expiries"`
+	Delegations []Delegation `json:"delegations"`
+}
+
+// Last height at which base permissions or a role set on an account are
+// valid. After it they are treated as unset.
+type PermExpiry struct {
+	// Base permissions expiring, zero for a role
+	Permission PermFlag `json:"permission"`
+	// Role expiring, empty for base permissions
+	Role             string `json:"role"`
+	ValidUntilHeight int    `json:"valid_until_height"`
+}
+
+// Permission that Delegator lets the account holding the delegation exercise
+// for as long as Delegator has it itself. A zero ValidUntilHeight never lapses.
+type Delegation struct {
+	Delegator        []byte   `json:"delegator"`
+	Permission       PermFlag `json:"permission"`
+	ValidUntilHeight int      `json:"valid_until_height"`
+}
+
+// Returns true if a grant valid until validUntilHeight has lapsed at height
+func GrantExpiredAt(validUntilHeight, height int) bool {
+	return validUntilHeight != 0 && height > validUntilHeight
+}
+
+// Returns true if any of the base permissions in ty have expired at height
+func (g *PermissionGrants) BaseExpired(ty PermFlag, height int) bool {
+	if g == nil {
+		return false
+	}
+	for _, e := range g.Expiries {
+		if e.Permission&ty != 0 && GrantExpiredAt(e.ValidUntilHeight, height) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the role has expired at height
+func (g *PermissionGrants) RoleExpired(role string, height int) bool {
+	if g == nil {
+		return false
+	}
+	role = PadRole(role)
+	for _, e := range g.Expiries {
+		if e.Permission == 0 && e.Role == role && GrantExpiredAt(e.ValidUntilHeight, height) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the height until which the role is valid, zero if it does not expire
+func (g *PermissionGrants) RoleExpiry(role string) int {
+	if g == nil {
+		return 0
+	}
+	role = PadRole(role)
+	for _, e := range g.Expiries {
+		if e.Permission == 0 && e.Role == role {
+			return e.ValidUntilHeight
+		}
+	}
+	return 0
+}
+
+// Sets the height until which the base permissions in ty are valid, replacing
+// any expiry they had. A zero validUntilHeight removes their expiry.
+func (g *PermissionGrants) SetBaseExpiry(ty PermFlag, validUntilHeight int) {
+	expiries := g.Expiries[:0:0]
+	for _, e := range g.Expiries {
+		e.Permission &^= ty
+		if e.Permission != 0 || e.Role != "" {
+			expiries = append(expiries, e)
+		}
+	}
+	if validUntilHeight != 0 {
+		expiries = append(expiries, PermExpiry{Permission: ty, ValidUntilHeight: validUntilHeight})
+	}
+	g.Expiries = expiries
+}
+
+// Sets the height until which the role is valid, replacing any expiry it had.
+// A zero validUntilHeight removes its expiry.
+func (g *PermissionGrants) SetRoleExpiry(role string, validUntilHeight int) {
+	role = PadRole(role)
+	expiries := g.Expiries[:0:0]
+	for _, e := range g.Expiries {
+		if e.Permission != 0 || e.Role != role {
+			expiries = append(expiries, e)
+		}
+	}
+	if validUntilHeight != 0 {
+		expiries = append(expiries, PermExpiry{Role: role, ValidUntilHeight: validUntilHeight})
+	}
+	g.Expiries = expiries
+}
+
+// Delegates the permission from delegator, replacing any existing delegation
+// of it by delegator. Returns true if there was no such delegation.
+func (g *PermissionGrants) Delegate(delegator []byte, ty PermFlag, validUntilHeight int) bool {
+	delegation := Delegation{
+		Delegator:        delegator,
+		Permission:       ty,
+		ValidUntilHeight: validUntilHeight,
+	}
+	if i := g.indexOfDelegation(delegator, ty); i >= 0 {
+		g.Delegations[i] = delegation
+		return false
+	}
+	g.Delegations = append(g.Delegations, delegation)
+	return true
+}
+
+// Returns true if the delegation is revoked, and false if it is not found
+func (g *PermissionGrants) RevokeDelegation(delegator []byte, ty PermFlag) bool {
+	i := g.indexOfDelegation(delegator, ty)
+	if i < 0 {
+		return false
+	}
+	g.Delegations = append(g.Delegations[:i:i], g.Delegations[i+1:]...)
+	return true
+}
+
+// Returns true if delegator has delegated the permission and the delegation
+// has not lapsed at height
+func (g *PermissionGrants) HasDelegation(delegator []byte, ty PermFlag, height int) bool {
+	i := g.indexOfDelegation(delegator, ty)
+	return i >= 0 && !GrantExpiredAt(g.Delegations[i].ValidUntilHeight, height)
+}
+
+// Returns the accounts whose delegation of the permission has not lapsed at
+// height
+func (g *PermissionGrants) Delegators(ty PermFlag, height int) [][]byte {
+	if g == nil {
+		return nil
+	}
+	var delegators [][]byte
+	for _, d := range g.Delegations {
+		if d.Permission == ty && !GrantExpiredAt(d.ValidUntilHeight, height) {
+			delegators = append(delegators, d.Delegator)
+		}
+	}
+	return delegators
+}
+
+// Returns true if there are no grants
+func (g *PermissionGrants) IsEmpty() bool {
+	return g == nil || (len(g.Expiries) == 0 && len(g.Delegations) == 0)
+}
+
+// Copy returns a copy of the grants that can be modified independently
+func (g *PermissionGrants) Copy() *PermissionGrants {
+	if g == nil {
+		return nil
+	}
+	grantsCopy := &PermissionGrants{
+		Expiries:    make([]PermExpiry, len(g.Expiries)),
+		Delegations: make([]Delegation, len(g.Delegations)),
+	}
+	// addresses are never modified in place so copying the slices suffices
+	copy(grantsCopy.Expiries, g.Expiries)
+	copy(grantsCopy.Delegations, g.Delegations)
+	return grantsCopy
+}
+
+func (g *PermissionGrants) String() string {
+	if g == nil {
+		return "PermissionGrants{}"
+	}
+	return fmt.Sprintf("PermissionGrants{Expiries: %v, Delegations: %v}", g.Expiries, g.Delegations)
+}
+
+func (g *PermissionGrants) indexOfDelegation(delegator []byte, ty PermFlag) int {
+	if g == nil {
+		return -1
+	}
+	for i, d := range g.Delegations {
+		if d.Permission == ty && bytes.Equal(d.Delegator, delegator) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns true if ty is a single known permission flag. Only single flags can
+// be delegated.
+func IsSinglePermFlag(ty PermFlag) bool {
+	return ty != 0 && ty&(ty-1) == 0 && ty <= TopPermFlag
+}
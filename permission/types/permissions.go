@@ -51,13 +51,58 @@ const (
 	AddRole
 	RmRole
 
-	NumPermissions uint = 14 // NOTE Adjust this too. We can support upto 64
+	// governance permissions
+	Governance
+
+	// may sign tx inputs at all
+	Input
+	// may delete or override any name in the name registry
+	NameAdmin
+
+	// NOTE: new flags go immediately above, with their names in permFlagNames.
+	// NumPermissions counts the flags so must follow the last. We can support upto 64
+	NumPermissions uint = iota
 
 	TopPermFlag      PermFlag = 1 << (NumPermissions - 1)
 	AllPermFlags     PermFlag = TopPermFlag | (TopPermFlag - 1)
-	DefaultPermFlags PermFlag = Send | Call | CreateContract | CreateAccount | Bond | Name | HasBase | HasRole
+	DefaultPermFlags PermFlag = Send | Call | CreateContract | CreateAccount | Bond | Name | HasBase | HasRole | Input
+
+	// Flags granted by the global permissions unless they explicitly set them,
+	// so that genesis files and proposals predating the flags keep working
+	DefaultGlobalPermFlags PermFlag = Input
 )
 
+// Names of each permission flag indexed by the flag's bit. The first name is
+// the one returned by PermFlagToString, the rest are aliases also accepted by
+// PermStringToFlag. Names are matched case insensitively.
+var permFlagNames = [NumPermissions][]string{
+	{"root"},
+	{"send"},
+	{"call"},
+	{"create_contract", "createContract"},
+	{"create_account", "createAccount"},
+	{"bond"},
+	{"name"},
+	{"hasBase", "has_base"},
+	{"setBase", "set_base"},
+	{"unsetBase", "unset_base"},
+	{"setGlobal", "set_global"},
+	{"hasRole", "has_role"},
+	{"addRole", "add_role"},
+	{"removeRole", "rmRole", "rm_role"},
+	{"governance"},
+	{"input"},
+	{"nameAdmin", "name_admin"},
+}
+
+func init() {
+	for i, names := range permFlagNames {
+		if len(names) == 0 {
+			panic(fmt.Sprintf("permission flag %v has no name", PermFlag(1)<<uint(i)))
+		}
+	}
+}
+
 var (
 	ZeroBasePermissions    = BasePermissions{0, 0}
 	ZeroAccountPermissions = AccountPermissions{
@@ -139,14 +184,60 @@ func (p BasePermissions) String() string {
 
 //---------------------------------------------------------------------------------------------
 
+// RoleGetter looks up the base permissions granted by a role. It returns nil
+// for a role that has not been defined.
+type RoleGetter interface {
+	GetRolePermissions(role string) *BasePermissions
+}
+
+// Roles are held right padded to 32 bytes so that they can be passed to and
+// from contracts as bytes32
+func PadRole(role string) string {
+	return string(word256.RightPadBytes([]byte(role), 32))
+}
+
 type AccountPermissions struct {
 	Base  BasePermissions `json:"base"`
 	Roles []string        `json:"roles"`
 }
 
+// Get a permission value granted through the account's roles, ignoring roles
+// that have expired at height according to the account's grants. If several of
+// the roles set the permission it is granted when any one of them grants it.
+// ErrValueNotSet is returned if none of the roles set the permission, and
+// should be caught by caller so the global permission can be fetched
+func (aP *AccountPermissions) GetFromRoles(ty PermFlag, roles RoleGetter,
+	grants *PermissionGrants, height int) (bool, error) {
+	if ty == 0 {
+		return false, ErrInvalidPermission(ty)
+	}
+	set := false
+	for _, role := range aP.Roles {
+		if grants.RoleExpired(role, height) {
+			continue
+		}
+		rolePerms := roles.GetRolePermissions(role)
+		if rolePerms == nil {
+			continue
+		}
+		v, err := rolePerms.Get(ty)
+		if err != nil {
+			continue
+		}
+		if v {
+			return true, nil
+		}
+		set = true
+	}
+	if !set {
+		return false, ErrValueNotSet(ty)
+	}
+	return false, nil
+}
+
 // Returns true if the role is found
 func (aP *AccountPermissions) HasRole(role string) bool {
-	role = string(word256.RightPadBytes([]byte(role), 32))
+	role = PadRole(role)
 	for _, r := range aP.Roles {
 		if r == role {
 			return true
@@ -155,9 +246,15 @@ func (aP *AccountPermissions) HasRole(role string) bool {
 	return false
 }
 
+// Returns true if the role is found and has not expired at height according
+// to the account's grants
+func (aP *AccountPermissions) HasRoleAt(role string, grants *PermissionGrants, height int) bool {
+	return aP.HasRole(role) && !grants.RoleExpired(role, height)
+}
+
 // Returns true if the role is added, and false if it already exists
 func (aP *AccountPermissions) AddRole(role string) bool {
-	role = string(word256.RightPadBytes([]byte(role), 32))
+	role = PadRole(role)
 	for _, r := range aP.Roles {
 		if r == role {
 			return false
@@ -169,7 +266,7 @@ func (aP *AccountPermissions) AddRole(role string) bool {
 
 // Returns true if the role is removed, and false if it is not found
 func (aP *AccountPermissions) RmRole(role string) bool {
-	role = string(word256.RightPadBytes([]byte(role), 32))
+	role = PadRole(role)
 	for i, r := range aP.Roles {
 		if r == role {
 			post := []string{}
@@ -202,76 +299,42 @@ func (accountPermissions *AccountPermissions) Clone() AccountPermissions {
 // string utilities
 
 // PermFlagToString assumes the permFlag is valid, else returns "#-UNKNOWN-#"
-func PermFlagToString(pf PermFlag) (perm string) {
-	switch pf {
-	case Root:
-		perm = "root"
-	case Send:
-		perm = "send"
-	case Call:
-		perm = "call"
-	case CreateContract:
-		perm = "create_contract"
-	case CreateAccount:
-		perm = "create_account"
-	case Bond:
-		perm = "bond"
-	case Name:
-		perm = "name"
-	case HasBase:
-		perm = "hasBase"
-	case SetBase:
-		perm = "setBase"
-	case UnsetBase:
-		perm = "unsetBase"
-	case SetGlobal:
-		perm = "setGlobal"
-	case HasRole:
-		perm = "hasRole"
-	case AddRole:
-		perm = "addRole"
-	case RmRole:
-		perm = "removeRole"
-	default:
-		perm = "#-UNKNOWN-#"
+func PermFlagToString(pf PermFlag) string {
+	for i, names := range permFlagNames {
+		if pf == PermFlag(1)<<uint(i) {
+			return names[0]
+		}
 	}
-	return
+	return "#-UNKNOWN-#"
 }
 
 // PermStringToFlag maps camel- and snake case strings to the
 // the corresponding permission flag.
-func PermStringToFlag(perm string) (pf PermFlag, err error) {
-	switch strings.ToLower(perm) {
-	case "root":
-		pf = Root
-	case "send":
-		pf = Send
-	case "call":
-		pf = Call
-	case "createcontract", "create_contract":
-		pf = CreateContract
-	case "createaccount", "create_account":
-		pf = CreateAccount
-	case "bond":
-		pf = Bond
-	case "name":
-		pf = Name
-	case "hasbase", "has_base":
-		pf = HasBase
-	case "setbase", "set_base":
-		pf = SetBase
-	case "unsetbase", "unset_base":
-		pf = UnsetBase
-	case "setglobal", "set_global":
-		pf = SetGlobal
-	case "hasrole", "has_role":
-		pf = HasRole
-	case "addrole", "add_role":
-		pf = AddRole
-	case "removerole", "rmrole", "rm_role":
-		pf = RmRole
-	default:
-		err = fmt.Errorf("Unknown permission %s", perm)
+func PermStringToFlag(perm string) (PermFlag, error) {
+	for i, names := range permFlagNames {
+		for _, name := range names {
+			if strings.EqualFold(name, perm) {
+				return PermFlag(1) << uint(i), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("Unknown permission %s", perm)
+}
+
+// PermFlagStrings returns the name of every permission flag in order
+func PermFlagStrings() []string {
+	perms := make([]string, NumPermissions)
+	for i, names := range permFlagNames {
+		perms[i] = names[0]
 	}
-	return
+	return perms
+}
+
+// Returns the global permissions with every set bit on, so that the fall back
+// to global permissions always finds a value. Flags in DefaultGlobalPermFlags
+// that were not set are granted.
+func GlobalBasePermissions(perms BasePermissions) BasePermissions {
+	perms.Perms |= DefaultGlobalPermFlags &^ perms.SetBit
+	perms.SetBit = AllPermFlags
+	return perms
 }
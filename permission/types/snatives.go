@@ -37,6 +37,21 @@ const (
 	PermArgsTypeHasRole   = byte(0x05)
 	PermArgsTypeAddRole   = byte(0x06)
 	PermArgsTypeRmRole    = byte(0x07)
+
+	PermArgsTypeSetRolePermission   = byte(0x08)
+	PermArgsTypeUnsetRolePermission = byte(0x09)
+
+	PermArgsTypeAddCallee     = byte(0x0A)
+	PermArgsTypeRmCallee      = byte(0x0B)
+	PermArgsTypeAddCaller     = byte(0x0C)
+	PermArgsTypeRmCaller      = byte(0x0D)
+	PermArgsTypeAddCallerRole = byte(0x0E)
+	PermArgsTypeRmCallerRole  = byte(0x0F)
+
+	PermArgsTypeSetBaseUntil       = byte(0x10)
+	PermArgsTypeAddRoleUntil       = byte(0x11)
+	PermArgsTypeDelegatePermission = byte(0x12)
+	PermArgsTypeRevokeDelegation   = byte(0x13)
 )
 
 // TODO: [ben] this registration needs to be lifted up
@@ -53,6 +68,18 @@ var _ = wire.RegisterInterface(
 	wire.ConcreteType{&HasRoleArgs{}, PermArgsTypeHasRole},
 	wire.ConcreteType{&AddRoleArgs{}, PermArgsTypeAddRole},
 	wire.ConcreteType{&RmRoleArgs{}, PermArgsTypeRmRole},
+	wire.ConcreteType{&SetRolePermissionArgs{}, PermArgsTypeSetRolePermission},
+	wire.ConcreteType{&UnsetRolePermissionArgs{}, PermArgsTypeUnsetRolePermission},
+	wire.ConcreteType{&AddCalleeArgs{}, PermArgsTypeAddCallee},
+	wire.ConcreteType{&RmCalleeArgs{}, PermArgsTypeRmCallee},
+	wire.ConcreteType{&AddCallerArgs{}, PermArgsTypeAddCaller},
+	wire.ConcreteType{&RmCallerArgs{}, PermArgsTypeRmCaller},
+	wire.ConcreteType{&AddCallerRoleArgs{}, PermArgsTypeAddCallerRole},
+	wire.ConcreteType{&RmCallerRoleArgs{}, PermArgsTypeRmCallerRole},
+	wire.ConcreteType{&SetBaseUntilArgs{}, PermArgsTypeSetBaseUntil},
+	wire.ConcreteType{&AddRoleUntilArgs{}, PermArgsTypeAddRoleUntil},
+	wire.ConcreteType{&DelegatePermissionArgs{}, PermArgsTypeDelegatePermission},
+	wire.ConcreteType{&RevokeDelegationArgs{}, PermArgsTypeRevokeDelegation},
 )
 
 type HasBaseArgs struct {
@@ -118,3 +145,125 @@ type RmRoleArgs struct {
 func (*RmRoleArgs) PermFlag() PermFlag {
 	return RmRole
 }
+
+// Defining what a role grants affects every account holding the role, so it
+// requires the same permission as changing the global permissions
+type SetRolePermissionArgs struct {
+	Role       string   `json:"role"`
+	Permission PermFlag `json:"permission"`
+	Value      bool     `json:"value"`
+}
+
+func (*SetRolePermissionArgs) PermFlag() PermFlag {
+	return SetGlobal
+}
+
+type UnsetRolePermissionArgs struct {
+	Role       string   `json:"role"`
+	Permission PermFlag `json:"permission"`
+}
+
+func (*UnsetRolePermissionArgs) PermFlag() PermFlag {
+	return SetGlobal
+}
+
+// Call ACLs narrow what an account may do with its Call permission, so
+// changing them requires the same permission as setting its base permissions
+type AddCalleeArgs struct {
+	Address []byte `json:"address"`
+	Callee  []byte `json:"callee"`
+}
+
+func (*AddCalleeArgs) PermFlag() PermFlag {
+	return SetBase
+}
+
+type RmCalleeArgs struct {
+	Address []byte `json:"address"`
+	Callee  []byte `json:"callee"`
+}
+
+func (*RmCalleeArgs) PermFlag() PermFlag {
+	return SetBase
+}
+
+type AddCallerArgs struct {
+	Address []byte `json:"address"`
+	Caller  []byte `json:"caller"`
+}
+
+func (*AddCallerArgs) PermFlag() PermFlag {
+	return SetBase
+}
+
+type RmCallerArgs struct {
+	Address []byte `json:"address"`
+	Caller  []byte `json:"caller"`
+}
+
+func (*RmCallerArgs) PermFlag() PermFlag {
+	return SetBase
+}
+
+type AddCallerRoleArgs struct {
+	Address []byte `json:"address"`
+	Role    string `json:"role"`
+}
+
+func (*AddCallerRoleArgs) PermFlag() PermFlag {
+	return SetBase
+}
+
+type RmCallerRoleArgs struct {
+	Address []byte `json:"address"`
+	Role    string `json:"role"`
+}
+
+func (*RmCallerRoleArgs) PermFlag() PermFlag {
+	return SetBase
+}
+
+// setBase with an expiry: the permission is treated as unset on the account
+// after ValidUntilHeight
+type SetBaseUntilArgs struct {
+	Address          []byte   `json:"address"`
+	Permission       PermFlag `json:"permission"`
+	Value            bool     `json:"value"`
+	ValidUntilHeight int      `json:"valid_until_height"`
+}
+
+func (*SetBaseUntilArgs) PermFlag() PermFlag {
+	return SetBase
+}
+
+// addRole with an expiry: the role is ignored after ValidUntilHeight
+type AddRoleUntilArgs struct {
+	Address          []byte `json:"address"`
+	Role             string `json:"role"`
+	ValidUntilHeight int    `json:"valid_until_height"`
+}
+
+func (*AddRoleUntilArgs) PermFlag() PermFlag {
+	return AddRole
+}
+
+// Lets Delegate exercise a permission held by the account making the
+// delegation, until ValidUntilHeight if it is not zero
+type DelegatePermissionArgs struct {
+	Delegate         []byte   `json:"delegate"`
+	Permission       PermFlag `json:"permission"`
+	ValidUntilHeight int      `json:"valid_until_height"`
+}
+
+func (*DelegatePermissionArgs) PermFlag() PermFlag {
+	return SetBase
+}
+
+type RevokeDelegationArgs struct {
+	Delegate   []byte   `json:"delegate"`
+	Permission PermFlag `json:"permission"`
+}
+
+func (*RevokeDelegationArgs) PermFlag() PermFlag {
+	return SetBase
+}
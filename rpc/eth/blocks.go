@@ -0,0 +1,196 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"bytes"
+
+	blockchain_types "github.com/hyperledger/burrow/blockchain/types"
+	"github.com/hyperledger/burrow/event"
+	"github.com/hyperledger/burrow/txs"
+
+	tm_types "github.com/tendermint/tendermint/types"
+)
+
+// Looks up the tx with txHash in the tx index of the event journal, which
+// covers the txs whose events it still holds. Returns a nil tx if it is not
+// found, and otherwise the tx's block and journaled events.
+func findTx(blockchain blockchain_types.Blockchain, journal *event.EventJournal,
+	chainId string, txHash []byte) (*tm_types.Block, int, txs.Tx, []*event.JournalEntry, error) {
+	entries, err := journal.TxEntries(txHash)
+	if err != nil || len(entries) == 0 {
+		return nil, 0, nil, nil, err
+	}
+	block := blockchain.Block(entries[0].Height)
+	if block == nil {
+		return nil, 0, nil, nil, nil
+	}
+	blockTxs, err := decodeBlockTxs(block)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+	for i, tx := range blockTxs {
+		if bytes.Equal(txs.TxHash(chainId, tx), txHash) {
+			return block, i, tx, entries, nil
+		}
+	}
+	return nil, 0, nil, nil, nil
+}
+
+// Maps a burrow tx onto an Ethereum transaction. CallTxs and EthTxs map
+// directly, the value of a SendTx is that of its first output, and other txs
+// only have a sender.
+func newTransaction(chainId string, block *tm_types.Block, index int,
+	tx txs.Tx) *Transaction {
+	transaction := &Transaction{
+		Hash:             EncodeHash(txs.TxHash(chainId, tx)),
+		Nonce:            EncodeQuantity(0),
+		BlockHash:        EncodeHash(block.Hash()),
+		BlockNumber:      EncodeQuantity(uint64(block.Height)),
+		TransactionIndex: EncodeQuantity(uint64(index)),
+		Value:            EncodeQuantity(0),
+		Gas:              EncodeQuantity(0),
+		GasPrice:         EncodeQuantity(0),
+		Input:            EncodeData(nil),
+	}
+	var input *txs.TxInput
+	switch tx := tx.(type) {
+	case *txs.CallTx:
+		input = tx.Input
+		if len(tx.Address) > 0 {
+			to := EncodeData(tx.Address)
+			transaction.To = &to
+		}
+		// the fee is paid out of the input amount
+		if tx.Input != nil && tx.Input.Amount > tx.Fee {
+			transaction.Value = EncodeQuantity(uint64(tx.Input.Amount - tx.Fee))
+		}
+		transaction.Gas = EncodeQuantity(uint64(tx.GasLimit))
+		transaction.Input = EncodeData(tx.Data)
+	case *txs.EthTx:
+		if sender, err := tx.Sender(); err == nil {
+			from := EncodeData(sender)
+			transaction.From = &from
+		}
+		if len(tx.To) > 0 {
+			to := EncodeData(tx.To)
+			transaction.To = &to
+		}
+		transaction.Nonce = EncodeQuantity(tx.Nonce)
+		transaction.Value = EncodeQuantity(tx.Value)
+		transaction.Gas = EncodeQuantity(tx.GasLimit)
+		transaction.GasPrice = EncodeQuantity(tx.GasPrice)
+		transaction.Input = EncodeData(tx.Data)
+	case *txs.SendTx:
+		if len(tx.Inputs) > 0 {
+			input = tx.Inputs[0]
+		}
+		if len(tx.Outputs) > 0 {
+			to := EncodeData(tx.Outputs[0].Address)
+			transaction.To = &to
+			transaction.Value = EncodeQuantity(uint64(tx.Outputs[0].Amount))
+		}
+	case *txs.NameTx:
+		input = tx.Input
+	case *txs.NameTransferTx:
+		input = tx.Input
+	case *txs.NameDataTx:
+		input = tx.Input
+	case *txs.PermissionsTx:
+		input = tx.Input
+	case *txs.GovTx:
+		input = tx.Input
+	case *txs.BondTx:
+		if len(tx.Inputs) > 0 {
+			input = tx.Inputs[0]
+		}
+	}
+	if input != nil {
+		from := EncodeData(input.Address)
+		transaction.From = &from
+		transaction.Nonce = EncodeQuantity(uint64(input.Sequence))
+	}
+	return transaction
+}
+
+// Caches the hash and tx hashes of the blocks logs are found in, so that the
+// logs of a request only load each of their blocks once
+type blockCache struct {
+	blockchain blockchain_types.Blockchain
+	chainId    string
+	blocks     map[int]*cachedBlock
+}
+
+type cachedBlock struct {
+	hash []byte
+	// index of each tx in the block by hash
+	txIndexes map[string]int
+}
+
+func newBlockCache(blockchain blockchain_types.Blockchain, chainId string) *blockCache {
+	return &blockCache{
+		blockchain: blockchain,
+		chainId:    chainId,
+		blocks:     make(map[int]*cachedBlock),
+	}
+}
+
+// Returns nil if the block can not be loaded
+func (cache *blockCache) block(height int) *cachedBlock {
+	if cached, ok := cache.blocks[height]; ok {
+		return cached
+	}
+	var cached *cachedBlock
+	if block := cache.blockchain.Block(height); block != nil {
+		if blockTxs, err := decodeBlockTxs(block); err == nil {
+			cached = &cachedBlock{hash: block.Hash(), txIndexes: make(map[string]int)}
+			for i, tx := range blockTxs {
+				cached.txIndexes[string(txs.TxHash(cache.chainId, tx))] = i
+			}
+		}
+	}
+	cache.blocks[height] = cached
+	return cached
+}
+
+// Maps a journaled log onto an Ethereum log
+func (cache *blockCache) newLog(jl *journalLog) *Log {
+	height := jl.height
+	log := &Log{
+		LogIndex:    EncodeQuantity(uint64(jl.index)),
+		BlockNumber: EncodeQuantity(uint64(height)),
+		Address:     EncodeData(jl.log.Address.Postfix(addressLength)),
+		Data:        EncodeData(jl.log.Data),
+		Topics:      make([]string, len(jl.log.Topics)),
+	}
+	for i, topic := range jl.log.Topics {
+		log.Topics[i] = EncodeData(topic.Bytes())
+	}
+	if block := cache.block(height); block != nil {
+		blockHash := EncodeHash(block.hash)
+		log.BlockHash = &blockHash
+		if jl.txHash != nil {
+			if index, ok := block.txIndexes[string(jl.txHash)]; ok {
+				txIndex := EncodeQuantity(uint64(index))
+				log.TransactionIndex = &txIndex
+			}
+		}
+	}
+	if jl.txHash != nil {
+		txHash := EncodeHash(jl.txHash)
+		log.TransactionHash = &txHash
+	}
+	return log
+}
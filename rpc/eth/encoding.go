@@ -0,0 +1,145 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/burrow/word256"
+)
+
+// Ethereum hashes are 32 bytes where burrow's tx and block hashes are 20, so
+// burrow hashes are left padded with zeros
+const (
+	hashLength    = 32
+	addressLength = 20
+)
+
+// Encodes a number as an Ethereum quantity: 0x prefixed hex with no leading
+// zeros
+func EncodeQuantity(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+// Encodes bytes as Ethereum data: 0x prefixed hex, two digits per byte
+func EncodeData(bs []byte) string {
+	return "0x" + hex.EncodeToString(bs)
+}
+
+// Encodes a burrow hash as an Ethereum 32 byte hash
+func EncodeHash(hash []byte) string {
+	return EncodeData(word256.LeftPadBytes(hash, hashLength))
+}
+
+func DecodeQuantity(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") || len(s) == 2 {
+		return 0, fmt.Errorf("Quantity '%s' is not 0x prefixed hex", s)
+	}
+	n, err := strconv.ParseUint(s[2:], 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Quantity '%s' is not 0x prefixed hex: %v", s, err)
+	}
+	return n, nil
+}
+
+func DecodeData(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return nil, fmt.Errorf("Data '%s' is not 0x prefixed hex", s)
+	}
+	bs, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return nil, fmt.Errorf("Data '%s' is not 0x prefixed hex: %v", s, err)
+	}
+	return bs, nil
+}
+
+func DecodeAddress(s string) ([]byte, error) {
+	address, err := DecodeData(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(address) != addressLength {
+		return nil, fmt.Errorf("Address '%s' is not %v bytes long", s, addressLength)
+	}
+	return address, nil
+}
+
+// Decodes a hash given either as burrow's 20 bytes or left padded to 32
+func DecodeHash(s string) ([]byte, error) {
+	hash, err := DecodeData(s)
+	if err != nil {
+		return nil, err
+	}
+	padding := hashLength - addressLength
+	if len(hash) == hashLength && bytes.Equal(hash[:padding], make([]byte, padding)) {
+		hash = hash[padding:]
+	}
+	if len(hash) != addressLength {
+		return nil, fmt.Errorf("Hash '%s' is not a burrow hash", s)
+	}
+	return hash, nil
+}
+
+// Decodes the hash of a tx, which is either a burrow hash or the 32 byte
+// keccak256 hash of an EthTx
+func DecodeTxHash(s string) ([]byte, error) {
+	hash, err := DecodeHash(s)
+	if err != nil {
+		if ethHash, dataErr := DecodeData(s); dataErr == nil && len(ethHash) == hashLength {
+			return ethHash, nil
+		}
+	}
+	return hash, err
+}
+
+// Decodes a storage position, which may be given as a quantity or as data of
+// at most 32 bytes, into a 32 byte key
+func decodeStorageKey(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") || len(s) == 2 {
+		return nil, fmt.Errorf("Storage position '%s' is not 0x prefixed hex", s)
+	}
+	digits := s[2:]
+	if len(digits)%2 == 1 {
+		digits = "0" + digits
+	}
+	key, err := hex.DecodeString(digits)
+	if err != nil || len(key) > hashLength {
+		return nil, fmt.Errorf("Storage position '%s' is not at most %v bytes of hex",
+			s, hashLength)
+	}
+	return word256.LeftPadBytes(key, hashLength), nil
+}
+
+// Resolves a block number parameter, which is a quantity or one of the tags
+// latest, pending or earliest. Burrow has no pending block so pending is the
+// latest block, and the earliest block is the first one as the genesis state
+// has no block.
+func decodeBlockNumber(s string, latestHeight int) (int, error) {
+	switch s {
+	case "", "latest", "pending":
+		return latestHeight, nil
+	case "earliest":
+		return 1, nil
+	}
+	n, err := DecodeQuantity(s)
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantities(t *testing.T) {
+	assert.Equal(t, "0x0", EncodeQuantity(0))
+	assert.Equal(t, "0x41", EncodeQuantity(65))
+	assert.Equal(t, "0x400", EncodeQuantity(1024))
+
+	n, err := DecodeQuantity("0x400")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1024), n)
+	for _, s := range []string{"", "0x", "400", "0xZZ"} {
+		_, err = DecodeQuantity(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestData(t *testing.T) {
+	assert.Equal(t, "0x", EncodeData(nil))
+	assert.Equal(t, "0x0f0f", EncodeData([]byte{15, 15}))
+
+	bs, err := DecodeData("0x0F0f")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{15, 15}, bs)
+	for _, s := range []string{"0f0f", "0xf0f", "0xzz"} {
+		_, err = DecodeData(s)
+		assert.Error(t, err, s)
+	}
+
+	_, err = DecodeAddress("0x0f0f")
+	assert.Error(t, err)
+}
+
+func TestHashes(t *testing.T) {
+	hash := make([]byte, addressLength)
+	hash[0] = 0xAB
+	encoded := EncodeHash(hash)
+	assert.Equal(t, "0x000000000000000000000000ab00000000000000000000000000000000000000",
+		encoded)
+
+	decoded, err := DecodeHash(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, decoded)
+	decoded, err = DecodeHash(EncodeData(hash))
+	assert.NoError(t, err)
+	assert.Equal(t, hash, decoded)
+
+	// a 32 byte hash that is not a padded burrow hash
+	_, err = DecodeHash("0x" + "ff" + encoded[4:])
+	assert.Error(t, err)
+
+	// which may be the hash of an EthTx
+	decoded, err = DecodeTxHash("0x" + "ff" + encoded[4:])
+	assert.NoError(t, err)
+	assert.Len(t, decoded, hashLength)
+	decoded, err = DecodeTxHash(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, decoded)
+}
+
+func TestStorageKeysAndBlockNumbers(t *testing.T) {
+	key, err := decodeStorageKey("0x1")
+	assert.NoError(t, err)
+	assert.Len(t, key, hashLength)
+	assert.Equal(t, byte(1), key[hashLength-1])
+
+	for s, expected := range map[string]int{"latest": 9, "pending": 9, "": 9,
+		"earliest": 1, "0x5": 5} {
+		height, err := decodeBlockNumber(s, 9)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, height, s)
+	}
+	_, err = decodeBlockNumber("first", 9)
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"encoding/json"
+	"net/http"
+
+	definitions "github.com/hyperledger/burrow/definitions"
+	rpc "github.com/hyperledger/burrow/rpc"
+	server "github.com/hyperledger/burrow/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server used to handle Ethereum JSON-RPC requests. Implements server.Server
+type EthJsonRpcServer struct {
+	service server.HttpService
+	running bool
+}
+
+// Create a new EthJsonRpcServer
+func NewEthJsonRpcServer(service server.HttpService) *EthJsonRpcServer {
+	return &EthJsonRpcServer{service: service}
+}
+
+// Start adds the Ethereum rpc path to the router, unless it is not configured.
+func (this *EthJsonRpcServer) Start(config *server.ServerConfig,
+	router *gin.Engine) {
+	if config.HTTP.EthJsonRpcEndpoint == "" {
+		return
+	}
+	router.POST(config.HTTP.EthJsonRpcEndpoint, this.handleFunc)
+	this.running = true
+}
+
+// Is the server currently running?
+func (this *EthJsonRpcServer) Running() bool {
+	return this.running
+}
+
+// Shut the server down. Does nothing.
+func (this *EthJsonRpcServer) ShutDown() {
+	this.running = false
+}
+
+func (this *EthJsonRpcServer) handleFunc(c *gin.Context) {
+	this.service.Process(c.Request, c.Writer)
+}
+
+// Serves the eth_* namespace. Implements server.HttpService. Responses are
+// encoded with encoding/json as Ethereum clients expect, rather than with
+// the go-wire codec of rpc/v0.
+type EthJsonService struct {
+	handlers map[string]RequestHandlerFunc
+	auth     *server.Auth
+	limiter  *server.RateLimiter
+}
+
+// Create a new Ethereum JSON-RPC service over the pipe. Requests are
+// authorized by auth and rate limited by limiter, either of which may be nil.
+func NewEthJsonService(pipe definitions.Pipe, auth *server.Auth,
+	limiter *server.RateLimiter) server.HttpService {
+	return &EthJsonService{handlers: NewEthMethods(pipe).getMethods(), auth: auth,
+		limiter: limiter}
+}
+
+// Process a request.
+func (this *EthJsonService) Process(r *http.Request, w http.ResponseWriter) {
+	req := &EthRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		this.writeError("Failed to parse request: "+err.Error(), nil,
+			rpc.PARSE_ERROR, w)
+		return
+	}
+
+	if req.JSONRPC != "2.0" {
+		this.writeError("Wrong protocol version: "+req.JSONRPC, req.Id,
+			rpc.INVALID_REQUEST, w)
+		return
+	}
+
+	handler, ok := this.handlers[req.Method]
+	if !ok {
+		this.writeError("Method not found: "+req.Method, req.Id,
+			rpc.METHOD_NOT_FOUND, w)
+		return
+	}
+	if err := this.authorize(r, req.Method); err != nil {
+		this.writeError(err.Error(), req.Id, rpc.UNAUTHORIZED, w)
+		return
+	}
+	release, err := this.limiter.Limit(this.auth.ClientId(r), req.Method,
+		methodClasses)
+	if err != nil {
+		this.writeError(err.Error(), req.Id, rpc.RATE_LIMITED, w)
+		return
+	}
+	result, errCode, err := handler(req)
+	release()
+	if err != nil {
+		this.writeError(err.Error(), req.Id, errCode, w)
+		return
+	}
+	this.write(&EthResultResponse{JSONRPC: "2.0", Id: nullId(req.Id), Result: result}, w)
+}
+
+// Authenticates the caller and checks that they may call method.
+func (this *EthJsonService) authorize(r *http.Request, method string) error {
+	role, err := this.auth.Authenticate(r)
+	if err != nil {
+		return err
+	}
+	return this.auth.Authorize(role, method, methodRoles)
+}
+
+// Helper for writing error responses.
+func (this *EthJsonService) writeError(msg string, id json.RawMessage, code int,
+	w http.ResponseWriter) {
+	this.write(&EthErrorResponse{
+		JSONRPC: "2.0",
+		Id:      nullId(id),
+		Error:   &rpc.RPCError{Code: code, Message: msg},
+	}, w)
+}
+
+func (this *EthJsonService) write(response interface{}, w http.ResponseWriter) {
+	bs, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, "Failed to marshal response: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(bs)
+}
+
+// The id of a response to a request without one is null
+func nullId(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
@@ -0,0 +1,368 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	account "github.com/hyperledger/burrow/account"
+	blockchain_types "github.com/hyperledger/burrow/blockchain/types"
+	core_types "github.com/hyperledger/burrow/core/types"
+	definitions "github.com/hyperledger/burrow/definitions"
+	"github.com/hyperledger/burrow/event"
+	rpc "github.com/hyperledger/burrow/rpc"
+	server "github.com/hyperledger/burrow/server"
+	"github.com/hyperledger/burrow/txs"
+	"github.com/hyperledger/burrow/word256"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+	dbm "github.com/tendermint/go-db"
+	tm_types "github.com/tendermint/tendermint/types"
+)
+
+// Only the parts of the pipe used by the eth methods are implemented, the
+// embedded interfaces are nil
+type mockPipe struct {
+	definitions.Pipe
+	accounts    *mockAccounts
+	blockchain  *mockBlockchain
+	journal     *event.EventJournal
+	genesisHash []byte
+}
+
+func (pipe *mockPipe) Accounts() definitions.Accounts          { return pipe.accounts }
+func (pipe *mockPipe) Blockchain() blockchain_types.Blockchain { return pipe.blockchain }
+func (pipe *mockPipe) EventJournal() *event.EventJournal       { return pipe.journal }
+func (pipe *mockPipe) GenesisHash() []byte                     { return pipe.genesisHash }
+
+type mockAccounts struct {
+	definitions.Accounts
+	accounts map[string]*account.Account
+	storage  map[string][]byte
+}
+
+func (accounts *mockAccounts) Account(address []byte) (*account.Account, error) {
+	return accounts.accounts[string(address)], nil
+}
+
+func (accounts *mockAccounts) StorageAt(address, key []byte) (*core_types.StorageItem,
+	error) {
+	return &core_types.StorageItem{key, accounts.storage[string(address)+string(key)]}, nil
+}
+
+type mockBlockchain struct {
+	chainId string
+	blocks  []*tm_types.Block
+}
+
+func (blockchain *mockBlockchain) Height() int                              { return len(blockchain.blocks) }
+func (blockchain *mockBlockchain) BlockMeta(height int) *tm_types.BlockMeta { return nil }
+func (blockchain *mockBlockchain) ChainId() string                          { return blockchain.chainId }
+
+func (blockchain *mockBlockchain) Block(height int) *tm_types.Block {
+	if height < 1 || height > len(blockchain.blocks) {
+		return nil
+	}
+	return blockchain.blocks[height-1]
+}
+
+func newBlock(chainId string, height int, blockTxs ...txs.Tx) *tm_types.Block {
+	data := &tm_types.Data{}
+	for _, tx := range blockTxs {
+		txBytes, err := txs.EncodeTx(tx)
+		if err != nil {
+			panic(err)
+		}
+		data.Txs = append(data.Txs, txBytes)
+	}
+	return &tm_types.Block{
+		Header: &tm_types.Header{
+			ChainID: chainId,
+			Height:  height,
+			Time:    time.Unix(int64(1000+height), 0),
+			NumTxs:  len(blockTxs),
+		},
+		Data:       data,
+		LastCommit: &tm_types.Commit{},
+	}
+}
+
+var (
+	testChainId  = "eth_test_chain"
+	callerAddr   = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	contractAddr = []byte{20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	testTopic    = word256.RightPadWord256([]byte("Transfer"))
+	testCallTx   = &txs.CallTx{
+		Input:    &txs.TxInput{Address: callerAddr, Amount: 101, Sequence: 3},
+		Address:  contractAddr,
+		GasLimit: 1000,
+		Fee:      1,
+		Data:     []byte{0xCA, 0xFE},
+	}
+)
+
+func newTestService(t *testing.T) server.HttpService {
+	journal, err := event.NewEventJournal(dbm.NewMemDB(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the log is fired while executing the tx in block 2, when the last
+	// block is 1
+	txHash := txs.TxHash(testChainId, testCallTx)
+	journal.Append(2, txHash, txs.EventStringLogEvent(contractAddr), txs.EventDataLog{
+		Address: word256.LeftPadWord256(contractAddr),
+		Topics:  []word256.Word256{testTopic},
+		Data:    []byte{1},
+		Height:  1,
+		TxID:    txHash,
+	})
+	journal.Append(2, txHash, txs.EventStringAccInput(callerAddr),
+		txs.EventDataTx{Tx: testCallTx})
+	return NewEthJsonService(&mockPipe{
+		accounts: &mockAccounts{
+			accounts: map[string]*account.Account{
+				string(contractAddr): {Address: contractAddr, Balance: 1000,
+					Code: []byte{0x60, 0x00}},
+			},
+			storage: map[string][]byte{
+				string(contractAddr) + string(word256.LeftPadBytes([]byte{1}, 32)): {0xFF},
+			},
+		},
+		blockchain: &mockBlockchain{
+			chainId: testChainId,
+			blocks: []*tm_types.Block{
+				newBlock(testChainId, 1),
+				newBlock(testChainId, 2, testCallTx),
+			},
+		},
+		journal:     journal,
+		genesisHash: []byte{0, 0, 1, 0, 7},
+	}, nil, nil)
+}
+
+type testResponse struct {
+	Id     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpc.RPCError   `json:"error"`
+}
+
+func doRequest(t *testing.T, service server.HttpService, method,
+	params string) *testResponse {
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":7,"method":"%s","params":%s}`,
+		method, params)
+	request, err := http.NewRequest("POST", "/eth", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	service.Process(request, recorder)
+	response := &testResponse{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "7", string(response.Id))
+	return response
+}
+
+func resultString(t *testing.T, response *testResponse) string {
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %v", response.Error.Message)
+	}
+	var s string
+	if err := json.Unmarshal(response.Result, &s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestEthStateMethods(t *testing.T) {
+	service := newTestService(t)
+	contract := fmt.Sprintf(`"%s"`, EncodeData(contractAddr))
+
+	assert.Equal(t, "0x2", resultString(t, doRequest(t, service, ETH_BLOCK_NUMBER, `[]`)))
+	assert.Equal(t, "0x3e8", resultString(t, doRequest(t, service, ETH_GET_BALANCE,
+		`[`+contract+`, "latest"]`)))
+	assert.Equal(t, "0x0", resultString(t, doRequest(t, service, ETH_GET_BALANCE,
+		fmt.Sprintf(`["%s"]`, EncodeData(callerAddr)))))
+	assert.Equal(t, "0x6000", resultString(t, doRequest(t, service, ETH_GET_CODE,
+		`[`+contract+`, "0x2"]`)))
+	assert.Equal(t, EncodeData(word256.LeftPadBytes([]byte{0xFF}, 32)),
+		resultString(t, doRequest(t, service, ETH_GET_STORAGE_AT,
+			`[`+contract+`, "0x1", "latest"]`)))
+	// genesis hash 0x00000100
+	assert.Equal(t, "256", resultString(t, doRequest(t, service, NET_VERSION, `[]`)))
+
+	// only the latest state is kept
+	response := doRequest(t, service, ETH_GET_BALANCE, `[`+contract+`, "0x1"]`)
+	if assert.NotNil(t, response.Error) {
+		assert.Equal(t, rpc.INVALID_PARAMS, response.Error.Code)
+	}
+	response = doRequest(t, service, "eth_mining", `[]`)
+	if assert.NotNil(t, response.Error) {
+		assert.Equal(t, rpc.METHOD_NOT_FOUND, response.Error.Code)
+	}
+}
+
+func TestEthBlocksAndReceipts(t *testing.T) {
+	service := newTestService(t)
+	txHash := EncodeHash(txs.TxHash(testChainId, testCallTx))
+
+	block := &Block{}
+	response := doRequest(t, service, ETH_GET_BLOCK_BY_NUMBER, `["latest", false]`)
+	if err := json.Unmarshal(response.Result, block); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "0x2", block.Number)
+	assert.Equal(t, "0x3ea", block.Timestamp)
+	assert.Equal(t, []interface{}{txHash}, block.Transactions)
+	response = doRequest(t, service, ETH_GET_BLOCK_BY_NUMBER, `["0x3", false]`)
+	assert.Equal(t, "null", string(response.Result))
+
+	receipt := &Receipt{}
+	response = doRequest(t, service, ETH_GET_TRANSACTION_RECEIPT, `["`+txHash+`"]`)
+	if err := json.Unmarshal(response.Result, receipt); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "0x2", receipt.BlockNumber)
+	assert.Equal(t, "0x0", receipt.TransactionIndex)
+	assert.Equal(t, "0x1", receipt.Status)
+	assert.Equal(t, EncodeData(callerAddr), *receipt.From)
+	assert.Equal(t, EncodeData(contractAddr), *receipt.To)
+	assert.Nil(t, receipt.ContractAddress)
+	if assert.Len(t, receipt.Logs, 1) {
+		assert.Equal(t, txHash, *receipt.Logs[0].TransactionHash)
+		assert.Equal(t, "0x2", receipt.Logs[0].BlockNumber)
+	}
+
+	response = doRequest(t, service, ETH_GET_TRANSACTION_RECEIPT,
+		fmt.Sprintf(`["%s"]`, EncodeHash(callerAddr)))
+	assert.Equal(t, "null", string(response.Result))
+}
+
+func TestEthGetLogs(t *testing.T) {
+	service := newTestService(t)
+	topic := EncodeData(testTopic.Bytes())
+	filters := map[string]int{
+		`{}`: 1,
+		`{"address": "` + EncodeData(contractAddr) + `"}`:                   1,
+		`{"address": ["` + EncodeData(callerAddr) + `"]}`:                   0,
+		`{"topics": ["` + topic + `"]}`:                                     1,
+		`{"topics": [null, "` + topic + `"]}`:                               0,
+		`{"topics": [["` + EncodeHash(callerAddr) + `", "` + topic + `"]]}`: 1,
+		`{"fromBlock": "0x1", "toBlock": "0x1"}`:                            0,
+		`{"fromBlock": "earliest", "toBlock": "latest"}`:                    1,
+	}
+	for filter, expected := range filters {
+		response := doRequest(t, service, ETH_GET_LOGS, `[`+filter+`]`)
+		if !assert.Nil(t, response.Error, filter) {
+			continue
+		}
+		logs := []*Log{}
+		if err := json.Unmarshal(response.Result, &logs); err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, logs, expected, filter)
+	}
+
+	response := doRequest(t, service, ETH_GET_LOGS, `[{"topics": ["0x01"]}]`)
+	if assert.NotNil(t, response.Error) {
+		assert.Equal(t, rpc.INVALID_PARAMS, response.Error.Code)
+	}
+}
+
+func TestEthTxs(t *testing.T) {
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x46}, 32))
+	ethTx := &txs.EthTx{Nonce: 4, GasLimit: 1000, Value: 10, Data: []byte{0x60, 0x00}}
+	if err := ethTx.Sign(256, key); err != nil {
+		t.Fatal(err)
+	}
+	sender := txs.EthAddress(key.PubKey())
+
+	// raw txs are either RLP or go-wire
+	tx, err := decodeRawTx(ethTx.Encode())
+	if assert.NoError(t, err) {
+		assert.Equal(t, ethTx.Hash(), txs.TxHash(testChainId, tx))
+	}
+	callTxBytes, err := txs.EncodeTx(testCallTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err = decodeRawTx(callTxBytes)
+	if assert.NoError(t, err) {
+		assert.Equal(t, txs.TxHash(testChainId, testCallTx), txs.TxHash(testChainId, tx))
+	}
+
+	// receipts are found through the journal
+	journal, err := event.NewEventJournal(dbm.NewMemDB(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal.Append(1, ethTx.Hash(), txs.EventStringAccInput(sender),
+		txs.EventDataTx{Tx: ethTx})
+	service := NewEthJsonService(&mockPipe{
+		blockchain: &mockBlockchain{
+			chainId: testChainId,
+			blocks:  []*tm_types.Block{newBlock(testChainId, 1, ethTx)},
+		},
+		journal: journal,
+	}, nil, nil)
+	receipt := &Receipt{}
+	response := doRequest(t, service, ETH_GET_TRANSACTION_RECEIPT,
+		`["`+EncodeData(ethTx.Hash())+`"]`)
+	if err := json.Unmarshal(response.Result, receipt); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, EncodeData(sender), *receipt.From)
+	assert.Nil(t, receipt.To)
+	assert.Equal(t, EncodeData(txs.NewContractAddress(sender, 5)),
+		*receipt.ContractAddress)
+}
+
+func TestReadLogs(t *testing.T) {
+	journal, err := event.NewEventJournal(dbm.NewMemDB(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, height := range []int{1, 2, 2, 3} {
+		journal.Append(height, nil, txs.EventStringLogEvent(contractAddr),
+			txs.EventDataLog{Height: int64(height - 1)})
+		journal.Append(height, nil, txs.EventStringAccInput(callerAddr),
+			txs.EventDataTx{Tx: testCallTx})
+	}
+
+	logs, err := readLogs(journal, &logMatcher{fromHeight: 2, toHeight: 2}, maxLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, logs, 2) {
+		// logs are numbered within their block
+		assert.Equal(t, 2, logs[1].height)
+		assert.Equal(t, 1, logs[1].index)
+	}
+
+	// no more than one log past max is read
+	logs, err = readLogs(journal, &logMatcher{fromHeight: 1, toHeight: 3}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, logs, 2)
+}
@@ -0,0 +1,200 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/burrow/event"
+	"github.com/hyperledger/burrow/txs"
+	. "github.com/hyperledger/burrow/word256"
+)
+
+// Burrow keeps no receipts, so logs and the outcome of txs are read back from
+// the event journal.
+
+// A log read from the journal
+type journalLog struct {
+	log txs.EventDataLog
+	// height of the block whose tx emitted the log
+	height int
+	// position of the log among the journaled logs of its block
+	index int
+	// hash of the tx that emitted the log
+	txHash []byte
+}
+
+// Matches the journaled logs
+type logFilter struct{}
+
+func (logFilter) Match(v interface{}) bool {
+	entry, ok := v.(*event.JournalEntry)
+	if !ok {
+		return false
+	}
+	_, ok = entry.Data.(txs.EventDataLog)
+	return ok
+}
+
+// Reads the journaled logs of the blocks in the height range of matcher,
+// returning those it matches but no more than max+1 of them, so that callers
+// can tell when there are more than max. Only the events of the blocks in
+// the range are read.
+func readLogs(journal *event.EventJournal, matcher *logMatcher,
+	max int) ([]*journalLog, error) {
+	sequence, err := journal.SequenceAtHeight(matcher.fromHeight)
+	if err != nil {
+		return nil, err
+	}
+	end, err := journal.SequenceAtHeight(matcher.toHeight + 1)
+	if err != nil {
+		return nil, err
+	}
+	matched := []*journalLog{}
+	height, index := 0, 0
+	for sequence < end {
+		entries, next, err := journal.EntriesMatching(logFilter{}, sequence, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Sequence >= end {
+				return matched, nil
+			}
+			if entry.Height != height {
+				height, index = entry.Height, 0
+			}
+			jl := &journalLog{
+				log:    entry.Data.(txs.EventDataLog),
+				height: entry.Height,
+				index:  index,
+				txHash: entry.TxHash,
+			}
+			index++
+			if matcher.match(jl) {
+				matched = append(matched, jl)
+				if len(matched) > max {
+					return matched, nil
+				}
+			}
+		}
+		sequence = next
+	}
+	return matched, nil
+}
+
+// Criteria of eth_getLogs. Empty address or topic lists match anything, a
+// nil tx hash logs of any tx.
+type logMatcher struct {
+	fromHeight int
+	toHeight   int
+	txHash     []byte
+	addresses  []Word256
+	// alternatives for each topic position
+	topics [][]Word256
+}
+
+func (matcher *logMatcher) match(jl *journalLog) bool {
+	if jl.height < matcher.fromHeight || jl.height > matcher.toHeight {
+		return false
+	}
+	if matcher.txHash != nil && !bytes.Equal(jl.txHash, matcher.txHash) {
+		return false
+	}
+	if len(matcher.addresses) > 0 && !containsWord(matcher.addresses, jl.log.Address) {
+		return false
+	}
+	for i, alternatives := range matcher.topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		if i >= len(jl.log.Topics) || !containsWord(alternatives, jl.log.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsWord(words []Word256, word Word256) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// Builds the matcher for a filter given the latest block height
+func newLogMatcher(args *LogFilterArgs, latestHeight int) (*logMatcher, error) {
+	fromHeight, err := decodeBlockNumber(args.FromBlock, latestHeight)
+	if err != nil {
+		return nil, err
+	}
+	toHeight, err := decodeBlockNumber(args.ToBlock, latestHeight)
+	if err != nil {
+		return nil, err
+	}
+	matcher := &logMatcher{fromHeight: fromHeight, toHeight: toHeight}
+
+	addresses, err := decodeOneOrMany(args.Address)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid address filter: %v", err)
+	}
+	for _, a := range addresses {
+		address, err := DecodeAddress(a)
+		if err != nil {
+			return nil, err
+		}
+		matcher.addresses = append(matcher.addresses, LeftPadWord256(address))
+	}
+
+	for _, rawTopic := range args.Topics {
+		topics, err := decodeOneOrMany(rawTopic)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid topic filter: %v", err)
+		}
+		alternatives := []Word256{}
+		for _, t := range topics {
+			topic, err := DecodeData(t)
+			if err != nil {
+				return nil, err
+			}
+			if len(topic) != hashLength {
+				return nil, fmt.Errorf("Topic '%s' is not %v bytes long", t, hashLength)
+			}
+			alternatives = append(alternatives, LeftPadWord256(topic))
+		}
+		matcher.topics = append(matcher.topics, alternatives)
+	}
+	return matcher, nil
+}
+
+// Decodes null, a string or a list of strings
+func decodeOneOrMany(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var one string
+	if err := json.Unmarshal(raw, &one); err == nil {
+		return []string{one}, nil
+	}
+	var many []string
+	if err := json.Unmarshal(raw, &many); err != nil {
+		return nil, err
+	}
+	return many, nil
+}
@@ -0,0 +1,485 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	account "github.com/hyperledger/burrow/account"
+	core_types "github.com/hyperledger/burrow/core/types"
+	definitions "github.com/hyperledger/burrow/definitions"
+	rpc "github.com/hyperledger/burrow/rpc"
+	server "github.com/hyperledger/burrow/server"
+	"github.com/hyperledger/burrow/txs"
+	"github.com/hyperledger/burrow/word256"
+
+	tm_types "github.com/tendermint/tendermint/types"
+)
+
+const (
+	ETH_CALL                      = "eth_call"
+	ETH_SEND_RAW_TRANSACTION      = "eth_sendRawTransaction"
+	ETH_GET_BALANCE               = "eth_getBalance"
+	ETH_GET_CODE                  = "eth_getCode"
+	ETH_GET_STORAGE_AT            = "eth_getStorageAt"
+	ETH_BLOCK_NUMBER              = "eth_blockNumber"
+	ETH_GET_BLOCK_BY_NUMBER       = "eth_getBlockByNumber"
+	ETH_GET_TRANSACTION_RECEIPT   = "eth_getTransactionReceipt"
+	ETH_GET_LOGS                  = "eth_getLogs"
+	NET_VERSION                   = "net_version"
+	maxLogs                       = 10000
+	emptyLogsBloomLength          = 256
+	emptyNonceLength              = 8
+	errorStateNotAtLatestBlockFmt = "Only the state at the latest block (%v) is available, not at block %v"
+	errorTooManyLogsFmt           = "The query matches more than %v logs, narrow its block range or filter"
+	errorNoEventJournal           = "Receipts and logs are not available as events are not journaled"
+)
+
+var (
+	emptyLogsBloom = EncodeData(make([]byte, emptyLogsBloomLength))
+	emptyNonce     = EncodeData(make([]byte, emptyNonceLength))
+	zeroHash       = EncodeData(make([]byte, hashLength))
+	zeroAddress    = EncodeData(make([]byte, addressLength))
+)
+
+// The least role needed to call each method when authentication is enabled
+var methodRoles = server.MethodRoles{
+	ETH_CALL:                    server.RoleReadOnly,
+	ETH_SEND_RAW_TRANSACTION:    server.RoleBroadcastOnly,
+	ETH_GET_BALANCE:             server.RoleReadOnly,
+	ETH_GET_CODE:                server.RoleReadOnly,
+	ETH_GET_STORAGE_AT:          server.RoleReadOnly,
+	ETH_BLOCK_NUMBER:            server.RoleReadOnly,
+	ETH_GET_BLOCK_BY_NUMBER:     server.RoleReadOnly,
+	ETH_GET_TRANSACTION_RECEIPT: server.RoleReadOnly,
+	ETH_GET_LOGS:                server.RoleReadOnly,
+	NET_VERSION:                 server.RoleReadOnly,
+}
+
+// The class of each method when rate limiting is enabled. Methods that are not
+// listed are server.ClassRead.
+var methodClasses = server.MethodClasses{
+	ETH_CALL:                 server.ClassSimulate,
+	ETH_SEND_RAW_TRANSACTION: server.ClassTransact,
+	ETH_GET_LOGS:             server.ClassScan,
+}
+
+type RequestHandlerFunc func(*EthRequest) (interface{}, int, error)
+
+// The eth_* and net_* method handlers, mapping burrow's accounts, blocks and
+// events onto the Ethereum JSON-RPC API.
+type EthMethods struct {
+	pipe definitions.Pipe
+}
+
+func NewEthMethods(pipe definitions.Pipe) *EthMethods {
+	return &EthMethods{pipe: pipe}
+}
+
+func (this *EthMethods) getMethods() map[string]RequestHandlerFunc {
+	dhMap := make(map[string]RequestHandlerFunc)
+	dhMap[ETH_CALL] = this.Call
+	dhMap[ETH_SEND_RAW_TRANSACTION] = this.SendRawTransaction
+	dhMap[ETH_GET_BALANCE] = this.GetBalance
+	dhMap[ETH_GET_CODE] = this.GetCode
+	dhMap[ETH_GET_STORAGE_AT] = this.GetStorageAt
+	dhMap[ETH_BLOCK_NUMBER] = this.BlockNumber
+	dhMap[ETH_GET_BLOCK_BY_NUMBER] = this.GetBlockByNumber
+	dhMap[ETH_GET_TRANSACTION_RECEIPT] = this.GetTransactionReceipt
+	dhMap[ETH_GET_LOGS] = this.GetLogs
+	dhMap[NET_VERSION] = this.NetVersion
+	return dhMap
+}
+
+// *************************************** State ************************************
+
+func (this *EthMethods) Call(request *EthRequest) (interface{}, int, error) {
+	args := &CallArgs{}
+	var blockNumber string
+	if err := decodeParams(request.Params, 1, args, &blockNumber); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	if err := this.checkLatestState(blockNumber); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	var from, data []byte
+	var err error
+	if args.From != "" {
+		if from, err = DecodeAddress(args.From); err != nil {
+			return nil, rpc.INVALID_PARAMS, err
+		}
+	}
+	if args.Data != "" {
+		if data, err = DecodeData(args.Data); err != nil {
+			return nil, rpc.INVALID_PARAMS, err
+		}
+	}
+	transactor := this.pipe.Transactor()
+	var call *core_types.Call
+	if args.To == "" {
+		// without a callee the data is run as code, as it would be on creation
+		call, err = transactor.CallCode(from, data, nil)
+	} else {
+		var to []byte
+		if to, err = DecodeAddress(args.To); err != nil {
+			return nil, rpc.INVALID_PARAMS, err
+		}
+		call, err = transactor.Call(from, to, data)
+	}
+	if err != nil {
+		return nil, rpc.INTERNAL_ERROR, err
+	}
+	// the return of a call is already hex encoded
+	return "0x" + strings.ToLower(call.Return), 0, nil
+}
+
+func (this *EthMethods) SendRawTransaction(request *EthRequest) (interface{}, int, error) {
+	var rawTx string
+	if err := decodeParams(request.Params, 1, &rawTx); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	txBytes, err := DecodeData(rawTx)
+	if err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	tx, err := decodeRawTx(txBytes)
+	if err != nil {
+		return nil, rpc.INVALID_PARAMS, fmt.Errorf("Could not decode tx: %v", err)
+	}
+	receipt, err := this.pipe.Transactor().BroadcastTx(tx)
+	if err != nil {
+		return nil, rpc.INTERNAL_ERROR, err
+	}
+	return EncodeHash(receipt.TxHash), 0, nil
+}
+
+func (this *EthMethods) GetBalance(request *EthRequest) (interface{}, int, error) {
+	var address, blockNumber string
+	if err := decodeParams(request.Params, 1, &address, &blockNumber); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	acc, errCode, err := this.account(address, blockNumber)
+	if err != nil {
+		return nil, errCode, err
+	}
+	return EncodeQuantity(uint64(acc.Balance)), 0, nil
+}
+
+func (this *EthMethods) GetCode(request *EthRequest) (interface{}, int, error) {
+	var address, blockNumber string
+	if err := decodeParams(request.Params, 1, &address, &blockNumber); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	acc, errCode, err := this.account(address, blockNumber)
+	if err != nil {
+		return nil, errCode, err
+	}
+	return EncodeData(acc.Code), 0, nil
+}
+
+func (this *EthMethods) GetStorageAt(request *EthRequest) (interface{}, int, error) {
+	var address, position, blockNumber string
+	if err := decodeParams(request.Params, 2, &address, &position,
+		&blockNumber); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	addr, err := DecodeAddress(address)
+	if err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	key, err := decodeStorageKey(position)
+	if err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	if err := this.checkLatestState(blockNumber); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	item, err := this.pipe.Accounts().StorageAt(addr, key)
+	if err != nil {
+		return nil, rpc.INTERNAL_ERROR, err
+	}
+	return EncodeData(word256.LeftPadBytes(item.Value, hashLength)), 0, nil
+}
+
+// *************************************** Blocks ************************************
+
+func (this *EthMethods) BlockNumber(request *EthRequest) (interface{}, int, error) {
+	return EncodeQuantity(uint64(this.pipe.Blockchain().Height())), 0, nil
+}
+
+// Returns null for blocks that do not exist
+func (this *EthMethods) GetBlockByNumber(request *EthRequest) (interface{}, int, error) {
+	var blockNumber string
+	var fullTxs bool
+	if err := decodeParams(request.Params, 1, &blockNumber, &fullTxs); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	blockchain := this.pipe.Blockchain()
+	height, err := decodeBlockNumber(blockNumber, blockchain.Height())
+	if err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	if height < 1 || height > blockchain.Height() {
+		return nil, 0, nil
+	}
+	block := blockchain.Block(height)
+	if block == nil {
+		return nil, 0, nil
+	}
+	blockTxs, err := decodeBlockTxs(block)
+	if err != nil {
+		return nil, rpc.INTERNAL_ERROR, err
+	}
+	chainId := blockchain.ChainId()
+	transactions := make([]interface{}, len(blockTxs))
+	for i, tx := range blockTxs {
+		if fullTxs {
+			transactions[i] = newTransaction(chainId, block, i, tx)
+		} else {
+			transactions[i] = EncodeHash(txs.TxHash(chainId, tx))
+		}
+	}
+	return &Block{
+		Number:           EncodeQuantity(uint64(block.Height)),
+		Hash:             EncodeHash(block.Hash()),
+		ParentHash:       EncodeHash(block.LastBlockID.Hash),
+		Nonce:            emptyNonce,
+		Sha3Uncles:       zeroHash,
+		LogsBloom:        emptyLogsBloom,
+		TransactionsRoot: EncodeHash(block.DataHash),
+		StateRoot:        EncodeHash(block.AppHash),
+		ReceiptsRoot:     zeroHash,
+		Miner:            zeroAddress,
+		Difficulty:       EncodeQuantity(0),
+		TotalDifficulty:  EncodeQuantity(0),
+		ExtraData:        EncodeData(nil),
+		Size:             EncodeQuantity(0),
+		GasLimit:         EncodeQuantity(0),
+		GasUsed:          EncodeQuantity(0),
+		Timestamp:        EncodeQuantity(uint64(block.Time.Unix())),
+		Transactions:     transactions,
+		Uncles:           []string{},
+	}, 0, nil
+}
+
+// *************************************** Receipts and logs ************************************
+
+// Looks for the tx in the tx index of the event journal and returns null if
+// it is not found there, which is the case once the journal has dropped the
+// tx's events. The status and logs of the receipt come from the journal too.
+func (this *EthMethods) GetTransactionReceipt(request *EthRequest) (interface{}, int, error) {
+	var hash string
+	if err := decodeParams(request.Params, 1, &hash); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	txHash, err := DecodeTxHash(hash)
+	if err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	journal := this.pipe.EventJournal()
+	if journal == nil {
+		return nil, rpc.INTERNAL_ERROR, fmt.Errorf(errorNoEventJournal)
+	}
+	blockchain := this.pipe.Blockchain()
+	chainId := blockchain.ChainId()
+	block, index, tx, entries, err := findTx(blockchain, journal, chainId, txHash)
+	if err != nil {
+		return nil, rpc.INTERNAL_ERROR, err
+	}
+	if tx == nil {
+		return nil, 0, nil
+	}
+	transaction := newTransaction(chainId, block, index, tx)
+	receipt := &Receipt{
+		TransactionHash:   transaction.Hash,
+		TransactionIndex:  transaction.TransactionIndex,
+		BlockHash:         transaction.BlockHash,
+		BlockNumber:       transaction.BlockNumber,
+		From:              transaction.From,
+		To:                transaction.To,
+		CumulativeGasUsed: EncodeQuantity(0),
+		GasUsed:           EncodeQuantity(0),
+		Logs:              []*Log{},
+		LogsBloom:         emptyLogsBloom,
+	}
+	switch tx := tx.(type) {
+	case *txs.CallTx:
+		if len(tx.Address) == 0 {
+			contractAddress := EncodeData(txs.NewContractAddress(tx.Input.Address,
+				tx.Input.Sequence))
+			receipt.ContractAddress = &contractAddress
+		}
+	case *txs.EthTx:
+		if contractAddr := txs.GenerateReceipt(chainId, tx).ContractAddr; contractAddr != nil {
+			contractAddress := EncodeData(contractAddr)
+			receipt.ContractAddress = &contractAddress
+		}
+	}
+
+	for _, entry := range entries {
+		if txEvent, ok := entry.Data.(txs.EventDataTx); ok {
+			receipt.Status = EncodeQuantity(1)
+			if txEvent.Exception != "" {
+				receipt.Status = EncodeQuantity(0)
+			}
+			break
+		}
+	}
+	// the logs of the whole block are read to number them within the block
+	logs, err := readLogs(journal, &logMatcher{
+		fromHeight: block.Height,
+		toHeight:   block.Height,
+		txHash:     txHash,
+	}, maxLogs)
+	if err != nil {
+		return nil, rpc.INTERNAL_ERROR, err
+	}
+	blocks := newBlockCache(blockchain, chainId)
+	for _, jl := range logs {
+		receipt.Logs = append(receipt.Logs, blocks.newLog(jl))
+	}
+	return receipt, 0, nil
+}
+
+// Logs are read from the event journal, so only those of the events it
+// still holds are returned. Queries matching more than maxLogs logs fail.
+func (this *EthMethods) GetLogs(request *EthRequest) (interface{}, int, error) {
+	args := &LogFilterArgs{}
+	if err := decodeParams(request.Params, 1, args); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	blockchain := this.pipe.Blockchain()
+	matcher, err := newLogMatcher(args, blockchain.Height())
+	if err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	journal := this.pipe.EventJournal()
+	if journal == nil {
+		return nil, rpc.INTERNAL_ERROR, fmt.Errorf(errorNoEventJournal)
+	}
+	logs, err := readLogs(journal, matcher, maxLogs)
+	if err != nil {
+		return nil, rpc.INTERNAL_ERROR, err
+	}
+	if len(logs) > maxLogs {
+		return nil, rpc.INVALID_PARAMS, fmt.Errorf(errorTooManyLogsFmt, maxLogs)
+	}
+	blocks := newBlockCache(blockchain, blockchain.ChainId())
+	result := make([]*Log, len(logs))
+	for i, jl := range logs {
+		result[i] = blocks.newLog(jl)
+	}
+	return result, 0, nil
+}
+
+// *************************************** Net ************************************
+
+// Ethereum networks are identified by a number. Chains whose id is a number
+// use it, others the first four bytes of their genesis hash.
+func (this *EthMethods) NetVersion(request *EthRequest) (interface{}, int, error) {
+	chainId := txs.EthChainID(this.pipe.Blockchain().ChainId(), this.pipe.GenesisHash())
+	return strconv.FormatUint(chainId, 10), 0, nil
+}
+
+// *************************************** Helpers ************************************
+
+// Decodes a raw tx, which is either an RLP encoded EthTx or a burrow tx in
+// go-wire binary. RLP lists start with a byte of at least 0xc0, which is not
+// the type byte of any burrow tx.
+func decodeRawTx(txBytes []byte) (txs.Tx, error) {
+	if len(txBytes) > 0 && txBytes[0] >= 0xc0 {
+		ethTx, err := txs.DecodeEthTx(txBytes)
+		if err != nil {
+			return nil, err
+		}
+		return ethTx, nil
+	}
+	return txs.DecodeTx(txBytes)
+}
+
+// Decodes positional params into targets, of which the first required must
+// be present
+func decodeParams(params json.RawMessage, required int, targets ...interface{}) error {
+	var rawParams []json.RawMessage
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &rawParams); err != nil {
+			return fmt.Errorf("Params must be a list: %v", err)
+		}
+	}
+	if len(rawParams) < required {
+		return fmt.Errorf("Expected at least %v params but got %v", required,
+			len(rawParams))
+	}
+	if len(rawParams) > len(targets) {
+		return fmt.Errorf("Expected at most %v params but got %v", len(targets),
+			len(rawParams))
+	}
+	for i, rawParam := range rawParams {
+		if string(rawParam) == "null" {
+			continue
+		}
+		if err := json.Unmarshal(rawParam, targets[i]); err != nil {
+			return fmt.Errorf("Invalid param %v: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// Burrow only keeps the latest state
+func (this *EthMethods) checkLatestState(blockNumber string) error {
+	latestHeight := this.pipe.Blockchain().Height()
+	height, err := decodeBlockNumber(blockNumber, latestHeight)
+	if err != nil {
+		return err
+	}
+	if height != latestHeight {
+		return fmt.Errorf(errorStateNotAtLatestBlockFmt, latestHeight, height)
+	}
+	return nil
+}
+
+func (this *EthMethods) account(address, blockNumber string) (*account.Account, int, error) {
+	addr, err := DecodeAddress(address)
+	if err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	if err := this.checkLatestState(blockNumber); err != nil {
+		return nil, rpc.INVALID_PARAMS, err
+	}
+	acc, err := this.pipe.Accounts().Account(addr)
+	if err != nil {
+		return nil, rpc.INTERNAL_ERROR, err
+	}
+	if acc == nil {
+		return &account.Account{Address: addr}, 0, nil
+	}
+	return acc, 0, nil
+}
+
+func decodeBlockTxs(block *tm_types.Block) ([]txs.Tx, error) {
+	blockTxs := make([]txs.Tx, len(block.Data.Txs))
+	for i, txBytes := range block.Data.Txs {
+		tx, err := txs.DecodeTx(txBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Could not decode tx %v of block %v: %v", i,
+				block.Height, err)
+		}
+		blockTxs[i] = tx
+	}
+	return blockTxs, nil
+}
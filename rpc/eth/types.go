@@ -0,0 +1,130 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eth
+
+import (
+	"encoding/json"
+
+	rpc "github.com/hyperledger/burrow/rpc"
+)
+
+// Ethereum clients send numeric as well as string ids and expect them echoed
+// back unchanged, so unlike rpc.RPCRequest the id is kept raw. Params are
+// positional.
+type (
+	EthRequest struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+		Id      json.RawMessage `json:"id"`
+	}
+
+	// Result must be present even when null
+	EthResultResponse struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Id      json.RawMessage `json:"id"`
+		Result  interface{}     `json:"result"`
+	}
+
+	EthErrorResponse struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Id      json.RawMessage `json:"id"`
+		Error   *rpc.RPCError   `json:"error"`
+	}
+)
+
+// Results. Numbers are quantities and bytes data, see encoding.go.
+type (
+	// Transaction object of eth_call
+	CallArgs struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		Data string `json:"data"`
+	}
+
+	// Filter object of eth_getLogs. Address is a single address or a list
+	// of them and each topic is null, a single topic or a list of them.
+	LogFilterArgs struct {
+		FromBlock string            `json:"fromBlock"`
+		ToBlock   string            `json:"toBlock"`
+		Address   json.RawMessage   `json:"address"`
+		Topics    []json.RawMessage `json:"topics"`
+	}
+
+	Block struct {
+		Number           string        `json:"number"`
+		Hash             string        `json:"hash"`
+		ParentHash       string        `json:"parentHash"`
+		Nonce            string        `json:"nonce"`
+		Sha3Uncles       string        `json:"sha3Uncles"`
+		LogsBloom        string        `json:"logsBloom"`
+		TransactionsRoot string        `json:"transactionsRoot"`
+		StateRoot        string        `json:"stateRoot"`
+		ReceiptsRoot     string        `json:"receiptsRoot"`
+		Miner            string        `json:"miner"`
+		Difficulty       string        `json:"difficulty"`
+		TotalDifficulty  string        `json:"totalDifficulty"`
+		ExtraData        string        `json:"extraData"`
+		Size             string        `json:"size"`
+		GasLimit         string        `json:"gasLimit"`
+		GasUsed          string        `json:"gasUsed"`
+		Timestamp        string        `json:"timestamp"`
+		Transactions     []interface{} `json:"transactions"`
+		Uncles           []string      `json:"uncles"`
+	}
+
+	// From and To are nil where the tx has no such address
+	Transaction struct {
+		Hash             string  `json:"hash"`
+		Nonce            string  `json:"nonce"`
+		BlockHash        string  `json:"blockHash"`
+		BlockNumber      string  `json:"blockNumber"`
+		TransactionIndex string  `json:"transactionIndex"`
+		From             *string `json:"from"`
+		To               *string `json:"to"`
+		Value            string  `json:"value"`
+		Gas              string  `json:"gas"`
+		GasPrice         string  `json:"gasPrice"`
+		Input            string  `json:"input"`
+	}
+
+	// Status is left out when the journal no longer holds the tx's events
+	Receipt struct {
+		TransactionHash   string  `json:"transactionHash"`
+		TransactionIndex  string  `json:"transactionIndex"`
+		BlockHash         string  `json:"blockHash"`
+		BlockNumber       string  `json:"blockNumber"`
+		From              *string `json:"from"`
+		To                *string `json:"to"`
+		CumulativeGasUsed string  `json:"cumulativeGasUsed"`
+		GasUsed           string  `json:"gasUsed"`
+		ContractAddress   *string `json:"contractAddress"`
+		Logs              []*Log  `json:"logs"`
+		LogsBloom         string  `json:"logsBloom"`
+		Status            string  `json:"status,omitempty"`
+	}
+
+	Log struct {
+		Removed          bool     `json:"removed"`
+		LogIndex         string   `json:"logIndex"`
+		TransactionIndex *string  `json:"transactionIndex"`
+		TransactionHash  *string  `json:"transactionHash"`
+		BlockHash        *string  `json:"blockHash"`
+		BlockNumber      string   `json:"blockNumber"`
+		Address          string   `json:"address"`
+		Data             string   `json:"data"`
+		Topics           []string `json:"topics"`
+	}
+)
@@ -0,0 +1,158 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"fmt"
+	"strings"
+
+	graphql_go "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	// deepest a query may nest fields
+	maxQueryDepth = 6
+	// most a query may cost. Every field costs 1, and the fields selected from
+	// each item of a list cost listCostFactor times their own cost.
+	maxQueryCost   = 2000
+	listCostFactor = 10
+)
+
+// Refuses queries that nest fields too deeply or cost too much before they
+// are executed. The cost is worked out from the types of the schema, so a
+// list of blocks each with a list of txs costs more than a single block.
+// Queries that can not be parsed are left for execution to report, and
+// introspection fields are not costed.
+func checkQueryCost(schema *graphql_go.Schema, query string) error {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+	coster := &queryCoster{
+		schema:    schema,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		visiting:  make(map[string]bool),
+	}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			coster.fragments[fragment.Name.Value] = fragment
+		}
+	}
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		cost, err := coster.cost(operation.SelectionSet, schema.QueryType(), 1)
+		if err != nil {
+			return err
+		}
+		if cost > maxQueryCost {
+			return fmt.Errorf("The query costs %v, more than the limit of %v", cost,
+				maxQueryCost)
+		}
+	}
+	return nil
+}
+
+type queryCoster struct {
+	schema    *graphql_go.Schema
+	fragments map[string]*ast.FragmentDefinition
+	// fragments being costed, so that cycles are not followed
+	visiting map[string]bool
+}
+
+// Cost of the selections of an object, nil if its type is not known
+func (coster *queryCoster) cost(selectionSet *ast.SelectionSet,
+	object *graphql_go.Object, depth int) (int, error) {
+	if selectionSet == nil {
+		return 0, nil
+	}
+	if depth > maxQueryDepth {
+		return 0, fmt.Errorf("The query nests fields deeper than the limit of %v",
+			maxQueryDepth)
+	}
+	cost := 0
+	for _, selection := range selectionSet.Selections {
+		var selectionCost int
+		var err error
+		switch selection := selection.(type) {
+		case *ast.Field:
+			selectionCost, err = coster.fieldCost(selection, object, depth)
+		case *ast.InlineFragment:
+			fragmentObject := object
+			if selection.TypeCondition != nil {
+				fragmentObject = coster.object(selection.TypeCondition.Name.Value)
+			}
+			selectionCost, err = coster.cost(selection.SelectionSet, fragmentObject, depth)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := coster.fragments[name]
+			if !ok || coster.visiting[name] {
+				continue
+			}
+			coster.visiting[name] = true
+			selectionCost, err = coster.cost(fragment.SelectionSet,
+				coster.object(fragment.TypeCondition.Name.Value), depth)
+			delete(coster.visiting, name)
+		}
+		if err != nil {
+			return 0, err
+		}
+		cost += selectionCost
+	}
+	return cost, nil
+}
+
+func (coster *queryCoster) fieldCost(field *ast.Field, object *graphql_go.Object,
+	depth int) (int, error) {
+	if strings.HasPrefix(field.Name.Value, "__") {
+		return 1, nil
+	}
+	var fieldType graphql_go.Type
+	if object != nil {
+		if definition, ok := object.Fields()[field.Name.Value]; ok {
+			fieldType = definition.Type
+		}
+	}
+	var fieldObject *graphql_go.Object
+	if fieldType != nil {
+		fieldObject, _ = graphql_go.GetNamed(fieldType).(*graphql_go.Object)
+	}
+	selectionCost, err := coster.cost(field.SelectionSet, fieldObject, depth+1)
+	if err != nil {
+		return 0, err
+	}
+	if isList(fieldType) {
+		selectionCost *= listCostFactor
+	}
+	return 1 + selectionCost, nil
+}
+
+// Returns nil if there is no object type with name
+func (coster *queryCoster) object(name string) *graphql_go.Object {
+	object, _ := coster.schema.Type(name).(*graphql_go.Object)
+	return object
+}
+
+func isList(ttype graphql_go.Type) bool {
+	if nonNull, ok := ttype.(*graphql_go.NonNull); ok {
+		ttype = nonNull.OfType
+	}
+	_, ok := ttype.(*graphql_go.List)
+	return ok
+}
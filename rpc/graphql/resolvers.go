@@ -0,0 +1,452 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/burrow/blockchain"
+	consensus_types "github.com/hyperledger/burrow/consensus/types"
+	core_types "github.com/hyperledger/burrow/core/types"
+	definitions "github.com/hyperledger/burrow/definitions"
+	"github.com/hyperledger/burrow/event"
+	"github.com/hyperledger/burrow/txs"
+
+	graphql_go "github.com/graphql-go/graphql"
+	"github.com/tendermint/go-wire"
+	tm_types "github.com/tendermint/tendermint/types"
+)
+
+const addressLength = 20
+
+// The values resolved for the object types of the schema. Fields are
+// resolved by their json tags unless the schema gives a resolver. Unexported
+// fields must not share the name of a field of the schema.
+
+type Block struct {
+	Height        int    `json:"height"`
+	Hash          string `json:"hash"`
+	ChainId       string `json:"chainId"`
+	Time          string `json:"time"`
+	NumTxs        int    `json:"numTxs"`
+	LastBlockHash string `json:"lastBlockHash"`
+	AppHash       string `json:"appHash"`
+	tmBlock       *tm_types.Block
+}
+
+type Tx struct {
+	Hash   string `json:"hash"`
+	Height int    `json:"height"`
+	Index  int    `json:"index"`
+	Type   string `json:"type"`
+	JSON   string `json:"json"`
+	txHash []byte
+	// shared by the txs of a block
+	blockEvents *blockEvents
+}
+
+type Event struct {
+	Sequence uint64 `json:"sequence"`
+	EventId  string `json:"eventId"`
+	Data     string `json:"data"`
+}
+
+type Account struct {
+	Address      string `json:"address"`
+	Balance      int64  `json:"balance"`
+	Sequence     int    `json:"sequence"`
+	Code         string `json:"code"`
+	StorageRoot  string `json:"storageRoot"`
+	addressBytes []byte
+}
+
+type StorageItem struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type Name struct {
+	Name     string `json:"name"`
+	Owner    string `json:"owner"`
+	Data     string `json:"data"`
+	Expires  int    `json:"expires"`
+	DataType string `json:"dataType"`
+}
+
+type Validator struct {
+	Address     string `json:"address"`
+	PubKey      string `json:"pubKey"`
+	VotingPower int64  `json:"votingPower"`
+}
+
+type resolver struct {
+	pipe definitions.Pipe
+}
+
+// *************************************** Blocks ************************************
+
+func (r *resolver) latestHeight(p graphql_go.ResolveParams) (interface{}, error) {
+	return r.pipe.Blockchain().Height(), nil
+}
+
+func (r *resolver) block(p graphql_go.ResolveParams) (interface{}, error) {
+	height, ok := p.Args["height"].(int)
+	if !ok {
+		height = r.pipe.Blockchain().Height()
+	}
+	return r.blockAt(height), nil
+}
+
+func (r *resolver) blocks(p graphql_go.ResolveParams) (interface{}, error) {
+	latestHeight := r.pipe.Blockchain().Height()
+	minHeight, ok := p.Args["minHeight"].(int)
+	if !ok || minHeight < 1 {
+		minHeight = 1
+	}
+	maxHeight, ok := p.Args["maxHeight"].(int)
+	if !ok || maxHeight > latestHeight {
+		maxHeight = latestHeight
+	}
+	blocks := []*Block{}
+	for height := maxHeight; height >= minHeight &&
+		len(blocks) < blockchain.BLOCK_MAX; height-- {
+		if block := r.blockAt(height); block != nil {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
+
+// Resolves to nil when there is no block at height
+func (r *resolver) blockAt(height int) *Block {
+	if height < 1 || height > r.pipe.Blockchain().Height() {
+		return nil
+	}
+	block := r.pipe.Blockchain().Block(height)
+	if block == nil {
+		return nil
+	}
+	return &Block{
+		Height:        block.Height,
+		Hash:          fmt.Sprintf("%X", block.Hash()),
+		ChainId:       block.ChainID,
+		Time:          block.Time.Format(time.RFC3339),
+		NumTxs:        block.NumTxs,
+		LastBlockHash: fmt.Sprintf("%X", block.LastBlockID.Hash),
+		AppHash:       fmt.Sprintf("%X", block.AppHash),
+		tmBlock:       block,
+	}
+}
+
+func (r *resolver) blockTxs(p graphql_go.ResolveParams) (interface{}, error) {
+	return blockTxs(p.Source.(*Block).tmBlock)
+}
+
+func blockTxs(block *tm_types.Block) ([]*Tx, error) {
+	blockTxs := make([]*Tx, len(block.Data.Txs))
+	events := &blockEvents{height: block.Height}
+	for i, txBytes := range block.Data.Txs {
+		tx, err := txs.DecodeTx(txBytes)
+		if err != nil {
+			return nil, fmt.Errorf("Could not decode tx %v of block %v: %v", i,
+				block.Height, err)
+		}
+		txHash := txs.TxHash(block.ChainID, tx)
+		blockTxs[i] = &Tx{
+			Hash:        fmt.Sprintf("%X", txHash),
+			Height:      block.Height,
+			Index:       i,
+			Type:        txs.TxTypeName(tx),
+			JSON:        string(wire.JSONBytes(tx)),
+			txHash:      txHash,
+			blockEvents: events,
+		}
+	}
+	return blockTxs, nil
+}
+
+// *************************************** Txs ************************************
+
+// Txs are looked up in the tx index of the event journal, which covers the
+// txs whose events it still holds
+func (r *resolver) tx(p graphql_go.ResolveParams) (interface{}, error) {
+	txHash, err := decodeHex("hash", p.Args["hash"].(string))
+	if err != nil {
+		return nil, err
+	}
+	journal, err := r.journal()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := journal.TxEntries(txHash)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	block := r.pipe.Blockchain().Block(entries[0].Height)
+	if block == nil {
+		return nil, nil
+	}
+	blockTxs, err := blockTxs(block)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range blockTxs {
+		if bytes.Equal(tx.txHash, txHash) {
+			return tx, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *resolver) txBlock(p graphql_go.ResolveParams) (interface{}, error) {
+	return r.blockAt(p.Source.(*Tx).Height), nil
+}
+
+// The journaled events of the txs of a block, which are read once for all of
+// them when the events of one are first resolved
+type blockEvents struct {
+	once   sync.Once
+	height int
+	// by tx hash
+	events map[string][]*Event
+	err    error
+}
+
+// Matches the journaled events fired by txs
+type txEventsFilter struct{}
+
+func (txEventsFilter) Match(v interface{}) bool {
+	entry, ok := v.(*event.JournalEntry)
+	return ok && entry.TxHash != nil
+}
+
+func (r *resolver) txEvents(p graphql_go.ResolveParams) (interface{}, error) {
+	tx := p.Source.(*Tx)
+	journal, err := r.journal()
+	if err != nil {
+		return nil, err
+	}
+	blockEvents := tx.blockEvents
+	blockEvents.once.Do(func() {
+		blockEvents.events, blockEvents.err = readBlockEvents(journal,
+			blockEvents.height)
+	})
+	if blockEvents.err != nil {
+		return nil, blockEvents.err
+	}
+	if events, ok := blockEvents.events[string(tx.txHash)]; ok {
+		return events, nil
+	}
+	return []*Event{}, nil
+}
+
+// Reads the journaled events of the txs of the block at height by tx hash,
+// reading only the events of that block
+func readBlockEvents(journal *event.EventJournal,
+	height int) (map[string][]*Event, error) {
+	sequence, err := journal.SequenceAtHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	end, err := journal.SequenceAtHeight(height + 1)
+	if err != nil {
+		return nil, err
+	}
+	events := make(map[string][]*Event)
+	for sequence < end {
+		entries, next, err := journal.EntriesMatching(txEventsFilter{}, sequence, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Sequence >= end {
+				break
+			}
+			txHash := string(entry.TxHash)
+			events[txHash] = append(events[txHash], &Event{
+				Sequence: entry.Sequence,
+				EventId:  entry.EventId,
+				Data:     string(wire.JSONBytes(entry.Data)),
+			})
+		}
+		sequence = next
+	}
+	return events, nil
+}
+
+func (r *resolver) journal() (*event.EventJournal, error) {
+	journal := r.pipe.EventJournal()
+	if journal == nil {
+		return nil, fmt.Errorf("Txs and their events are not available since " +
+			"the event journal is not enabled")
+	}
+	return journal, nil
+}
+
+// *************************************** Accounts ************************************
+
+func (r *resolver) account(p graphql_go.ResolveParams) (interface{}, error) {
+	address, err := decodeAddress(p.Args["address"].(string))
+	if err != nil {
+		return nil, err
+	}
+	acc, err := r.pipe.Accounts().Account(address)
+	if err != nil || acc == nil {
+		return nil, err
+	}
+	return &Account{
+		Address:      fmt.Sprintf("%X", acc.Address),
+		Balance:      acc.Balance,
+		Sequence:     acc.Sequence,
+		Code:         fmt.Sprintf("%X", acc.Code),
+		StorageRoot:  fmt.Sprintf("%X", acc.StorageRoot),
+		addressBytes: acc.Address,
+	}, nil
+}
+
+func (r *resolver) accountStorage(p graphql_go.ResolveParams) (interface{}, error) {
+	acc := p.Source.(*Account)
+	if k, ok := p.Args["key"].(string); ok {
+		key, err := decodeHex("key", k)
+		if err != nil {
+			return nil, err
+		}
+		item, err := r.pipe.Accounts().StorageAt(acc.addressBytes, key)
+		if err != nil {
+			return nil, err
+		}
+		return []*StorageItem{newStorageItem(item.Key, item.Value)}, nil
+	}
+	var after []byte
+	if a, ok := p.Args["after"].(string); ok {
+		var err error
+		if after, err = decodeHex("after", a); err != nil {
+			return nil, err
+		}
+	}
+	storage, err := r.pipe.Accounts().Storage(acc.addressBytes,
+		pageAfter(after, p.Args))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*StorageItem, len(storage.StorageItems))
+	for i, item := range storage.StorageItems {
+		items[i] = newStorageItem(item.Key, item.Value)
+	}
+	return items, nil
+}
+
+// Returns the page of the first items after the key after, when it is
+// given. The smallest key after it is the key followed by a zero byte, which
+// is the cursor of the page.
+func pageAfter(after []byte, args map[string]interface{}) *core_types.PageRequest {
+	page := &core_types.PageRequest{}
+	if after != nil {
+		page.Cursor = fmt.Sprintf("%X00", after)
+	}
+	if first, ok := args["first"].(int); ok {
+		page.Limit = first
+	}
+	return page
+}
+
+func newStorageItem(key, value []byte) *StorageItem {
+	return &StorageItem{
+		Key:   fmt.Sprintf("%X", key),
+		Value: fmt.Sprintf("%X", value),
+	}
+}
+
+// *************************************** NameReg ************************************
+
+// The name registry errors for names that are not registered, which resolve
+// to null
+func (r *resolver) name(p graphql_go.ResolveParams) (interface{}, error) {
+	entry, err := r.pipe.NameReg().Entry(p.Args["name"].(string))
+	if err != nil || entry == nil {
+		return nil, nil
+	}
+	return &Name{
+		Name:     entry.Name,
+		Owner:    fmt.Sprintf("%X", entry.Owner),
+		Data:     entry.Data,
+		Expires:  entry.Expires,
+		DataType: entry.DataType.String(),
+	}, nil
+}
+
+func (r *resolver) names(p graphql_go.ResolveParams) (interface{}, error) {
+	var after []byte
+	if a, ok := p.Args["after"].(string); ok {
+		after = []byte(a)
+	}
+	list, err := r.pipe.NameReg().Entries(nil, pageAfter(after, p.Args))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]*Name, len(list.Names))
+	for i, entry := range list.Names {
+		names[i] = &Name{
+			Name:     entry.Name,
+			Owner:    fmt.Sprintf("%X", entry.Owner),
+			Data:     entry.Data,
+			Expires:  entry.Expires,
+			DataType: entry.DataType.String(),
+		}
+	}
+	return names, nil
+}
+
+// *************************************** Consensus ************************************
+
+func (r *resolver) validators(p graphql_go.ResolveParams) (interface{}, error) {
+	validators := []*Validator{}
+	for _, v := range r.pipe.GetConsensusEngine().ListValidators() {
+		tmValidator, ok := v.(*consensus_types.TendermintValidator)
+		if !ok {
+			return nil, fmt.Errorf("Unknown type of validator %T", v)
+		}
+		validators = append(validators, &Validator{
+			Address:     fmt.Sprintf("%X", tmValidator.Validator.Address),
+			PubKey:      fmt.Sprintf("%X", tmValidator.Validator.PubKey.Bytes()),
+			VotingPower: tmValidator.Validator.VotingPower,
+		})
+	}
+	return validators, nil
+}
+
+func decodeHex(arg, s string) ([]byte, error) {
+	bs, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("Argument %s is not hex: %v", arg, err)
+	}
+	return bs, nil
+}
+
+func decodeAddress(s string) ([]byte, error) {
+	address, err := decodeHex("address", s)
+	if err != nil {
+		return nil, err
+	}
+	if len(address) != addressLength {
+		return nil, fmt.Errorf("Address %X is not %v bytes long", address,
+			addressLength)
+	}
+	return address, nil
+}
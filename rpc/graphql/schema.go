@@ -0,0 +1,232 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	definitions "github.com/hyperledger/burrow/definitions"
+
+	graphql_go "github.com/graphql-go/graphql"
+)
+
+// Builds the GraphQL schema of the chain state, resolved over the pipe.
+// Bytes are upper case hex strings, as in the JSON-RPC API of rpc/v0.
+//
+//	type Query {
+//	  latestHeight: Int!
+//	  block(height: Int): Block
+//	  blocks(minHeight: Int, maxHeight: Int): [Block!]!
+//	  tx(hash: String!): Tx
+//	  account(address: String!): Account
+//	  name(name: String!): Name
+//	  names: [Name!]!
+//	  validators: [Validator!]!
+//	}
+func NewSchema(pipe definitions.Pipe) (graphql_go.Schema, error) {
+	resolver := &resolver{pipe: pipe}
+
+	eventType := graphql_go.NewObject(graphql_go.ObjectConfig{
+		Name:        "Event",
+		Description: "An event in the event journal",
+		Fields: graphql_go.Fields{
+			"sequence": &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.Int)},
+			"eventId":  &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"data": &graphql_go.Field{
+				Type:        graphql_go.NewNonNull(graphql_go.String),
+				Description: "Event data as JSON, as returned by burrow.eventPoll",
+			},
+		},
+	})
+
+	txType := graphql_go.NewObject(graphql_go.ObjectConfig{
+		Name:        "Tx",
+		Description: "A tx committed in a block",
+		Fields: graphql_go.Fields{
+			"hash":   &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"height": &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.Int)},
+			"index": &graphql_go.Field{
+				Type:        graphql_go.NewNonNull(graphql_go.Int),
+				Description: "Position of the tx in its block",
+			},
+			"type": &graphql_go.Field{
+				Type:        graphql_go.NewNonNull(graphql_go.String),
+				Description: "One of send, call, name, name_transfer, name_data, eth, bond, unbond, rebond, dupeout, permissions, gov or batch",
+			},
+			"json": &graphql_go.Field{
+				Type:        graphql_go.NewNonNull(graphql_go.String),
+				Description: "The tx as JSON",
+			},
+			"events": &graphql_go.Field{
+				Type:        graphql_go.NewNonNull(graphql_go.NewList(graphql_go.NewNonNull(eventType))),
+				Description: "Journaled events fired by the tx",
+				Resolve:     resolver.txEvents,
+			},
+		},
+	})
+
+	blockType := graphql_go.NewObject(graphql_go.ObjectConfig{
+		Name: "Block",
+		Fields: graphql_go.Fields{
+			"height":  &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.Int)},
+			"hash":    &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"chainId": &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"time": &graphql_go.Field{
+				Type:        graphql_go.NewNonNull(graphql_go.String),
+				Description: "Block time in RFC 3339 format",
+			},
+			"numTxs":        &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.Int)},
+			"lastBlockHash": &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"appHash":       &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"txs": &graphql_go.Field{
+				Type:    graphql_go.NewNonNull(graphql_go.NewList(graphql_go.NewNonNull(txType))),
+				Resolve: resolver.blockTxs,
+			},
+		},
+	})
+	// defined after the block type, which refers to txs
+	txType.AddFieldConfig("block", &graphql_go.Field{
+		Type:    blockType,
+		Resolve: resolver.txBlock,
+	})
+
+	storageItemType := graphql_go.NewObject(graphql_go.ObjectConfig{
+		Name: "StorageItem",
+		Fields: graphql_go.Fields{
+			"key":   &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"value": &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+		},
+	})
+
+	accountType := graphql_go.NewObject(graphql_go.ObjectConfig{
+		Name: "Account",
+		Fields: graphql_go.Fields{
+			"address":     &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"balance":     &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.Int)},
+			"sequence":    &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.Int)},
+			"code":        &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"storageRoot": &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"storage": &graphql_go.Field{
+				Type: graphql_go.NewNonNull(graphql_go.NewList(graphql_go.NewNonNull(storageItemType))),
+				Description: "A page of the storage of the account in key order, " +
+					"of the first items after the key after, or only the item at " +
+					"key when it is given",
+				Args: graphql_go.FieldConfigArgument{
+					"key":   &graphql_go.ArgumentConfig{Type: graphql_go.String},
+					"after": &graphql_go.ArgumentConfig{Type: graphql_go.String},
+					"first": &graphql_go.ArgumentConfig{Type: graphql_go.Int},
+				},
+				Resolve: resolver.accountStorage,
+			},
+		},
+	})
+
+	nameType := graphql_go.NewObject(graphql_go.ObjectConfig{
+		Name:        "Name",
+		Description: "A name registry entry",
+		Fields: graphql_go.Fields{
+			"name":    &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"owner":   &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"data":    &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"expires": &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.Int)},
+			"dataType": &graphql_go.Field{
+				Type:        graphql_go.NewNonNull(graphql_go.String),
+				Description: "One of plain, bytes, text or json",
+			},
+		},
+	})
+
+	validatorType := graphql_go.NewObject(graphql_go.ObjectConfig{
+		Name: "Validator",
+		Fields: graphql_go.Fields{
+			"address": &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.String)},
+			"pubKey": &graphql_go.Field{
+				Type:        graphql_go.NewNonNull(graphql_go.String),
+				Description: "go-wire encoded public key",
+			},
+			"votingPower": &graphql_go.Field{Type: graphql_go.NewNonNull(graphql_go.Int)},
+		},
+	})
+
+	queryType := graphql_go.NewObject(graphql_go.ObjectConfig{
+		Name: "Query",
+		Fields: graphql_go.Fields{
+			"latestHeight": &graphql_go.Field{
+				Type:    graphql_go.NewNonNull(graphql_go.Int),
+				Resolve: resolver.latestHeight,
+			},
+			"block": &graphql_go.Field{
+				Type:        blockType,
+				Description: "The block at height, or the latest block",
+				Args: graphql_go.FieldConfigArgument{
+					"height": &graphql_go.ArgumentConfig{Type: graphql_go.Int},
+				},
+				Resolve: resolver.block,
+			},
+			"blocks": &graphql_go.Field{
+				Type: graphql_go.NewNonNull(graphql_go.NewList(graphql_go.NewNonNull(blockType))),
+				Description: "Blocks from maxHeight down to minHeight, which default " +
+					"to the latest and first block. At most 50 blocks are returned.",
+				Args: graphql_go.FieldConfigArgument{
+					"minHeight": &graphql_go.ArgumentConfig{Type: graphql_go.Int},
+					"maxHeight": &graphql_go.ArgumentConfig{Type: graphql_go.Int},
+				},
+				Resolve: resolver.blocks,
+			},
+			"tx": &graphql_go.Field{
+				Type:        txType,
+				Description: "The tx with hash, while the event journal holds its events",
+				Args: graphql_go.FieldConfigArgument{
+					"hash": &graphql_go.ArgumentConfig{
+						Type: graphql_go.NewNonNull(graphql_go.String),
+					},
+				},
+				Resolve: resolver.tx,
+			},
+			"account": &graphql_go.Field{
+				Type: accountType,
+				Args: graphql_go.FieldConfigArgument{
+					"address": &graphql_go.ArgumentConfig{
+						Type: graphql_go.NewNonNull(graphql_go.String),
+					},
+				},
+				Resolve: resolver.account,
+			},
+			"name": &graphql_go.Field{
+				Type: nameType,
+				Args: graphql_go.FieldConfigArgument{
+					"name": &graphql_go.ArgumentConfig{
+						Type: graphql_go.NewNonNull(graphql_go.String),
+					},
+				},
+				Resolve: resolver.name,
+			},
+			"names": &graphql_go.Field{
+				Type: graphql_go.NewNonNull(graphql_go.NewList(graphql_go.NewNonNull(nameType))),
+				Description: "A page of the entries in name order, of the first " +
+					"entries after the name after",
+				Args: graphql_go.FieldConfigArgument{
+					"after": &graphql_go.ArgumentConfig{Type: graphql_go.String},
+					"first": &graphql_go.ArgumentConfig{Type: graphql_go.Int},
+				},
+				Resolve: resolver.names,
+			},
+			"validators": &graphql_go.Field{
+				Type:    graphql_go.NewNonNull(graphql_go.NewList(graphql_go.NewNonNull(validatorType))),
+				Resolve: resolver.validators,
+			},
+		},
+	})
+
+	return graphql_go.NewSchema(graphql_go.SchemaConfig{Query: queryType})
+}
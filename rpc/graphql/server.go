@@ -0,0 +1,160 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	definitions "github.com/hyperledger/burrow/definitions"
+	server "github.com/hyperledger/burrow/server"
+
+	"github.com/gin-gonic/gin"
+	graphql_go "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Server used to handle GraphQL queries. Implements server.Server
+type GraphQLServer struct {
+	service server.HttpService
+	running bool
+}
+
+// Create a new GraphQLServer
+func NewGraphQLServer(service server.HttpService) *GraphQLServer {
+	return &GraphQLServer{service: service}
+}
+
+// Start adds the GraphQL path to the router, unless it is not configured.
+func (this *GraphQLServer) Start(config *server.ServerConfig,
+	router *gin.Engine) {
+	if config.HTTP.GraphQLEndpoint == "" {
+		return
+	}
+	router.GET(config.HTTP.GraphQLEndpoint, this.handleFunc)
+	router.POST(config.HTTP.GraphQLEndpoint, this.handleFunc)
+	this.running = true
+}
+
+// Is the server currently running?
+func (this *GraphQLServer) Running() bool {
+	return this.running
+}
+
+// Shut the server down. Does nothing.
+func (this *GraphQLServer) ShutDown() {
+	this.running = false
+}
+
+func (this *GraphQLServer) handleFunc(c *gin.Context) {
+	this.service.Process(c.Request, c.Writer)
+}
+
+// A GraphQL query, posted as JSON or passed in the query string of a GET
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Executes GraphQL queries against the chain state. Implements
+// server.HttpService.
+type GraphQLService struct {
+	schema  graphql_go.Schema
+	auth    *server.Auth
+	limiter *server.RateLimiter
+}
+
+// Queries only read, so they are authorized as the single method graphql,
+// which needs server.RoleReadOnly unless the policy of the config says
+// otherwise. Since a query may ask for many blocks at once, they are rate
+// limited as scans.
+const GRAPHQL = "graphql"
+
+var methodRoles = server.MethodRoles{GRAPHQL: server.RoleReadOnly}
+
+var methodClasses = server.MethodClasses{GRAPHQL: server.ClassScan}
+
+// Create a new GraphQL service over the pipe. Requests are authorized by auth
+// and rate limited by limiter, either of which may be nil.
+func NewGraphQLService(pipe definitions.Pipe, auth *server.Auth,
+	limiter *server.RateLimiter) (server.HttpService, error) {
+	schema, err := NewSchema(pipe)
+	if err != nil {
+		return nil, err
+	}
+	return &GraphQLService{schema: schema, auth: auth, limiter: limiter}, nil
+}
+
+// Process a request. Errors in the query, including queries refused for
+// costing too much, are returned in the errors of the result, as GraphQL
+// clients expect, and only a request that can not be read is a bad request.
+// Callers that are not authorized, or that exceed their rate limit, are
+// refused before the query is read.
+func (this *GraphQLService) Process(r *http.Request, w http.ResponseWriter) {
+	role, err := this.auth.Authenticate(r)
+	if err != nil {
+		http.Error(w, "Failed to authenticate: "+err.Error(), 401)
+		return
+	}
+	if err := this.auth.Authorize(role, GRAPHQL, methodRoles); err != nil {
+		http.Error(w, err.Error(), 403)
+		return
+	}
+	release, err := this.limiter.Limit(this.auth.ClientId(r), GRAPHQL,
+		methodClasses)
+	if err != nil {
+		http.Error(w, err.Error(), 429)
+		return
+	}
+	defer release()
+	req := &GraphQLRequest{}
+	if r.Method == "GET" {
+		values := r.URL.Query()
+		req.Query = values.Get("query")
+		req.OperationName = values.Get("operationName")
+		if variables := values.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				http.Error(w, "Failed to parse variables: "+err.Error(), 400)
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, "Failed to parse request: "+err.Error(), 400)
+		return
+	}
+
+	var result *graphql_go.Result
+	if err := checkQueryCost(&this.schema, req.Query); err != nil {
+		result = &graphql_go.Result{
+			Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)},
+		}
+	} else {
+		result = graphql_go.Do(graphql_go.Params{
+			Schema:         this.schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+		})
+	}
+	bs, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, "Failed to marshal response: "+err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+	w.Write(bs)
+}
@@ -0,0 +1,399 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	account "github.com/hyperledger/burrow/account"
+	blockchain_types "github.com/hyperledger/burrow/blockchain/types"
+	consensus_types "github.com/hyperledger/burrow/consensus/types"
+	core_types "github.com/hyperledger/burrow/core/types"
+	definitions "github.com/hyperledger/burrow/definitions"
+	"github.com/hyperledger/burrow/event"
+	server "github.com/hyperledger/burrow/server"
+	"github.com/hyperledger/burrow/txs"
+	"github.com/hyperledger/burrow/word256"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/go-crypto"
+	dbm "github.com/tendermint/go-db"
+	tm_types "github.com/tendermint/tendermint/types"
+)
+
+// Only the parts of the pipe used by the resolvers are implemented, the
+// embedded interfaces are nil
+type mockPipe struct {
+	definitions.Pipe
+	accounts   *mockAccounts
+	blockchain *mockBlockchain
+	nameReg    *mockNameReg
+	consensus  *mockConsensusEngine
+	journal    *event.EventJournal
+}
+
+func (pipe *mockPipe) Accounts() definitions.Accounts          { return pipe.accounts }
+func (pipe *mockPipe) Blockchain() blockchain_types.Blockchain { return pipe.blockchain }
+func (pipe *mockPipe) NameReg() definitions.NameReg            { return pipe.nameReg }
+func (pipe *mockPipe) EventJournal() *event.EventJournal       { return pipe.journal }
+
+func (pipe *mockPipe) GetConsensusEngine() consensus_types.ConsensusEngine {
+	return pipe.consensus
+}
+
+type mockAccounts struct {
+	definitions.Accounts
+	accounts map[string]*account.Account
+	storage  map[string][]byte
+}
+
+func (accounts *mockAccounts) Account(address []byte) (*account.Account, error) {
+	return accounts.accounts[string(address)], nil
+}
+
+func (accounts *mockAccounts) StorageAt(address, key []byte) (*core_types.StorageItem,
+	error) {
+	return &core_types.StorageItem{key, accounts.storage[string(key)]}, nil
+}
+
+func (accounts *mockAccounts) Storage(address []byte,
+	page *core_types.PageRequest) (*core_types.Storage, error) {
+	storage := &core_types.Storage{}
+	for key, value := range accounts.storage {
+		storage.StorageItems = append(storage.StorageItems,
+			core_types.StorageItem{[]byte(key), value})
+	}
+	return storage, nil
+}
+
+type mockBlockchain struct {
+	chainId string
+	blocks  []*tm_types.Block
+}
+
+func (blockchain *mockBlockchain) Height() int                              { return len(blockchain.blocks) }
+func (blockchain *mockBlockchain) BlockMeta(height int) *tm_types.BlockMeta { return nil }
+func (blockchain *mockBlockchain) ChainId() string                          { return blockchain.chainId }
+
+func (blockchain *mockBlockchain) Block(height int) *tm_types.Block {
+	if height < 1 || height > len(blockchain.blocks) {
+		return nil
+	}
+	return blockchain.blocks[height-1]
+}
+
+type mockNameReg struct {
+	definitions.NameReg
+	entries map[string]*core_types.NameRegEntry
+}
+
+func (nameReg *mockNameReg) Entry(name string) (*core_types.NameRegEntry, error) {
+	entry, ok := nameReg.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("Entry %s not found", name)
+	}
+	return entry, nil
+}
+
+type mockConsensusEngine struct {
+	consensus_types.ConsensusEngine
+	validators []consensus_types.Validator
+}
+
+func (engine *mockConsensusEngine) ListValidators() []consensus_types.Validator {
+	return engine.validators
+}
+
+var (
+	testChainId  = "graphql_test_chain"
+	callerAddr   = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+	contractAddr = []byte{20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	testSendTx   = &txs.SendTx{
+		Inputs:  []*txs.TxInput{{Address: callerAddr, Amount: 5, Sequence: 1}},
+		Outputs: []*txs.TxOutput{{Address: contractAddr, Amount: 5}},
+	}
+	testCallTx = &txs.CallTx{
+		Input:    &txs.TxInput{Address: callerAddr, Amount: 101, Sequence: 2},
+		Address:  contractAddr,
+		GasLimit: 1000,
+		Fee:      1,
+		Data:     []byte{0xCA, 0xFE},
+	}
+)
+
+func newBlock(height int, blockTxs ...txs.Tx) *tm_types.Block {
+	data := &tm_types.Data{}
+	for _, tx := range blockTxs {
+		txBytes, err := txs.EncodeTx(tx)
+		if err != nil {
+			panic(err)
+		}
+		data.Txs = append(data.Txs, txBytes)
+	}
+	return &tm_types.Block{
+		Header: &tm_types.Header{
+			ChainID: testChainId,
+			Height:  height,
+			Time:    time.Unix(int64(1000+height), 0).UTC(),
+			NumTxs:  len(blockTxs),
+		},
+		Data:       data,
+		LastCommit: &tm_types.Commit{},
+	}
+}
+
+func newTestService(t *testing.T) server.HttpService {
+	journal, err := event.NewEventJournal(dbm.NewMemDB(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendTxHash := txs.TxHash(testChainId, testSendTx)
+	callTxHash := txs.TxHash(testChainId, testCallTx)
+	journal.Append(1, sendTxHash, txs.EventStringAccInput(callerAddr),
+		txs.EventDataTx{Tx: testSendTx})
+	journal.Append(1, sendTxHash, txs.EventStringAccOutput(contractAddr),
+		txs.EventDataTx{Tx: testSendTx})
+	// events are found by the hash of the tx that fired them
+	journal.Append(2, callTxHash, txs.EventStringLogEvent(contractAddr), txs.EventDataLog{
+		Address: word256.LeftPadWord256(contractAddr),
+		Data:    []byte{1},
+		Height:  1,
+		TxID:    callTxHash,
+	})
+	journal.Append(2, callTxHash, txs.EventStringAccInput(callerAddr),
+		txs.EventDataTx{Tx: testCallTx})
+	service, err := NewGraphQLService(&mockPipe{
+		accounts: &mockAccounts{
+			accounts: map[string]*account.Account{
+				string(contractAddr): {Address: contractAddr, Balance: 1000,
+					Code: []byte{0x60, 0x00}},
+			},
+			storage: map[string][]byte{string([]byte{1}): {0xFF}},
+		},
+		blockchain: &mockBlockchain{
+			chainId: testChainId,
+			blocks: []*tm_types.Block{
+				newBlock(1, testSendTx),
+				newBlock(2, testCallTx),
+			},
+		},
+		nameReg: &mockNameReg{entries: map[string]*core_types.NameRegEntry{
+			"foo": {Name: "foo", Owner: callerAddr, Data: "bar", Expires: 100},
+		}},
+		consensus: &mockConsensusEngine{
+			validators: consensus_types.FromTendermintValidators([]*tm_types.Validator{
+				{Address: callerAddr, PubKey: crypto.PubKeyEd25519{1}, VotingPower: 10},
+			}),
+		},
+		journal: journal,
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service
+}
+
+type testResult struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func doQuery(t *testing.T, service server.HttpService, query string,
+	result interface{}) *testResult {
+	body, err := json.Marshal(&GraphQLRequest{Query: query})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := http.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return process(t, service, request, result)
+}
+
+func process(t *testing.T, service server.HttpService, request *http.Request,
+	result interface{}) *testResult {
+	recorder := httptest.NewRecorder()
+	service.Process(request, recorder)
+	response := &testResult{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+		t.Fatal(err)
+	}
+	if result != nil {
+		if len(response.Errors) > 0 {
+			t.Fatalf("Unexpected error: %v", response.Errors[0].Message)
+		}
+		if err := json.Unmarshal(response.Data, result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return response
+}
+
+func TestBlocksWithTxsAndEvents(t *testing.T) {
+	service := newTestService(t)
+	result := &struct {
+		LatestHeight int
+		Blocks       []struct {
+			Height int
+			Time   string
+			Txs    []struct {
+				Hash   string
+				Type   string
+				Block  struct{ Height int }
+				Events []struct{ EventId string }
+			}
+		}
+	}{}
+	doQuery(t, service, `{
+		latestHeight
+		blocks {
+			height
+			time
+			txs { hash type block { height } events { eventId } }
+		}
+	}`, result)
+
+	assert.Equal(t, 2, result.LatestHeight)
+	if !assert.Len(t, result.Blocks, 2) {
+		return
+	}
+	// latest first
+	callBlock, sendBlock := result.Blocks[0], result.Blocks[1]
+	assert.Equal(t, 2, callBlock.Height)
+	assert.Equal(t, "1970-01-01T00:16:42Z", callBlock.Time)
+	if assert.Len(t, callBlock.Txs, 1) {
+		tx := callBlock.Txs[0]
+		assert.Equal(t, fmt.Sprintf("%X", txs.TxHash(testChainId, testCallTx)), tx.Hash)
+		assert.Equal(t, "call", tx.Type)
+		assert.Equal(t, 2, tx.Block.Height)
+		if assert.Len(t, tx.Events, 2) {
+			assert.Equal(t, txs.EventStringLogEvent(contractAddr), tx.Events[0].EventId)
+			assert.Equal(t, txs.EventStringAccInput(callerAddr), tx.Events[1].EventId)
+		}
+	}
+	if assert.Len(t, sendBlock.Txs, 1) {
+		assert.Equal(t, "send", sendBlock.Txs[0].Type)
+		assert.Len(t, sendBlock.Txs[0].Events, 2)
+	}
+
+	tx := &struct{ Tx *struct{ Height int } }{}
+	doQuery(t, service, fmt.Sprintf(`{ tx(hash: "%X") { height } }`,
+		txs.TxHash(testChainId, testSendTx)), tx)
+	if assert.NotNil(t, tx.Tx) {
+		assert.Equal(t, 1, tx.Tx.Height)
+	}
+	block := &struct{ Block *struct{ Height int } }{}
+	doQuery(t, service, `{ block(height: 3) { height } }`, block)
+	assert.Nil(t, block.Block)
+}
+
+func TestAccountsNamesAndValidators(t *testing.T) {
+	service := newTestService(t)
+	result := &struct {
+		Contract *struct {
+			Balance int
+			Code    string
+			Item    []struct{ Key, Value string }
+			Storage []struct{ Key, Value string }
+		}
+		Caller     *struct{ Balance int }
+		Foo        *struct{ Data, Owner string }
+		Baz        *struct{ Data string }
+		Validators []struct {
+			Address     string
+			VotingPower int
+		}
+	}{}
+	doQuery(t, service, fmt.Sprintf(`{
+		contract: account(address: "%X") {
+			balance
+			code
+			item: storage(key: "01") { key value }
+			storage { key value }
+		}
+		caller: account(address: "%X") { balance }
+		foo: name(name: "foo") { data owner }
+		baz: name(name: "baz") { data }
+		validators { address votingPower }
+	}`, contractAddr, callerAddr), result)
+
+	if assert.NotNil(t, result.Contract) {
+		assert.Equal(t, 1000, result.Contract.Balance)
+		assert.Equal(t, "6000", result.Contract.Code)
+		assert.Equal(t, []struct{ Key, Value string }{{"01", "FF"}}, result.Contract.Item)
+		assert.Equal(t, result.Contract.Item, result.Contract.Storage)
+	}
+	assert.Nil(t, result.Caller)
+	if assert.NotNil(t, result.Foo) {
+		assert.Equal(t, "bar", result.Foo.Data)
+		assert.Equal(t, fmt.Sprintf("%X", callerAddr), result.Foo.Owner)
+	}
+	assert.Nil(t, result.Baz)
+	if assert.Len(t, result.Validators, 1) {
+		assert.Equal(t, fmt.Sprintf("%X", callerAddr), result.Validators[0].Address)
+		assert.Equal(t, 10, result.Validators[0].VotingPower)
+	}
+
+	response := doQuery(t, service, `{ account(address: "0102") { balance } }`, nil)
+	assert.Len(t, response.Errors, 1)
+}
+
+func TestQueryCost(t *testing.T) {
+	service := newTestService(t)
+	costs := map[string]string{
+		`{ blocks { txs { block { txs { block { txs { hash } } } } } } }`: "deeper",
+		`{ blocks { txs { block { txs { events { data } } } } } }`:        "costs",
+		`{ ...Deep } fragment Deep on Query {
+			blocks { txs { block { txs { block { txs { hash } } } } } }
+		}`: "deeper",
+	}
+	for query, message := range costs {
+		response := doQuery(t, service, query, nil)
+		if assert.Len(t, response.Errors, 1, query) {
+			assert.Contains(t, response.Errors[0].Message, message, query)
+		}
+	}
+
+	// introspection is not costed
+	result := &struct {
+		Schema struct{ Types []struct{ Name string } }
+	}{}
+	doQuery(t, service, `{ schema: __schema { types { name fields { name
+		type { name ofType { name ofType { name ofType { name } } } } } } } }`, result)
+	assert.NotEmpty(t, result.Schema.Types)
+}
+
+func TestGetQuery(t *testing.T) {
+	service := newTestService(t)
+	values := url.Values{}
+	values.Set("query", `query Height { latestHeight }`)
+	request, err := http.NewRequest("GET", "/graphql?"+values.Encode(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &struct{ LatestHeight int }{}
+	process(t, service, request, result)
+	assert.Equal(t, 2, result.LatestHeight)
+}
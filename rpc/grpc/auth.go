@@ -0,0 +1,144 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"net"
+	"strings"
+
+	server "github.com/hyperledger/burrow/server"
+
+	context "golang.org/x/net/context"
+	google_grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// The least role needed to call each method when authentication is enabled.
+// Methods are named <service>/<method> as in burrow.proto, for example
+// burrow.Accounts/GetAccount, which is also how the policy of the config
+// names them.
+var methodRoles = server.MethodRoles{
+	"burrow.Accounts/GetAccount":        server.RoleReadOnly,
+	"burrow.Accounts/GetStorageAt":      server.RoleReadOnly,
+	"burrow.Transactor/BroadcastTx":     server.RoleBroadcastOnly,
+	"burrow.Transactor/Call":            server.RoleReadOnly,
+	"burrow.Transactor/CallCode":        server.RoleReadOnly,
+	"burrow.NameReg/GetEntry":           server.RoleReadOnly,
+	"burrow.Blockchain/GetLatestHeight": server.RoleReadOnly,
+	"burrow.Blockchain/GetBlock":        server.RoleReadOnly,
+	"burrow.Blockchain/StreamBlocks":    server.RoleReadOnly,
+	"burrow.Events/Stream":              server.RoleReadOnly,
+}
+
+// The class of each method when rate limiting is enabled. Methods that are not
+// listed are server.ClassRead.
+var methodClasses = server.MethodClasses{
+	"burrow.Transactor/BroadcastTx":  server.ClassTransact,
+	"burrow.Transactor/Call":         server.ClassSimulate,
+	"burrow.Transactor/CallCode":     server.ClassSimulate,
+	"burrow.Blockchain/StreamBlocks": server.ClassScan,
+	"burrow.Events/Stream":           server.ClassScan,
+}
+
+// Authenticates, authorizes and rate limits the calls of a gRPC server
+type interceptor struct {
+	auth    *server.Auth
+	limiter *server.RateLimiter
+}
+
+func (interceptor *interceptor) unary(ctx context.Context, req interface{},
+	info *google_grpc.UnaryServerInfo,
+	handler google_grpc.UnaryHandler) (interface{}, error) {
+	release, err := interceptor.admit(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return handler(ctx, req)
+}
+
+// Streams are limited as a single call when they are opened
+func (interceptor *interceptor) stream(srv interface{},
+	stream google_grpc.ServerStream, info *google_grpc.StreamServerInfo,
+	handler google_grpc.StreamHandler) error {
+	release, err := interceptor.admit(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return handler(srv, stream)
+}
+
+// Admits a call of fullMethod, as /<service>/<method>, returning a function
+// to call once it returns, or an error with the gRPC status of the refusal.
+// Callers are checked for their role before being counted against their
+// rate limits, so that refused calls do not use them up.
+func (interceptor *interceptor) admit(ctx context.Context,
+	fullMethod string) (func(), error) {
+	method := strings.TrimPrefix(fullMethod, "/")
+	creds := callCredentials(ctx)
+	role, err := interceptor.auth.AuthenticateCredentials(creds)
+	if err != nil {
+		return nil, google_grpc.Errorf(codes.Unauthenticated, "%v", err)
+	}
+	if err := interceptor.auth.Authorize(role, method, methodRoles); err != nil {
+		return nil, google_grpc.Errorf(codes.PermissionDenied, "%v", err)
+	}
+	clientId := interceptor.auth.CredentialsClientId(creds, callHost(ctx))
+	release, err := interceptor.limiter.Limit(clientId, method, methodClasses)
+	if err != nil {
+		return nil, google_grpc.Errorf(codes.ResourceExhausted, "%v", err)
+	}
+	return release, nil
+}
+
+// Reads the credentials of a call from its metadata, where a bearer token is
+// sent as "authorization: Bearer <token>" and an API key as "x-api-key", and
+// from the TLS client certificate of its connection.
+func callCredentials(ctx context.Context) server.Credentials {
+	var creds server.Credentials
+	if md, ok := metadata.FromContext(ctx); ok {
+		if values := md["authorization"]; len(values) > 0 &&
+			strings.HasPrefix(values[0], "Bearer ") {
+			creds.BearerToken = strings.TrimSpace(values[0][len("Bearer "):])
+		}
+		if values := md["x-api-key"]; len(values) > 0 {
+			creds.APIKey = values[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			creds.ClientCert = len(tlsInfo.State.VerifiedChains) > 0
+		}
+	}
+	return creds
+}
+
+// Returns the IP address a call came from, identifying the client when it
+// presents no credentials
+func callHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
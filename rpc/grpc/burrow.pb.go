@@ -0,0 +1,1220 @@
+// Code generated by protoc-gen-go.
+// source: burrow.proto
+// DO NOT EDIT!
+
+/*
+Package grpc is a generated protocol buffer package.
+
+It is generated from these files:
+
+	burrow.proto
+
+It has these top-level messages:
+
+	Empty
+	AddressParam
+	StorageAtParam
+	Account
+	StorageItem
+	TxParam
+	TxReceipt
+	CallParam
+	CallCodeParam
+	CallResult
+	NameParam
+	NameEntry
+	Height
+	HeightParam
+	BlocksParam
+	Block
+	EventsParam
+	Event
+*/
+package grpc
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc1 "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Empty struct {
+}
+
+func (m *Empty) Reset()                    { *m = Empty{} }
+func (m *Empty) String() string            { return proto.CompactTextString(m) }
+func (*Empty) ProtoMessage()               {}
+func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type AddressParam struct {
+	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (m *AddressParam) Reset()                    { *m = AddressParam{} }
+func (m *AddressParam) String() string            { return proto.CompactTextString(m) }
+func (*AddressParam) ProtoMessage()               {}
+func (*AddressParam) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *AddressParam) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+type StorageAtParam struct {
+	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Key     []byte `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (m *StorageAtParam) Reset()                    { *m = StorageAtParam{} }
+func (m *StorageAtParam) String() string            { return proto.CompactTextString(m) }
+func (*StorageAtParam) ProtoMessage()               {}
+func (*StorageAtParam) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *StorageAtParam) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *StorageAtParam) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+type Account struct {
+	Address     []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Sequence    int64  `protobuf:"varint,2,opt,name=sequence" json:"sequence,omitempty"`
+	Balance     int64  `protobuf:"varint,3,opt,name=balance" json:"balance,omitempty"`
+	Code        []byte `protobuf:"bytes,4,opt,name=code,proto3" json:"code,omitempty"`
+	StorageRoot []byte `protobuf:"bytes,5,opt,name=storage_root,json=storageRoot,proto3" json:"storage_root,omitempty"`
+}
+
+func (m *Account) Reset()                    { *m = Account{} }
+func (m *Account) String() string            { return proto.CompactTextString(m) }
+func (*Account) ProtoMessage()               {}
+func (*Account) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *Account) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *Account) GetSequence() int64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Account) GetBalance() int64 {
+	if m != nil {
+		return m.Balance
+	}
+	return 0
+}
+
+func (m *Account) GetCode() []byte {
+	if m != nil {
+		return m.Code
+	}
+	return nil
+}
+
+func (m *Account) GetStorageRoot() []byte {
+	if m != nil {
+		return m.StorageRoot
+	}
+	return nil
+}
+
+type StorageItem struct {
+	Key   []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *StorageItem) Reset()                    { *m = StorageItem{} }
+func (m *StorageItem) String() string            { return proto.CompactTextString(m) }
+func (*StorageItem) ProtoMessage()               {}
+func (*StorageItem) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *StorageItem) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *StorageItem) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type TxParam struct {
+	Tx []byte `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+}
+
+func (m *TxParam) Reset()                    { *m = TxParam{} }
+func (m *TxParam) String() string            { return proto.CompactTextString(m) }
+func (*TxParam) ProtoMessage()               {}
+func (*TxParam) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *TxParam) GetTx() []byte {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+type TxReceipt struct {
+	TxHash          []byte `protobuf:"bytes,1,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	CreatesContract bool   `protobuf:"varint,2,opt,name=creates_contract,json=createsContract" json:"creates_contract,omitempty"`
+	ContractAddress []byte `protobuf:"bytes,3,opt,name=contract_address,json=contractAddress,proto3" json:"contract_address,omitempty"`
+	// One for each tx of a BatchTx
+	Receipts []*TxReceipt `protobuf:"bytes,4,rep,name=receipts" json:"receipts,omitempty"`
+}
+
+func (m *TxReceipt) Reset()                    { *m = TxReceipt{} }
+func (m *TxReceipt) String() string            { return proto.CompactTextString(m) }
+func (*TxReceipt) ProtoMessage()               {}
+func (*TxReceipt) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *TxReceipt) GetTxHash() []byte {
+	if m != nil {
+		return m.TxHash
+	}
+	return nil
+}
+
+func (m *TxReceipt) GetCreatesContract() bool {
+	if m != nil {
+		return m.CreatesContract
+	}
+	return false
+}
+
+func (m *TxReceipt) GetContractAddress() []byte {
+	if m != nil {
+		return m.ContractAddress
+	}
+	return nil
+}
+
+func (m *TxReceipt) GetReceipts() []*TxReceipt {
+	if m != nil {
+		return m.Receipts
+	}
+	return nil
+}
+
+type CallParam struct {
+	From    []byte `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Address []byte `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Data    []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *CallParam) Reset()                    { *m = CallParam{} }
+func (m *CallParam) String() string            { return proto.CompactTextString(m) }
+func (*CallParam) ProtoMessage()               {}
+func (*CallParam) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *CallParam) GetFrom() []byte {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *CallParam) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *CallParam) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type CallCodeParam struct {
+	From []byte `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Code []byte `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Data []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *CallCodeParam) Reset()                    { *m = CallCodeParam{} }
+func (m *CallCodeParam) String() string            { return proto.CompactTextString(m) }
+func (*CallCodeParam) ProtoMessage()               {}
+func (*CallCodeParam) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *CallCodeParam) GetFrom() []byte {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *CallCodeParam) GetCode() []byte {
+	if m != nil {
+		return m.Code
+	}
+	return nil
+}
+
+func (m *CallCodeParam) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type CallResult struct {
+	Return  []byte `protobuf:"bytes,1,opt,name=return,proto3" json:"return,omitempty"`
+	GasUsed int64  `protobuf:"varint,2,opt,name=gas_used,json=gasUsed" json:"gas_used,omitempty"`
+}
+
+func (m *CallResult) Reset()                    { *m = CallResult{} }
+func (m *CallResult) String() string            { return proto.CompactTextString(m) }
+func (*CallResult) ProtoMessage()               {}
+func (*CallResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *CallResult) GetReturn() []byte {
+	if m != nil {
+		return m.Return
+	}
+	return nil
+}
+
+func (m *CallResult) GetGasUsed() int64 {
+	if m != nil {
+		return m.GasUsed
+	}
+	return 0
+}
+
+type NameParam struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *NameParam) Reset()                    { *m = NameParam{} }
+func (m *NameParam) String() string            { return proto.CompactTextString(m) }
+func (*NameParam) ProtoMessage()               {}
+func (*NameParam) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *NameParam) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type NameEntry struct {
+	Name    string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Owner   []byte `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Data    string `protobuf:"bytes,3,opt,name=data" json:"data,omitempty"`
+	Expires int64  `protobuf:"varint,4,opt,name=expires" json:"expires,omitempty"`
+	// plain, bytes, text or json
+	DataType string `protobuf:"bytes,5,opt,name=data_type,json=dataType" json:"data_type,omitempty"`
+}
+
+func (m *NameEntry) Reset()                    { *m = NameEntry{} }
+func (m *NameEntry) String() string            { return proto.CompactTextString(m) }
+func (*NameEntry) ProtoMessage()               {}
+func (*NameEntry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *NameEntry) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *NameEntry) GetOwner() []byte {
+	if m != nil {
+		return m.Owner
+	}
+	return nil
+}
+
+func (m *NameEntry) GetData() string {
+	if m != nil {
+		return m.Data
+	}
+	return ""
+}
+
+func (m *NameEntry) GetExpires() int64 {
+	if m != nil {
+		return m.Expires
+	}
+	return 0
+}
+
+func (m *NameEntry) GetDataType() string {
+	if m != nil {
+		return m.DataType
+	}
+	return ""
+}
+
+type Height struct {
+	Height int64 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+}
+
+func (m *Height) Reset()                    { *m = Height{} }
+func (m *Height) String() string            { return proto.CompactTextString(m) }
+func (*Height) ProtoMessage()               {}
+func (*Height) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *Height) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+type HeightParam struct {
+	Height int64 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+}
+
+func (m *HeightParam) Reset()                    { *m = HeightParam{} }
+func (m *HeightParam) String() string            { return proto.CompactTextString(m) }
+func (*HeightParam) ProtoMessage()               {}
+func (*HeightParam) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *HeightParam) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+type BlocksParam struct {
+	FromHeight int64 `protobuf:"varint,1,opt,name=from_height,json=fromHeight" json:"from_height,omitempty"`
+}
+
+func (m *BlocksParam) Reset()                    { *m = BlocksParam{} }
+func (m *BlocksParam) String() string            { return proto.CompactTextString(m) }
+func (*BlocksParam) ProtoMessage()               {}
+func (*BlocksParam) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *BlocksParam) GetFromHeight() int64 {
+	if m != nil {
+		return m.FromHeight
+	}
+	return 0
+}
+
+type Block struct {
+	Height  int64  `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+	Hash    []byte `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	ChainId string `protobuf:"bytes,3,opt,name=chain_id,json=chainId" json:"chain_id,omitempty"`
+	// unix time in nanoseconds
+	Time          int64    `protobuf:"varint,4,opt,name=time" json:"time,omitempty"`
+	LastBlockHash []byte   `protobuf:"bytes,5,opt,name=last_block_hash,json=lastBlockHash,proto3" json:"last_block_hash,omitempty"`
+	AppHash       []byte   `protobuf:"bytes,6,opt,name=app_hash,json=appHash,proto3" json:"app_hash,omitempty"`
+	Txs           [][]byte `protobuf:"bytes,7,rep,name=txs,proto3" json:"txs,omitempty"`
+}
+
+func (m *Block) Reset()                    { *m = Block{} }
+func (m *Block) String() string            { return proto.CompactTextString(m) }
+func (*Block) ProtoMessage()               {}
+func (*Block) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *Block) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *Block) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *Block) GetChainId() string {
+	if m != nil {
+		return m.ChainId
+	}
+	return ""
+}
+
+func (m *Block) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *Block) GetLastBlockHash() []byte {
+	if m != nil {
+		return m.LastBlockHash
+	}
+	return nil
+}
+
+func (m *Block) GetAppHash() []byte {
+	if m != nil {
+		return m.AppHash
+	}
+	return nil
+}
+
+func (m *Block) GetTxs() [][]byte {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
+
+type EventsParam struct {
+	EventId      string `protobuf:"bytes,1,opt,name=event_id,json=eventId" json:"event_id,omitempty"`
+	Filter       string `protobuf:"bytes,2,opt,name=filter" json:"filter,omitempty"`
+	FromSequence uint64 `protobuf:"varint,3,opt,name=from_sequence,json=fromSequence" json:"from_sequence,omitempty"`
+	// start with the next event fired, ignoring from_sequence
+	Latest bool `protobuf:"varint,4,opt,name=latest" json:"latest,omitempty"`
+}
+
+func (m *EventsParam) Reset()                    { *m = EventsParam{} }
+func (m *EventsParam) String() string            { return proto.CompactTextString(m) }
+func (*EventsParam) ProtoMessage()               {}
+func (*EventsParam) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *EventsParam) GetEventId() string {
+	if m != nil {
+		return m.EventId
+	}
+	return ""
+}
+
+func (m *EventsParam) GetFilter() string {
+	if m != nil {
+		return m.Filter
+	}
+	return ""
+}
+
+func (m *EventsParam) GetFromSequence() uint64 {
+	if m != nil {
+		return m.FromSequence
+	}
+	return 0
+}
+
+func (m *EventsParam) GetLatest() bool {
+	if m != nil {
+		return m.Latest
+	}
+	return false
+}
+
+type Event struct {
+	Sequence uint64 `protobuf:"varint,1,opt,name=sequence" json:"sequence,omitempty"`
+	EventId  string `protobuf:"bytes,2,opt,name=event_id,json=eventId" json:"event_id,omitempty"`
+	// event data as JSON, as returned by the JSON-RPC event poll
+	Data []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *Event) Reset()                    { *m = Event{} }
+func (m *Event) String() string            { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()               {}
+func (*Event) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *Event) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *Event) GetEventId() string {
+	if m != nil {
+		return m.EventId
+	}
+	return ""
+}
+
+func (m *Event) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Empty)(nil), "burrow.Empty")
+	proto.RegisterType((*AddressParam)(nil), "burrow.AddressParam")
+	proto.RegisterType((*StorageAtParam)(nil), "burrow.StorageAtParam")
+	proto.RegisterType((*Account)(nil), "burrow.Account")
+	proto.RegisterType((*StorageItem)(nil), "burrow.StorageItem")
+	proto.RegisterType((*TxParam)(nil), "burrow.TxParam")
+	proto.RegisterType((*TxReceipt)(nil), "burrow.TxReceipt")
+	proto.RegisterType((*CallParam)(nil), "burrow.CallParam")
+	proto.RegisterType((*CallCodeParam)(nil), "burrow.CallCodeParam")
+	proto.RegisterType((*CallResult)(nil), "burrow.CallResult")
+	proto.RegisterType((*NameParam)(nil), "burrow.NameParam")
+	proto.RegisterType((*NameEntry)(nil), "burrow.NameEntry")
+	proto.RegisterType((*Height)(nil), "burrow.Height")
+	proto.RegisterType((*HeightParam)(nil), "burrow.HeightParam")
+	proto.RegisterType((*BlocksParam)(nil), "burrow.BlocksParam")
+	proto.RegisterType((*Block)(nil), "burrow.Block")
+	proto.RegisterType((*EventsParam)(nil), "burrow.EventsParam")
+	proto.RegisterType((*Event)(nil), "burrow.Event")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc1.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc1.SupportPackageIsVersion4
+
+// Client API for Accounts service
+
+type AccountsClient interface {
+	GetAccount(ctx context.Context, in *AddressParam, opts ...grpc1.CallOption) (*Account, error)
+	GetStorageAt(ctx context.Context, in *StorageAtParam, opts ...grpc1.CallOption) (*StorageItem, error)
+}
+
+type accountsClient struct {
+	cc *grpc1.ClientConn
+}
+
+func NewAccountsClient(cc *grpc1.ClientConn) AccountsClient {
+	return &accountsClient{cc}
+}
+
+func (c *accountsClient) GetAccount(ctx context.Context, in *AddressParam, opts ...grpc1.CallOption) (*Account, error) {
+	out := new(Account)
+	err := grpc1.Invoke(ctx, "/burrow.Accounts/GetAccount", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accountsClient) GetStorageAt(ctx context.Context, in *StorageAtParam, opts ...grpc1.CallOption) (*StorageItem, error) {
+	out := new(StorageItem)
+	err := grpc1.Invoke(ctx, "/burrow.Accounts/GetStorageAt", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Accounts service
+
+type AccountsServer interface {
+	GetAccount(context.Context, *AddressParam) (*Account, error)
+	GetStorageAt(context.Context, *StorageAtParam) (*StorageItem, error)
+}
+
+func RegisterAccountsServer(s *grpc1.Server, srv AccountsServer) {
+	s.RegisterService(&_Accounts_serviceDesc, srv)
+}
+
+func _Accounts_GetAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc1.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).GetAccount(ctx, in)
+	}
+	info := &grpc1.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/burrow.Accounts/GetAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).GetAccount(ctx, req.(*AddressParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Accounts_GetStorageAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc1.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageAtParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountsServer).GetStorageAt(ctx, in)
+	}
+	info := &grpc1.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/burrow.Accounts/GetStorageAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountsServer).GetStorageAt(ctx, req.(*StorageAtParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Accounts_serviceDesc = grpc1.ServiceDesc{
+	ServiceName: "burrow.Accounts",
+	HandlerType: (*AccountsServer)(nil),
+	Methods: []grpc1.MethodDesc{
+		{
+			MethodName: "GetAccount",
+			Handler:    _Accounts_GetAccount_Handler,
+		},
+		{
+			MethodName: "GetStorageAt",
+			Handler:    _Accounts_GetStorageAt_Handler,
+		},
+	},
+	Streams:  []grpc1.StreamDesc{},
+	Metadata: "burrow.proto",
+}
+
+// Client API for Transactor service
+
+type TransactorClient interface {
+	// Broadcasts a signed tx without waiting for it to be committed
+	BroadcastTx(ctx context.Context, in *TxParam, opts ...grpc1.CallOption) (*TxReceipt, error)
+	// Calls a contract against the latest state without persisting changes
+	Call(ctx context.Context, in *CallParam, opts ...grpc1.CallOption) (*CallResult, error)
+	// Runs code against the latest state without persisting changes
+	CallCode(ctx context.Context, in *CallCodeParam, opts ...grpc1.CallOption) (*CallResult, error)
+}
+
+type transactorClient struct {
+	cc *grpc1.ClientConn
+}
+
+func NewTransactorClient(cc *grpc1.ClientConn) TransactorClient {
+	return &transactorClient{cc}
+}
+
+func (c *transactorClient) BroadcastTx(ctx context.Context, in *TxParam, opts ...grpc1.CallOption) (*TxReceipt, error) {
+	out := new(TxReceipt)
+	err := grpc1.Invoke(ctx, "/burrow.Transactor/BroadcastTx", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactorClient) Call(ctx context.Context, in *CallParam, opts ...grpc1.CallOption) (*CallResult, error) {
+	out := new(CallResult)
+	err := grpc1.Invoke(ctx, "/burrow.Transactor/Call", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactorClient) CallCode(ctx context.Context, in *CallCodeParam, opts ...grpc1.CallOption) (*CallResult, error) {
+	out := new(CallResult)
+	err := grpc1.Invoke(ctx, "/burrow.Transactor/CallCode", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Transactor service
+
+type TransactorServer interface {
+	// Broadcasts a signed tx without waiting for it to be committed
+	BroadcastTx(context.Context, *TxParam) (*TxReceipt, error)
+	// Calls a contract against the latest state without persisting changes
+	Call(context.Context, *CallParam) (*CallResult, error)
+	// Runs code against the latest state without persisting changes
+	CallCode(context.Context, *CallCodeParam) (*CallResult, error)
+}
+
+func RegisterTransactorServer(s *grpc1.Server, srv TransactorServer) {
+	s.RegisterService(&_Transactor_serviceDesc, srv)
+}
+
+func _Transactor_BroadcastTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc1.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactorServer).BroadcastTx(ctx, in)
+	}
+	info := &grpc1.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/burrow.Transactor/BroadcastTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactorServer).BroadcastTx(ctx, req.(*TxParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transactor_Call_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc1.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactorServer).Call(ctx, in)
+	}
+	info := &grpc1.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/burrow.Transactor/Call",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactorServer).Call(ctx, req.(*CallParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Transactor_CallCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc1.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallCodeParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactorServer).CallCode(ctx, in)
+	}
+	info := &grpc1.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/burrow.Transactor/CallCode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactorServer).CallCode(ctx, req.(*CallCodeParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Transactor_serviceDesc = grpc1.ServiceDesc{
+	ServiceName: "burrow.Transactor",
+	HandlerType: (*TransactorServer)(nil),
+	Methods: []grpc1.MethodDesc{
+		{
+			MethodName: "BroadcastTx",
+			Handler:    _Transactor_BroadcastTx_Handler,
+		},
+		{
+			MethodName: "Call",
+			Handler:    _Transactor_Call_Handler,
+		},
+		{
+			MethodName: "CallCode",
+			Handler:    _Transactor_CallCode_Handler,
+		},
+	},
+	Streams:  []grpc1.StreamDesc{},
+	Metadata: "burrow.proto",
+}
+
+// Client API for NameReg service
+
+type NameRegClient interface {
+	GetEntry(ctx context.Context, in *NameParam, opts ...grpc1.CallOption) (*NameEntry, error)
+}
+
+type nameRegClient struct {
+	cc *grpc1.ClientConn
+}
+
+func NewNameRegClient(cc *grpc1.ClientConn) NameRegClient {
+	return &nameRegClient{cc}
+}
+
+func (c *nameRegClient) GetEntry(ctx context.Context, in *NameParam, opts ...grpc1.CallOption) (*NameEntry, error) {
+	out := new(NameEntry)
+	err := grpc1.Invoke(ctx, "/burrow.NameReg/GetEntry", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for NameReg service
+
+type NameRegServer interface {
+	GetEntry(context.Context, *NameParam) (*NameEntry, error)
+}
+
+func RegisterNameRegServer(s *grpc1.Server, srv NameRegServer) {
+	s.RegisterService(&_NameReg_serviceDesc, srv)
+}
+
+func _NameReg_GetEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc1.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NameParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NameRegServer).GetEntry(ctx, in)
+	}
+	info := &grpc1.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/burrow.NameReg/GetEntry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NameRegServer).GetEntry(ctx, req.(*NameParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _NameReg_serviceDesc = grpc1.ServiceDesc{
+	ServiceName: "burrow.NameReg",
+	HandlerType: (*NameRegServer)(nil),
+	Methods: []grpc1.MethodDesc{
+		{
+			MethodName: "GetEntry",
+			Handler:    _NameReg_GetEntry_Handler,
+		},
+	},
+	Streams:  []grpc1.StreamDesc{},
+	Metadata: "burrow.proto",
+}
+
+// Client API for Blockchain service
+
+type BlockchainClient interface {
+	GetLatestHeight(ctx context.Context, in *Empty, opts ...grpc1.CallOption) (*Height, error)
+	GetBlock(ctx context.Context, in *HeightParam, opts ...grpc1.CallOption) (*Block, error)
+	// Streams the blocks from from_height, then each new block as it is
+	// committed. A from_height of 0 starts with the next block.
+	StreamBlocks(ctx context.Context, in *BlocksParam, opts ...grpc1.CallOption) (Blockchain_StreamBlocksClient, error)
+}
+
+type blockchainClient struct {
+	cc *grpc1.ClientConn
+}
+
+func NewBlockchainClient(cc *grpc1.ClientConn) BlockchainClient {
+	return &blockchainClient{cc}
+}
+
+func (c *blockchainClient) GetLatestHeight(ctx context.Context, in *Empty, opts ...grpc1.CallOption) (*Height, error) {
+	out := new(Height)
+	err := grpc1.Invoke(ctx, "/burrow.Blockchain/GetLatestHeight", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockchainClient) GetBlock(ctx context.Context, in *HeightParam, opts ...grpc1.CallOption) (*Block, error) {
+	out := new(Block)
+	err := grpc1.Invoke(ctx, "/burrow.Blockchain/GetBlock", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockchainClient) StreamBlocks(ctx context.Context, in *BlocksParam, opts ...grpc1.CallOption) (Blockchain_StreamBlocksClient, error) {
+	stream, err := grpc1.NewClientStream(ctx, &_Blockchain_serviceDesc.Streams[0], c.cc, "/burrow.Blockchain/StreamBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blockchainStreamBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Blockchain_StreamBlocksClient interface {
+	Recv() (*Block, error)
+	grpc1.ClientStream
+}
+
+type blockchainStreamBlocksClient struct {
+	grpc1.ClientStream
+}
+
+func (x *blockchainStreamBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Blockchain service
+
+type BlockchainServer interface {
+	GetLatestHeight(context.Context, *Empty) (*Height, error)
+	GetBlock(context.Context, *HeightParam) (*Block, error)
+	// Streams the blocks from from_height, then each new block as it is
+	// committed. A from_height of 0 starts with the next block.
+	StreamBlocks(*BlocksParam, Blockchain_StreamBlocksServer) error
+}
+
+func RegisterBlockchainServer(s *grpc1.Server, srv BlockchainServer) {
+	s.RegisterService(&_Blockchain_serviceDesc, srv)
+}
+
+func _Blockchain_GetLatestHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc1.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockchainServer).GetLatestHeight(ctx, in)
+	}
+	info := &grpc1.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/burrow.Blockchain/GetLatestHeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockchainServer).GetLatestHeight(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Blockchain_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc1.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeightParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockchainServer).GetBlock(ctx, in)
+	}
+	info := &grpc1.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/burrow.Blockchain/GetBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockchainServer).GetBlock(ctx, req.(*HeightParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Blockchain_StreamBlocks_Handler(srv interface{}, stream grpc1.ServerStream) error {
+	m := new(BlocksParam)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlockchainServer).StreamBlocks(m, &blockchainStreamBlocksServer{stream})
+}
+
+type Blockchain_StreamBlocksServer interface {
+	Send(*Block) error
+	grpc1.ServerStream
+}
+
+type blockchainStreamBlocksServer struct {
+	grpc1.ServerStream
+}
+
+func (x *blockchainStreamBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Blockchain_serviceDesc = grpc1.ServiceDesc{
+	ServiceName: "burrow.Blockchain",
+	HandlerType: (*BlockchainServer)(nil),
+	Methods: []grpc1.MethodDesc{
+		{
+			MethodName: "GetLatestHeight",
+			Handler:    _Blockchain_GetLatestHeight_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _Blockchain_GetBlock_Handler,
+		},
+	},
+	Streams: []grpc1.StreamDesc{
+		{
+			StreamName:    "StreamBlocks",
+			Handler:       _Blockchain_StreamBlocks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "burrow.proto",
+}
+
+// Client API for Events service
+
+type EventsClient interface {
+	// Streams the journaled events with event_id and/or matching filter, which
+	// uses the query syntax of event subscriptions. The stream resumes from the
+	// event with from_sequence, where 0 is the oldest event the journal keeps as
+	// for the JSON-RPC and REST subscriptions, unless latest is set.
+	Stream(ctx context.Context, in *EventsParam, opts ...grpc1.CallOption) (Events_StreamClient, error)
+}
+
+type eventsClient struct {
+	cc *grpc1.ClientConn
+}
+
+func NewEventsClient(cc *grpc1.ClientConn) EventsClient {
+	return &eventsClient{cc}
+}
+
+func (c *eventsClient) Stream(ctx context.Context, in *EventsParam, opts ...grpc1.CallOption) (Events_StreamClient, error) {
+	stream, err := grpc1.NewClientStream(ctx, &_Events_serviceDesc.Streams[0], c.cc, "/burrow.Events/Stream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventsStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Events_StreamClient interface {
+	Recv() (*Event, error)
+	grpc1.ClientStream
+}
+
+type eventsStreamClient struct {
+	grpc1.ClientStream
+}
+
+func (x *eventsStreamClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Events service
+
+type EventsServer interface {
+	// Streams the journaled events with event_id and/or matching filter, which
+	// uses the query syntax of event subscriptions. The stream resumes from the
+	// event with from_sequence, where 0 is the oldest event the journal keeps as
+	// for the JSON-RPC and REST subscriptions, unless latest is set.
+	Stream(*EventsParam, Events_StreamServer) error
+}
+
+func RegisterEventsServer(s *grpc1.Server, srv EventsServer) {
+	s.RegisterService(&_Events_serviceDesc, srv)
+}
+
+func _Events_Stream_Handler(srv interface{}, stream grpc1.ServerStream) error {
+	m := new(EventsParam)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventsServer).Stream(m, &eventsStreamServer{stream})
+}
+
+type Events_StreamServer interface {
+	Send(*Event) error
+	grpc1.ServerStream
+}
+
+type eventsStreamServer struct {
+	grpc1.ServerStream
+}
+
+func (x *eventsStreamServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Events_serviceDesc = grpc1.ServiceDesc{
+	ServiceName: "burrow.Events",
+	HandlerType: (*EventsServer)(nil),
+	Methods:     []grpc1.MethodDesc{},
+	Streams: []grpc1.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _Events_Stream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "burrow.proto",
+}
+
+func init() { proto.RegisterFile("burrow.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 912 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0x5f, 0x6f, 0xe3, 0x44,
+	0x10, 0x97, 0xe3, 0xd4, 0x76, 0x26, 0x49, 0x53, 0xf6, 0x8e, 0x23, 0x0d, 0x0f, 0x57, 0x8c, 0x40,
+	0xe5, 0xe1, 0xa2, 0x92, 0xe3, 0x24, 0xa4, 0x43, 0x42, 0x6d, 0x55, 0xb5, 0x15, 0x7f, 0x84, 0xdc,
+	0xf0, 0xc2, 0x8b, 0xb5, 0xb5, 0xf7, 0x92, 0xe8, 0x1c, 0xdb, 0xec, 0x4e, 0xee, 0x1c, 0x09, 0x24,
+	0x3e, 0x02, 0x1f, 0x02, 0x1e, 0xf8, 0x04, 0x7c, 0x3d, 0xb4, 0xb3, 0x6b, 0x37, 0x3e, 0x5a, 0x78,
+	0x9b, 0xdf, 0xec, 0x6f, 0x66, 0x67, 0x76, 0xfe, 0x2c, 0x0c, 0x6e, 0x37, 0x52, 0x16, 0x6f, 0xa7,
+	0xa5, 0x2c, 0xb0, 0x60, 0x9e, 0x41, 0xa1, 0x0f, 0x7b, 0x17, 0xeb, 0x12, 0xb7, 0xe1, 0x31, 0x0c,
+	0x4e, 0xd3, 0x54, 0x0a, 0xa5, 0x7e, 0xe0, 0x92, 0xaf, 0xd9, 0x18, 0x7c, 0x6e, 0xf0, 0xd8, 0x39,
+	0x72, 0x8e, 0x07, 0x51, 0x0d, 0xc3, 0xaf, 0x60, 0xff, 0x06, 0x0b, 0xc9, 0x17, 0xe2, 0x14, 0xff,
+	0x87, 0xcb, 0x0e, 0xc0, 0x7d, 0x2d, 0xb6, 0xe3, 0x0e, 0x69, 0xb5, 0x18, 0xfe, 0xee, 0x80, 0x7f,
+	0x9a, 0x24, 0xc5, 0x26, 0xc7, 0xff, 0xb0, 0x9b, 0x40, 0xa0, 0xc4, 0xcf, 0x1b, 0x91, 0x27, 0x82,
+	0x8c, 0xdd, 0xa8, 0xc1, 0xda, 0xea, 0x96, 0x67, 0x5c, 0x1f, 0xb9, 0x74, 0x54, 0x43, 0xc6, 0xa0,
+	0x9b, 0x14, 0xa9, 0x18, 0x77, 0xc9, 0x19, 0xc9, 0xec, 0x23, 0x18, 0x28, 0x13, 0x6d, 0x2c, 0x8b,
+	0x02, 0xc7, 0x7b, 0x74, 0xd6, 0xb7, 0xba, 0xa8, 0x28, 0x30, 0x7c, 0x01, 0x7d, 0x9b, 0xd0, 0x35,
+	0x8a, 0x75, 0x1d, 0xb3, 0xd3, 0xc4, 0xcc, 0x1e, 0xc3, 0xde, 0x1b, 0x9e, 0x6d, 0x84, 0xcd, 0xc3,
+	0x80, 0xf0, 0x10, 0xfc, 0x79, 0x65, 0x1e, 0x60, 0x1f, 0x3a, 0x58, 0x59, 0x8b, 0x0e, 0x56, 0xe1,
+	0x5f, 0x0e, 0xf4, 0xe6, 0x55, 0x24, 0x12, 0xb1, 0x2a, 0x91, 0x7d, 0x00, 0x3e, 0x56, 0xf1, 0x92,
+	0xab, 0xa5, 0xa5, 0x78, 0x58, 0x5d, 0x71, 0xb5, 0x64, 0x9f, 0xc1, 0x41, 0x22, 0x05, 0x47, 0xa1,
+	0xe2, 0xa4, 0xc8, 0x51, 0xf2, 0x04, 0xe9, 0x8a, 0x20, 0x1a, 0x59, 0xfd, 0xb9, 0x55, 0x13, 0xd5,
+	0xca, 0x71, 0xfd, 0x66, 0x2e, 0x39, 0x1b, 0xd5, 0x7a, 0x5b, 0x3e, 0xf6, 0x0c, 0x02, 0x69, 0x6e,
+	0x56, 0xe3, 0xee, 0x91, 0x7b, 0xdc, 0x9f, 0xbd, 0x37, 0xb5, 0xb5, 0x6f, 0x62, 0x8a, 0x1a, 0x4a,
+	0xf8, 0x1d, 0xf4, 0xce, 0x79, 0x96, 0x99, 0x44, 0x18, 0x74, 0x5f, 0xc9, 0x62, 0x6d, 0xe3, 0x24,
+	0x79, 0xb7, 0x4a, 0x9d, 0x76, 0x95, 0x18, 0x74, 0x53, 0x8e, 0xdc, 0x06, 0x42, 0x72, 0xf8, 0x0d,
+	0x0c, 0xb5, 0xbb, 0xf3, 0x22, 0x15, 0x0f, 0xbb, 0xac, 0x0b, 0xd5, 0xd9, 0x29, 0xd4, 0x7d, 0xce,
+	0xbe, 0x06, 0xd0, 0xce, 0x22, 0xa1, 0x36, 0x19, 0xb2, 0x27, 0xe0, 0x49, 0x81, 0x1b, 0x99, 0xd7,
+	0xcf, 0x68, 0x10, 0x3b, 0x84, 0x60, 0xc1, 0x55, 0xbc, 0x51, 0x22, 0xb5, 0xcd, 0xe2, 0x2f, 0xb8,
+	0xfa, 0x51, 0x89, 0x34, 0x7c, 0x0a, 0xbd, 0xef, 0xf9, 0xfa, 0x2e, 0x92, 0x9c, 0xaf, 0x05, 0x59,
+	0xf7, 0x22, 0x92, 0xc3, 0xdf, 0x1c, 0xc3, 0xb8, 0xc8, 0x51, 0x6e, 0xef, 0x63, 0xe8, 0xe2, 0x17,
+	0x6f, 0x73, 0x21, 0xeb, 0xe2, 0x13, 0x68, 0x45, 0xdb, 0x33, 0xd1, 0xea, 0x87, 0x12, 0x55, 0xb9,
+	0x92, 0x42, 0x51, 0x07, 0xba, 0x51, 0x0d, 0xd9, 0x87, 0xd0, 0xd3, 0x8c, 0x18, 0xb7, 0xa5, 0xa0,
+	0x0e, 0xec, 0x45, 0x81, 0x56, 0xcc, 0xb7, 0xa5, 0x08, 0x8f, 0xc0, 0xbb, 0x12, 0xab, 0xc5, 0x92,
+	0x12, 0x5c, 0x92, 0x44, 0x01, 0xb8, 0x91, 0x45, 0xe1, 0x27, 0xd0, 0x37, 0x0c, 0x93, 0xc7, 0x43,
+	0xb4, 0x29, 0xf4, 0xcf, 0xb2, 0x22, 0x79, 0x6d, 0x27, 0xf8, 0x29, 0xf4, 0xf5, 0x63, 0xc7, 0x2d,
+	0x2e, 0x68, 0x95, 0x71, 0x16, 0xfe, 0xed, 0xc0, 0x1e, 0x19, 0x3c, 0xe4, 0x51, 0x67, 0x49, 0x6d,
+	0x6b, 0xeb, 0xa4, 0x65, 0xfd, 0xda, 0xc9, 0x92, 0xaf, 0xf2, 0x78, 0x95, 0xda, 0xec, 0x7d, 0xc2,
+	0xd7, 0xa9, 0xa6, 0xe3, 0x6a, 0x2d, 0x6c, 0xf6, 0x24, 0xb3, 0x4f, 0x61, 0x94, 0x71, 0x85, 0xf1,
+	0xad, 0xbe, 0xc8, 0x0c, 0x81, 0x19, 0xc1, 0xa1, 0x56, 0xd3, 0xf5, 0x57, 0xd6, 0x2d, 0x2f, 0x4b,
+	0x43, 0xf0, 0x6c, 0x9b, 0x95, 0x25, 0x1d, 0x1d, 0x80, 0x8b, 0x95, 0x1a, 0xfb, 0x47, 0xae, 0x1e,
+	0x48, 0xac, 0x54, 0xf8, 0x2b, 0xf4, 0x2f, 0xde, 0x88, 0x1c, 0x6d, 0xa6, 0x87, 0x10, 0x08, 0x0d,
+	0x75, 0x48, 0xa6, 0x74, 0x3e, 0xe1, 0xeb, 0x54, 0x67, 0xf6, 0x6a, 0x95, 0xa1, 0x2d, 0x5f, 0x2f,
+	0xb2, 0x88, 0x7d, 0x0c, 0x43, 0x7a, 0x9c, 0x66, 0xcb, 0xe8, 0x54, 0xba, 0xd1, 0x40, 0x2b, 0x6f,
+	0xac, 0x4e, 0x1b, 0x67, 0x7a, 0x0a, 0x91, 0x32, 0x0a, 0x22, 0x8b, 0xc2, 0x08, 0xf6, 0xe8, 0xfa,
+	0xd6, 0x9a, 0x72, 0xc8, 0x41, 0x83, 0x5b, 0x41, 0x75, 0xda, 0x41, 0xdd, 0xd3, 0xea, 0xb3, 0x5f,
+	0x20, 0xb0, 0x6b, 0x51, 0xb1, 0xe7, 0x00, 0x97, 0x02, 0x2d, 0x64, 0x8f, 0xeb, 0xe9, 0xdd, 0xdd,
+	0xcf, 0x93, 0x51, 0xa3, 0xb5, 0xb4, 0x97, 0x30, 0xb8, 0x14, 0xd8, 0x6c, 0x66, 0xf6, 0xa4, 0x26,
+	0xb4, 0x97, 0xf5, 0xe4, 0xd1, 0x3b, 0x7a, 0xbd, 0xf3, 0x66, 0x7f, 0x3a, 0x00, 0x73, 0xc9, 0x73,
+	0xc5, 0x13, 0x2c, 0x24, 0xfb, 0x1c, 0xfa, 0x67, 0xb2, 0xe0, 0x69, 0xc2, 0x15, 0xce, 0x2b, 0x36,
+	0xba, 0xdb, 0x1f, 0xc6, 0xc7, 0xbf, 0x17, 0x0a, 0x7b, 0x06, 0x5d, 0x3d, 0xaa, 0xac, 0x39, 0x6a,
+	0x96, 0xca, 0x84, 0xed, 0xaa, 0xec, 0x2c, 0xbf, 0x80, 0xa0, 0x5e, 0x13, 0xec, 0xfd, 0xdd, 0xf3,
+	0x66, 0x71, 0xdc, 0x67, 0x36, 0x7b, 0x09, 0xbe, 0x9e, 0xd6, 0x48, 0x2c, 0xd8, 0x09, 0x04, 0x97,
+	0x02, 0xcd, 0xdc, 0x36, 0x97, 0x36, 0xc3, 0x3e, 0x69, 0xa9, 0x88, 0x35, 0xfb, 0xc3, 0x01, 0xa0,
+	0x86, 0xa3, 0x7e, 0x65, 0x27, 0x30, 0xba, 0x14, 0xf8, 0x2d, 0x95, 0xd4, 0x0e, 0xe0, 0xb0, 0x36,
+	0xa2, 0x3f, 0x71, 0xb2, 0x5f, 0x43, 0x7b, 0x3c, 0xa5, 0x2b, 0xcd, 0xc8, 0x3c, 0x6a, 0x9f, 0x99,
+	0x4b, 0x1b, 0x7b, 0xc3, 0xf9, 0x02, 0x06, 0x37, 0x28, 0x05, 0x5f, 0x13, 0x54, 0x77, 0x36, 0x3b,
+	0x63, 0xfa, 0x8e, 0xcd, 0x89, 0x33, 0xfb, 0x12, 0x3c, 0xd3, 0xdc, 0x6c, 0x0a, 0x9e, 0xb1, 0xbf,
+	0xb3, 0xdc, 0x69, 0xfb, 0xc9, 0xb0, 0xa5, 0x3c, 0x71, 0xce, 0xbc, 0x9f, 0xba, 0x0b, 0x59, 0x26,
+	0xb7, 0x1e, 0xfd, 0xf1, 0xcf, 0xff, 0x19, 0x00, 0xa4, 0xef, 0x0d, 0x00, 0xf3, 0x07, 0x00, 0x00,
+}
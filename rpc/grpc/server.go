@@ -0,0 +1,104 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// burrow.pb.go is generated from burrow.proto by protoc-gen-go at the version
+// of github.com/golang/protobuf in glide.lock
+//go:generate protoc --go_out=plugins=grpc:. burrow.proto
+
+package grpc
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	definitions "github.com/hyperledger/burrow/definitions"
+	server "github.com/hyperledger/burrow/server"
+
+	google_grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Serves the gRPC API of burrow.proto over the pipe on its own listener,
+// since gRPC needs HTTP/2 and can not share the gin router of rpc/v0.
+type GrpcServer struct {
+	server   *google_grpc.Server
+	listener net.Listener
+}
+
+// Starts listening on [servers.grpc] listen_address, using the TLS
+// certificate of the gateway if TLS is enabled. Calls are authenticated and
+// authorized by auth and rate limited by limiter, which are nil when not
+// enabled.
+func NewGrpcServer(config *server.ServerConfig, pipe definitions.Pipe,
+	auth *server.Auth, limiter *server.RateLimiter) (*GrpcServer, error) {
+	if config.GRPC.ListenAddress == "" {
+		return nil, fmt.Errorf("No gRPC listening address provided in " +
+			"[servers.grpc.listen_address] in configuration file")
+	}
+	interceptor := &interceptor{auth: auth, limiter: limiter}
+	options := []google_grpc.ServerOption{
+		google_grpc.UnaryInterceptor(interceptor.unary),
+		google_grpc.StreamInterceptor(interceptor.stream),
+	}
+	if config.TLS.TLS {
+		tlsConfig := &tls.Config{}
+		cert, err := tls.LoadX509KeyPair(config.TLS.CertPath, config.TLS.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load TLS credentials for gRPC: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		// as for the HTTP gateway, client certificates are asked for but not
+		// required, leaving callers without one to the other credentials
+		tlsConfig.ClientCAs, err = server.ClientCAs(config)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig.ClientCAs != nil {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+		options = append(options, google_grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	listener, err := net.Listen("tcp", config.GRPC.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to listen for gRPC on %s: %v",
+			config.GRPC.ListenAddress, err)
+	}
+	grpcServer := google_grpc.NewServer(options...)
+	RegisterServices(grpcServer, pipe)
+	go grpcServer.Serve(listener)
+	return &GrpcServer{
+		server:   grpcServer,
+		listener: listener,
+	}, nil
+}
+
+// Registers every service of burrow.proto, backed by pipe
+func RegisterServices(grpcServer *google_grpc.Server, pipe definitions.Pipe) {
+	RegisterAccountsServer(grpcServer, &accountsService{pipe})
+	RegisterTransactorServer(grpcServer, &transactorService{pipe})
+	RegisterNameRegServer(grpcServer, &nameRegService{pipe})
+	RegisterBlockchainServer(grpcServer, &blockchainService{pipe})
+	RegisterEventsServer(grpcServer, &eventsService{pipe})
+}
+
+// Address the server is listening on
+func (grpcServer *GrpcServer) Addr() net.Addr {
+	return grpcServer.listener.Addr()
+}
+
+// Stops the server, closing its listener and any open streams
+func (grpcServer *GrpcServer) Shutdown() {
+	grpcServer.server.Stop()
+}
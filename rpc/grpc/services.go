@@ -0,0 +1,290 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"encoding/hex"
+	"time"
+
+	definitions "github.com/hyperledger/burrow/definitions"
+	"github.com/hyperledger/burrow/event"
+	"github.com/hyperledger/burrow/txs"
+
+	"github.com/tendermint/go-wire"
+	tm_types "github.com/tendermint/tendermint/types"
+	context "golang.org/x/net/context"
+	google_grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	addressLength = 20
+	// how often a block stream checks for new blocks
+	blockPollInterval = 250 * time.Millisecond
+	// most events read from the journal at a time by an event stream
+	maxStreamEvents = 1000
+)
+
+// *************************************** Accounts ************************************
+
+type accountsService struct {
+	pipe definitions.Pipe
+}
+
+func (service *accountsService) GetAccount(ctx context.Context,
+	param *AddressParam) (*Account, error) {
+	if len(param.Address) != addressLength {
+		return nil, errInvalidAddress(param.Address)
+	}
+	acc, err := service.pipe.Accounts().Account(param.Address)
+	if err != nil {
+		return nil, google_grpc.Errorf(codes.Internal, err.Error())
+	}
+	if acc == nil {
+		return &Account{Address: param.Address}, nil
+	}
+	return &Account{
+		Address:     acc.Address,
+		Sequence:    int64(acc.Sequence),
+		Balance:     acc.Balance,
+		Code:        acc.Code,
+		StorageRoot: acc.StorageRoot,
+	}, nil
+}
+
+func (service *accountsService) GetStorageAt(ctx context.Context,
+	param *StorageAtParam) (*StorageItem, error) {
+	if len(param.Address) != addressLength {
+		return nil, errInvalidAddress(param.Address)
+	}
+	item, err := service.pipe.Accounts().StorageAt(param.Address, param.Key)
+	if err != nil {
+		return nil, google_grpc.Errorf(codes.Internal, err.Error())
+	}
+	return &StorageItem{Key: item.Key, Value: item.Value}, nil
+}
+
+// *************************************** Transactor ************************************
+
+type transactorService struct {
+	pipe definitions.Pipe
+}
+
+func (service *transactorService) BroadcastTx(ctx context.Context,
+	param *TxParam) (*TxReceipt, error) {
+	tx, err := txs.DecodeTx(param.Tx)
+	if err != nil {
+		return nil, google_grpc.Errorf(codes.InvalidArgument,
+			"Could not decode tx: %v", err)
+	}
+	receipt, err := service.pipe.Transactor().BroadcastTx(tx)
+	if err != nil {
+		return nil, google_grpc.Errorf(codes.Internal, err.Error())
+	}
+	return newTxReceipt(receipt), nil
+}
+
+func newTxReceipt(receipt *txs.Receipt) *TxReceipt {
+	txReceipt := &TxReceipt{
+		TxHash:          receipt.TxHash,
+		CreatesContract: receipt.CreatesContract == 1,
+		ContractAddress: receipt.ContractAddr,
+	}
+	for i := range receipt.Receipts {
+		txReceipt.Receipts = append(txReceipt.Receipts,
+			newTxReceipt(&receipt.Receipts[i]))
+	}
+	return txReceipt
+}
+
+func (service *transactorService) Call(ctx context.Context,
+	param *CallParam) (*CallResult, error) {
+	call, err := service.pipe.Transactor().Call(param.From, param.Address, param.Data)
+	if err != nil {
+		return nil, google_grpc.Errorf(codes.Internal, err.Error())
+	}
+	return newCallResult(call.Return, call.GasUsed)
+}
+
+func (service *transactorService) CallCode(ctx context.Context,
+	param *CallCodeParam) (*CallResult, error) {
+	call, err := service.pipe.Transactor().CallCode(param.From, param.Code, param.Data)
+	if err != nil {
+		return nil, google_grpc.Errorf(codes.Internal, err.Error())
+	}
+	return newCallResult(call.Return, call.GasUsed)
+}
+
+// The pipe returns calls hex encoded
+func newCallResult(ret string, gasUsed int64) (*CallResult, error) {
+	retBytes, err := hex.DecodeString(ret)
+	if err != nil {
+		return nil, google_grpc.Errorf(codes.Internal, err.Error())
+	}
+	return &CallResult{Return: retBytes, GasUsed: gasUsed}, nil
+}
+
+// *************************************** NameReg ************************************
+
+type nameRegService struct {
+	pipe definitions.Pipe
+}
+
+func (service *nameRegService) GetEntry(ctx context.Context,
+	param *NameParam) (*NameEntry, error) {
+	entry, err := service.pipe.NameReg().Entry(param.Name)
+	if err != nil {
+		return nil, google_grpc.Errorf(codes.NotFound, err.Error())
+	}
+	return &NameEntry{
+		Name:     entry.Name,
+		Owner:    entry.Owner,
+		Data:     entry.Data,
+		Expires:  int64(entry.Expires),
+		DataType: entry.DataType.String(),
+	}, nil
+}
+
+// *************************************** Blockchain ************************************
+
+type blockchainService struct {
+	pipe definitions.Pipe
+}
+
+func (service *blockchainService) GetLatestHeight(ctx context.Context,
+	param *Empty) (*Height, error) {
+	return &Height{Height: int64(service.pipe.Blockchain().Height())}, nil
+}
+
+func (service *blockchainService) GetBlock(ctx context.Context,
+	param *HeightParam) (*Block, error) {
+	blockchain := service.pipe.Blockchain()
+	if param.Height < 1 || int(param.Height) > blockchain.Height() {
+		return nil, google_grpc.Errorf(codes.NotFound, "No block at height %v",
+			param.Height)
+	}
+	block := blockchain.Block(int(param.Height))
+	if block == nil {
+		return nil, google_grpc.Errorf(codes.NotFound, "No block at height %v",
+			param.Height)
+	}
+	return newBlock(block), nil
+}
+
+// Blocks are committed by consensus, which does not fire its events through
+// the pipe, so the stream checks the height of the chain for new blocks.
+func (service *blockchainService) StreamBlocks(param *BlocksParam,
+	stream Blockchain_StreamBlocksServer) error {
+	blockchain := service.pipe.Blockchain()
+	height := int(param.FromHeight)
+	if height < 1 {
+		height = blockchain.Height() + 1
+	}
+	ticker := time.NewTicker(blockPollInterval)
+	defer ticker.Stop()
+	for {
+		for ; height <= blockchain.Height(); height++ {
+			block := blockchain.Block(height)
+			if block == nil {
+				return google_grpc.Errorf(codes.NotFound, "No block at height %v",
+					height)
+			}
+			if err := stream.Send(newBlock(block)); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func newBlock(block *tm_types.Block) *Block {
+	blockTxs := make([][]byte, len(block.Data.Txs))
+	for i, tx := range block.Data.Txs {
+		blockTxs[i] = tx
+	}
+	return &Block{
+		Height:        int64(block.Height),
+		Hash:          block.Hash(),
+		ChainId:       block.ChainID,
+		Time:          block.Time.UnixNano(),
+		LastBlockHash: block.LastBlockID.Hash,
+		AppHash:       block.AppHash,
+		Txs:           blockTxs,
+	}
+}
+
+// *************************************** Events ************************************
+
+type eventsService struct {
+	pipe definitions.Pipe
+}
+
+// Events are streamed from the journal, waiting for it to be appended to
+// once the stream has caught up.
+func (service *eventsService) Stream(param *EventsParam,
+	stream Events_StreamServer) error {
+	journal := service.pipe.EventJournal()
+	if journal == nil {
+		return google_grpc.Errorf(codes.Unavailable, "Events can not be "+
+			"streamed since the event journal is not enabled")
+	}
+	filter, err := event.NewJournalFilter(param.EventId, param.Filter)
+	if err != nil {
+		return google_grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+	fromSequence := param.FromSequence
+	if param.Latest {
+		fromSequence = journal.LastSequence() + 1
+	}
+	for {
+		appended := journal.Appended()
+		entries, nextSequence, err := journal.EntriesMatching(filter, fromSequence,
+			maxStreamEvents)
+		if err != nil {
+			if _, ok := err.(event.ErrEventsDropped); ok {
+				return google_grpc.Errorf(codes.OutOfRange, err.Error())
+			}
+			return google_grpc.Errorf(codes.Internal, err.Error())
+		}
+		for _, entry := range entries {
+			err := stream.Send(&Event{
+				Sequence: entry.Sequence,
+				EventId:  entry.EventId,
+				Data:     wire.JSONBytes(entry.Data),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		fromSequence = nextSequence
+		if fromSequence <= journal.LastSequence() {
+			continue
+		}
+		select {
+		case <-appended:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func errInvalidAddress(address []byte) error {
+	return google_grpc.Errorf(codes.InvalidArgument,
+		"Address %X is not %v bytes long", address, addressLength)
+}
@@ -0,0 +1,370 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	account "github.com/hyperledger/burrow/account"
+	blockchain_types "github.com/hyperledger/burrow/blockchain/types"
+	core_types "github.com/hyperledger/burrow/core/types"
+	definitions "github.com/hyperledger/burrow/definitions"
+	"github.com/hyperledger/burrow/event"
+	server "github.com/hyperledger/burrow/server"
+	"github.com/hyperledger/burrow/txs"
+
+	"github.com/stretchr/testify/assert"
+	dbm "github.com/tendermint/go-db"
+	tm_types "github.com/tendermint/tendermint/types"
+	context "golang.org/x/net/context"
+	google_grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// Only the parts of the pipe used by the services are implemented, the
+// embedded interfaces are nil
+type mockPipe struct {
+	definitions.Pipe
+	accounts   *mockAccounts
+	blockchain *mockBlockchain
+	transactor *mockTransactor
+	journal    *event.EventJournal
+}
+
+func (pipe *mockPipe) Accounts() definitions.Accounts          { return pipe.accounts }
+func (pipe *mockPipe) Blockchain() blockchain_types.Blockchain { return pipe.blockchain }
+func (pipe *mockPipe) EventJournal() *event.EventJournal       { return pipe.journal }
+func (pipe *mockPipe) Transactor() definitions.Transactor      { return pipe.transactor }
+
+type mockAccounts struct {
+	definitions.Accounts
+	accounts map[string]*account.Account
+}
+
+func (accounts *mockAccounts) Account(address []byte) (*account.Account, error) {
+	return accounts.accounts[string(address)], nil
+}
+
+// Calls signal running, and are then held until release is closed
+type mockTransactor struct {
+	definitions.Transactor
+	running chan struct{}
+	release chan struct{}
+}
+
+func (transactor *mockTransactor) Call(fromAddress, toAddress,
+	data []byte) (*core_types.Call, error) {
+	transactor.running <- struct{}{}
+	<-transactor.release
+	return &core_types.Call{Return: "01", GasUsed: 1}, nil
+}
+
+type mockBlockchain struct {
+	sync.Mutex
+	chainId string
+	blocks  []*tm_types.Block
+}
+
+func (blockchain *mockBlockchain) BlockMeta(height int) *tm_types.BlockMeta { return nil }
+func (blockchain *mockBlockchain) ChainId() string                          { return blockchain.chainId }
+
+func (blockchain *mockBlockchain) Height() int {
+	blockchain.Lock()
+	defer blockchain.Unlock()
+	return len(blockchain.blocks)
+}
+
+func (blockchain *mockBlockchain) Block(height int) *tm_types.Block {
+	blockchain.Lock()
+	defer blockchain.Unlock()
+	if height < 1 || height > len(blockchain.blocks) {
+		return nil
+	}
+	return blockchain.blocks[height-1]
+}
+
+func (blockchain *mockBlockchain) append(block *tm_types.Block) {
+	blockchain.Lock()
+	defer blockchain.Unlock()
+	blockchain.blocks = append(blockchain.blocks, block)
+}
+
+var testAddress = []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+
+func testBlock(height int) *tm_types.Block {
+	return &tm_types.Block{
+		Header: &tm_types.Header{
+			ChainID: "grpc_test_chain",
+			Height:  height,
+			Time:    time.Unix(int64(1000+height), 0),
+		},
+		Data:       &tm_types.Data{Txs: tm_types.Txs{tm_types.Tx{byte(height)}}},
+		LastCommit: &tm_types.Commit{},
+	}
+}
+
+// Starts a server over a mock pipe and returns a connection to it
+func startTestServer(t *testing.T) (*mockPipe, *GrpcServer, *google_grpc.ClientConn) {
+	return startConfiguredTestServer(t, server.DefaultServerConfig())
+}
+
+func startConfiguredTestServer(t *testing.T, config *server.ServerConfig) (
+	*mockPipe, *GrpcServer, *google_grpc.ClientConn) {
+	journal, err := event.NewEventJournal(dbm.NewMemDB(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipe := &mockPipe{
+		accounts: &mockAccounts{accounts: map[string]*account.Account{
+			string(testAddress): {Address: testAddress, Sequence: 4, Balance: 100},
+		}},
+		blockchain: &mockBlockchain{
+			chainId: "grpc_test_chain",
+			blocks:  []*tm_types.Block{testBlock(1), testBlock(2)},
+		},
+		transactor: &mockTransactor{
+			running: make(chan struct{}, 1),
+			release: make(chan struct{}),
+		},
+		journal: journal,
+	}
+	config.GRPC.ListenAddress = "127.0.0.1:0"
+	auth, err := server.NewAuth(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter, err := server.NewRateLimiter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer, err := NewGrpcServer(config, pipe, auth, limiter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := google_grpc.Dial(grpcServer.Addr().String(), google_grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pipe, grpcServer, conn
+}
+
+func TestAccountsAndBlocks(t *testing.T) {
+	_, grpcServer, conn := startTestServer(t)
+	defer grpcServer.Shutdown()
+	defer conn.Close()
+	ctx := context.Background()
+
+	acc, err := NewAccountsClient(conn).GetAccount(ctx, &AddressParam{testAddress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(100), acc.Balance)
+	assert.Equal(t, int64(4), acc.Sequence)
+	_, err = NewAccountsClient(conn).GetAccount(ctx, &AddressParam{[]byte{1}})
+	assert.Equal(t, codes.InvalidArgument, google_grpc.Code(err))
+
+	blockchain := NewBlockchainClient(conn)
+	height, err := blockchain.GetLatestHeight(ctx, &Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(2), height.Height)
+	block, err := blockchain.GetBlock(ctx, &HeightParam{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(1), block.Height)
+	assert.Equal(t, [][]byte{{1}}, block.Txs)
+	_, err = blockchain.GetBlock(ctx, &HeightParam{3})
+	assert.Equal(t, codes.NotFound, google_grpc.Code(err))
+}
+
+func TestStreamBlocks(t *testing.T) {
+	pipe, grpcServer, conn := startTestServer(t)
+	defer grpcServer.Shutdown()
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := NewBlockchainClient(conn).StreamBlocks(ctx, &BlocksParam{FromHeight: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(2), block.Height)
+	// the stream picks up the new block on its next check of the height
+	pipe.blockchain.append(testBlock(3))
+	block, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(3), block.Height)
+}
+
+func TestStreamEvents(t *testing.T) {
+	pipe, grpcServer, conn := startTestServer(t)
+	defer grpcServer.Shutdown()
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logEvent := txs.EventDataLog{Data: []byte{1}, Height: 1}
+	pipe.journal.Append(1, nil, "Log/A", logEvent)
+	pipe.journal.Append(1, nil, "Log/B", logEvent)
+
+	events := NewEventsClient(conn)
+	// resume from the first event, the filter skips Log/B
+	stream, err := events.Stream(ctx, &EventsParam{Filter: "event:Log/A", FromSequence: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(1), ev.Sequence)
+	assert.Equal(t, "Log/A", ev.EventId)
+
+	// events appended later are streamed as they come
+	pipe.journal.Append(1, nil, "Log/A", logEvent)
+	ev, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(3), ev.Sequence)
+
+	// a from_sequence of 0 starts at the oldest event, as the journal does
+	stream, err = events.Stream(ctx, &EventsParam{EventId: "Log/B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(2), ev.Sequence)
+
+	// while the latest stream starts with the next event fired, which is
+	// appended until the stream has started
+	stream, err = events.Stream(ctx, &EventsParam{EventId: "Log/A", Latest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan struct{})
+	go func() {
+		for {
+			pipe.journal.Append(1, nil, "Log/A", logEvent)
+			select {
+			case <-received:
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}()
+	ev, err = stream.Recv()
+	close(received)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, ev.Sequence > 3, "expected an event appended after the stream started, got %v", ev.Sequence)
+
+	stream, err = events.Stream(ctx, &EventsParam{Filter: "value:lots"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, google_grpc.Code(err))
+}
+
+func TestAuth(t *testing.T) {
+	config := server.DefaultServerConfig()
+	config.Auth.Enable = true
+	config.Auth.APIKeys = []string{"reader:read_only", "sender:broadcast_only"}
+	_, grpcServer, conn := startConfiguredTestServer(t, config)
+	defer grpcServer.Shutdown()
+	defer conn.Close()
+	withKey := func(apiKey string) context.Context {
+		return metadata.NewContext(context.Background(),
+			metadata.Pairs("x-api-key", apiKey))
+	}
+	accounts := NewAccountsClient(conn)
+	transactor := NewTransactorClient(conn)
+
+	_, err := accounts.GetAccount(context.Background(), &AddressParam{testAddress})
+	assert.Equal(t, codes.Unauthenticated, google_grpc.Code(err))
+	_, err = accounts.GetAccount(withKey("forged"), &AddressParam{testAddress})
+	assert.Equal(t, codes.Unauthenticated, google_grpc.Code(err))
+	acc, err := accounts.GetAccount(withKey("reader"), &AddressParam{testAddress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(100), acc.Balance)
+
+	// a read only caller is refused before the tx is decoded, while an empty
+	// tx from a broadcast only caller gets as far as decoding
+	_, err = transactor.BroadcastTx(withKey("reader"), &TxParam{})
+	assert.Equal(t, codes.PermissionDenied, google_grpc.Code(err))
+	_, err = transactor.BroadcastTx(withKey("sender"), &TxParam{})
+	assert.Equal(t, codes.InvalidArgument, google_grpc.Code(err))
+
+	// streams are checked when they are opened
+	stream, err := NewBlockchainClient(conn).StreamBlocks(context.Background(),
+		&BlocksParam{FromHeight: 1})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	assert.Equal(t, codes.Unauthenticated, google_grpc.Code(err))
+}
+
+func TestRateLimit(t *testing.T) {
+	config := server.DefaultServerConfig()
+	config.RateLimit.Enable = true
+	config.RateLimit.Transact = server.Limit{Rate: 0.001, Burst: 1}
+	config.RateLimit.MaxConcurrentSimulations = 1
+	pipe, grpcServer, conn := startConfiguredTestServer(t, config)
+	defer grpcServer.Shutdown()
+	defer conn.Close()
+	ctx := context.Background()
+	transactor := NewTransactorClient(conn)
+
+	_, err := transactor.BroadcastTx(ctx, &TxParam{})
+	assert.Equal(t, codes.InvalidArgument, google_grpc.Code(err))
+	_, err = transactor.BroadcastTx(ctx, &TxParam{})
+	assert.Equal(t, codes.ResourceExhausted, google_grpc.Code(err))
+
+	// a second call is refused while the first runs in the VM, and admitted
+	// once it returns
+	done := make(chan error)
+	go func() {
+		_, err := transactor.Call(ctx, &CallParam{})
+		done <- err
+	}()
+	<-pipe.transactor.running
+	_, err = transactor.Call(ctx, &CallParam{})
+	assert.Equal(t, codes.ResourceExhausted, google_grpc.Code(err))
+	close(pipe.transactor.release)
+	assert.NoError(t, <-done)
+	result, err := transactor.Call(ctx, &CallParam{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []byte{1}, result.Return)
+}
@@ -25,6 +25,15 @@ const (
 	INVALID_PARAMS   = -32602
 	INTERNAL_ERROR   = -32603
 	PARSE_ERROR      = -32700
+	// Implementation defined server error, for callers that fail to
+	// authenticate or are not authorized to call a method
+	UNAUTHORIZED = -32001
+	// Implementation defined server error, for calls refused by a rate limit
+	// or by the cap on concurrent calls
+	RATE_LIMITED = -32005
+	// Implementation defined server error, noticing a subscriber that events
+	// were dropped because it read them too slowly
+	EVENTS_DROPPED = -32006
 )
 
 // Request and Response objects. Id is a string. Error data not used.
@@ -0,0 +1,294 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Generates OpenRPC documents, with JSON schemas of the params and results,
+// from the method tables of the RPC APIs. The schemas describe the JSON that
+// go-wire encodes, which is what both APIs speak.
+package schema
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The version of the OpenRPC specification that documents follow
+const OPENRPC_VERSION = "1.2.6"
+
+type (
+	// A method as described by the tables of an API
+	MethodSpec struct {
+		Name        string
+		Description string
+		Params      []Param
+		// How the params are passed, "by-name" (the default when empty) or
+		// "by-position"
+		ParamStructure string
+		Result         Param
+		// The least role needed to call the method
+		Role string
+		// The class the method is rate limited in
+		RateLimitClass string
+	}
+
+	// A param or result of a method
+	Param struct {
+		Name string
+		Type reflect.Type
+		// When set, the value is encoded as a go-wire interface, that is as
+		// an array of this type byte and the value
+		WireType *byte
+	}
+
+	// An OpenRPC document
+	Document struct {
+		OpenRPC    string     `json:"openrpc"`
+		Info       Info       `json:"info"`
+		Methods    []*Method  `json:"methods"`
+		Components Components `json:"components"`
+	}
+
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	Method struct {
+		Name           string               `json:"name"`
+		Description    string               `json:"description,omitempty"`
+		ParamStructure string               `json:"paramStructure"`
+		Params         []*ContentDescriptor `json:"params"`
+		Result         *ContentDescriptor   `json:"result"`
+		Role           string               `json:"x-role,omitempty"`
+		RateLimitClass string               `json:"x-rate-limit-class,omitempty"`
+	}
+
+	ContentDescriptor struct {
+		Name   string `json:"name"`
+		Schema Schema `json:"schema"`
+	}
+
+	Components struct {
+		Schemas map[string]Schema `json:"schemas"`
+	}
+
+	// A JSON schema
+	Schema map[string]interface{}
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	// Characters not allowed in the names of components
+	invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+)
+
+// Returns the params of a method that are decoded into the fields of a
+// struct, named as the fields are in JSON.
+func FieldParams(params interface{}) []Param {
+	t := reflect.TypeOf(params)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	fields := []Param{}
+	for _, field := range structFields(t) {
+		fields = append(fields, Param{Name: field.name, Type: field.Type})
+	}
+	return fields
+}
+
+// Returns the OpenRPC document of the methods. Methods are listed by name,
+// and the named structs they refer to are components of the document.
+func NewDocument(info Info, specs []MethodSpec) *Document {
+	document := &Document{
+		OpenRPC:    OPENRPC_VERSION,
+		Info:       info,
+		Methods:    make([]*Method, 0, len(specs)),
+		Components: Components{Schemas: make(map[string]Schema)},
+	}
+	sorted := make(map[string]MethodSpec, len(specs))
+	names := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		sorted[spec.Name] = spec
+		names = append(names, spec.Name)
+	}
+	sort.Strings(names)
+	generator := &generator{
+		schemas: document.Components.Schemas,
+		refs:    make(map[reflect.Type]Schema),
+	}
+	for _, name := range names {
+		spec := sorted[name]
+		paramStructure := spec.ParamStructure
+		if paramStructure == "" {
+			paramStructure = "by-name"
+		}
+		method := &Method{
+			Name:           spec.Name,
+			Description:    spec.Description,
+			ParamStructure: paramStructure,
+			Params:         make([]*ContentDescriptor, len(spec.Params)),
+			Result:         generator.contentDescriptor(spec.Result),
+			Role:           spec.Role,
+			RateLimitClass: spec.RateLimitClass,
+		}
+		for i, param := range spec.Params {
+			method.Params[i] = generator.contentDescriptor(param)
+		}
+		document.Methods = append(document.Methods, method)
+	}
+	return document
+}
+
+type generator struct {
+	// the schemas of named structs, by component name
+	schemas map[string]Schema
+	// references to the schemas of named structs
+	refs map[reflect.Type]Schema
+}
+
+func (generator *generator) contentDescriptor(param Param) *ContentDescriptor {
+	schema := generator.schema(param.Type)
+	if param.WireType != nil {
+		schema = wireSchema(Schema{"const": *param.WireType}, schema)
+	}
+	return &ContentDescriptor{Name: param.Name, Schema: schema}
+}
+
+// Returns the schema of the JSON go-wire encodes values of t as
+func (generator *generator) schema(t reflect.Type) Schema {
+	if t == nil {
+		return Schema{"type": "null"}
+	}
+	if t == timeType {
+		return Schema{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return generator.schema(t.Elem())
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		// bytes are hex strings
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "pattern": "^([0-9A-Fa-f]{2})*$"}
+		}
+		return Schema{"type": "array", "items": generator.schema(t.Elem())}
+	case reflect.Map:
+		return Schema{
+			"type":                 "object",
+			"additionalProperties": generator.schema(t.Elem()),
+		}
+	case reflect.Interface:
+		// the concrete types of interfaces are registered with go-wire,
+		// rather than declared where they can be reflected on
+		return wireSchema(Schema{"type": "integer"}, Schema{})
+	case reflect.Struct:
+		if t.Name() == "" {
+			return generator.structSchema(t)
+		}
+		if ref, ok := generator.refs[t]; ok {
+			return ref
+		}
+		name := generator.componentName(t)
+		ref := Schema{"$ref": "#/components/schemas/" + name}
+		// the reference and name are taken before the fields are reflected
+		// on, in case they refer back to t
+		generator.refs[t] = ref
+		generator.schemas[name] = nil
+		generator.schemas[name] = generator.structSchema(t)
+		return ref
+	}
+	// channels and functions are not encoded
+	return Schema{}
+}
+
+func (generator *generator) structSchema(t reflect.Type) Schema {
+	properties := make(map[string]Schema)
+	for _, field := range structFields(t) {
+		properties[field.name] = generator.schema(field.Type)
+	}
+	return Schema{"type": "object", "properties": properties}
+}
+
+// Returns the name of the component of the named struct t, which is its
+// name unless a struct of another package has taken it.
+func (generator *generator) componentName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := generator.schemas[name]; taken {
+		name = invalidNameChars.ReplaceAllString(
+			strings.Replace(t.PkgPath(), "/", ".", -1)+"."+name, "_")
+	}
+	return name
+}
+
+// Schema of a go-wire interface, encoded as an array of the type byte of its
+// concrete type and the value of that type
+func wireSchema(typeByte, value Schema) Schema {
+	return Schema{
+		"type":     "array",
+		"items":    []Schema{typeByte, value},
+		"minItems": 2,
+		"maxItems": 2,
+	}
+}
+
+type structField struct {
+	reflect.StructField
+	name string
+}
+
+// Returns the fields of a struct that are encoded, with the fields of
+// embedded structs in their place
+func structFields(t reflect.Type) []structField {
+	fields := []structField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// unexported fields have a package path
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			fields = append(fields, structFields(fieldType)...)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, structField{StructField: field, name: name})
+	}
+	return fields
+}
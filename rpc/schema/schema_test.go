@@ -0,0 +1,111 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEmbedded struct {
+	Height int `json:"height"`
+}
+
+type testNode struct {
+	testEmbedded
+	Hash     []byte      `json:"hash"`
+	Children []*testNode `json:"children"`
+	Tx       interface{} `json:"tx"`
+	Ignored  string      `json:"-"`
+	private  string
+}
+
+type testParam struct {
+	Address []byte `json:"address"`
+	Limit   int    `json:"limit"`
+}
+
+func TestFieldParams(t *testing.T) {
+	assert.Equal(t, []Param{
+		{Name: "address", Type: reflect.TypeOf([]byte{})},
+		{Name: "limit", Type: reflect.TypeOf(0)},
+	}, FieldParams(&testParam{}))
+}
+
+func TestNewDocument(t *testing.T) {
+	typeByte := byte(0x01)
+	document := NewDocument(Info{Title: "test", Version: "0.1"}, []MethodSpec{
+		{
+			Name:   "getNode",
+			Params: FieldParams(&testParam{}),
+			Result: Param{
+				Name:     "node",
+				Type:     reflect.TypeOf(&testNode{}),
+				WireType: &typeByte,
+			},
+			Role: "read_only",
+		},
+		{
+			Name:   "chainId",
+			Result: Param{Name: "chainId", Type: reflect.TypeOf("")},
+		},
+		{
+			Name:           "broadcast",
+			Params:         []Param{{Name: "tx", Type: reflect.TypeOf("")}},
+			ParamStructure: "by-position",
+			Result:         Param{Name: "hash", Type: reflect.TypeOf("")},
+		},
+	})
+	// methods are sorted by name
+	assert.Equal(t, "broadcast", document.Methods[0].Name)
+	assert.Equal(t, "chainId", document.Methods[1].Name)
+	assert.Equal(t, "getNode", document.Methods[2].Name)
+	// params are by name unless the spec says otherwise
+	assert.Equal(t, "by-position", document.Methods[0].ParamStructure)
+	assert.Equal(t, "by-name", document.Methods[2].ParamStructure)
+
+	bs, err := json.Marshal(document)
+	assert.NoError(t, err)
+	decoded := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(bs, &decoded))
+	methods := decoded["methods"].([]interface{})
+	getNode := methods[2].(map[string]interface{})
+	assert.Equal(t, "read_only", getNode["x-role"])
+	assert.Equal(t, map[string]interface{}{
+		"type":     "array",
+		"minItems": 2.0,
+		"maxItems": 2.0,
+		"items": []interface{}{
+			map[string]interface{}{"const": 1.0},
+			map[string]interface{}{"$ref": "#/components/schemas/testNode"},
+		},
+	}, getNode["result"].(map[string]interface{})["schema"])
+
+	node := document.Components.Schemas["testNode"]
+	properties := node["properties"].(map[string]Schema)
+	// embedded fields are in place, unencoded fields are left out
+	assert.Len(t, properties, 4)
+	assert.Equal(t, Schema{"type": "integer"}, properties["height"])
+	assert.Equal(t, "string", properties["hash"]["type"])
+	// recursive types refer to their component
+	assert.Equal(t, Schema{
+		"type":  "array",
+		"items": Schema{"$ref": "#/components/schemas/testNode"},
+	}, properties["children"])
+	assert.Equal(t, "array", properties["tx"]["type"])
+}
@@ -84,16 +84,34 @@ func BroadcastTx(client rpcclient.Client,
 
 }
 
-func DumpStorage(client rpcclient.Client,
-	address []byte) (*rpc_types.ResultDumpStorage, error) {
+// Returns the page of the storage of address starting at cursor, of at most
+// limit items. The first page has an empty cursor, and a limit of 0 gets the
+// default page size.
+func DumpStorage(client rpcclient.Client, address []byte, cursor string,
+	limit int) (*rpc_types.ResultDumpStorage, error) {
 	res, err := performCall(client, "dump_storage",
-		"address", address)
+		"address", address,
+		"cursor", cursor,
+		"limit", limit)
 	if err != nil {
 		return nil, err
 	}
 	return res.(*rpc_types.ResultDumpStorage), err
 }
 
+// Returns the page of accounts starting at cursor, of at most limit accounts,
+// paged as DumpStorage is
+func ListAccounts(client rpcclient.Client, cursor string,
+	limit int) (*rpc_types.ResultListAccounts, error) {
+	res, err := performCall(client, "list_accounts",
+		"cursor", cursor,
+		"limit", limit)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*rpc_types.ResultListAccounts), err
+}
+
 func GetStorage(client rpcclient.Client, address, key []byte) ([]byte, error) {
 	res, err := performCall(client, "get_storage",
 		"address", address,
@@ -137,6 +155,37 @@ func GetName(client rpcclient.Client, name string) (*core_types.NameRegEntry, er
 	return res.(*rpc_types.ResultGetName).Entry, nil
 }
 
+// Returns the page of name registry entries starting at cursor, of at most
+// limit entries, paged as DumpStorage is
+func ListNames(client rpcclient.Client, cursor string,
+	limit int) (*rpc_types.ResultListNames, error) {
+	res, err := performCall(client, "list_names",
+		"cursor", cursor,
+		"limit", limit)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*rpc_types.ResultListNames), err
+}
+
+func GetNamesByOwner(client rpcclient.Client, owner []byte) ([]*core_types.NameRegEntry, error) {
+	res, err := performCall(client, "get_names_by_owner",
+		"owner", owner)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*rpc_types.ResultListNames).Names, nil
+}
+
+func ReverseLookup(client rpcclient.Client, address []byte) ([]string, error) {
+	res, err := performCall(client, "reverse_lookup",
+		"address", address)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*rpc_types.ResultReverseLookup).Names, nil
+}
+
 func BlockchainInfo(client rpcclient.Client, minHeight,
 	maxHeight int) (*rpc_types.ResultBlockchainInfo, error) {
 	res, err := performCall(client, "blockchain",
@@ -165,6 +214,31 @@ func ListUnconfirmedTxs(client rpcclient.Client) (*rpc_types.ResultListUnconfirm
 	return res.(*rpc_types.ResultListUnconfirmedTxs), err
 }
 
+func GetChainParams(client rpcclient.Client) (*rpc_types.ResultGetChainParams, error) {
+	res, err := performCall(client, "get_chain_params")
+	if err != nil {
+		return nil, err
+	}
+	return res.(*rpc_types.ResultGetChainParams), err
+}
+
+// Returns the page of the permission changes involving address and affecting
+// permission, either of which may be empty, starting at cursor, of at most
+// limit changes
+func ListPermissionChanges(client rpcclient.Client, address []byte,
+	permission string, cursor string,
+	limit int) (*rpc_types.ResultListPermissionChanges, error) {
+	res, err := performCall(client, "list_permission_changes",
+		"address", address,
+		"permission", permission,
+		"cursor", cursor,
+		"limit", limit)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*rpc_types.ResultListPermissionChanges), err
+}
+
 func ListValidators(client rpcclient.Client) (*rpc_types.ResultListValidators, error) {
 	res, err := performCall(client, "list_validators")
 	if err != nil {
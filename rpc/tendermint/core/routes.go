@@ -16,10 +16,13 @@ package core
 
 import (
 	"fmt"
+	"reflect"
 
 	acm "github.com/hyperledger/burrow/account"
+	core_types "github.com/hyperledger/burrow/core/types"
 	"github.com/hyperledger/burrow/definitions"
 	ctypes "github.com/hyperledger/burrow/rpc/tendermint/core/types"
+	server "github.com/hyperledger/burrow/server"
 	"github.com/hyperledger/burrow/txs"
 	rpc "github.com/tendermint/go-rpc/server"
 	rpctypes "github.com/tendermint/go-rpc/types"
@@ -34,62 +37,210 @@ const maxBlockLookback = 20
 // TODO: eliminate redundancy between here and reading code from core/
 type TendermintRoutes struct {
 	tendermintPipe definitions.TendermintPipe
+	// Applied to subscriptions whose events are read slower than they are
+	// fired, once eventQueueSize of their events are queued
+	slowClientPolicy server.SlowClientPolicy
+	eventQueueSize   int
+	subscriptions    *subscriptions
 }
 
-func (tmRoutes *TendermintRoutes) GetRoutes() map[string]*rpc.RPCFunc {
-	var routes = map[string]*rpc.RPCFunc{
-		"subscribe":               rpc.NewWSRPCFunc(tmRoutes.Subscribe, "event"),
-		"unsubscribe":             rpc.NewWSRPCFunc(tmRoutes.Unsubscribe, "subscriptionId"),
-		"status":                  rpc.NewRPCFunc(tmRoutes.StatusResult, ""),
-		"net_info":                rpc.NewRPCFunc(tmRoutes.NetInfoResult, ""),
-		"genesis":                 rpc.NewRPCFunc(tmRoutes.GenesisResult, ""),
-		"chain_id":                rpc.NewRPCFunc(tmRoutes.ChainIdResult, ""),
-		"get_account":             rpc.NewRPCFunc(tmRoutes.GetAccountResult, "address"),
-		"get_storage":             rpc.NewRPCFunc(tmRoutes.GetStorageResult, "address,key"),
-		"call":                    rpc.NewRPCFunc(tmRoutes.CallResult, "fromAddress,toAddress,data"),
-		"call_code":               rpc.NewRPCFunc(tmRoutes.CallCodeResult, "fromAddress,code,data"),
-		"dump_storage":            rpc.NewRPCFunc(tmRoutes.DumpStorageResult, "address"),
-		"list_accounts":           rpc.NewRPCFunc(tmRoutes.ListAccountsResult, ""),
-		"get_name":                rpc.NewRPCFunc(tmRoutes.GetNameResult, "name"),
-		"list_names":              rpc.NewRPCFunc(tmRoutes.ListNamesResult, ""),
-		"broadcast_tx":            rpc.NewRPCFunc(tmRoutes.BroadcastTxResult, "tx"),
-		"blockchain":              rpc.NewRPCFunc(tmRoutes.BlockchainInfo, "minHeight,maxHeight"),
-		"get_block":               rpc.NewRPCFunc(tmRoutes.GetBlock, "height"),
-		"list_unconfirmed_txs":    rpc.NewRPCFunc(tmRoutes.ListUnconfirmedTxs, ""),
-		"list_validators":         rpc.NewRPCFunc(tmRoutes.ListValidators, ""),
-		"dump_consensus_state":    rpc.NewRPCFunc(tmRoutes.DumpConsensusState, ""),
-		"unsafe/gen_priv_account": rpc.NewRPCFunc(tmRoutes.GenPrivAccountResult, ""),
-		"unsafe/sign_tx":          rpc.NewRPCFunc(tmRoutes.SignTxResult, "tx,privAccounts"),
+// The least role needed to call each route when authentication is enabled.
+// The unsafe routes, which take or generate private keys, need
+// server.RoleAdmin.
+var routeRoles = server.MethodRoles{
+	"subscribe":               server.RoleReadOnly,
+	"unsubscribe":             server.RoleReadOnly,
+	"status":                  server.RoleReadOnly,
+	"net_info":                server.RoleReadOnly,
+	"genesis":                 server.RoleReadOnly,
+	"chain_id":                server.RoleReadOnly,
+	"get_chain_params":        server.RoleReadOnly,
+	"get_account":             server.RoleReadOnly,
+	"get_storage":             server.RoleReadOnly,
+	"call":                    server.RoleReadOnly,
+	"call_code":               server.RoleReadOnly,
+	"dump_storage":            server.RoleReadOnly,
+	"list_accounts":           server.RoleReadOnly,
+	"get_name":                server.RoleReadOnly,
+	"list_names":              server.RoleReadOnly,
+	"get_names_by_owner":      server.RoleReadOnly,
+	"reverse_lookup":          server.RoleReadOnly,
+	"list_permission_changes": server.RoleReadOnly,
+	"broadcast_tx":            server.RoleBroadcastOnly,
+	"blockchain":              server.RoleReadOnly,
+	"get_block":               server.RoleReadOnly,
+	"list_unconfirmed_txs":    server.RoleReadOnly,
+	"list_validators":         server.RoleReadOnly,
+	"dump_consensus_state":    server.RoleReadOnly,
+}
+
+// The class of each route when rate limiting is enabled. Routes that are not
+// listed are server.ClassRead.
+var routeClasses = server.MethodClasses{
+	"call":                    server.ClassSimulate,
+	"call_code":               server.ClassSimulate,
+	"dump_storage":            server.ClassScan,
+	"list_accounts":           server.ClassScan,
+	"list_names":              server.ClassScan,
+	"get_names_by_owner":      server.ClassScan,
+	"reverse_lookup":          server.ClassScan,
+	"list_permission_changes": server.ClassScan,
+	"blockchain":              server.ClassScan,
+	"list_unconfirmed_txs":    server.ClassScan,
+	"dump_consensus_state":    server.ClassScan,
+	"broadcast_tx":            server.ClassTransact,
+	"unsafe/sign_tx":          server.ClassTransact,
+}
+
+// The result each route returns, from which the schema of the API is
+// generated along with the params of the route functions.
+var routeResults = map[string]ctypes.BurrowResult{
+	"subscribe":               &ctypes.ResultSubscribe{},
+	"unsubscribe":             &ctypes.ResultUnsubscribe{},
+	"status":                  &ctypes.ResultStatus{},
+	"net_info":                &ctypes.ResultNetInfo{},
+	"genesis":                 &ctypes.ResultGenesis{},
+	"chain_id":                &ctypes.ResultChainId{},
+	"get_chain_params":        &ctypes.ResultGetChainParams{},
+	"get_account":             &ctypes.ResultGetAccount{},
+	"get_storage":             &ctypes.ResultGetStorage{},
+	"call":                    &ctypes.ResultCall{},
+	"call_code":               &ctypes.ResultCall{},
+	"dump_storage":            &ctypes.ResultDumpStorage{},
+	"list_accounts":           &ctypes.ResultListAccounts{},
+	"get_name":                &ctypes.ResultGetName{},
+	"list_names":              &ctypes.ResultListNames{},
+	"get_names_by_owner":      &ctypes.ResultListNames{},
+	"reverse_lookup":          &ctypes.ResultReverseLookup{},
+	"list_permission_changes": &ctypes.ResultListPermissionChanges{},
+	"broadcast_tx":            &ctypes.ResultBroadcastTx{},
+	"blockchain":              &ctypes.ResultBlockchainInfo{},
+	"get_block":               &ctypes.ResultGetBlock{},
+	"list_unconfirmed_txs":    &ctypes.ResultListUnconfirmedTxs{},
+	"list_validators":         &ctypes.ResultListValidators{},
+	"dump_consensus_state":    &ctypes.ResultDumpConsensusState{},
+	"unsafe/gen_priv_account": &ctypes.ResultGenPrivAccount{},
+	"unsafe/sign_tx":          &ctypes.ResultSignTx{},
+}
+
+// A route before it is wrapped in an RPCFunc
+type route struct {
+	f    interface{}
+	args string
+	ws   bool
+}
+
+func (tmRoutes *TendermintRoutes) routes() map[string]route {
+	return map[string]route{
+		"subscribe":               {tmRoutes.Subscribe, "event", true},
+		"unsubscribe":             {tmRoutes.Unsubscribe, "subscriptionId", true},
+		"status":                  {tmRoutes.StatusResult, "", false},
+		"net_info":                {tmRoutes.NetInfoResult, "", false},
+		"genesis":                 {tmRoutes.GenesisResult, "", false},
+		"chain_id":                {tmRoutes.ChainIdResult, "", false},
+		"get_chain_params":        {tmRoutes.GetChainParamsResult, "", false},
+		"get_account":             {tmRoutes.GetAccountResult, "address", false},
+		"get_storage":             {tmRoutes.GetStorageResult, "address,key", false},
+		"call":                    {tmRoutes.CallResult, "fromAddress,toAddress,data", false},
+		"call_code":               {tmRoutes.CallCodeResult, "fromAddress,code,data", false},
+		"dump_storage":            {tmRoutes.DumpStorageResult, "address,cursor,limit", false},
+		"list_accounts":           {tmRoutes.ListAccountsResult, "cursor,limit", false},
+		"get_name":                {tmRoutes.GetNameResult, "name", false},
+		"list_names":              {tmRoutes.ListNamesResult, "cursor,limit", false},
+		"get_names_by_owner":      {tmRoutes.GetNamesByOwnerResult, "owner", false},
+		"reverse_lookup":          {tmRoutes.ReverseLookupResult, "address", false},
+		"list_permission_changes": {tmRoutes.ListPermissionChangesResult, "address,permission,cursor,limit", false},
+		"broadcast_tx":            {tmRoutes.BroadcastTxResult, "tx", false},
+		"blockchain":              {tmRoutes.BlockchainInfo, "minHeight,maxHeight", false},
+		"get_block":               {tmRoutes.GetBlock, "height", false},
+		"list_unconfirmed_txs":    {tmRoutes.ListUnconfirmedTxs, "", false},
+		"list_validators":         {tmRoutes.ListValidators, "", false},
+		"dump_consensus_state":    {tmRoutes.DumpConsensusState, "", false},
+		"unsafe/gen_priv_account": {tmRoutes.GenPrivAccountResult, "", false},
+		"unsafe/sign_tx":          {tmRoutes.SignTxResult, "tx,privAccounts", false},
 		// TODO: [Silas] do we also carry forward "consensus_state" as in v0?
 	}
+}
+
+func (tmRoutes *TendermintRoutes) GetRoutes() map[string]*rpc.RPCFunc {
+	return tmRoutes.GetAuthorizedRoutes(nil, server.RoleAdmin, nil)
+}
+
+// Returns the routes as seen by callers with role. Routes they are not
+// authorized to call keep their params, so that calls are still parsed, but
+// return the authorization error. Simulations take a place among those
+// running from limiter, which may be nil, since the clients of calls over
+// websockets can not be told apart here.
+func (tmRoutes *TendermintRoutes) GetAuthorizedRoutes(auth *server.Auth,
+	role server.Role, limiter *server.RateLimiter) map[string]*rpc.RPCFunc {
+	routes := make(map[string]*rpc.RPCFunc)
+	for name, r := range tmRoutes.routes() {
+		f := r.f
+		if err := auth.Authorize(role, name, routeRoles); err != nil {
+			f = refuse(f, err)
+		} else if limiter != nil && routeClasses[name] == server.ClassSimulate {
+			f = capSimulations(f, limiter)
+		}
+		if r.ws {
+			routes[name] = rpc.NewWSRPCFunc(f, r.args)
+		} else {
+			routes[name] = rpc.NewRPCFunc(f, r.args)
+		}
+	}
 	return routes
 }
 
+// Returns a function of the same type as f that returns err
+func refuse(f interface{}, err error) interface{} {
+	fType := reflect.TypeOf(f)
+	return reflect.MakeFunc(fType, func([]reflect.Value) []reflect.Value {
+		return []reflect.Value{reflect.Zero(fType.Out(0)), reflect.ValueOf(&err).Elem()}
+	}).Interface()
+}
+
+// Returns a function of the same type as f that calls it once it takes a
+// place among the simulations running, or returns the error of limiter
+func capSimulations(f interface{}, limiter *server.RateLimiter) interface{} {
+	fValue := reflect.ValueOf(f)
+	fType := fValue.Type()
+	return reflect.MakeFunc(fType, func(args []reflect.Value) []reflect.Value {
+		release, err := limiter.AcquireSimulation()
+		if err != nil {
+			return []reflect.Value{reflect.Zero(fType.Out(0)), reflect.ValueOf(&err).Elem()}
+		}
+		defer release()
+		return fValue.Call(args)
+	}).Interface()
+}
+
 func (tmRoutes *TendermintRoutes) Subscribe(wsCtx rpctypes.WSRPCContext,
 	event string) (ctypes.BurrowResult, error) {
-	// NOTE: RPCResponses of subscribed events have id suffix "#event"
 	// TODO: we really ought to allow multiple subscriptions from the same client address
 	// to the same event. The code as it stands reflects the somewhat broken tendermint
 	// implementation. We can use GenerateSubId to randomize the subscriptions id
 	// and return it in the result. This would require clients to hang on to a
 	// subscription id if they wish to unsubscribe, but then again they can just
 	// drop their connection
-	result, err := tmRoutes.tendermintPipe.Subscribe(event,
-		func(result ctypes.BurrowResult) {
-			wsCtx.GetRemoteAddr()
-			// NOTE: EventSwitch callbacks must be nonblocking
-			wsCtx.TryWriteRPCResponse(
-				rpctypes.NewRPCResponse(wsCtx.Request.ID+"#event", &result, ""))
-		})
+	sub := newSubscription(wsCtx, tmRoutes.eventQueueSize,
+		tmRoutes.slowClientPolicy)
+	result, err := tmRoutes.tendermintPipe.Subscribe(event, sub.push)
 	if err != nil {
 		return nil, err
-	} else {
-		return result, nil
 	}
+	tmRoutes.subscriptions.add(result.SubscriptionId, sub)
+	// the subscription is dropped once the connection stops, unless it was
+	// unsubscribed or subscribed again first
+	go sub.forward(result.SubscriptionId, func(subscriptionId string) {
+		if tmRoutes.subscriptions.remove(subscriptionId, sub) {
+			tmRoutes.tendermintPipe.Unsubscribe(subscriptionId)
+		}
+	})
+	return result, nil
 }
 
 func (tmRoutes *TendermintRoutes) Unsubscribe(wsCtx rpctypes.WSRPCContext,
 	subscriptionId string) (ctypes.BurrowResult, error) {
+	tmRoutes.subscriptions.remove(subscriptionId, nil)
 	result, err := tmRoutes.tendermintPipe.Unsubscribe(subscriptionId)
 	if err != nil {
 		return nil, err
@@ -164,16 +315,39 @@ func (tmRoutes *TendermintRoutes) CallCodeResult(fromAddress, code,
 	}
 }
 
-func (tmRoutes *TendermintRoutes) DumpStorageResult(address []byte) (ctypes.BurrowResult, error) {
-	if r, err := tmRoutes.tendermintPipe.DumpStorage(address); err != nil {
+func (tmRoutes *TendermintRoutes) DumpStorageResult(address []byte, cursor string,
+	limit int) (ctypes.BurrowResult, error) {
+	page := &core_types.PageRequest{Cursor: cursor, Limit: limit}
+	if r, err := tmRoutes.tendermintPipe.DumpStorage(address, page); err != nil {
 		return nil, err
 	} else {
 		return r, nil
 	}
 }
 
-func (tmRoutes *TendermintRoutes) ListAccountsResult() (ctypes.BurrowResult, error) {
-	if r, err := tmRoutes.tendermintPipe.ListAccounts(); err != nil {
+func (tmRoutes *TendermintRoutes) ListAccountsResult(cursor string,
+	limit int) (ctypes.BurrowResult, error) {
+	page := &core_types.PageRequest{Cursor: cursor, Limit: limit}
+	if r, err := tmRoutes.tendermintPipe.ListAccounts(page); err != nil {
+		return nil, err
+	} else {
+		return r, nil
+	}
+}
+
+func (tmRoutes *TendermintRoutes) GetChainParamsResult() (ctypes.BurrowResult, error) {
+	if r, err := tmRoutes.tendermintPipe.GetChainParams(); err != nil {
+		return nil, err
+	} else {
+		return r, nil
+	}
+}
+
+func (tmRoutes *TendermintRoutes) ListPermissionChangesResult(address []byte,
+	permission string, cursor string, limit int) (ctypes.BurrowResult, error) {
+	page := &core_types.PageRequest{Cursor: cursor, Limit: limit}
+	if r, err := tmRoutes.tendermintPipe.ListPermissionChanges(address, permission,
+		page); err != nil {
 		return nil, err
 	} else {
 		return r, nil
@@ -188,8 +362,26 @@ func (tmRoutes *TendermintRoutes) GetNameResult(name string) (ctypes.BurrowResul
 	}
 }
 
-func (tmRoutes *TendermintRoutes) ListNamesResult() (ctypes.BurrowResult, error) {
-	if r, err := tmRoutes.tendermintPipe.ListNames(); err != nil {
+func (tmRoutes *TendermintRoutes) ListNamesResult(cursor string,
+	limit int) (ctypes.BurrowResult, error) {
+	page := &core_types.PageRequest{Cursor: cursor, Limit: limit}
+	if r, err := tmRoutes.tendermintPipe.ListNames(page); err != nil {
+		return nil, err
+	} else {
+		return r, nil
+	}
+}
+
+func (tmRoutes *TendermintRoutes) GetNamesByOwnerResult(owner []byte) (ctypes.BurrowResult, error) {
+	if r, err := tmRoutes.tendermintPipe.GetNamesByOwner(owner); err != nil {
+		return nil, err
+	} else {
+		return r, nil
+	}
+}
+
+func (tmRoutes *TendermintRoutes) ReverseLookupResult(address []byte) (ctypes.BurrowResult, error) {
+	if r, err := tmRoutes.tendermintPipe.ReverseLookup(address); err != nil {
 		return nil, err
 	} else {
 		return r, nil
@@ -0,0 +1,99 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/burrow/rpc/schema"
+	ctypes "github.com/hyperledger/burrow/rpc/tendermint/core/types"
+)
+
+// Returns the routes of the Tendermint RPC as described by its tables and the
+// params of its route functions, for generating its schema. Results are
+// go-wire interfaces, so they are prefixed with the type byte of their
+// concrete type.
+func RouteSpecs() ([]schema.MethodSpec, error) {
+	// the route functions are only reflected on, so need no pipe
+	routes := (&TendermintRoutes{}).routes()
+	specs := make([]schema.MethodSpec, 0, len(routes))
+	for name, r := range routes {
+		result, ok := routeResults[name]
+		if !ok {
+			return nil, fmt.Errorf("No result type for route %s", name)
+		}
+		typeByte, err := resultTypeByte(result)
+		if err != nil {
+			return nil, err
+		}
+		params, err := routeParams(r)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid args of route %s: %v", name, err)
+		}
+		spec := schema.MethodSpec{
+			Name:   name,
+			Params: params,
+			Result: schema.Param{
+				Name:     "result",
+				Type:     reflect.TypeOf(result),
+				WireType: &typeByte,
+			},
+			Role:           routeRoles.Role(name).String(),
+			RateLimitClass: routeClasses[name].String(),
+		}
+		if r.ws {
+			spec.Description = "Only served over the websocket."
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// Returns the params of a route, named by its args and typed by the params
+// of its function
+func routeParams(r route) ([]schema.Param, error) {
+	fType := reflect.TypeOf(r.f)
+	argNames := []string{}
+	if r.args != "" {
+		argNames = strings.Split(r.args, ",")
+	}
+	offset := 0
+	// websocket routes take the context of the connection first
+	if r.ws {
+		offset = 1
+	}
+	if fType.NumIn()-offset != len(argNames) {
+		return nil, fmt.Errorf("%v args named for %v params", len(argNames),
+			fType.NumIn()-offset)
+	}
+	params := make([]schema.Param, len(argNames))
+	for i, argName := range argNames {
+		params[i] = schema.Param{Name: argName, Type: fType.In(i + offset)}
+	}
+	return params, nil
+}
+
+func resultTypeByte(result ctypes.BurrowResult) (byte, error) {
+	resultType := reflect.TypeOf(result)
+	for _, concreteType := range ctypes.ConcreteTypes() {
+		if reflect.TypeOf(concreteType.O) == resultType {
+			return concreteType.Byte, nil
+		}
+	}
+	return 0, fmt.Errorf("Result type %v is not registered with go-wire",
+		resultType)
+}
@@ -0,0 +1,70 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	ctypes "github.com/hyperledger/burrow/rpc/tendermint/core/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The route functions all return the BurrowResult interface, so their result
+// types are kept in routeResults, which must describe every route served or
+// the schema drifts from the API
+func TestRouteResults(t *testing.T) {
+	routes := (&TendermintRoutes{}).routes()
+	for name := range routes {
+		assert.Contains(t, routeResults, name)
+	}
+	for name := range routeResults {
+		assert.Contains(t, routes, name, "result of a route that is not served")
+	}
+	// as are the tables of roles and classes
+	for name := range routeRoles {
+		assert.Contains(t, routes, name, "role of a route that is not served")
+	}
+	for name := range routeClasses {
+		assert.Contains(t, routes, name, "class of a route that is not served")
+	}
+}
+
+func TestRouteSpecs(t *testing.T) {
+	specs, err := RouteSpecs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, specs, len(routeResults))
+	for _, spec := range specs {
+		switch spec.Name {
+		case "list_accounts":
+			if assert.Len(t, spec.Params, 2) {
+				assert.Equal(t, "cursor", spec.Params[0].Name)
+				assert.Equal(t, reflect.TypeOf(0), spec.Params[1].Type)
+			}
+			assert.Equal(t, reflect.TypeOf(&ctypes.ResultListAccounts{}),
+				spec.Result.Type)
+			assert.Equal(t, "scan", spec.RateLimitClass)
+		case "subscribe":
+			// the websocket context is not a param
+			assert.Len(t, spec.Params, 1)
+			assert.NotEmpty(t, spec.Description)
+		case "unsafe/sign_tx":
+			assert.Equal(t, "admin", spec.Role)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	ctypes "github.com/hyperledger/burrow/rpc/tendermint/core/types"
+	server "github.com/hyperledger/burrow/server"
+	rpctypes "github.com/tendermint/go-rpc/types"
+)
+
+// How often a subscription checks whether its connection has stopped, when
+// it has no events to send
+const connectionCheckInterval = time.Second
+
+// The go-rpc websocket connections, which are services
+type connectionService interface {
+	Stop() bool
+	IsRunning() bool
+}
+
+// Queues the events of a subscription of a websocket connection, which are
+// sent by its own routine so that firing events never blocks on a client.
+// When the queue is full the slow client policy applies to the subscription
+// alone, since go-rpc queues the responses of a connection in order and can
+// only refuse the latest.
+type subscription struct {
+	wsCtx rpctypes.WSRPCContext
+	// nil when the connection is not a service, so can not be stopped
+	conn   connectionService
+	id     string
+	events *server.EventQueue
+	// closed once the subscription is unsubscribed
+	quit     chan struct{}
+	quitOnce sync.Once
+}
+
+func newSubscription(wsCtx rpctypes.WSRPCContext, size int,
+	policy server.SlowClientPolicy) *subscription {
+	conn, _ := wsCtx.WSRPCConnection.(connectionService)
+	return &subscription{
+		wsCtx: wsCtx,
+		conn:  conn,
+		// NOTE: RPCResponses of subscribed events have id suffix "#event"
+		id:     wsCtx.Request.ID + "#event",
+		events: server.NewEventQueue(size, policy),
+		quit:   make(chan struct{}),
+	}
+}
+
+// Queues an event, applying the policy when the queue is full. Events of a
+// stopped connection are not queued, and not counted as dropped.
+func (sub *subscription) push(result ctypes.BurrowResult) {
+	// NOTE: EventSwitch callbacks must be nonblocking
+	if !sub.running() {
+		sub.events.Signal()
+		return
+	}
+	if !sub.events.Push(sub.id, result) {
+		if sub.conn != nil && sub.conn.Stop() {
+			server.CountSlowClientDisconnect()
+		}
+		sub.events.Signal()
+	}
+}
+
+func (sub *subscription) take() ([]ctypes.BurrowResult, uint64) {
+	events, dropped := sub.events.Take()
+	results := make([]ctypes.BurrowResult, len(events))
+	for i, event := range events {
+		results[i] = event.Msg.(ctypes.BurrowResult)
+	}
+	return results, dropped[sub.id]
+}
+
+func (sub *subscription) running() bool {
+	return sub.conn == nil || sub.conn.IsRunning()
+}
+
+// Stops forwarding the events, once the subscription is unsubscribed
+func (sub *subscription) stop() {
+	sub.quitOnce.Do(func() {
+		close(sub.quit)
+	})
+}
+
+// Sends the queued events until the subscription is stopped, or until the
+// connection stops, when it unsubscribes subscriptionId. The client is sent a
+// notice of the events dropped before the events that follow them.
+func (sub *subscription) forward(subscriptionId string,
+	unsubscribe func(subscriptionId string)) {
+	ticker := time.NewTicker(connectionCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sub.quit:
+			return
+		case <-sub.events.Ready():
+		case <-ticker.C:
+		}
+		if !sub.running() {
+			unsubscribe(subscriptionId)
+			return
+		}
+		results, dropped := sub.take()
+		if dropped > 0 {
+			sub.wsCtx.WriteRPCResponse(rpctypes.NewRPCResponse(sub.id, nil,
+				fmt.Sprintf("%v events dropped, the client is reading events "+
+					"too slowly", dropped)))
+		}
+		for _, result := range results {
+			// blocks until the connection has room or stops
+			sub.wsCtx.WriteRPCResponse(rpctypes.NewRPCResponse(sub.id, &result, ""))
+		}
+	}
+}
+
+// The subscriptions being forwarded, by subscription id
+type subscriptions struct {
+	mtx  sync.Mutex
+	subs map[string]*subscription
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{subs: make(map[string]*subscription)}
+}
+
+// Adds a subscription, stopping the one it replaces
+func (subs *subscriptions) add(subscriptionId string, sub *subscription) {
+	subs.mtx.Lock()
+	defer subs.mtx.Unlock()
+	if replaced, ok := subs.subs[subscriptionId]; ok {
+		replaced.stop()
+	}
+	subs.subs[subscriptionId] = sub
+}
+
+// Removes and stops the subscription of subscriptionId, or only sub when it
+// is not nil. Returns false when there was none to remove.
+func (subs *subscriptions) remove(subscriptionId string,
+	sub *subscription) bool {
+	subs.mtx.Lock()
+	defer subs.mtx.Unlock()
+	removed, ok := subs.subs[subscriptionId]
+	if !ok || (sub != nil && removed != sub) {
+		return false
+	}
+	delete(subs.subs, subscriptionId)
+	removed.stop()
+	return true
+}
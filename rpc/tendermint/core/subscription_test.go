@@ -0,0 +1,150 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"sync"
+	"testing"
+
+	ctypes "github.com/hyperledger/burrow/rpc/tendermint/core/types"
+	server "github.com/hyperledger/burrow/server"
+
+	"github.com/stretchr/testify/assert"
+	rpctypes "github.com/tendermint/go-rpc/types"
+)
+
+// Only the parts of the connection used by subscriptions are implemented
+type mockConnection struct {
+	rpctypes.WSRPCConnection
+	mtx       sync.Mutex
+	stopped   bool
+	responses []rpctypes.RPCResponse
+}
+
+func (conn *mockConnection) WriteRPCResponse(response rpctypes.RPCResponse) {
+	conn.mtx.Lock()
+	defer conn.mtx.Unlock()
+	conn.responses = append(conn.responses, response)
+}
+
+func (conn *mockConnection) Stop() bool {
+	conn.mtx.Lock()
+	defer conn.mtx.Unlock()
+	stopped := conn.stopped
+	conn.stopped = true
+	return !stopped
+}
+
+func (conn *mockConnection) IsRunning() bool {
+	conn.mtx.Lock()
+	defer conn.mtx.Unlock()
+	return !conn.stopped
+}
+
+func testSubscription(policy server.SlowClientPolicy) (*subscription,
+	*mockConnection) {
+	conn := &mockConnection{}
+	wsCtx := rpctypes.WSRPCContext{
+		Request:         rpctypes.RPCRequest{ID: "1"},
+		WSRPCConnection: conn,
+	}
+	return newSubscription(wsCtx, 2, policy), conn
+}
+
+func result(subscriptionId string) ctypes.BurrowResult {
+	return &ctypes.ResultUnsubscribe{SubscriptionId: subscriptionId}
+}
+
+func TestSubscriptionDropOldest(t *testing.T) {
+	metrics := server.GetEventMetrics()
+	sub, _ := testSubscription(server.SlowClientDropOldest)
+	sub.push(result("a"))
+	sub.push(result("b"))
+	sub.push(result("c"))
+	results, dropped := sub.take()
+	assert.Equal(t, []ctypes.BurrowResult{result("b"), result("c")}, results)
+	assert.Equal(t, uint64(1), dropped)
+	assert.Equal(t, metrics.Dropped+1, server.GetEventMetrics().Dropped)
+}
+
+func TestSubscriptionCoalesce(t *testing.T) {
+	metrics := server.GetEventMetrics()
+	sub, _ := testSubscription(server.SlowClientCoalesce)
+	sub.push(result("a"))
+	sub.push(result("b"))
+	sub.push(result("c"))
+	results, dropped := sub.take()
+	assert.Equal(t, []ctypes.BurrowResult{result("a"), result("c")}, results)
+	assert.Equal(t, uint64(1), dropped)
+	assert.Equal(t, metrics.Coalesced+1, server.GetEventMetrics().Coalesced)
+}
+
+func TestSubscriptionDisconnect(t *testing.T) {
+	metrics := server.GetEventMetrics()
+	sub, conn := testSubscription(server.SlowClientDisconnect)
+	sub.push(result("a"))
+	sub.push(result("b"))
+	sub.push(result("c"))
+	assert.False(t, conn.IsRunning())
+	// events of the stopped connection are neither queued nor counted
+	sub.push(result("d"))
+	results, dropped := sub.take()
+	assert.Equal(t, []ctypes.BurrowResult{result("a"), result("b")}, results)
+	assert.Zero(t, dropped)
+	assert.Equal(t, metrics.Dropped, server.GetEventMetrics().Dropped)
+	assert.Equal(t, metrics.Disconnected+1, server.GetEventMetrics().Disconnected)
+}
+
+func TestSubscriptionForward(t *testing.T) {
+	sub, conn := testSubscription(server.SlowClientDropOldest)
+	unsubscribed := make(chan string)
+	go sub.forward("sub", func(subscriptionId string) {
+		unsubscribed <- subscriptionId
+	})
+	sub.push(result("a"))
+	sub.push(result("b"))
+	sub.push(result("c"))
+	// the subscription is dropped once the connection stops
+	conn.Stop()
+	sub.events.Signal()
+	assert.Equal(t, "sub", <-unsubscribed)
+	conn.mtx.Lock()
+	defer conn.mtx.Unlock()
+	for _, response := range conn.responses {
+		assert.Equal(t, "1#event", response.ID)
+	}
+}
+
+func TestSubscriptionsRemove(t *testing.T) {
+	subs := newSubscriptions()
+	sub, _ := testSubscription(server.SlowClientDropOldest)
+	subs.add("sub", sub)
+	unsubscribed := false
+	forwarded := make(chan struct{})
+	go func() {
+		sub.forward("sub", func(subscriptionId string) {
+			unsubscribed = true
+		})
+		close(forwarded)
+	}()
+	// only the subscription added is removed when it is given
+	other, _ := testSubscription(server.SlowClientDropOldest)
+	assert.False(t, subs.remove("sub", other))
+	// the forwarder stops once unsubscribed, without unsubscribing again
+	assert.True(t, subs.remove("sub", nil))
+	<-forwarded
+	assert.False(t, unsubscribed)
+	assert.False(t, subs.remove("sub", sub))
+}
@@ -18,6 +18,7 @@ import (
 	acm "github.com/hyperledger/burrow/account"
 	core_types "github.com/hyperledger/burrow/core/types"
 	genesis "github.com/hyperledger/burrow/genesis"
+	ptypes "github.com/hyperledger/burrow/permission/types"
 	"github.com/hyperledger/burrow/txs"
 	tendermint_types "github.com/tendermint/tendermint/types"
 
@@ -43,11 +44,15 @@ type ResultCall struct {
 type ResultListAccounts struct {
 	BlockHeight int            `json:"block_height"`
 	Accounts    []*acm.Account `json:"accounts"`
+	// Cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor"`
 }
 
 type ResultDumpStorage struct {
 	StorageRoot  []byte        `json:"storage_root"`
 	StorageItems []StorageItem `json:"storage_items"`
+	// Cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor"`
 }
 
 type StorageItem struct {
@@ -114,6 +119,28 @@ type ResultPeerConsensusState struct {
 type ResultListNames struct {
 	BlockHeight int                        `json:"block_height"`
 	Names       []*core_types.NameRegEntry `json:"names"`
+	// Cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+type ResultReverseLookup struct {
+	BlockHeight int      `json:"block_height"`
+	Address     []byte   `json:"address"`
+	Names       []string `json:"names"`
+}
+
+type ResultGetChainParams struct {
+	BlockHeight       int                    `json:"block_height"`
+	Params            *txs.ChainParams       `json:"params"`
+	GlobalPermissions ptypes.BasePermissions `json:"global_permissions"`
+	PendingProposals  []*txs.GovProposal     `json:"pending_proposals"`
+}
+
+type ResultListPermissionChanges struct {
+	BlockHeight int                        `json:"block_height"`
+	Changes     []*ptypes.PermissionChange `json:"changes"`
+	// Cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor"`
 }
 
 type ResultGenPrivAccount struct {
@@ -156,29 +183,32 @@ type ResultEvent struct {
 // result types
 
 const (
-	ResultTypeGetStorage         = byte(0x01)
-	ResultTypeCall               = byte(0x02)
-	ResultTypeListAccounts       = byte(0x03)
-	ResultTypeDumpStorage        = byte(0x04)
-	ResultTypeBlockchainInfo     = byte(0x05)
-	ResultTypeGetBlock           = byte(0x06)
-	ResultTypeStatus             = byte(0x07)
-	ResultTypeNetInfo            = byte(0x08)
-	ResultTypeListValidators     = byte(0x09)
-	ResultTypeDumpConsensusState = byte(0x0A)
-	ResultTypeListNames          = byte(0x0B)
-	ResultTypeGenPrivAccount     = byte(0x0C)
-	ResultTypeGetAccount         = byte(0x0D)
-	ResultTypeBroadcastTx        = byte(0x0E)
-	ResultTypeListUnconfirmedTxs = byte(0x0F)
-	ResultTypeGetName            = byte(0x10)
-	ResultTypeGenesis            = byte(0x11)
-	ResultTypeSignTx             = byte(0x12)
-	ResultTypeEvent              = byte(0x13) // so websockets can respond to rpc functions
-	ResultTypeSubscribe          = byte(0x14)
-	ResultTypeUnsubscribe        = byte(0x15)
-	ResultTypePeerConsensusState = byte(0x16)
-	ResultTypeChainId            = byte(0x17)
+	ResultTypeGetStorage            = byte(0x01)
+	ResultTypeCall                  = byte(0x02)
+	ResultTypeListAccounts          = byte(0x03)
+	ResultTypeDumpStorage           = byte(0x04)
+	ResultTypeBlockchainInfo        = byte(0x05)
+	ResultTypeGetBlock              = byte(0x06)
+	ResultTypeStatus                = byte(0x07)
+	ResultTypeNetInfo               = byte(0x08)
+	ResultTypeListValidators        = byte(0x09)
+	ResultTypeDumpConsensusState    = byte(0x0A)
+	ResultTypeListNames             = byte(0x0B)
+	ResultTypeGenPrivAccount        = byte(0x0C)
+	ResultTypeGetAccount            = byte(0x0D)
+	ResultTypeBroadcastTx           = byte(0x0E)
+	ResultTypeListUnconfirmedTxs    = byte(0x0F)
+	ResultTypeGetName               = byte(0x10)
+	ResultTypeGenesis               = byte(0x11)
+	ResultTypeSignTx                = byte(0x12)
+	ResultTypeEvent                 = byte(0x13) // so websockets can respond to rpc functions
+	ResultTypeSubscribe             = byte(0x14)
+	ResultTypeUnsubscribe           = byte(0x15)
+	ResultTypePeerConsensusState    = byte(0x16)
+	ResultTypeChainId               = byte(0x17)
+	ResultTypeGetChainParams        = byte(0x18)
+	ResultTypeListPermissionChanges = byte(0x19)
+	ResultTypeReverseLookup         = byte(0x1A)
 )
 
 type BurrowResult interface {
@@ -210,6 +240,9 @@ func ConcreteTypes() []wire.ConcreteType {
 		{&ResultSubscribe{}, ResultTypeSubscribe},
 		{&ResultUnsubscribe{}, ResultTypeUnsubscribe},
 		{&ResultChainId{}, ResultTypeChainId},
+		{&ResultGetChainParams{}, ResultTypeGetChainParams},
+		{&ResultListPermissionChanges{}, ResultTypeListPermissionChanges},
+		{&ResultReverseLookup{}, ResultTypeReverseLookup},
 	}
 }
 
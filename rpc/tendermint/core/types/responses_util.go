@@ -39,5 +39,6 @@ func UnwrapResultDumpStorage(result *ResultDumpStorage) *types.Storage {
 	return &types.Storage{
 		StorageRoot:  storageRoot,
 		StorageItems: storageItems,
+		NextCursor:   result.NextCursor,
 	}
 }
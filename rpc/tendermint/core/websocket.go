@@ -15,18 +15,26 @@
 package core
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"strings"
 
 	events "github.com/tendermint/go-events"
 	rpcserver "github.com/tendermint/go-rpc/server"
+	rpctypes "github.com/tendermint/go-rpc/types"
 
 	definitions "github.com/hyperledger/burrow/definitions"
+	rpc "github.com/hyperledger/burrow/rpc"
 	server "github.com/hyperledger/burrow/server"
 )
 
+// Most bytes in the body of a request, as for the JSON-RPC server of rpc/v0
+const maxRequestSize = 1000000
+
 type TendermintWebsocketServer struct {
 	routes    TendermintRoutes
 	listeners []net.Listener
@@ -39,10 +47,23 @@ func NewTendermintWebsocketServer(config *server.ServerConfig,
 	if tendermintPipe == nil {
 		return nil, fmt.Errorf("No Tendermint pipe provided.")
 	}
+	// the policy is validated as the config is read
+	slowClientPolicy, _ := server.ParseSlowClientPolicy(
+		config.WebSocket.SlowClientPolicy)
 	tendermintRoutes := TendermintRoutes{
-		tendermintPipe: tendermintPipe,
+		tendermintPipe:   tendermintPipe,
+		slowClientPolicy: slowClientPolicy,
+		eventQueueSize:   config.WebSocket.EventQueueSize,
+		subscriptions:    newSubscriptions(),
+	}
+	auth, err := server.NewAuth(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load authentication: %v", err)
+	}
+	limiter, err := server.NewRateLimiter(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load rate limits: %v", err)
 	}
-	routes := tendermintRoutes.GetRoutes()
 	listenerAddresses := strings.Split(config.Tendermint.RpcLocalAddress, ",")
 	if len(listenerAddresses) == 0 {
 		return nil, fmt.Errorf("No RPC listening addresses provided in [servers.tendermint.rpc_local_address] in configuration file: %s",
@@ -50,11 +71,15 @@ func NewTendermintWebsocketServer(config *server.ServerConfig,
 	}
 	listeners := make([]net.Listener, len(listenerAddresses))
 	for i, listenerAddress := range listenerAddresses {
-		mux := http.NewServeMux()
-		wm := rpcserver.NewWebsocketManager(routes, evsw)
-		mux.HandleFunc(config.Tendermint.Endpoint, wm.WebsocketHandler)
-		rpcserver.RegisterRPCFuncs(mux, routes)
-		listener, err := rpcserver.StartHTTPServer(listenerAddress, mux)
+		handler := newAuthorizingHandler(auth, func(role server.Role) http.Handler {
+			routes := tendermintRoutes.GetAuthorizedRoutes(auth, role, limiter)
+			mux := http.NewServeMux()
+			wm := rpcserver.NewWebsocketManager(routes, evsw)
+			mux.HandleFunc(config.Tendermint.Endpoint, wm.WebsocketHandler)
+			rpcserver.RegisterRPCFuncs(mux, routes)
+			return newLimitingHandler(auth, limiter, mux)
+		})
+		listener, err := rpcserver.StartHTTPServer(listenerAddress, handler)
 		if err != nil {
 			return nil, err
 		}
@@ -71,3 +96,88 @@ func (tmServer *TendermintWebsocketServer) Shutdown() {
 		listener.Close()
 	}
 }
+
+// Each request, and each websocket connection, is served by the routes of the
+// role its caller authenticates as.
+type authorizingHandler struct {
+	auth     *server.Auth
+	handlers map[server.Role]http.Handler
+}
+
+func newAuthorizingHandler(auth *server.Auth,
+	newHandler func(role server.Role) http.Handler) http.Handler {
+	if auth == nil {
+		return newHandler(server.RoleAdmin)
+	}
+	handlers := make(map[server.Role]http.Handler)
+	for _, role := range []server.Role{server.RoleReadOnly,
+		server.RoleBroadcastOnly, server.RoleAdmin} {
+		handlers[role] = newHandler(role)
+	}
+	return &authorizingHandler{auth: auth, handlers: handlers}
+}
+
+func (handler *authorizingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	role, err := handler.auth.Authenticate(r)
+	if err != nil {
+		http.Error(w, "Failed to authenticate: "+err.Error(), 401)
+		return
+	}
+	roleHandler, ok := handler.handlers[role]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Role %v may not call any route", role), 403)
+		return
+	}
+	roleHandler.ServeHTTP(w, r)
+}
+
+// Rate limits each request by the route it calls, which is the path of GET
+// requests and the method of JSON-RPC requests. Websocket connections are
+// limited as they are opened, but not the calls made over them, whose callers
+// the routes can not tell apart. Bodies of more than maxRequestSize bytes are
+// refused. Refused requests get status 429 and a response whose error starts
+// with the RATE_LIMITED code, since the errors of the RPC are strings.
+type limitingHandler struct {
+	auth    *server.Auth
+	limiter *server.RateLimiter
+	handler http.Handler
+}
+
+func newLimitingHandler(auth *server.Auth, limiter *server.RateLimiter,
+	handler http.Handler) http.Handler {
+	if limiter == nil {
+		return handler
+	}
+	return &limitingHandler{auth: auth, limiter: limiter, handler: handler}
+}
+
+func (handler *limitingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route := strings.TrimPrefix(r.URL.Path, "/")
+	id := ""
+	if r.Method == "POST" {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		if err != nil {
+			http.Error(w, "Failed to read request: "+err.Error(), 400)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		// requests that do not parse are left for the RPC server to refuse
+		request := &struct {
+			Method string `json:"method"`
+			ID     string `json:"id"`
+		}{}
+		json.Unmarshal(body, request)
+		route, id = request.Method, request.ID
+	}
+	err := handler.limiter.Take(handler.auth.ClientId(r), route, routeClasses)
+	if err != nil {
+		response := rpctypes.NewRPCResponse(id, nil,
+			fmt.Sprintf("%v: %v", rpc.RATE_LIMITED, err))
+		responseBytes, _ := json.Marshal(response)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(429)
+		w.Write(responseBytes)
+		return
+	}
+	handler.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,87 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	rpc "github.com/hyperledger/burrow/rpc"
+	server "github.com/hyperledger/burrow/server"
+
+	"github.com/stretchr/testify/assert"
+	rpctypes "github.com/tendermint/go-rpc/types"
+)
+
+func newTestLimitingHandler(t *testing.T) (http.Handler, *int) {
+	config := server.DefaultServerConfig()
+	config.RateLimit.Enable = true
+	config.RateLimit.Read = server.Limit{Rate: 0.001, Burst: 1}
+	limiter, err := server.NewRateLimiter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := 0
+	handler := newLimitingHandler(nil, limiter,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served++
+		}))
+	return handler, &served
+}
+
+func servePost(t *testing.T, handler http.Handler, body string) *httptest.ResponseRecorder {
+	request, err := http.NewRequest("POST", "/", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestLimitingHandlerRequestSize(t *testing.T) {
+	handler, served := newTestLimitingHandler(t)
+
+	body := `{"jsonrpc":"2.0","id":"1","method":"status","params":{}}`
+	recorder := servePost(t, handler, body+strings.Repeat(" ", maxRequestSize))
+	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, 0, *served)
+
+	recorder = servePost(t, handler, body)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, 1, *served)
+}
+
+func TestLimitingHandlerRateLimited(t *testing.T) {
+	handler, served := newTestLimitingHandler(t)
+
+	recorder := servePost(t, handler, `{"jsonrpc":"2.0","id":"1","method":"status","params":{}}`)
+	assert.Equal(t, 200, recorder.Code)
+	recorder = servePost(t, handler, `{"jsonrpc":"2.0","id":"2","method":"status","params":{}}`)
+	assert.Equal(t, 429, recorder.Code)
+	assert.Equal(t, 1, *served)
+
+	response := rpctypes.RPCResponse{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "2", response.ID)
+	assert.True(t, strings.HasPrefix(response.Error, fmt.Sprintf("%v:", rpc.RATE_LIMITED)),
+		"unexpected error %s", response.Error)
+}
@@ -108,12 +108,82 @@ genesis_file = "genesis.json"
 
   [servers.http]
   json_rpc_endpoint = "/rpc"
+  # Ethereum JSON-RPC (eth_* methods), leave empty to disable
+  eth_json_rpc_endpoint = "/eth"
+  # GraphQL queries over the chain state, leave empty to disable
+  graphql_endpoint = "/graphql"
+  # most requests in a JSON-RPC batch (an array of requests), over HTTP and
+  # websockets
+  max_batch_size = 100
 
   [servers.websocket]
   endpoint = "/socketrpc"
   max_sessions = 50
   read_buffer_size = 4096
   write_buffer_size = 4096
+  # most events queued for each session, including those of the tendermint
+  # websocket, before the slow client policy applies
+  event_queue_size = 1000
+  # what to do with events when the queue of a session is full: disconnect
+  # the client, drop_oldest event, or coalesce the events of a subscription
+  # so that only its latest is sent. Clients are sent a notice of the events
+  # dropped.
+  slow_client_policy = "drop_oldest"
+
+  [servers.grpc]
+  # gRPC API of rpc/grpc/burrow.proto, which is only served when an address
+  # such as "0.0.0.0:1338" is given
+  listen_address = ""
+
+  [servers.auth]
+  # When enabled, callers authenticate by API key (X-Api-Key header or
+  # api_key parameter), HS256 JWT bearer token (Authorization header or
+  # access_token parameter) or TLS client certificate, and the methods they
+  # may call depend on their role: read_only, broadcast_only or admin
+  enable = false
+  # role of callers without credentials, none to refuse them
+  anonymous_role = "read_only"
+  # as "<key>:<role>"
+  api_keys = []
+  # tokens are not accepted when empty; the role is the "role" claim
+  jwt_secret = ""
+  # client certificates are not requested when empty, and need TLS
+  client_ca_path = ""
+  client_cert_role = "admin"
+
+  [servers.auth.policy]
+  # methods to require each role for, in place of their default
+  read_only = []
+  broadcast_only = []
+  admin = []
+
+  [servers.rate_limit]
+  # When enabled, the calls of each client are limited by a token bucket per
+  # class of method: read, scan (storage dumps, block ranges and listings),
+  # simulate (calls run in the VM) and transact. Clients are identified by
+  # their credentials when auth is enabled, and by IP address otherwise.
+  # Refused calls get JSON-RPC error -32005, or HTTP status 429.
+  enable = false
+  # most calls running in the VM at once across all clients, 0 for no cap
+  max_concurrent_simulations = 8
+
+  # calls per second, and the most calls allowed at once above it; classes
+  # with a rate of 0 are not limited
+  [servers.rate_limit.read]
+  rate = 50.0
+  burst = 100
+
+  [servers.rate_limit.scan]
+  rate = 2.0
+  burst = 5
+
+  [servers.rate_limit.simulate]
+  rate = 5.0
+  burst = 10
+
+  [servers.rate_limit.transact]
+  rate = 10.0
+  burst = 20
 
 	[servers.tendermint]
 	# Multiple listeners can be separated with a comma
@@ -17,6 +17,15 @@ package test
 // priv keys generated deterministically eg rpc/tests/shared.go
 var defaultGenesis = `{
   "chain_id" : "MyChainId",
+  "params": {
+    "chain_params": {
+      "min_bond_amount": 1,
+      "unbonding_period_blocks": 525600,
+      "min_name_registration_period": 1,
+      "name_byte_cost_multiplier": 1,
+      "name_block_cost_multiplier": 1
+    }
+  },
   "accounts": [
     {
 	    "address": "E9B5D87313356465FAE33C406CE2C2979DE60BCB",
@@ -24,8 +24,11 @@ import (
 	"testing"
 	"time"
 
+	acm "github.com/hyperledger/burrow/account"
 	consensus_types "github.com/hyperledger/burrow/consensus/types"
+	core_types "github.com/hyperledger/burrow/core/types"
 	edbcli "github.com/hyperledger/burrow/rpc/tendermint/client"
+	rpc_types "github.com/hyperledger/burrow/rpc/tendermint/core/types"
 	"github.com/hyperledger/burrow/txs"
 	"github.com/hyperledger/burrow/word256"
 
@@ -142,6 +145,77 @@ func TestGetStorage(t *testing.T) {
 			t.Fatalf("Wrong storage value. Got %x, expected %x", got.Bytes(),
 				expected.Bytes())
 		}
+
+		// the contract stores a single item, which is on the first page of
+		// any size
+		storage := dumpStorage(t, client, contractAddr, "", 1)
+		if assert.Len(t, storage.StorageItems, 1) {
+			assert.Equal(t, expected,
+				word256.LeftPadWord256(storage.StorageItems[0].Value))
+		}
+		storage = callWithParams(t, "dump_storage", map[string]interface{}{
+			"address": contractAddr,
+		}).(*rpc_types.ResultDumpStorage)
+		assert.Len(t, storage.StorageItems, 1)
+		assert.Empty(t, storage.NextCursor)
+	})
+}
+
+func TestListAccounts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
+	}
+	// without a cursor or limit the first page is of the default size, which
+	// holds every account of the test chain
+	all := callWithParams(t, "list_accounts",
+		map[string]interface{}{}).(*rpc_types.ResultListAccounts)
+	assert.Empty(t, all.NextCursor)
+	assert.True(t, len(all.Accounts) >= len(user))
+	testWithAllClients(t, func(t *testing.T, clientName string, client rpcclient.Client) {
+		// following the cursors through pages of two gets the same accounts
+		var accounts []*acm.Account
+		cursor := ""
+		for i := 0; i <= len(all.Accounts); i++ {
+			page, err := edbcli.ListAccounts(client, cursor, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, len(page.Accounts) <= 2)
+			accounts = append(accounts, page.Accounts...)
+			cursor = page.NextCursor
+			if cursor == "" {
+				break
+			}
+		}
+		assert.Empty(t, cursor)
+		assert.Equal(t, all.Accounts, accounts)
+	})
+}
+
+func TestListNames(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
+	}
+	all := callWithParams(t, "list_names",
+		map[string]interface{}{}).(*rpc_types.ResultListNames)
+	assert.Empty(t, all.NextCursor)
+	testWithAllClients(t, func(t *testing.T, clientName string, client rpcclient.Client) {
+		var names []*core_types.NameRegEntry
+		cursor := ""
+		for i := 0; i <= len(all.Names); i++ {
+			page, err := edbcli.ListNames(client, cursor, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, len(page.Names) <= 1)
+			names = append(names, page.Names...)
+			cursor = page.NextCursor
+			if cursor == "" {
+				break
+			}
+		}
+		assert.Empty(t, cursor)
+		assert.Equal(t, all.Names, names)
 	})
 }
 
@@ -228,16 +228,28 @@ func broadcastTx(t *testing.T, client rpcclient.Client, tx txs.Tx) txs.Receipt {
 	return rec
 }
 
-// dump all storage for an account. currently unused
-func dumpStorage(t *testing.T, addr []byte) *rpc_types.ResultDumpStorage {
-	client := clients["HTTP"]
-	resp, err := edbcli.DumpStorage(client, addr)
+// dump a page of the storage of an account
+func dumpStorage(t *testing.T, client rpcclient.Client, addr []byte,
+	cursor string, limit int) *rpc_types.ResultDumpStorage {
+	resp, err := edbcli.DumpStorage(client, addr, cursor, limit)
 	if err != nil {
 		t.Fatal(err)
 	}
 	return resp
 }
 
+// call a route over HTTP with only some of its params, as a client that
+// predates the others would. Params that are left out get their zero values.
+func callWithParams(t *testing.T, method string,
+	params map[string]interface{}) rpc_types.BurrowResult {
+	var res rpc_types.BurrowResult
+	_, err := httpClient.(*rpcclient.ClientURI).Call(method, params, &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
 func getStorage(t *testing.T, client rpcclient.Client, addr, key []byte) []byte {
 	resp, err := edbcli.GetStorage(client, addr, key)
 	if err != nil {
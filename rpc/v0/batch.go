@@ -0,0 +1,102 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v0
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	rpc "github.com/hyperledger/burrow/rpc"
+)
+
+// Is the message a JSON-RPC 2.0 batch, that is an array of requests?
+func isBatch(msg []byte) bool {
+	trimmed := bytes.TrimLeft(msg, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// A request of a batch, and its response once it has been handled
+type batchItem struct {
+	// nil when the request could not be decoded, which the response says
+	request  *rpc.RPCRequest
+	response rpc.RPCResponse
+	// requests without an id are notifications, which are handled but not
+	// answered
+	notification bool
+}
+
+// Decodes the requests of a batch. Requests that can not be decoded are
+// answered with an error response in their place, so that they do not fail
+// the other requests of the batch. Returns the JSON-RPC error code of a batch
+// that can not be decoded as a whole, or that has more than maxBatchSize
+// requests.
+func decodeBatch(msg []byte, maxBatchSize int) ([]*batchItem, int, error) {
+	rawItems := []json.RawMessage{}
+	if err := json.Unmarshal(msg, &rawItems); err != nil {
+		return nil, rpc.PARSE_ERROR, fmt.Errorf("Failed to parse batch: %v", err)
+	}
+	if len(rawItems) == 0 {
+		return nil, rpc.INVALID_REQUEST, fmt.Errorf("Empty batch")
+	}
+	if len(rawItems) > maxBatchSize {
+		return nil, rpc.INVALID_REQUEST, fmt.Errorf("Batch of %v requests "+
+			"exceeds the maximum of %v", len(rawItems), maxBatchSize)
+	}
+	items := make([]*batchItem, len(rawItems))
+	for i, rawItem := range rawItems {
+		req := &rpc.RPCRequest{}
+		if err := json.Unmarshal(rawItem, req); err != nil {
+			items[i] = &batchItem{response: rpc.NewRPCErrorResponse("",
+				rpc.INVALID_REQUEST, "Failed to parse request: "+err.Error())}
+			continue
+		}
+		// a request decodes from an object or null, so the fields of an
+		// object are there to look for the id in
+		var fields map[string]json.RawMessage
+		json.Unmarshal(rawItem, &fields)
+		_, hasId := fields["id"]
+		items[i] = &batchItem{request: req, notification: fields != nil && !hasId}
+	}
+	return items, 0, nil
+}
+
+// Encodes the responses to a batch as a JSON array, in the order of the
+// requests. Returns nil when every request was a notification, in which case
+// nothing is to be sent.
+func encodeBatch(codec rpc.Codec, items []*batchItem) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('[')
+	for _, item := range items {
+		if item.notification {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		// each response is encoded on its own, since the codec can not encode
+		// the RPCResponse interface in a slice
+		bs, err := codec.EncodeBytes(item.response)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(bs)
+	}
+	if buf.Len() == 1 {
+		return nil, nil
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
@@ -16,6 +16,7 @@ package v0
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 
 	definitions "github.com/hyperledger/burrow/definitions"
@@ -26,6 +27,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Most bytes in the body of a request or batch, as for websocket messages
+const maxRequestSize = 1000000
+
 // Server used to handle JSON-RPC 2.0 requests. Implements server.Server
 type JsonRpcServer struct {
 	service server.HttpService
@@ -68,14 +72,21 @@ type BurrowJsonService struct {
 	codec           rpc.Codec
 	pipe            definitions.Pipe
 	eventSubs       *event.EventSubscriptions
+	auth            *server.Auth
+	limiter         *server.RateLimiter
+	maxBatchSize    int
 	defaultHandlers map[string]RequestHandlerFunc
 }
 
-// Create a new JSON-RPC 2.0 service for burrow (tendermint).
+// Create a new JSON-RPC 2.0 service for burrow (tendermint). Requests are
+// authorized by auth and rate limited by limiter, either of which may be nil.
+// Batches of more than maxBatchSize requests are refused.
 func NewBurrowJsonService(codec rpc.Codec, pipe definitions.Pipe,
-	eventSubs *event.EventSubscriptions) server.HttpService {
+	eventSubs *event.EventSubscriptions, auth *server.Auth,
+	limiter *server.RateLimiter, maxBatchSize int) server.HttpService {
 
-	tmhttps := &BurrowJsonService{codec: codec, pipe: pipe, eventSubs: eventSubs}
+	tmhttps := &BurrowJsonService{codec: codec, pipe: pipe, eventSubs: eventSubs,
+		auth: auth, limiter: limiter, maxBatchSize: maxBatchSize}
 	mtds := NewBurrowMethods(codec, pipe)
 
 	dhMap := mtds.getMethods()
@@ -87,60 +98,108 @@ func NewBurrowJsonService(codec rpc.Codec, pipe definitions.Pipe,
 	return tmhttps
 }
 
-// Process a request.
+// Process a request, or a batch of requests. The requests of a batch are
+// answered in one array, each with its own result or error, except for
+// notifications. Bodies of more than maxRequestSize bytes are refused.
 func (this *BurrowJsonService) Process(r *http.Request, w http.ResponseWriter) {
+	msg, errR := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if errR != nil {
+		this.writeError("Failed to read request: "+errR.Error(), "",
+			rpc.PARSE_ERROR, w)
+		return
+	}
 
-	// Create new request object and unmarshal.
-	req := &rpc.RPCRequest{}
-	decoder := json.NewDecoder(r.Body)
-	errU := decoder.Decode(req)
+	if !isBatch(msg) {
+		// Create new request object and unmarshal.
+		req := &rpc.RPCRequest{}
+		errU := json.Unmarshal(msg, req)
 
-	// Error when decoding.
-	if errU != nil {
-		this.writeError("Failed to parse request: "+errU.Error(), "",
-			rpc.PARSE_ERROR, w)
+		// Error when decoding.
+		if errU != nil {
+			this.writeError("Failed to parse request: "+errU.Error(), "",
+				rpc.PARSE_ERROR, w)
+			return
+		}
+		this.writeResponse(this.handle(r, w, req), w)
+		return
+	}
+
+	items, errCode, err := decodeBatch(msg, this.maxBatchSize)
+	if err != nil {
+		this.writeError(err.Error(), "", errCode, w)
 		return
 	}
+	for _, item := range items {
+		if item.request != nil {
+			item.response = this.handle(r, w, item.request)
+		}
+	}
+	bts, err := encodeBatch(this.codec, items)
+	if err != nil {
+		this.writeError("Internal error: "+err.Error(), "", rpc.INTERNAL_ERROR, w)
+		return
+	}
+	if bts == nil {
+		// a batch of notifications is not answered
+		w.WriteHeader(204)
+		return
+	}
+	w.WriteHeader(200)
+	w.Write(bts)
+}
 
+// Calls the handler of a request, returning its response.
+func (this *BurrowJsonService) handle(r *http.Request, w http.ResponseWriter,
+	req *rpc.RPCRequest) rpc.RPCResponse {
 	// Wrong protocol version.
 	if req.JSONRPC != "2.0" {
-		this.writeError("Wrong protocol version: "+req.JSONRPC, req.Id,
-			rpc.INVALID_REQUEST, w)
-		return
+		return rpc.NewRPCErrorResponse(req.Id, rpc.INVALID_REQUEST,
+			"Wrong protocol version: "+req.JSONRPC)
 	}
 
 	mName := req.Method
 
-	if handler, ok := this.defaultHandlers[mName]; ok {
-		resp, errCode, err := handler(req, w)
-		if err != nil {
-			this.writeError(err.Error(), req.Id, errCode, w)
-		} else {
-			this.writeResponse(req.Id, resp, w)
-		}
-	} else {
-		this.writeError("Method not found: "+mName, req.Id, rpc.METHOD_NOT_FOUND, w)
+	handler, ok := this.defaultHandlers[mName]
+	if !ok {
+		return rpc.NewRPCErrorResponse(req.Id, rpc.METHOD_NOT_FOUND,
+			"Method not found: "+mName)
+	}
+	if err := this.authorize(r, mName); err != nil {
+		return rpc.NewRPCErrorResponse(req.Id, rpc.UNAUTHORIZED, err.Error())
+	}
+	release, err := this.limiter.Limit(this.auth.ClientId(r), mName,
+		methodClasses)
+	if err != nil {
+		return rpc.NewRPCErrorResponse(req.Id, rpc.RATE_LIMITED, err.Error())
 	}
+	resp, errCode, err := handler(req, w)
+	release()
+	if err != nil {
+		return rpc.NewRPCErrorResponse(req.Id, errCode, err.Error())
+	}
+	return rpc.NewRPCResponse(req.Id, resp)
 }
 
-// Helper for writing error responses.
-func (this *BurrowJsonService) writeError(msg, id string, code int, w http.ResponseWriter) {
-	response := rpc.NewRPCErrorResponse(id, code, msg)
-	err := this.codec.Encode(response, w)
-	// If there's an error here all bets are off.
+// Authenticates the caller and checks that they may call method.
+func (this *BurrowJsonService) authorize(r *http.Request, method string) error {
+	role, err := this.auth.Authenticate(r)
 	if err != nil {
-		http.Error(w, "Failed to marshal standard error response: "+err.Error(), 500)
-		return
+		return err
 	}
-	w.WriteHeader(200)
+	return this.auth.Authorize(role, method, methodRoles)
+}
+
+// Helper for writing error responses.
+func (this *BurrowJsonService) writeError(msg, id string, code int, w http.ResponseWriter) {
+	this.writeResponse(rpc.NewRPCErrorResponse(id, code, msg), w)
 }
 
 // Helper for writing responses.
-func (this *BurrowJsonService) writeResponse(id string, result interface{}, w http.ResponseWriter) {
-	response := rpc.NewRPCResponse(id, result)
+func (this *BurrowJsonService) writeResponse(response rpc.RPCResponse, w http.ResponseWriter) {
 	err := this.codec.Encode(response, w)
+	// If there's an error here all bets are off.
 	if err != nil {
-		this.writeError("Internal error: "+err.Error(), id, rpc.INTERNAL_ERROR, w)
+		http.Error(w, "Failed to marshal response: "+err.Error(), 500)
 		return
 	}
 	w.WriteHeader(200)
@@ -157,7 +216,15 @@ func (this *BurrowJsonService) EventSubscribe(request *rpc.RPCRequest,
 		return nil, rpc.INVALID_PARAMS, err
 	}
 	eventId := param.EventId
-	subId, errC := this.eventSubs.Add(eventId)
+	var subId string
+	var errC error
+	if param.Filter != "" {
+		subId, errC = this.eventSubs.AddFilter(eventId, param.Filter, param.FromSequence)
+	} else if param.FromSequence != nil {
+		subId, errC = this.eventSubs.AddFromSequence(eventId, *param.FromSequence)
+	} else {
+		subId, errC = this.eventSubs.Add(eventId)
+	}
 	if errC != nil {
 		return nil, rpc.INTERNAL_ERROR, errC
 	}
@@ -191,9 +258,9 @@ func (this *BurrowJsonService) EventPoll(request *rpc.RPCRequest,
 	}
 	subId := param.SubId
 
-	result, errC := this.eventSubs.Poll(subId)
+	result, errC := this.eventSubs.PollFromSequence(subId, param.FromSequence)
 	if errC != nil {
 		return nil, rpc.INTERNAL_ERROR, errC
 	}
-	return &event.PollResponse{result}, 0, nil
+	return result, 0, nil
 }
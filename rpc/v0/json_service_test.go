@@ -15,6 +15,11 @@
 package v0
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/burrow/account"
@@ -44,6 +49,76 @@ func TestBroadcastTx(t *testing.T) {
 	assert.Equal(t, txs.TxHash(testData.GetChainId.Output.ChainId, tx), receipt.TxHash)
 }
 
+func TestBatch(t *testing.T) {
+	service := NewBurrowJsonService(NewTCodec(), NewMockPipe(LoadTestData()),
+		nil, nil, nil, 3)
+	process := func(body string) []byte {
+		r, err := http.NewRequest("POST", "/rpc", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		service.Process(r, w)
+		return w.Body.Bytes()
+	}
+	type response struct {
+		Id     string           `json:"id"`
+		Result *json.RawMessage `json:"result"`
+		Error  *rpc.RPCError    `json:"error"`
+	}
+
+	// each request is answered in order, and errors do not fail the others
+	responses := []response{}
+	err := json.Unmarshal(process(`[
+		{"jsonrpc": "2.0", "method": "`+GET_CHAIN_ID+`", "id": "1"},
+		{"jsonrpc": "2.0", "method": "burrow.noSuchMethod", "id": "2"},
+		1
+	]`), &responses)
+	assert.NoError(t, err)
+	if assert.Len(t, responses, 3) {
+		assert.Equal(t, "1", responses[0].Id)
+		assert.NotNil(t, responses[0].Result)
+		assert.Nil(t, responses[0].Error)
+		assert.Equal(t, "2", responses[1].Id)
+		assert.Equal(t, rpc.METHOD_NOT_FOUND, responses[1].Error.Code)
+		assert.Equal(t, rpc.INVALID_REQUEST, responses[2].Error.Code)
+	}
+
+	// batches that are too large or empty fail as a whole
+	for _, body := range []string{`[1, 2, 3, 4]`, `[]`} {
+		single := response{}
+		assert.NoError(t, json.Unmarshal(process(body), &single))
+		assert.Equal(t, rpc.INVALID_REQUEST, single.Error.Code)
+	}
+
+	// notifications are handled but not answered, so a batch of only
+	// notifications gets an empty response
+	responses = []response{}
+	err = json.Unmarshal(process(`[
+		{"jsonrpc": "2.0", "method": "`+GET_CHAIN_ID+`"},
+		{"jsonrpc": "2.0", "method": "`+GET_CHAIN_ID+`", "id": "2"}
+	]`), &responses)
+	assert.NoError(t, err)
+	if assert.Len(t, responses, 1) {
+		assert.Equal(t, "2", responses[0].Id)
+	}
+	assert.Empty(t, process(`[{"jsonrpc": "2.0", "method": "`+GET_CHAIN_ID+`"}]`))
+
+	// bodies that are too large are not read
+	single := response{}
+	err = json.Unmarshal(process(`[`+strings.Repeat(" ", maxRequestSize)+`]`),
+		&single)
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PARSE_ERROR, single.Error.Code)
+
+	// a single request is answered as before
+	single = response{}
+	err = json.Unmarshal(process(`{"jsonrpc": "2.0", "method": "`+
+		GET_CHAIN_ID+`", "id": "1"}`), &single)
+	assert.NoError(t, err)
+	assert.NotNil(t, single.Result)
+}
+
 // Allows us to get the type byte included but then omit the outer struct and
 // embedded field
 type wrappedTx struct {
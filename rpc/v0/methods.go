@@ -15,13 +15,18 @@
 package v0
 
 import (
+	account "github.com/hyperledger/burrow/account"
 	"github.com/hyperledger/burrow/blockchain"
+	consensus_types "github.com/hyperledger/burrow/consensus/types"
 	core_types "github.com/hyperledger/burrow/core/types"
 	definitions "github.com/hyperledger/burrow/definitions"
 	"github.com/hyperledger/burrow/event"
 	"github.com/hyperledger/burrow/rpc"
 	"github.com/hyperledger/burrow/rpc/v0/shared"
+	"github.com/hyperledger/burrow/server"
 	"github.com/hyperledger/burrow/txs"
+
+	tendermint_types "github.com/tendermint/tendermint/types"
 )
 
 // TODO use the method name definition file.
@@ -63,10 +68,116 @@ const (
 	EVENT_SUBSCRIBE           = SERVICE_NAME + ".eventSubscribe" // Events
 	EVENT_UNSUBSCRIBE         = SERVICE_NAME + ".eventUnsubscribe"
 	EVENT_POLL                = SERVICE_NAME + ".eventPoll"
+	GET_EVENT_METRICS         = SERVICE_NAME + ".getEventMetrics"
 	GET_NAMEREG_ENTRY         = SERVICE_NAME + ".getNameRegEntry" // Namereg
 	GET_NAMEREG_ENTRIES       = SERVICE_NAME + ".getNameRegEntries"
 )
 
+// The least role needed to call each method when authentication is enabled.
+// The methods that take or generate private keys need server.RoleAdmin. REST
+// routes are authorized as the methods they correspond to.
+var methodRoles = server.MethodRoles{
+	GET_ACCOUNTS:            server.RoleReadOnly,
+	GET_ACCOUNT:             server.RoleReadOnly,
+	GET_STORAGE:             server.RoleReadOnly,
+	GET_STORAGE_AT:          server.RoleReadOnly,
+	GET_BLOCKCHAIN_INFO:     server.RoleReadOnly,
+	GET_GENESIS_HASH:        server.RoleReadOnly,
+	GET_LATEST_BLOCK_HEIGHT: server.RoleReadOnly,
+	GET_LATEST_BLOCK:        server.RoleReadOnly,
+	GET_BLOCKS:              server.RoleReadOnly,
+	GET_BLOCK:               server.RoleReadOnly,
+	GET_CONSENSUS_STATE:     server.RoleReadOnly,
+	GET_VALIDATORS:          server.RoleReadOnly,
+	GET_NETWORK_INFO:        server.RoleReadOnly,
+	GET_CLIENT_VERSION:      server.RoleReadOnly,
+	GET_MONIKER:             server.RoleReadOnly,
+	GET_CHAIN_ID:            server.RoleReadOnly,
+	IS_LISTENING:            server.RoleReadOnly,
+	GET_LISTENERS:           server.RoleReadOnly,
+	GET_PEERS:               server.RoleReadOnly,
+	GET_PEER:                server.RoleReadOnly,
+	CALL:                    server.RoleReadOnly,
+	CALL_CODE:               server.RoleReadOnly,
+	GET_UNCONFIRMED_TXS:     server.RoleReadOnly,
+	EVENT_SUBSCRIBE:         server.RoleReadOnly,
+	EVENT_UNSUBSCRIBE:       server.RoleReadOnly,
+	EVENT_POLL:              server.RoleReadOnly,
+	GET_EVENT_METRICS:       server.RoleReadOnly,
+	GET_NAMEREG_ENTRY:       server.RoleReadOnly,
+	GET_NAMEREG_ENTRIES:     server.RoleReadOnly,
+	BROADCAST_TX:            server.RoleBroadcastOnly,
+}
+
+// The class of each method when rate limiting is enabled. Methods that are not
+// listed are server.ClassRead. REST routes are limited as the methods they
+// correspond to.
+var methodClasses = server.MethodClasses{
+	GET_ACCOUNTS:        server.ClassScan,
+	GET_STORAGE:         server.ClassScan,
+	GET_BLOCKS:          server.ClassScan,
+	GET_CONSENSUS_STATE: server.ClassScan,
+	GET_UNCONFIRMED_TXS: server.ClassScan,
+	GET_NAMEREG_ENTRIES: server.ClassScan,
+	CALL:                server.ClassSimulate,
+	CALL_CODE:           server.ClassSimulate,
+	BROADCAST_TX:        server.ClassTransact,
+	SIGN_TX:             server.ClassTransact,
+	TRANSACT:            server.ClassTransact,
+	TRANSACT_AND_HOLD:   server.ClassTransact,
+	SEND:                server.ClassTransact,
+	SEND_AND_HOLD:       server.ClassTransact,
+	TRANSACT_NAMEREG:    server.ClassTransact,
+}
+
+// The params each method decodes and the result it returns, from which the
+// schema of the API is generated. Params are nil for methods that take none.
+var methodTypes = map[string]methodType{
+	GET_ACCOUNTS:              {&AccountsParam{}, &core_types.AccountList{}},
+	GET_ACCOUNT:               {&AddressParam{}, &account.Account{}},
+	GET_STORAGE:               {&StorageParam{}, &core_types.Storage{}},
+	GET_STORAGE_AT:            {&StorageAtParam{}, &core_types.StorageItem{}},
+	GEN_PRIV_ACCOUNT:          {nil, &account.PrivAccount{}},
+	GEN_PRIV_ACCOUNT_FROM_KEY: {&PrivKeyParam{}, &account.PrivAccount{}},
+	GET_BLOCKCHAIN_INFO:       {nil, &core_types.BlockchainInfo{}},
+	GET_GENESIS_HASH:          {nil, &core_types.GenesisHash{}},
+	GET_LATEST_BLOCK_HEIGHT:   {nil, &core_types.LatestBlockHeight{}},
+	GET_LATEST_BLOCK:          {nil, &tendermint_types.Block{}},
+	GET_BLOCKS:                {&BlocksParam{}, &core_types.Blocks{}},
+	GET_BLOCK:                 {&HeightParam{}, &tendermint_types.Block{}},
+	GET_CONSENSUS_STATE:       {nil, &consensus_types.ConsensusState{}},
+	GET_VALIDATORS:            {nil, []consensus_types.Validator{}},
+	GET_NETWORK_INFO:          {nil, &shared.NetworkInfo{}},
+	GET_CLIENT_VERSION:        {nil, &core_types.ClientVersion{}},
+	GET_MONIKER:               {nil, &core_types.Moniker{}},
+	GET_CHAIN_ID:              {nil, &core_types.ChainId{}},
+	IS_LISTENING:              {nil, &core_types.Listening{}},
+	GET_LISTENERS:             {nil, &core_types.Listeners{}},
+	GET_PEERS:                 {nil, []*consensus_types.Peer{}},
+	GET_PEER:                  {&PeerParam{}, &consensus_types.Peer{}},
+	CALL:                      {&CallParam{}, &core_types.Call{}},
+	CALL_CODE:                 {&CallCodeParam{}, &core_types.Call{}},
+	BROADCAST_TX:              {new(txs.Tx), &txs.Receipt{}},
+	GET_UNCONFIRMED_TXS:       {nil, &txs.UnconfirmedTxs{}},
+	SIGN_TX:                   {&SignTxParam{}, new(txs.Tx)},
+	TRANSACT:                  {&TransactParam{}, &txs.Receipt{}},
+	TRANSACT_AND_HOLD:         {&TransactParam{}, &txs.EventDataCall{}},
+	SEND:                      {&SendParam{}, &txs.Receipt{}},
+	SEND_AND_HOLD:             {&SendParam{}, &txs.Receipt{}},
+	TRANSACT_NAMEREG:          {&TransactNameRegParam{}, &txs.Receipt{}},
+	EVENT_SUBSCRIBE:           {&EventIdParam{}, &event.EventSub{}},
+	EVENT_UNSUBSCRIBE:         {&SubIdParam{}, &event.EventUnsub{}},
+	EVENT_POLL:                {&SubIdParam{}, &event.PollResponse{}},
+	GET_EVENT_METRICS:         {nil, &server.EventMetrics{}},
+	GET_NAMEREG_ENTRY:         {&NameRegEntryParam{}, &core_types.NameRegEntry{}},
+	GET_NAMEREG_ENTRIES:       {&FilterListParam{}, &core_types.ResultListNames{}},
+}
+
+type methodType struct {
+	params interface{}
+	result interface{}
+}
+
 // The rpc method handlers.
 type BurrowMethods struct {
 	codec         rpc.Codec
@@ -127,6 +238,8 @@ func (burrowMethods *BurrowMethods) getMethods() map[string]RequestHandlerFunc {
 	dhMap[SEND] = burrowMethods.Send
 	dhMap[SEND_AND_HOLD] = burrowMethods.SendAndHold
 	dhMap[TRANSACT_NAMEREG] = burrowMethods.TransactNameReg
+	// Events
+	dhMap[GET_EVENT_METRICS] = burrowMethods.EventMetrics
 	// Namereg
 	dhMap[GET_NAMEREG_ENTRY] = burrowMethods.NameRegEntry
 	dhMap[GET_NAMEREG_ENTRIES] = burrowMethods.NameRegEntries
@@ -184,7 +297,8 @@ func (burrowMethods *BurrowMethods) Accounts(request *rpc.RPCRequest, requester
 	if err != nil {
 		return nil, rpc.INVALID_PARAMS, err
 	}
-	list, errC := burrowMethods.pipe.Accounts().Accounts(param.Filters)
+	list, errC := burrowMethods.pipe.Accounts().Accounts(param.Filters,
+		&core_types.PageRequest{Cursor: param.Cursor, Limit: param.Limit})
 	if errC != nil {
 		return nil, rpc.INTERNAL_ERROR, errC
 	}
@@ -192,13 +306,14 @@ func (burrowMethods *BurrowMethods) Accounts(request *rpc.RPCRequest, requester
 }
 
 func (burrowMethods *BurrowMethods) AccountStorage(request *rpc.RPCRequest, requester interface{}) (interface{}, int, error) {
-	param := &AddressParam{}
+	param := &StorageParam{}
 	err := burrowMethods.codec.DecodeBytes(param, request.Params)
 	if err != nil {
 		return nil, rpc.INVALID_PARAMS, err
 	}
 	address := param.Address
-	storage, errC := burrowMethods.pipe.Accounts().Storage(address)
+	storage, errC := burrowMethods.pipe.Accounts().Storage(address,
+		&core_types.PageRequest{Cursor: param.Cursor, Limit: param.Limit})
 	if errC != nil {
 		return nil, rpc.INTERNAL_ERROR, errC
 	}
@@ -253,7 +368,8 @@ func (burrowMethods *BurrowMethods) Blocks(request *rpc.RPCRequest, requester in
 	if err != nil {
 		return nil, rpc.INVALID_PARAMS, err
 	}
-	blocks, errC := blockchain.FilterBlocks(burrowMethods.pipe.Blockchain(), burrowMethods.filterFactory, param.Filters)
+	blocks, errC := blockchain.FilterBlocks(burrowMethods.pipe.Blockchain(), burrowMethods.filterFactory, param.Filters,
+		&core_types.PageRequest{Cursor: param.Cursor, Limit: param.Limit})
 	if errC != nil {
 		return nil, rpc.INTERNAL_ERROR, errC
 	}
@@ -324,6 +440,15 @@ func (burrowMethods *BurrowMethods) Peer(request *rpc.RPCRequest, requester inte
 	return peer, 0, nil
 }
 
+// *************************************** Events ************************************
+
+// Counts of the events dropped for websocket clients that read them too
+// slowly, since the node started.
+func (burrowMethods *BurrowMethods) EventMetrics(request *rpc.RPCRequest, requester interface{}) (interface{}, int, error) {
+	metrics := server.GetEventMetrics()
+	return &metrics, 0, nil
+}
+
 // *************************************** Txs ************************************
 
 func (burrowMethods *BurrowMethods) Call(request *rpc.RPCRequest, requester interface{}) (interface{}, int, error) {
@@ -483,7 +608,8 @@ func (burrowMethods *BurrowMethods) NameRegEntries(request *rpc.RPCRequest, requ
 	if err != nil {
 		return nil, rpc.INVALID_PARAMS, err
 	}
-	list, errC := burrowMethods.pipe.NameReg().Entries(param.Filters)
+	list, errC := burrowMethods.pipe.NameReg().Entries(param.Filters,
+		&core_types.PageRequest{Cursor: param.Cursor, Limit: param.Limit})
 	if errC != nil {
 		return nil, rpc.INTERNAL_ERROR, errC
 	}
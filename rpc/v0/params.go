@@ -32,11 +32,25 @@ type (
 	// TODO deprecate in favor of 'FilterListParam'
 	AccountsParam struct {
 		Filters []*event.FilterData `json:"filters"`
+		// Page of the accounts, see core/types.PageRequest
+		Cursor string `json:"cursor"`
+		Limit  int    `json:"limit"`
 	}
 
 	// Used to send an address
 	FilterListParam struct {
 		Filters []*event.FilterData `json:"filters"`
+		// Page of the list, see core/types.PageRequest
+		Cursor string `json:"cursor"`
+		Limit  int    `json:"limit"`
+	}
+
+	// Storage of an account
+	StorageParam struct {
+		Address []byte `json:"address"`
+		// Page of the storage, see core/types.PageRequest
+		Cursor string `json:"cursor"`
+		Limit  int    `json:"limit"`
 	}
 
 	PrivKeyParam struct {
@@ -58,16 +72,25 @@ type (
 	// TODO deprecate in favor of 'FilterListParam'
 	BlocksParam struct {
 		Filters []*event.FilterData `json:"filters"`
+		// Page of the blocks, see core/types.PageRequest
+		Cursor string `json:"cursor"`
+		Limit  int    `json:"limit"`
 	}
 
 	// Event Id
 	EventIdParam struct {
 		EventId string `json:"event_id"`
+		// Resume from this sequence in the event journal, if set
+		FromSequence *uint64 `json:"from_sequence"`
+		// Query over the data of journaled events, eg. "tx_type:call value:>100"
+		Filter string `json:"filter"`
 	}
 
 	// Event Id
 	SubIdParam struct {
 		SubId string `json:"sub_id"`
+		// Rewind or skip to this sequence in the event journal, if set
+		FromSequence *uint64 `json:"from_sequence"`
 	}
 
 	PeerParam struct {
@@ -18,7 +18,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -41,67 +40,85 @@ type RestServer struct {
 	pipe          definitions.Pipe
 	eventSubs     *event.EventSubscriptions
 	filterFactory *event.FilterFactory
+	auth          *server.Auth
+	limiter       *server.RateLimiter
 	running       bool
 }
 
-// Create a new rest server.
+// Create a new rest server. Routes are authorized by auth and rate limited by
+// limiter, either of which may be nil, as the JSON-RPC methods they correspond
+// to.
 func NewRestServer(codec rpc.Codec, pipe definitions.Pipe,
-	eventSubs *event.EventSubscriptions) *RestServer {
+	eventSubs *event.EventSubscriptions, auth *server.Auth,
+	limiter *server.RateLimiter) *RestServer {
 	return &RestServer{
 		codec:         codec,
 		pipe:          pipe,
 		eventSubs:     eventSubs,
 		filterFactory: blockchain.NewBlockchainFilterFactory(),
+		auth:          auth,
+		limiter:       limiter,
 	}
 }
 
 // Starting the server means registering all the handlers with the router.
 func (restServer *RestServer) Start(config *server.ServerConfig, router *gin.Engine) {
 	// Accounts
-	router.GET("/accounts", parseSearchQuery, restServer.handleAccounts)
-	router.GET("/accounts/:address", addressParam, restServer.handleAccount)
-	router.GET("/accounts/:address/storage", addressParam, restServer.handleStorage)
-	router.GET("/accounts/:address/storage/:key", addressParam, keyParam, restServer.handleStorageAt)
+	router.GET("/accounts", restServer.authorize(GET_ACCOUNTS), restServer.limit(GET_ACCOUNTS), parseSearchQuery, parsePageQuery, restServer.handleAccounts)
+	router.GET("/accounts/:address", restServer.authorize(GET_ACCOUNT), restServer.limit(GET_ACCOUNT), addressParam, restServer.handleAccount)
+	router.GET("/accounts/:address/storage", restServer.authorize(GET_STORAGE), restServer.limit(GET_STORAGE), addressParam, parsePageQuery, restServer.handleStorage)
+	router.GET("/accounts/:address/storage/:key", restServer.authorize(GET_STORAGE_AT), restServer.limit(GET_STORAGE_AT), addressParam, keyParam, restServer.handleStorageAt)
 	// Blockchain
-	router.GET("/blockchain", restServer.handleBlockchainInfo)
-	router.GET("/blockchain/chain_id", restServer.handleChainId)
-	router.GET("/blockchain/genesis_hash", restServer.handleGenesisHash)
-	router.GET("/blockchain/latest_block_height", restServer.handleLatestBlockHeight)
-	router.GET("/blockchain/latest_block", restServer.handleLatestBlock)
-	router.GET("/blockchain/blocks", parseSearchQuery, restServer.handleBlocks)
-	router.GET("/blockchain/block/:height", heightParam, restServer.handleBlock)
+	router.GET("/blockchain", restServer.authorize(GET_BLOCKCHAIN_INFO), restServer.limit(GET_BLOCKCHAIN_INFO), restServer.handleBlockchainInfo)
+	router.GET("/blockchain/chain_id", restServer.authorize(GET_CHAIN_ID), restServer.limit(GET_CHAIN_ID), restServer.handleChainId)
+	router.GET("/blockchain/genesis_hash", restServer.authorize(GET_GENESIS_HASH), restServer.limit(GET_GENESIS_HASH), restServer.handleGenesisHash)
+	router.GET("/blockchain/latest_block_height", restServer.authorize(GET_LATEST_BLOCK_HEIGHT), restServer.limit(GET_LATEST_BLOCK_HEIGHT), restServer.handleLatestBlockHeight)
+	router.GET("/blockchain/latest_block", restServer.authorize(GET_LATEST_BLOCK), restServer.limit(GET_LATEST_BLOCK), restServer.handleLatestBlock)
+	router.GET("/blockchain/blocks", restServer.authorize(GET_BLOCKS), restServer.limit(GET_BLOCKS), parseSearchQuery, parsePageQuery, restServer.handleBlocks)
+	router.GET("/blockchain/block/:height", restServer.authorize(GET_BLOCK), restServer.limit(GET_BLOCK), heightParam, restServer.handleBlock)
 	// Consensus
-	router.GET("/consensus", restServer.handleConsensusState)
-	router.GET("/consensus/validators", restServer.handleValidatorList)
+	router.GET("/consensus", restServer.authorize(GET_CONSENSUS_STATE), restServer.limit(GET_CONSENSUS_STATE), restServer.handleConsensusState)
+	router.GET("/consensus/validators", restServer.authorize(GET_VALIDATORS), restServer.limit(GET_VALIDATORS), restServer.handleValidatorList)
 	// Events
-	router.POST("/event_subs", restServer.handleEventSubscribe)
-	router.GET("/event_subs/:id", restServer.handleEventPoll)
-	router.DELETE("/event_subs/:id", restServer.handleEventUnsubscribe)
+	router.POST("/event_subs", restServer.authorize(EVENT_SUBSCRIBE), restServer.limit(EVENT_SUBSCRIBE), restServer.handleEventSubscribe)
+	router.GET("/event_subs/:id", restServer.authorize(EVENT_POLL), restServer.limit(EVENT_POLL), restServer.handleEventPoll)
+	router.DELETE("/event_subs/:id", restServer.authorize(EVENT_UNSUBSCRIBE), restServer.limit(EVENT_UNSUBSCRIBE), restServer.handleEventUnsubscribe)
+	router.GET("/event_metrics", restServer.authorize(GET_EVENT_METRICS), restServer.limit(GET_EVENT_METRICS), restServer.handleEventMetrics)
 	// NameReg
-	router.GET("/namereg", parseSearchQuery, restServer.handleNameRegEntries)
-	router.GET("/namereg/:key", nameParam, restServer.handleNameRegEntry)
+	router.GET("/namereg", restServer.authorize(GET_NAMEREG_ENTRIES), restServer.limit(GET_NAMEREG_ENTRIES), parseSearchQuery, parsePageQuery, restServer.handleNameRegEntries)
+	router.GET("/namereg/:key", restServer.authorize(GET_NAMEREG_ENTRY), restServer.limit(GET_NAMEREG_ENTRY), nameParam, restServer.handleNameRegEntry)
 	// Network
-	router.GET("/network", restServer.handleNetworkInfo)
-	router.GET("/network/client_version", restServer.handleClientVersion)
-	router.GET("/network/moniker", restServer.handleMoniker)
-	router.GET("/network/listening", restServer.handleListening)
-	router.GET("/network/listeners", restServer.handleListeners)
-	router.GET("/network/peers", restServer.handlePeers)
-	router.GET("/network/peers/:address", peerAddressParam, restServer.handlePeer)
+	router.GET("/network", restServer.authorize(GET_NETWORK_INFO), restServer.limit(GET_NETWORK_INFO), restServer.handleNetworkInfo)
+	router.GET("/network/client_version", restServer.authorize(GET_CLIENT_VERSION), restServer.limit(GET_CLIENT_VERSION), restServer.handleClientVersion)
+	router.GET("/network/moniker", restServer.authorize(GET_MONIKER), restServer.limit(GET_MONIKER), restServer.handleMoniker)
+	router.GET("/network/listening", restServer.authorize(IS_LISTENING), restServer.limit(IS_LISTENING), restServer.handleListening)
+	router.GET("/network/listeners", restServer.authorize(GET_LISTENERS), restServer.limit(GET_LISTENERS), restServer.handleListeners)
+	router.GET("/network/peers", restServer.authorize(GET_PEERS), restServer.limit(GET_PEERS), restServer.handlePeers)
+	router.GET("/network/peers/:address", restServer.authorize(GET_PEER), restServer.limit(GET_PEER), peerAddressParam, restServer.handlePeer)
 	// Tx related (TODO get txs has still not been implemented)
-	router.POST("/txpool", restServer.handleBroadcastTx)
-	router.GET("/txpool", restServer.handleUnconfirmedTxs)
+	router.POST("/txpool", restServer.authorize(BROADCAST_TX), restServer.limit(BROADCAST_TX), restServer.handleBroadcastTx)
+	router.GET("/txpool", restServer.authorize(GET_UNCONFIRMED_TXS), restServer.limit(GET_UNCONFIRMED_TXS), restServer.handleUnconfirmedTxs)
 	// Code execution
-	router.POST("/calls", restServer.handleCall)
-	router.POST("/codecalls", restServer.handleCallCode)
+	router.POST("/calls", restServer.authorize(CALL), restServer.limit(CALL), restServer.handleCall)
+	router.POST("/codecalls", restServer.authorize(CALL_CODE), restServer.limit(CALL_CODE), restServer.handleCallCode)
 	// Unsafe
-	router.GET("/unsafe/pa_generator", restServer.handleGenPrivAcc)
-	router.POST("/unsafe/txpool", parseTxModifier, restServer.handleTransact)
-	router.POST("/unsafe/namereg/txpool", restServer.handleTransactNameReg)
-	router.POST("/unsafe/tx_signer", restServer.handleSignTx)
+	router.GET("/unsafe/pa_generator", restServer.authorize(GEN_PRIV_ACCOUNT), restServer.limit(GEN_PRIV_ACCOUNT), restServer.handleGenPrivAcc)
+	router.POST("/unsafe/txpool", restServer.authorize(TRANSACT), restServer.limit(TRANSACT), parseTxModifier, restServer.handleTransact)
+	router.POST("/unsafe/namereg/txpool", restServer.authorize(TRANSACT_NAMEREG), restServer.limit(TRANSACT_NAMEREG), restServer.handleTransactNameReg)
+	router.POST("/unsafe/tx_signer", restServer.authorize(SIGN_TX), restServer.limit(SIGN_TX), restServer.handleSignTx)
 	restServer.running = true
 }
 
+// Authorizes the requests of a route as method
+func (restServer *RestServer) authorize(method string) gin.HandlerFunc {
+	return restServer.auth.Handler(method, methodRoles)
+}
+
+// Rate limits the requests of a route as method
+func (restServer *RestServer) limit(method string) gin.HandlerFunc {
+	return restServer.limiter.Handler(restServer.auth, method, methodClasses)
+}
+
 // Is the server currently running?
 func (restServer *RestServer) Running() bool {
 	return restServer.running
@@ -137,7 +154,7 @@ func (restServer *RestServer) handleAccounts(c *gin.Context) {
 	if exists {
 		filters = fs.([]*event.FilterData)
 	}
-	accs, err := restServer.pipe.Accounts().Accounts(filters)
+	accs, err := restServer.pipe.Accounts().Accounts(filters, pageOf(c))
 	if err != nil {
 		c.AbortWithError(500, err)
 	}
@@ -157,7 +174,7 @@ func (restServer *RestServer) handleAccount(c *gin.Context) {
 
 func (restServer *RestServer) handleStorage(c *gin.Context) {
 	addr := c.MustGet("addrBts").([]byte)
-	s, err := restServer.pipe.Accounts().Storage(addr)
+	s, err := restServer.pipe.Accounts().Storage(addr, pageOf(c))
 	if err != nil {
 		c.AbortWithError(500, err)
 	}
@@ -217,7 +234,7 @@ func (restServer *RestServer) handleBlocks(c *gin.Context) {
 	}
 
 	blocks, err := blockchain.FilterBlocks(restServer.pipe.Blockchain(),
-		restServer.filterFactory, filters)
+		restServer.filterFactory, filters, pageOf(c))
 
 	if err != nil {
 		c.AbortWithError(500, err)
@@ -254,7 +271,16 @@ func (restServer *RestServer) handleEventSubscribe(c *gin.Context) {
 	if errD != nil {
 		c.AbortWithError(500, errD)
 	}
-	subId, err := restServer.eventSubs.Add(param.EventId)
+	var subId string
+	var err error
+	if param.Filter != "" {
+		subId, err = restServer.eventSubs.AddFilter(param.EventId, param.Filter,
+			param.FromSequence)
+	} else if param.FromSequence != nil {
+		subId, err = restServer.eventSubs.AddFromSequence(param.EventId, *param.FromSequence)
+	} else {
+		subId, err = restServer.eventSubs.Add(param.EventId)
+	}
 	if err != nil {
 		c.AbortWithError(500, err)
 	}
@@ -264,12 +290,21 @@ func (restServer *RestServer) handleEventSubscribe(c *gin.Context) {
 
 func (restServer *RestServer) handleEventPoll(c *gin.Context) {
 	subId := c.MustGet("id").(string)
-	data, err := restServer.eventSubs.Poll(subId)
+	var fromSequence *uint64
+	if fromSequenceS := c.Query("from_sequence"); fromSequenceS != "" {
+		sequence, err := strconv.ParseUint(fromSequenceS, 10, 64)
+		if err != nil {
+			c.AbortWithError(400, fmt.Errorf("from_sequence is misformatted: %v", err))
+			return
+		}
+		fromSequence = &sequence
+	}
+	response, err := restServer.eventSubs.PollFromSequence(subId, fromSequence)
 	if err != nil {
 		c.AbortWithError(500, err)
 	}
 	c.Writer.WriteHeader(200)
-	restServer.codec.Encode(&event.PollResponse{data}, c.Writer)
+	restServer.codec.Encode(response, c.Writer)
 }
 
 func (restServer *RestServer) handleEventUnsubscribe(c *gin.Context) {
@@ -282,6 +317,12 @@ func (restServer *RestServer) handleEventUnsubscribe(c *gin.Context) {
 	restServer.codec.Encode(&event.EventUnsub{true}, c.Writer)
 }
 
+func (restServer *RestServer) handleEventMetrics(c *gin.Context) {
+	metrics := server.GetEventMetrics()
+	c.Writer.WriteHeader(200)
+	restServer.codec.Encode(&metrics, c.Writer)
+}
+
 // ********************************* NameReg *********************************
 
 func (restServer *RestServer) handleNameRegEntries(c *gin.Context) {
@@ -290,7 +331,7 @@ func (restServer *RestServer) handleNameRegEntries(c *gin.Context) {
 	if exists {
 		filters = fs.([]*event.FilterData)
 	}
-	entries, err := restServer.pipe.NameReg().Entries(filters)
+	entries, err := restServer.pipe.NameReg().Entries(filters, pageOf(c))
 	if err != nil {
 		c.AbortWithError(500, err)
 	}
@@ -545,74 +586,29 @@ func parseSearchQuery(c *gin.Context) {
 }
 
 func _parseSearchQuery(queryString string) ([]*event.FilterData, error) {
-	if len(queryString) == 0 {
-		return nil, nil
-	}
-	filters := strings.Split(queryString, " ")
-	fdArr := []*event.FilterData{}
-	for _, f := range filters {
-		kv := strings.Split(f, ":")
-		if len(kv) != 2 {
-			return nil, fmt.Errorf("Malformed query. Missing ':' separator: " + f)
-		}
-		if kv[0] == "" {
-			return nil, fmt.Errorf("Malformed query. Field name missing: " + f)
-		}
+	return event.ParseFilterQuery(queryString)
+}
 
-		fd, fd2, errTfd := toFilterData(kv[0], kv[1])
-		if errTfd != nil {
-			return nil, errTfd
-		}
-		fdArr = append(fdArr, fd)
-		if fd2 != nil {
-			fdArr = append(fdArr, fd2)
+// Reads the page of a listing from the cursor and limit parameters.
+func parsePageQuery(c *gin.Context) {
+	page := &core_types.PageRequest{Cursor: c.Query("cursor")}
+	if limit := c.Query("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			c.Writer.WriteHeader(400)
+			c.Writer.Write([]byte("Invalid limit: " + limit))
+			c.Abort()
+			return
 		}
+		page.Limit = l
 	}
-	return fdArr, nil
+	c.Set("page", page)
 }
 
-// Parse the query statement and create . Two filter data in case of a range param.
-func toFilterData(field, stmt string) (*event.FilterData, *event.FilterData, error) {
-	// In case statement is empty
-	if stmt == "" {
-		return &event.FilterData{field, "==", ""}, nil, nil
-	}
-	// Simple routine based on string splitting. TODO add quoted range query.
-	if stmt[0] == '>' || stmt[0] == '<' || stmt[0] == '=' || stmt[0] == '!' {
-		// restServer means a normal operator. If one character then stop, otherwise
-		// peek at next and check if it's a "=".
-
-		if len(stmt) == 1 {
-			return &event.FilterData{field, stmt[0:1], ""}, nil, nil
-		} else if stmt[1] == '=' {
-			return &event.FilterData{field, stmt[:2], stmt[2:]}, nil, nil
-		} else {
-			return &event.FilterData{field, stmt[0:1], stmt[1:]}, nil, nil
-		}
-	} else {
-		// Either we have a range query here or a malformed query.
-		rng := strings.Split(stmt, "..")
-		// restServer is for when there is no op, but the value is not empty.
-		if len(rng) == 1 {
-			return &event.FilterData{field, "==", stmt}, nil, nil
-		}
-		// The rest.
-		if len(rng) != 2 || rng[0] == "" || rng[1] == "" {
-			return nil, nil, fmt.Errorf("Malformed query statement: " + stmt)
-		}
-		var min string
-		var max string
-		if rng[0] == "*" {
-			min = "min"
-		} else {
-			min = rng[0]
-		}
-		if rng[1] == "*" {
-			max = "max"
-		} else {
-			max = rng[1]
-		}
-		return &event.FilterData{field, ">=", min}, &event.FilterData{field, "<=", max}, nil
+// The page set by parsePageQuery, or nil for the first page
+func pageOf(c *gin.Context) *core_types.PageRequest {
+	if page, exists := c.Get("page"); exists {
+		return page.(*core_types.PageRequest)
 	}
-	return nil, nil, nil
+	return nil
 }
@@ -77,6 +77,10 @@ func (pipe *MockPipe) Events() event.EventEmitter {
 	return pipe.events
 }
 
+func (pipe *MockPipe) EventJournal() *event.EventJournal {
+	return nil
+}
+
 func (pipe *MockPipe) NameReg() definitions.NameReg {
 	return pipe.namereg
 }
@@ -135,7 +139,8 @@ func (acc *accounts) GenPrivAccountFromKey(key []byte) (*account.PrivAccount, er
 	return acc.testData.GenPrivAccount.Output, nil
 }
 
-func (acc *accounts) Accounts([]*event.FilterData) (*core_types.AccountList, error) {
+func (acc *accounts) Accounts([]*event.FilterData,
+	*core_types.PageRequest) (*core_types.AccountList, error) {
 	return acc.testData.GetAccounts.Output, nil
 }
 
@@ -143,7 +148,8 @@ func (acc *accounts) Account(address []byte) (*account.Account, error) {
 	return acc.testData.GetAccount.Output, nil
 }
 
-func (acc *accounts) Storage(address []byte) (*core_types.Storage, error) {
+func (acc *accounts) Storage(address []byte,
+	page *core_types.PageRequest) (*core_types.Storage, error) {
 	return acc.testData.GetStorage.Output, nil
 }
 
@@ -258,7 +264,8 @@ func (nmreg *namereg) Entry(key string) (*core_types.NameRegEntry, error) {
 	return nmreg.testData.GetNameRegEntry.Output, nil
 }
 
-func (nmreg *namereg) Entries(filters []*event.FilterData) (*core_types.ResultListNames, error) {
+func (nmreg *namereg) Entries(filters []*event.FilterData,
+	page *core_types.PageRequest) (*core_types.ResultListNames, error) {
 	return nmreg.testData.GetNameRegEntries.Output, nil
 }
 
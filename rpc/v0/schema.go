@@ -0,0 +1,52 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v0
+
+import (
+	"reflect"
+
+	"github.com/hyperledger/burrow/rpc/schema"
+)
+
+// Returns the methods of the JSON-RPC API as described by its tables, for
+// generating its schema.
+func MethodSpecs() []schema.MethodSpec {
+	specs := make([]schema.MethodSpec, 0, len(methodTypes))
+	for method, types := range methodTypes {
+		spec := schema.MethodSpec{
+			Name: method,
+			Result: schema.Param{
+				Name: "result",
+				Type: reflect.TypeOf(types.result),
+			},
+			Role:           methodRoles.Role(method).String(),
+			RateLimitClass: methodClasses[method].String(),
+		}
+		if types.params != nil {
+			paramsType := reflect.TypeOf(types.params).Elem()
+			if paramsType.Kind() == reflect.Struct {
+				spec.Params = schema.FieldParams(types.params)
+			} else {
+				// such as the tx of broadcastTx
+				spec.Description = "The params are a single value rather " +
+					"than an object of named params."
+				spec.ParamStructure = "by-position"
+				spec.Params = []schema.Param{{Name: "params", Type: paramsType}}
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
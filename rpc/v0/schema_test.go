@@ -0,0 +1,53 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v0
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Every method served must be described, or the schema drifts from the API
+func TestMethodTypes(t *testing.T) {
+	methods := NewBurrowMethods(NewTCodec(), nil).getMethods()
+	for _, method := range []string{EVENT_SUBSCRIBE, EVENT_UNSUBSCRIBE,
+		EVENT_POLL} {
+		methods[method] = nil
+	}
+	for method := range methods {
+		assert.Contains(t, methodTypes, method)
+	}
+	assert.Len(t, methodTypes, len(methods))
+}
+
+func TestMethodSpecs(t *testing.T) {
+	for _, spec := range MethodSpecs() {
+		switch spec.Name {
+		case GET_ACCOUNTS:
+			assert.Len(t, spec.Params, 3)
+			assert.Equal(t, "filters", spec.Params[0].Name)
+			assert.Equal(t, "read_only", spec.Role)
+			assert.Equal(t, "scan", spec.RateLimitClass)
+		case BROADCAST_TX:
+			assert.Len(t, spec.Params, 1)
+			assert.Equal(t, "by-position", spec.ParamStructure)
+			assert.NotEmpty(t, spec.Description)
+		case GEN_PRIV_ACCOUNT:
+			assert.Empty(t, spec.Params)
+			assert.Equal(t, "admin", spec.Role)
+		}
+	}
+}
@@ -29,13 +29,20 @@ import (
 type BurrowWsService struct {
 	codec           rpc.Codec
 	pipe            definitions.Pipe
+	auth            *server.Auth
+	limiter         *server.RateLimiter
+	maxBatchSize    int
 	defaultHandlers map[string]RequestHandlerFunc
 }
 
-// Create a new websocket service.
-func NewBurrowWsService(codec rpc.Codec,
-	pipe definitions.Pipe) server.WebSocketService {
-	tmwss := &BurrowWsService{codec: codec, pipe: pipe}
+// Create a new websocket service. Requests are authorized by auth for the role
+// the session authenticated as, and rate limited by limiter for its client.
+// Either may be nil. Batches of more than maxBatchSize requests are refused.
+func NewBurrowWsService(codec rpc.Codec, pipe definitions.Pipe,
+	auth *server.Auth, limiter *server.RateLimiter,
+	maxBatchSize int) server.WebSocketService {
+	tmwss := &BurrowWsService{codec: codec, pipe: pipe, auth: auth,
+		limiter: limiter, maxBatchSize: maxBatchSize}
 	mtds := NewBurrowMethods(codec, pipe)
 
 	dhMap := mtds.getMethods()
@@ -46,39 +53,76 @@ func NewBurrowWsService(codec rpc.Codec,
 	return tmwss
 }
 
-// Process a request.
+// Process a request, or a batch of requests. The requests of a batch are
+// answered in one message, with an array of their results and errors.
 func (this *BurrowWsService) Process(msg []byte, session *server.WSSession) {
-	// Create new request object and unmarshal.
-	req := &rpc.RPCRequest{}
-	errU := json.Unmarshal(msg, req)
-
-	// Error when unmarshaling.
-	if errU != nil {
-		this.writeError("Failed to parse request: "+errU.Error()+" . Raw: "+string(msg),
-			"", rpc.PARSE_ERROR, session)
+	if !isBatch(msg) {
+		// Create new request object and unmarshal.
+		req := &rpc.RPCRequest{}
+		errU := json.Unmarshal(msg, req)
+
+		// Error when unmarshaling.
+		if errU != nil {
+			this.writeError("Failed to parse request: "+errU.Error()+" . Raw: "+string(msg),
+				"", rpc.PARSE_ERROR, session)
+			return
+		}
+		this.write(this.handle(req, session), session)
 		return
 	}
 
+	items, errCode, err := decodeBatch(msg, this.maxBatchSize)
+	if err != nil {
+		this.writeError(err.Error(), "", errCode, session)
+		return
+	}
+	for _, item := range items {
+		if item.request != nil {
+			item.response = this.handle(item.request, session)
+		}
+	}
+	bts, err := encodeBatch(this.codec, items)
+	if err != nil {
+		this.writeError("Internal error: "+err.Error(), "", rpc.INTERNAL_ERROR,
+			session)
+		return
+	}
+	if bts != nil {
+		session.Write(bts)
+	}
+}
+
+// Calls the handler of a request, returning its response.
+func (this *BurrowWsService) handle(req *rpc.RPCRequest,
+	session *server.WSSession) rpc.RPCResponse {
 	// Wrong protocol version.
 	if req.JSONRPC != "2.0" {
-		this.writeError("Wrong protocol version: "+req.JSONRPC, req.Id,
-			rpc.INVALID_REQUEST, session)
-		return
+		return rpc.NewRPCErrorResponse(req.Id, rpc.INVALID_REQUEST,
+			"Wrong protocol version: "+req.JSONRPC)
 	}
 
 	mName := req.Method
 
-	if handler, ok := this.defaultHandlers[mName]; ok {
-		resp, errCode, err := handler(req, session)
-		if err != nil {
-			this.writeError(err.Error(), req.Id, errCode, session)
-		} else {
-			this.writeResponse(req.Id, resp, session)
-		}
-	} else {
-		this.writeError("Method not found: "+mName, req.Id,
-			rpc.METHOD_NOT_FOUND, session)
+	handler, ok := this.defaultHandlers[mName]
+	if !ok {
+		return rpc.NewRPCErrorResponse(req.Id, rpc.METHOD_NOT_FOUND,
+			"Method not found: "+mName)
+	}
+	err := this.auth.Authorize(session.Role(), mName, methodRoles)
+	if err != nil {
+		return rpc.NewRPCErrorResponse(req.Id, rpc.UNAUTHORIZED, err.Error())
+	}
+	release, err := this.limiter.Limit(session.ClientId(), mName,
+		methodClasses)
+	if err != nil {
+		return rpc.NewRPCErrorResponse(req.Id, rpc.RATE_LIMITED, err.Error())
+	}
+	resp, errCode, err := handler(req, session)
+	release()
+	if err != nil {
+		return rpc.NewRPCErrorResponse(req.Id, errCode, err.Error())
 	}
+	return rpc.NewRPCResponse(req.Id, resp)
 }
 
 // Convenience method for writing error responses.
@@ -96,10 +140,15 @@ func (this *BurrowWsService) writeError(msg, id string, code int,
 // Convenience method for writing responses.
 func (this *BurrowWsService) writeResponse(id string, result interface{},
 	session *server.WSSession) error {
-	response := rpc.NewRPCResponse(id, result)
+	return this.write(rpc.NewRPCResponse(id, result), session)
+}
+
+// Writes a response, which may be an error response.
+func (this *BurrowWsService) write(response rpc.RPCResponse,
+	session *server.WSSession) error {
 	bts, err := this.codec.EncodeBytes(response)
 	if err != nil {
-		this.writeError("Internal error: "+err.Error(), id, rpc.INTERNAL_ERROR, session)
+		this.writeError("Internal error: "+err.Error(), "", rpc.INTERNAL_ERROR, session)
 		return err
 	}
 	return session.Write(bts)
@@ -125,8 +174,16 @@ func (this *BurrowWsService) EventSubscribe(request *rpc.RPCRequest,
 		return nil, rpc.INTERNAL_ERROR, errSID
 	}
 
+	// Events are queued by the session, so a slow client cannot block the
+	// firing of events
 	callback := func(ret txs.EventData) {
-		this.writeResponse(subId, ret, session)
+		bts, err := this.codec.EncodeBytes(rpc.NewRPCResponse(subId, ret))
+		if err != nil {
+			this.writeError("Internal error: "+err.Error(), subId,
+				rpc.INTERNAL_ERROR, session)
+			return
+		}
+		session.WriteEvent(subId, bts)
 	}
 	errC := this.pipe.Events().Subscribe(subId, eventId, callback)
 	if errC != nil {
@@ -135,6 +192,21 @@ func (this *BurrowWsService) EventSubscribe(request *rpc.RPCRequest,
 	return &event.EventSub{subId}, 0, nil
 }
 
+// Implements server.EventDropNotifier. The notice is an error response with
+// the id of the subscription the events were dropped from.
+func (this *BurrowWsService) DroppedEventsNotice(subId string,
+	dropped uint64) []byte {
+	response := rpc.NewRPCErrorResponse(subId, rpc.EVENTS_DROPPED,
+		fmt.Sprintf("%v events dropped, the client is reading events too "+
+			"slowly", dropped))
+	bts, err := this.codec.EncodeBytes(response)
+	// If there's an error here all bets are off.
+	if err != nil {
+		panic("Failed to marshal standard error response." + err.Error())
+	}
+	return bts
+}
+
 func (this *BurrowWsService) EventUnsubscribe(request *rpc.RPCRequest, requester interface{}) (interface{}, int, error) {
 	param := &EventIdParam{}
 	err := this.codec.DecodeBytes(param, request.Params)
@@ -0,0 +1,368 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Roles of RPC callers. Each role may call the methods of the roles below it.
+type Role int
+
+const (
+	// May call nothing
+	RoleNone Role = iota
+	// May read the chain state, run calls that are not persisted and
+	// subscribe to events
+	RoleReadOnly
+	// May also broadcast signed txs
+	RoleBroadcastOnly
+	// May also call the methods that take or generate private keys
+	RoleAdmin
+)
+
+var roleNames = map[Role]string{
+	RoleNone:          "none",
+	RoleReadOnly:      "read_only",
+	RoleBroadcastOnly: "broadcast_only",
+	RoleAdmin:         "admin",
+}
+
+func (role Role) String() string {
+	return roleNames[role]
+}
+
+func ParseRole(name string) (Role, error) {
+	for role, roleName := range roleNames {
+		if name == roleName {
+			return role, nil
+		}
+	}
+	return RoleNone, fmt.Errorf("Unknown role '%s', expected one of none, "+
+		"read_only, broadcast_only or admin", name)
+}
+
+// The least role needed to call each method of an API. Methods that are not
+// listed need RoleAdmin.
+type MethodRoles map[string]Role
+
+// Returns the least role needed to call method
+func (methodRoles MethodRoles) Role(method string) Role {
+	if role, ok := methodRoles[method]; ok {
+		return role
+	}
+	return RoleAdmin
+}
+
+// Authenticates RPC callers by API key, JWT bearer token or TLS client
+// certificate, and authorizes the methods they call by their role. A nil
+// *Auth, as returned when authentication is not enabled, lets every caller
+// call every method.
+type Auth struct {
+	anonymousRole  Role
+	apiKeys        map[string]Role
+	jwtSecret      []byte
+	clientCertRole Role
+	// roles set by the policy of the config, overriding the defaults of APIs
+	policy MethodRoles
+}
+
+// Returns the Auth configured in [servers.auth], which is nil unless it is
+// enabled.
+func NewAuth(config *ServerConfig) (*Auth, error) {
+	authConfig := config.Auth
+	if !authConfig.Enable {
+		return nil, nil
+	}
+	auth := &Auth{
+		apiKeys:   make(map[string]Role),
+		jwtSecret: []byte(authConfig.JWTSecret),
+		policy:    make(MethodRoles),
+	}
+	var err error
+	if auth.anonymousRole, err = parseConfigRole(authConfig.AnonymousRole); err != nil {
+		return nil, fmt.Errorf("Invalid anonymous_role: %v", err)
+	}
+	if auth.clientCertRole, err = parseConfigRole(authConfig.ClientCertRole); err != nil {
+		return nil, fmt.Errorf("Invalid client_cert_role: %v", err)
+	}
+	for _, apiKey := range authConfig.APIKeys {
+		i := strings.LastIndex(apiKey, ":")
+		if i < 1 {
+			return nil, fmt.Errorf("API key is not of the form <key>:<role>")
+		}
+		role, err := ParseRole(apiKey[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid role of API key: %v", err)
+		}
+		auth.apiKeys[apiKey[:i]] = role
+	}
+	for role, methods := range map[Role][]string{
+		RoleReadOnly:      authConfig.Policy.ReadOnly,
+		RoleBroadcastOnly: authConfig.Policy.BroadcastOnly,
+		RoleAdmin:         authConfig.Policy.Admin,
+	} {
+		for _, method := range methods {
+			auth.policy[method] = role
+		}
+	}
+	return auth, nil
+}
+
+// Empty roles in the config are none
+func parseConfigRole(name string) (Role, error) {
+	if name == "" {
+		return RoleNone, nil
+	}
+	return ParseRole(name)
+}
+
+// Loads the CAs of TLS client certificates, returning nil when client
+// certificates are not to be requested.
+func ClientCAs(config *ServerConfig) (*x509.CertPool, error) {
+	if !config.Auth.Enable || config.Auth.ClientCAPath == "" {
+		return nil, nil
+	}
+	pem, err := ioutil.ReadFile(config.Auth.ClientCAPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read client CA: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("No certificates found in client CA %s",
+			config.Auth.ClientCAPath)
+	}
+	return pool, nil
+}
+
+// Credentials presented by a caller, as read from its request by the
+// transport it called over
+type Credentials struct {
+	BearerToken string
+	APIKey      string
+	// whether the caller presented a verified TLS client certificate
+	ClientCert bool
+}
+
+// Returns the role of the caller making the request. Credentials are taken
+// from, in order:
+//
+//	the Authorization: Bearer <token> header, or the access_token parameter
+//	the X-Api-Key header, or the api_key parameter
+//	a verified TLS client certificate
+//
+// The parameters are for websocket clients that can not set headers. Invalid
+// credentials are an error, as are none when anonymous callers are refused.
+func (auth *Auth) Authenticate(r *http.Request) (Role, error) {
+	return auth.AuthenticateCredentials(requestCredentials(r))
+}
+
+// Returns the role of a caller presenting credentials, which are taken in the
+// order of Authenticate
+func (auth *Auth) AuthenticateCredentials(credentials Credentials) (Role, error) {
+	if auth == nil {
+		return RoleAdmin, nil
+	}
+	if credentials.BearerToken != "" {
+		return auth.jwtRole(credentials.BearerToken)
+	}
+	if credentials.APIKey != "" {
+		return auth.apiKeyRole(credentials.APIKey)
+	}
+	if credentials.ClientCert && auth.clientCertRole != RoleNone {
+		return auth.clientCertRole, nil
+	}
+	if auth.anonymousRole == RoleNone {
+		return RoleNone, fmt.Errorf("Authentication required")
+	}
+	return auth.anonymousRole, nil
+}
+
+func requestCredentials(r *http.Request) Credentials {
+	query := r.URL.Query()
+	credentials := Credentials{
+		BearerToken: bearerToken(r),
+		APIKey:      r.Header.Get("X-Api-Key"),
+		ClientCert:  r.TLS != nil && len(r.TLS.VerifiedChains) > 0,
+	}
+	if credentials.BearerToken == "" {
+		credentials.BearerToken = query.Get("access_token")
+	}
+	if credentials.APIKey == "" {
+		credentials.APIKey = query.Get("api_key")
+	}
+	return credentials
+}
+
+// Returns an error unless role may call method, whose least role is set by
+// the policy of the config or else by methodRoles.
+func (auth *Auth) Authorize(role Role, method string, methodRoles MethodRoles) error {
+	if auth == nil {
+		return nil
+	}
+	required, ok := auth.policy[method]
+	if !ok {
+		required = methodRoles.Role(method)
+	}
+	if role < required {
+		return fmt.Errorf("Role %v is not authorized to call %s", role, method)
+	}
+	return nil
+}
+
+// Handler authenticating and authorizing the requests of a route as method,
+// for APIs that map routes rather than requests onto methods.
+func (auth *Auth) Handler(method string, methodRoles MethodRoles) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, err := auth.Authenticate(c.Request)
+		if err != nil {
+			c.AbortWithError(401, err)
+			return
+		}
+		if err := auth.Authorize(role, method, methodRoles); err != nil {
+			c.AbortWithError(403, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// Identifies the client making the request, for rate limiting. Clients are
+// identified by the credentials they present when authentication is enabled,
+// so that the requests of a caller are counted together from any address, and
+// by their IP address otherwise, since unchecked credentials could be made up
+// anew for every request.
+func (auth *Auth) ClientId(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return auth.CredentialsClientId(requestCredentials(r), host)
+}
+
+// Identifies the client at host presenting credentials, as ClientId does
+func (auth *Auth) CredentialsClientId(credentials Credentials, host string) string {
+	if auth != nil {
+		credential := credentials.BearerToken
+		if credential == "" {
+			credential = credentials.APIKey
+		}
+		if credential != "" {
+			// the credential itself is not kept
+			hash := sha256.Sum256([]byte(credential))
+			return "credential:" + hex.EncodeToString(hash[:16])
+		}
+	}
+	return "address:" + host
+}
+
+func bearerToken(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(authorization[len("Bearer "):])
+}
+
+// Keys are compared in constant time so that they can not be guessed from
+// timings
+func (auth *Auth) apiKeyRole(apiKey string) (Role, error) {
+	role := RoleNone
+	for key, keyRole := range auth.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+			role = keyRole
+		}
+	}
+	if role == RoleNone {
+		return RoleNone, fmt.Errorf("Invalid API key")
+	}
+	return role, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+// Claims of JWT bearer tokens. The role claim is the role of the bearer.
+type jwtClaims struct {
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+	NotBefore int64  `json:"nbf"`
+}
+
+// Verifies an HS256 signed JWT, returning the role it grants
+func (auth *Auth) jwtRole(token string) (Role, error) {
+	if len(auth.jwtSecret) == 0 {
+		return RoleNone, fmt.Errorf("JWT bearer tokens are not accepted")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return RoleNone, fmt.Errorf("Malformed JWT")
+	}
+	header := &jwtHeader{}
+	if err := decodeJWTPart(parts[0], header); err != nil {
+		return RoleNone, err
+	}
+	if header.Alg != "HS256" {
+		return RoleNone, fmt.Errorf("Unsupported JWT algorithm '%s', only "+
+			"HS256 is accepted", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return RoleNone, fmt.Errorf("Malformed JWT signature: %v", err)
+	}
+	mac := hmac.New(sha256.New, auth.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return RoleNone, fmt.Errorf("Invalid JWT signature")
+	}
+	claims := &jwtClaims{}
+	if err := decodeJWTPart(parts[1], claims); err != nil {
+		return RoleNone, err
+	}
+	now := time.Now().Unix()
+	if claims.ExpiresAt != 0 && now >= claims.ExpiresAt {
+		return RoleNone, fmt.Errorf("JWT has expired")
+	}
+	if claims.NotBefore != 0 && now < claims.NotBefore {
+		return RoleNone, fmt.Errorf("JWT is not valid yet")
+	}
+	return ParseRole(claims.Role)
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	bs, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("Malformed JWT: %v", err)
+	}
+	if err := json.Unmarshal(bs, v); err != nil {
+		return fmt.Errorf("Malformed JWT: %v", err)
+	}
+	return nil
+}
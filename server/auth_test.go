@@ -0,0 +1,154 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAuth(t *testing.T) *Auth {
+	config := DefaultServerConfig()
+	config.Auth.Enable = true
+	config.Auth.AnonymousRole = "read_only"
+	config.Auth.APIKeys = []string{"broadcaster:broadcast_only", "root:admin"}
+	config.Auth.JWTSecret = "secret"
+	config.Auth.Policy.Admin = []string{"getAccounts"}
+	auth, err := NewAuth(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
+func newRequest(t *testing.T, url string, header ...string) *http.Request {
+	r, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	return r
+}
+
+func signJWT(secret, header, claims string) string {
+	encoding := base64.RawURLEncoding
+	signed := encoding.EncodeToString([]byte(header)) + "." +
+		encoding.EncodeToString([]byte(claims))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + encoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuthenticate(t *testing.T) {
+	auth := newTestAuth(t)
+	hs256 := `{"alg":"HS256","typ":"JWT"}`
+	expired := `{"role":"admin","exp":` +
+		strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10) + `}`
+	cases := []struct {
+		request *http.Request
+		role    Role
+		valid   bool
+	}{
+		{newRequest(t, "/rpc"), RoleReadOnly, true},
+		{newRequest(t, "/rpc", "X-Api-Key", "broadcaster"), RoleBroadcastOnly, true},
+		{newRequest(t, "/socketrpc?api_key=root"), RoleAdmin, true},
+		{newRequest(t, "/rpc", "X-Api-Key", "root2"), RoleNone, false},
+		{newRequest(t, "/rpc", "Authorization",
+			"Bearer "+signJWT("secret", hs256, `{"role":"admin"}`)), RoleAdmin, true},
+		{newRequest(t, "/socketrpc?access_token="+
+			signJWT("secret", hs256, `{"role":"broadcast_only"}`)), RoleBroadcastOnly, true},
+		{newRequest(t, "/rpc", "Authorization",
+			"Bearer "+signJWT("guess", hs256, `{"role":"admin"}`)), RoleNone, false},
+		{newRequest(t, "/rpc", "Authorization",
+			"Bearer "+signJWT("secret", `{"alg":"none"}`, `{"role":"admin"}`)), RoleNone, false},
+		{newRequest(t, "/rpc", "Authorization",
+			"Bearer "+signJWT("secret", hs256, expired)), RoleNone, false},
+	}
+	for i, c := range cases {
+		role, err := auth.Authenticate(c.request)
+		assert.Equal(t, c.valid, err == nil, "case %v", i)
+		assert.Equal(t, c.role, role, "case %v", i)
+	}
+
+	// anonymous callers can be refused
+	auth.anonymousRole = RoleNone
+	_, err := auth.Authenticate(newRequest(t, "/rpc"))
+	assert.Error(t, err)
+}
+
+func TestAuthorize(t *testing.T) {
+	auth := newTestAuth(t)
+	methodRoles := MethodRoles{
+		"getAccount":  RoleReadOnly,
+		"getAccounts": RoleReadOnly,
+		"broadcastTx": RoleBroadcastOnly,
+	}
+	assert.NoError(t, auth.Authorize(RoleReadOnly, "getAccount", methodRoles))
+	assert.Error(t, auth.Authorize(RoleReadOnly, "broadcastTx", methodRoles))
+	assert.NoError(t, auth.Authorize(RoleBroadcastOnly, "broadcastTx", methodRoles))
+	// unlisted methods need admin
+	assert.Error(t, auth.Authorize(RoleBroadcastOnly, "transact", methodRoles))
+	assert.NoError(t, auth.Authorize(RoleAdmin, "transact", methodRoles))
+	// the policy of the config overrides the defaults
+	assert.Error(t, auth.Authorize(RoleBroadcastOnly, "getAccounts", methodRoles))
+
+	// without auth anyone may call anything
+	var noAuth *Auth
+	role, err := noAuth.Authenticate(newRequest(t, "/rpc"))
+	assert.NoError(t, err)
+	assert.NoError(t, noAuth.Authorize(role, "transact", methodRoles))
+}
+
+func TestClientId(t *testing.T) {
+	auth := newTestAuth(t)
+	r := newRequest(t, "/rpc", "X-Api-Key", "root")
+	r.RemoteAddr = "10.0.0.1:5000"
+	other := newRequest(t, "/socketrpc?api_key=root")
+	other.RemoteAddr = "10.0.0.2:5000"
+	// callers are identified by their credentials from any address
+	assert.Equal(t, auth.ClientId(r), auth.ClientId(other))
+	assert.NotContains(t, auth.ClientId(r), "root")
+	anonymous := newRequest(t, "/rpc")
+	anonymous.RemoteAddr = "10.0.0.1:5001"
+	assert.Equal(t, "address:10.0.0.1", auth.ClientId(anonymous))
+
+	// without auth credentials are not checked, so they are ignored
+	var noAuth *Auth
+	assert.Equal(t, "address:10.0.0.1", noAuth.ClientId(r))
+}
+
+func TestNewAuth(t *testing.T) {
+	config := DefaultServerConfig()
+	auth, err := NewAuth(config)
+	assert.NoError(t, err)
+	assert.Nil(t, auth)
+
+	config.Auth.Enable = true
+	config.Auth.APIKeys = []string{"key:root"}
+	_, err = NewAuth(config)
+	assert.Error(t, err)
+	config.Auth.APIKeys = []string{"key"}
+	_, err = NewAuth(config)
+	assert.Error(t, err)
+}
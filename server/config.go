@@ -21,14 +21,20 @@ import (
 	viper "github.com/spf13/viper"
 )
 
+// Most requests in a JSON-RPC batch when http.max_batch_size is not set
+const DefaultMaxBatchSize = 100
+
 type (
 	ServerConfig struct {
 		ChainId    string
-		Bind       Bind      `toml:"bind"`
-		TLS        TLS       `toml:"TLS"`
-		CORS       CORS      `toml:"CORS"`
-		HTTP       HTTP      `toml:"HTTP"`
-		WebSocket  WebSocket `toml:"web_socket"`
+		Bind       Bind       `toml:"bind"`
+		TLS        TLS        `toml:"TLS"`
+		CORS       CORS       `toml:"CORS"`
+		HTTP       HTTP       `toml:"HTTP"`
+		WebSocket  WebSocket  `toml:"web_socket"`
+		GRPC       GRPC       `toml:"grpc"`
+		Auth       AuthConfig `toml:"auth"`
+		RateLimit  RateLimit  `toml:"rate_limit"`
 		Tendermint Tendermint
 		Logging    Logging `toml:"logging"`
 	}
@@ -57,6 +63,12 @@ type (
 
 	HTTP struct {
 		JsonRpcEndpoint string `toml:"json_rpc_endpoint"`
+		// Ethereum JSON-RPC is not served when empty
+		EthJsonRpcEndpoint string `toml:"eth_json_rpc_endpoint"`
+		// GraphQL is not served when empty
+		GraphQLEndpoint string `toml:"graphql_endpoint"`
+		// Most requests in a JSON-RPC batch, over HTTP and websockets alike
+		MaxBatchSize uint16 `toml:"max_batch_size"`
 	}
 
 	WebSocket struct {
@@ -64,6 +76,61 @@ type (
 		MaxWebSocketSessions uint16 `toml:"max_websocket_sessions"`
 		ReadBufferSize       uint64 `toml:"read_buffer_size"`
 		WriteBufferSize      uint64 `toml:"write_buffer_size"`
+		// Most events queued for each session, before the slow client
+		// policy applies
+		EventQueueSize int `toml:"event_queue_size"`
+		// One of disconnect, drop_oldest or coalesce
+		SlowClientPolicy string `toml:"slow_client_policy"`
+	}
+
+	// gRPC is not served when ListenAddress is empty
+	GRPC struct {
+		ListenAddress string `toml:"listen_address"`
+	}
+
+	// Authentication of RPC callers and the least role needed to call each
+	// method. Callers may call any method when it is not enabled.
+	AuthConfig struct {
+		Enable bool `toml:"enable"`
+		// Role of callers without credentials, none to refuse them
+		AnonymousRole string `toml:"anonymous_role"`
+		// Static API keys as "<key>:<role>"
+		APIKeys []string `toml:"api_keys"`
+		// Secret of HS256 signed JWT bearer tokens, which are not accepted when
+		// empty
+		JWTSecret string `toml:"jwt_secret"`
+		// CA of TLS client certificates, which are not requested when empty
+		ClientCAPath   string `toml:"client_ca_path"`
+		ClientCertRole string `toml:"client_cert_role"`
+		// Methods to require each role for, in place of their default role
+		Policy AuthPolicy `toml:"policy"`
+	}
+
+	AuthPolicy struct {
+		ReadOnly      []string `toml:"read_only"`
+		BroadcastOnly []string `toml:"broadcast_only"`
+		Admin         []string `toml:"admin"`
+	}
+
+	// Rate limits of the calls of each client, by method class. Clients are
+	// identified by their credentials when authentication is enabled, and by
+	// their IP address otherwise.
+	RateLimit struct {
+		Enable   bool  `toml:"enable"`
+		Read     Limit `toml:"read"`
+		Scan     Limit `toml:"scan"`
+		Simulate Limit `toml:"simulate"`
+		Transact Limit `toml:"transact"`
+		// Most calls running in the VM at once, across all clients. They are
+		// not capped when 0.
+		MaxConcurrentSimulations int `toml:"max_concurrent_simulations"`
+	}
+
+	// A token bucket allowing Rate calls per second, in bursts of up to Burst
+	// calls. Calls are not limited when Rate is 0.
+	Limit struct {
+		Rate  float64 `toml:"rate"`
+		Burst int     `toml:"burst"`
 	}
 
 	Tendermint struct {
@@ -99,6 +166,18 @@ func ReadServerConfig(viper *viper.Viper) (*ServerConfig, error) {
 	} else {
 		return nil, fmt.Errorf("Failed to read maximum age for CORS: %v", maxAge)
 	}
+	// check domain range for http.max_batch_size, which is the default when
+	// not set since no batch could be served with a maximum of 0
+	maxBatchSize := viper.GetInt("http.max_batch_size")
+	var maxBatchSizeUint16 uint16 = 0
+	if maxBatchSize == 0 {
+		maxBatchSizeUint16 = DefaultMaxBatchSize
+	} else if maxBatchSize > 0 && maxBatchSize <= math.MaxUint16 {
+		maxBatchSizeUint16 = uint16(maxBatchSize)
+	} else {
+		return nil, fmt.Errorf("Failed to read maximum batch size: %v",
+			maxBatchSize)
+	}
 	// check domain range for websocket.max_sessions
 	maxWebsocketSessions := viper.GetInt("websocket.max_sessions")
 	var maxWebsocketSessionsUint16 uint16 = 0
@@ -127,6 +206,16 @@ func ReadServerConfig(viper *viper.Viper) (*ServerConfig, error) {
 		return nil, fmt.Errorf("Failed to read websocket write buffer size: %v",
 			writeBufferSize)
 	}
+	// check domain range for websocket.event_queue_size
+	eventQueueSize := viper.GetInt("websocket.event_queue_size")
+	if eventQueueSize < 0 {
+		return nil, fmt.Errorf("Failed to read websocket event queue size: %v",
+			eventQueueSize)
+	}
+	slowClientPolicy := viper.GetString("websocket.slow_client_policy")
+	if _, err := ParseSlowClientPolicy(slowClientPolicy); err != nil {
+		return nil, err
+	}
 
 	return &ServerConfig{
 		Bind: Bind{
@@ -148,13 +237,42 @@ func ReadServerConfig(viper *viper.Viper) (*ServerConfig, error) {
 			MaxAge:           maxAgeUint64,
 		},
 		HTTP: HTTP{
-			JsonRpcEndpoint: viper.GetString("http.json_rpc_endpoint"),
+			JsonRpcEndpoint:    viper.GetString("http.json_rpc_endpoint"),
+			EthJsonRpcEndpoint: viper.GetString("http.eth_json_rpc_endpoint"),
+			GraphQLEndpoint:    viper.GetString("http.graphql_endpoint"),
+			MaxBatchSize:       maxBatchSizeUint16,
 		},
 		WebSocket: WebSocket{
 			WebSocketEndpoint:    viper.GetString("websocket.endpoint"),
 			MaxWebSocketSessions: maxWebsocketSessionsUint16,
 			ReadBufferSize:       readBufferSizeUint64,
 			WriteBufferSize:      writeBufferSizeUint64,
+			EventQueueSize:       eventQueueSize,
+			SlowClientPolicy:     slowClientPolicy,
+		},
+		GRPC: GRPC{
+			ListenAddress: viper.GetString("grpc.listen_address"),
+		},
+		Auth: AuthConfig{
+			Enable:         viper.GetBool("auth.enable"),
+			AnonymousRole:  viper.GetString("auth.anonymous_role"),
+			APIKeys:        viper.GetStringSlice("auth.api_keys"),
+			JWTSecret:      viper.GetString("auth.jwt_secret"),
+			ClientCAPath:   viper.GetString("auth.client_ca_path"),
+			ClientCertRole: viper.GetString("auth.client_cert_role"),
+			Policy: AuthPolicy{
+				ReadOnly:      viper.GetStringSlice("auth.policy.read_only"),
+				BroadcastOnly: viper.GetStringSlice("auth.policy.broadcast_only"),
+				Admin:         viper.GetStringSlice("auth.policy.admin"),
+			},
+		},
+		RateLimit: RateLimit{
+			Enable:                   viper.GetBool("rate_limit.enable"),
+			Read:                     readLimit(viper, "rate_limit.read"),
+			Scan:                     readLimit(viper, "rate_limit.scan"),
+			Simulate:                 readLimit(viper, "rate_limit.simulate"),
+			Transact:                 readLimit(viper, "rate_limit.transact"),
+			MaxConcurrentSimulations: viper.GetInt("rate_limit.max_concurrent_simulations"),
 		},
 		Tendermint: Tendermint{
 			RpcLocalAddress: viper.GetString("tendermint.rpc_local_address"),
@@ -168,6 +286,13 @@ func ReadServerConfig(viper *viper.Viper) (*ServerConfig, error) {
 	}, nil
 }
 
+func readLimit(viper *viper.Viper, key string) Limit {
+	return Limit{
+		Rate:  viper.GetFloat64(key + ".rate"),
+		Burst: viper.GetInt(key + ".burst"),
+	}
+}
+
 // NOTE: [ben] only preserved for /test/server tests; but should not be used and
 // will be deprecated.
 func DefaultServerConfig() *ServerConfig {
@@ -183,12 +308,18 @@ func DefaultServerConfig() *ServerConfig {
 			KeyPath:  kp,
 		},
 		CORS: CORS{},
-		HTTP: HTTP{JsonRpcEndpoint: "/rpc"},
+		HTTP: HTTP{JsonRpcEndpoint: "/rpc", EthJsonRpcEndpoint: "/eth",
+			GraphQLEndpoint: "/graphql", MaxBatchSize: DefaultMaxBatchSize},
 		WebSocket: WebSocket{
 			WebSocketEndpoint:    "/socketrpc",
 			MaxWebSocketSessions: 50,
 			ReadBufferSize:       4096,
 			WriteBufferSize:      4096,
+			EventQueueSize:       DefaultEventQueueSize,
+			SlowClientPolicy:     string(SlowClientDropOldest),
+		},
+		GRPC: GRPC{
+			ListenAddress: "",
 		},
 		Tendermint: Tendermint{
 			RpcLocalAddress: "0.0.0.0:46657",
@@ -0,0 +1,39 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+
+	viper "github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadServerConfigMaxBatchSize(t *testing.T) {
+	// configs written before max_batch_size was added get the default
+	config, err := ReadServerConfig(viper.New())
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(DefaultMaxBatchSize), config.HTTP.MaxBatchSize)
+
+	v := viper.New()
+	v.Set("http.max_batch_size", 10)
+	config, err = ReadServerConfig(v)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(10), config.HTTP.MaxBatchSize)
+
+	v.Set("http.max_batch_size", -1)
+	_, err = ReadServerConfig(v)
+	assert.Error(t, err)
+}
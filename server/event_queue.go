@@ -0,0 +1,198 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// What a websocket session does with an event when its queue of events is
+// full, because its client reads them slower than they are fired.
+type SlowClientPolicy string
+
+const (
+	// Close the session, so the client knows to reconnect and resubscribe
+	SlowClientDisconnect SlowClientPolicy = "disconnect"
+	// Drop the oldest queued event to make room
+	SlowClientDropOldest SlowClientPolicy = "drop_oldest"
+	// Replace the queued event of the same subscription, so that only its
+	// latest event is delivered. The oldest event is dropped when the
+	// subscription has none queued.
+	SlowClientCoalesce SlowClientPolicy = "coalesce"
+)
+
+// Events queued for a session when the configured size is 0
+const DefaultEventQueueSize = 1000
+
+// Parses a policy, defaulting to drop_oldest
+func ParseSlowClientPolicy(policy string) (SlowClientPolicy, error) {
+	switch SlowClientPolicy(policy) {
+	case "":
+		return SlowClientDropOldest, nil
+	case SlowClientDisconnect, SlowClientDropOldest, SlowClientCoalesce:
+		return SlowClientPolicy(policy), nil
+	}
+	return "", fmt.Errorf("Unknown slow client policy '%s', expected "+
+		"disconnect, drop_oldest or coalesce", policy)
+}
+
+// Counts of the events dropped for slow clients across all websocket
+// sessions, including those of the Tendermint websocket
+type EventMetrics struct {
+	// Events dropped, including those coalesced
+	Dropped uint64 `json:"dropped"`
+	// Events replaced by a later event of the same subscription
+	Coalesced uint64 `json:"coalesced"`
+	// Sessions closed because their queue was full
+	Disconnected uint64 `json:"disconnected"`
+}
+
+var eventMetrics EventMetrics
+
+func GetEventMetrics() EventMetrics {
+	return EventMetrics{
+		Dropped:      atomic.LoadUint64(&eventMetrics.Dropped),
+		Coalesced:    atomic.LoadUint64(&eventMetrics.Coalesced),
+		Disconnected: atomic.LoadUint64(&eventMetrics.Disconnected),
+	}
+}
+
+// Counts events dropped by a websocket that does not queue them in a session
+func CountDroppedEvents(dropped uint64) {
+	atomic.AddUint64(&eventMetrics.Dropped, dropped)
+}
+
+// Counts an event replaced by a later event of the same subscription, by a
+// websocket that does not queue them in a session. The event is also counted
+// as dropped.
+func CountCoalescedEvent() {
+	atomic.AddUint64(&eventMetrics.Coalesced, 1)
+	CountDroppedEvents(1)
+}
+
+// Counts a session closed because its client was too slow
+func CountSlowClientDisconnect() {
+	atomic.AddUint64(&eventMetrics.Disconnected, 1)
+}
+
+// Services whose sessions deliver events implement this to tell clients how
+// many events of a subscription were dropped since the last were delivered.
+type EventDropNotifier interface {
+	DroppedEventsNotice(subId string, dropped uint64) []byte
+}
+
+// An event of a subscription, as queued
+type QueuedEvent struct {
+	SubId string
+	// the message of a session, or the result of a Tendermint subscription
+	Msg interface{}
+}
+
+// The bounded queue of the events of the subscriptions of a session, which
+// is drained by its write pump so that firing events never blocks on a
+// client.
+type EventQueue struct {
+	mtx    sync.Mutex
+	size   int
+	policy SlowClientPolicy
+	events []QueuedEvent
+	// by subscription, since the events were last taken
+	dropped map[string]uint64
+	// signalled when there are events to take
+	ready chan struct{}
+}
+
+func NewEventQueue(size int, policy SlowClientPolicy) *EventQueue {
+	if size <= 0 {
+		size = DefaultEventQueueSize
+	}
+	if policy == "" {
+		policy = SlowClientDropOldest
+	}
+	return &EventQueue{
+		size:    size,
+		policy:  policy,
+		dropped: make(map[string]uint64),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// Queues an event of a subscription, applying the policy when the queue is
+// full. Returns false when the policy is to disconnect.
+func (queue *EventQueue) Push(subId string, msg interface{}) bool {
+	queue.mtx.Lock()
+	defer queue.mtx.Unlock()
+	if len(queue.events) >= queue.size {
+		if queue.policy == SlowClientDisconnect {
+			return false
+		}
+		if queue.policy == SlowClientCoalesce && queue.coalesce(subId, msg) {
+			return true
+		}
+		queue.drop(queue.events[0].SubId)
+		queue.events = queue.events[1:]
+	}
+	queue.events = append(queue.events, QueuedEvent{SubId: subId, Msg: msg})
+	queue.Signal()
+	return true
+}
+
+// Replaces the latest queued event of the subscription, returning false when
+// it has none
+func (queue *EventQueue) coalesce(subId string, msg interface{}) bool {
+	for i := len(queue.events) - 1; i >= 0; i-- {
+		if queue.events[i].SubId == subId {
+			queue.events[i].Msg = msg
+			queue.drop(subId)
+			atomic.AddUint64(&eventMetrics.Coalesced, 1)
+			return true
+		}
+	}
+	return false
+}
+
+func (queue *EventQueue) drop(subId string) {
+	queue.dropped[subId]++
+	CountDroppedEvents(1)
+	// the notice is sent once the events are taken
+	queue.Signal()
+}
+
+// Wakes the reader of the queue, also when no event was queued, so that it
+// can check whether it should stop
+func (queue *EventQueue) Signal() {
+	select {
+	case queue.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Signalled when there are events to take
+func (queue *EventQueue) Ready() <-chan struct{} {
+	return queue.ready
+}
+
+// Takes the queued events, and the number of events of each subscription
+// dropped since the last take
+func (queue *EventQueue) Take() ([]QueuedEvent, map[string]uint64) {
+	queue.mtx.Lock()
+	defer queue.mtx.Unlock()
+	events, dropped := queue.events, queue.dropped
+	queue.events = nil
+	queue.dropped = make(map[string]uint64)
+	return events, dropped
+}
@@ -0,0 +1,92 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns the messages of the queued events
+func takeMessages(queue *EventQueue) ([]string, map[string]uint64) {
+	events, dropped := queue.Take()
+	msgs := make([]string, len(events))
+	for i, event := range events {
+		msgs[i] = string(event.Msg.([]byte))
+	}
+	return msgs, dropped
+}
+
+func TestEventQueueDropOldest(t *testing.T) {
+	metrics := GetEventMetrics()
+	queue := NewEventQueue(2, SlowClientDropOldest)
+	assert.True(t, queue.Push("a", []byte("a1")))
+	assert.True(t, queue.Push("b", []byte("b1")))
+	assert.True(t, queue.Push("a", []byte("a2")))
+	<-queue.Ready()
+	msgs, dropped := takeMessages(queue)
+	assert.Equal(t, []string{"b1", "a2"}, msgs)
+	assert.Equal(t, map[string]uint64{"a": 1}, dropped)
+	assert.Equal(t, metrics.Dropped+1, GetEventMetrics().Dropped)
+
+	// the drops are only noticed once
+	msgs, dropped = takeMessages(queue)
+	assert.Empty(t, msgs)
+	assert.Empty(t, dropped)
+}
+
+func TestEventQueueCoalesce(t *testing.T) {
+	metrics := GetEventMetrics()
+	queue := NewEventQueue(2, SlowClientCoalesce)
+	assert.True(t, queue.Push("a", []byte("a1")))
+	assert.True(t, queue.Push("b", []byte("b1")))
+	assert.True(t, queue.Push("b", []byte("b2")))
+	assert.True(t, queue.Push("a", []byte("a2")))
+	msgs, dropped := takeMessages(queue)
+	assert.Equal(t, []string{"a2", "b2"}, msgs)
+	assert.Equal(t, map[string]uint64{"a": 1, "b": 1}, dropped)
+	assert.Equal(t, metrics.Coalesced+2, GetEventMetrics().Coalesced)
+
+	// the oldest event is dropped for a subscription with none queued
+	assert.True(t, queue.Push("a", []byte("a3")))
+	assert.True(t, queue.Push("b", []byte("b3")))
+	assert.True(t, queue.Push("c", []byte("c1")))
+	msgs, dropped = takeMessages(queue)
+	assert.Equal(t, []string{"b3", "c1"}, msgs)
+	assert.Equal(t, map[string]uint64{"a": 1}, dropped)
+}
+
+func TestEventQueueDisconnect(t *testing.T) {
+	metrics := GetEventMetrics()
+	queue := NewEventQueue(1, SlowClientDisconnect)
+	assert.True(t, queue.Push("a", []byte("a1")))
+	assert.False(t, queue.Push("a", []byte("a2")))
+	msgs, dropped := takeMessages(queue)
+	assert.Equal(t, []string{"a1"}, msgs)
+	assert.Empty(t, dropped)
+	assert.Equal(t, metrics.Dropped, GetEventMetrics().Dropped)
+}
+
+func TestParseSlowClientPolicy(t *testing.T) {
+	policy, err := ParseSlowClientPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, SlowClientDropOldest, policy)
+	policy, err = ParseSlowClientPolicy("coalesce")
+	assert.NoError(t, err)
+	assert.Equal(t, SlowClientCoalesce, policy)
+	_, err = ParseSlowClientPolicy("drop_newest")
+	assert.Error(t, err)
+}
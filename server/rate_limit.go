@@ -0,0 +1,229 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Classes of methods, whose calls are rate limited separately.
+type MethodClass int
+
+const (
+	// Reads of single items, and methods that are not classed otherwise
+	ClassRead MethodClass = iota
+	// Reads that may scan many items, such as storage dumps and block ranges
+	ClassScan
+	// Calls that run the VM without persisting. They are also subject to the
+	// cap on concurrent simulations.
+	ClassSimulate
+	// Methods that sign or broadcast txs
+	ClassTransact
+)
+
+var classNames = map[MethodClass]string{
+	ClassRead:     "read",
+	ClassScan:     "scan",
+	ClassSimulate: "simulate",
+	ClassTransact: "transact",
+}
+
+func (class MethodClass) String() string {
+	return classNames[class]
+}
+
+// The class of each method of an API. Methods that are not listed are
+// ClassRead.
+type MethodClasses map[string]MethodClass
+
+// Buckets are swept this often, dropping those that have filled up again.
+const sweepInterval = time.Minute
+
+// Rate limits the calls of each client with a token bucket per method class,
+// and caps the number of simulations running at once across all clients. A
+// nil *RateLimiter, as returned when rate limiting is not enabled, lets every
+// call through.
+type RateLimiter struct {
+	mtx       sync.Mutex
+	limits    map[MethodClass]Limit
+	buckets   map[bucketKey]*tokenBucket
+	lastSweep time.Time
+	// holds a token for each simulation running, nil when they are not capped
+	simulations chan struct{}
+	// the clock, replaced in tests
+	now func() time.Time
+}
+
+type bucketKey struct {
+	client string
+	class  MethodClass
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Returns the RateLimiter configured in [servers.rate_limit], which is nil
+// unless it is enabled.
+func NewRateLimiter(config *ServerConfig) (*RateLimiter, error) {
+	rateLimitConfig := config.RateLimit
+	if !rateLimitConfig.Enable {
+		return nil, nil
+	}
+	limiter := &RateLimiter{
+		limits:  make(map[MethodClass]Limit),
+		buckets: make(map[bucketKey]*tokenBucket),
+		now:     time.Now,
+	}
+	for class, limit := range map[MethodClass]Limit{
+		ClassRead:     rateLimitConfig.Read,
+		ClassScan:     rateLimitConfig.Scan,
+		ClassSimulate: rateLimitConfig.Simulate,
+		ClassTransact: rateLimitConfig.Transact,
+	} {
+		if limit.Rate < 0 || limit.Burst < 0 {
+			return nil, fmt.Errorf("Negative rate limit for %v calls", class)
+		}
+		if limit.Rate == 0 {
+			continue
+		}
+		// A burst of less than one would refuse every call
+		if limit.Burst == 0 {
+			limit.Burst = 1
+		}
+		limiter.limits[class] = limit
+	}
+	if rateLimitConfig.MaxConcurrentSimulations < 0 {
+		return nil, fmt.Errorf("Negative max_concurrent_simulations")
+	}
+	if rateLimitConfig.MaxConcurrentSimulations > 0 {
+		limiter.simulations = make(chan struct{},
+			rateLimitConfig.MaxConcurrentSimulations)
+	}
+	return limiter, nil
+}
+
+// Takes a call of method by client from the bucket of its class, and for
+// simulations a place among those running. The returned function releases
+// the place, and must be called once the call returns. When the call is
+// refused the error says why, and the function does nothing.
+func (limiter *RateLimiter) Limit(client, method string,
+	methodClasses MethodClasses) (func(), error) {
+	if err := limiter.Take(client, method, methodClasses); err != nil {
+		return func() {}, err
+	}
+	if methodClasses[method] != ClassSimulate {
+		return func() {}, nil
+	}
+	return limiter.AcquireSimulation()
+}
+
+// Takes a call of method by client from the bucket of its class, returning
+// an error when the bucket is empty.
+func (limiter *RateLimiter) Take(client, method string,
+	methodClasses MethodClasses) error {
+	if limiter == nil {
+		return nil
+	}
+	return limiter.take(client, methodClasses[method])
+}
+
+// Takes a place among the simulations running, for APIs that can not tell
+// clients apart when a call runs. The returned function releases the place.
+func (limiter *RateLimiter) AcquireSimulation() (func(), error) {
+	if limiter == nil || limiter.simulations == nil {
+		return func() {}, nil
+	}
+	select {
+	case limiter.simulations <- struct{}{}:
+		return func() { <-limiter.simulations }, nil
+	default:
+		return func() {}, fmt.Errorf("Too many calls running in the VM, "+
+			"at most %v may run at once", cap(limiter.simulations))
+	}
+}
+
+// Handler rate limiting the requests of a route as method, for APIs that map
+// routes rather than requests onto methods. Clients are identified by auth,
+// which may be nil.
+func (limiter *RateLimiter) Handler(auth *Auth, method string,
+	methodClasses MethodClasses) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		release, err := limiter.Limit(auth.ClientId(c.Request), method,
+			methodClasses)
+		if err != nil {
+			c.AbortWithError(429, err)
+			return
+		}
+		defer release()
+		c.Next()
+	}
+}
+
+func (limiter *RateLimiter) take(client string, class MethodClass) error {
+	limit, ok := limiter.limits[class]
+	if !ok {
+		return nil
+	}
+	limiter.mtx.Lock()
+	defer limiter.mtx.Unlock()
+	now := limiter.now()
+	limiter.sweep(now)
+	key := bucketKey{client: client, class: class}
+	bucket, ok := limiter.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		limiter.buckets[key] = bucket
+	}
+	bucket.refill(limit, now)
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / limit.Rate * float64(time.Second))
+		return fmt.Errorf("Rate limit of %v %v calls per second exceeded, "+
+			"retry in %v", limit.Rate, class, wait)
+	}
+	bucket.tokens--
+	return nil
+}
+
+// Drops the buckets that have filled up again, since a new bucket is full
+// anyway. Must be called with the lock held.
+func (limiter *RateLimiter) sweep(now time.Time) {
+	if now.Sub(limiter.lastSweep) < sweepInterval {
+		return
+	}
+	limiter.lastSweep = now
+	for key, bucket := range limiter.buckets {
+		limit := limiter.limits[key.class]
+		if bucket.refill(limit, now); bucket.tokens >= float64(limit.Burst) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
+
+func (bucket *tokenBucket) refill(limit Limit, now time.Time) {
+	elapsed := now.Sub(bucket.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	bucket.tokens = math.Min(float64(limit.Burst),
+		bucket.tokens+elapsed*limit.Rate)
+	bucket.last = now
+}
@@ -0,0 +1,120 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testMethodClasses = MethodClasses{
+	"call":        ClassSimulate,
+	"dumpStorage": ClassScan,
+}
+
+// Returns a limiter whose clock only moves when the returned function is
+// called.
+func newTestRateLimiter(t *testing.T) (*RateLimiter, func(time.Duration)) {
+	config := DefaultServerConfig()
+	config.RateLimit.Enable = true
+	config.RateLimit.Read = Limit{Rate: 10, Burst: 2}
+	config.RateLimit.Scan = Limit{Rate: 1}
+	config.RateLimit.MaxConcurrentSimulations = 1
+	limiter, err := NewRateLimiter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1000, 0)
+	limiter.now = func() time.Time { return now }
+	return limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter, sleep := newTestRateLimiter(t)
+	take := func(client, method string) error {
+		return limiter.Take(client, method, testMethodClasses)
+	}
+	// the burst is allowed, and then no more
+	assert.NoError(t, take("a", "getAccount"))
+	assert.NoError(t, take("a", "getAccount"))
+	assert.Error(t, take("a", "getAccount"))
+	// other clients and classes have their own buckets
+	assert.NoError(t, take("b", "getAccount"))
+	assert.NoError(t, take("a", "dumpStorage"))
+	assert.Error(t, take("a", "dumpStorage"))
+	// buckets refill at the rate
+	sleep(100 * time.Millisecond)
+	assert.NoError(t, take("a", "getAccount"))
+	assert.Error(t, take("a", "getAccount"))
+	// but not beyond the burst
+	sleep(time.Hour)
+	assert.NoError(t, take("a", "getAccount"))
+	assert.NoError(t, take("a", "getAccount"))
+	assert.Error(t, take("a", "getAccount"))
+	// classes without a rate are not limited
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, take("a", "call"))
+	}
+}
+
+func TestRateLimitSweep(t *testing.T) {
+	limiter, sleep := newTestRateLimiter(t)
+	limiter.Take("a", "getAccount", testMethodClasses)
+	limiter.Take("b", "dumpStorage", testMethodClasses)
+	assert.Len(t, limiter.buckets, 2)
+	sleep(sweepInterval - 500*time.Millisecond)
+	limiter.Take("b", "dumpStorage", testMethodClasses)
+	// a has refilled by the next sweep, and b has not
+	sleep(500 * time.Millisecond)
+	limiter.Take("c", "getAccount", testMethodClasses)
+	assert.Len(t, limiter.buckets, 2)
+	assert.Contains(t, limiter.buckets, bucketKey{"b", ClassScan})
+}
+
+func TestMaxConcurrentSimulations(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t)
+	release, err := limiter.Limit("a", "call", testMethodClasses)
+	assert.NoError(t, err)
+	// the cap is shared by all clients
+	_, err = limiter.Limit("b", "call", testMethodClasses)
+	assert.Error(t, err)
+	// other classes are not capped
+	_, err = limiter.Limit("b", "getAccount", testMethodClasses)
+	assert.NoError(t, err)
+	release()
+	release, err = limiter.Limit("b", "call", testMethodClasses)
+	assert.NoError(t, err)
+	release()
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	config := DefaultServerConfig()
+	limiter, err := NewRateLimiter(config)
+	assert.NoError(t, err)
+	assert.Nil(t, limiter)
+	// without rate limiting every call is let through
+	for i := 0; i < 100; i++ {
+		release, err := limiter.Limit("a", "call", testMethodClasses)
+		assert.NoError(t, err)
+		release()
+	}
+
+	config.RateLimit.Enable = true
+	config.RateLimit.Scan = Limit{Rate: -1}
+	_, err = NewRateLimiter(config)
+	assert.Error(t, err)
+}
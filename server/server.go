@@ -114,6 +114,15 @@ func (this *ServeProcess) Start() error {
 		if tErr != nil {
 			return tErr
 		}
+		// Client certificates are optional, callers without one may still
+		// authenticate by API key or token
+		tConfig.ClientCAs, tErr = ClientCAs(config)
+		if tErr != nil {
+			return tErr
+		}
+		if tConfig.ClientCAs != nil {
+			tConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
 
 		lst = tls.NewListener(l, tConfig)
 	} else {
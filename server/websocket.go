@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -29,7 +30,7 @@ import (
 
 const (
 	// Time allowed to write a message to the peer.
-	writeWait = 0 * time.Second
+	writeWait = 10 * time.Second
 	// Time allowed to read the next pong message from the peer.
 	pongWait = 0 * time.Second
 	// Send pings to peer with this period. Must be less than pongWait.
@@ -41,7 +42,7 @@ const (
 // Services requests. Message bytes are passed along with the session
 // object. The service is expected to write any response back using
 // the Write function on WSSession, which passes the message over
-// a channel to the write pump.
+// a channel to the write pump, and events using WriteEvent.
 type WebSocketService interface {
 	Process([]byte, *WSSession)
 }
@@ -56,17 +57,22 @@ type WebSocketServer struct {
 	sessionManager *SessionManager
 	config         *ServerConfig
 	allOrigins     bool
+	auth           *Auth
 }
 
 // Create a new server.
 // maxSessions is the maximum number of active websocket connections that is allowed.
 // NOTE: This is not the total number of connections allowed - only those that are
 // upgraded to websockets. Requesting a websocket connection will fail with a 503 if
-// the server is at capacity.
-func NewWebSocketServer(maxSessions uint16, service WebSocketService) *WebSocketServer {
+// the server is at capacity. Connections are authenticated by auth when they
+// are upgraded, and their sessions keep the role and the client id of the
+// caller.
+func NewWebSocketServer(maxSessions uint16, service WebSocketService,
+	auth *Auth) *WebSocketServer {
 	return &WebSocketServer{
 		maxSessions:    maxSessions,
 		sessionManager: NewSessionManager(maxSessions, service),
+		auth:           auth,
 	}
 }
 
@@ -81,6 +87,9 @@ func (this *WebSocketServer) Start(config *ServerConfig, router *gin.Engine) {
 		WriteBufferSize: int(config.WebSocket.WriteBufferSize),
 	}
 	this.upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+	// the policy is validated as the config is read
+	policy, _ := ParseSlowClientPolicy(config.WebSocket.SlowClientPolicy)
+	this.sessionManager.SetEventQueue(config.WebSocket.EventQueueSize, policy)
 	router.GET(config.WebSocket.WebSocketEndpoint, this.handleFunc)
 	this.running = true
 }
@@ -105,6 +114,11 @@ func (this *WebSocketServer) SessionManager() *SessionManager {
 func (this *WebSocketServer) handleFunc(c *gin.Context) {
 	r := c.Request
 	w := c.Writer
+	role, aErr := this.auth.Authenticate(r)
+	if aErr != nil {
+		http.Error(w, "Failed to authenticate: "+aErr.Error(), 401)
+		return
+	}
 	// Upgrade to websocket.
 	wsConn, uErr := this.upgrader.Upgrade(w, r, nil)
 
@@ -115,7 +129,8 @@ func (this *WebSocketServer) handleFunc(c *gin.Context) {
 		return
 	}
 
-	session, cErr := this.sessionManager.createSession(wsConn)
+	session, cErr := this.sessionManager.createSession(wsConn, role,
+		this.auth.ClientId(r))
 
 	if cErr != nil {
 		cErrStr := "Failed to establish websocket connection: " + cErr.Error()
@@ -138,21 +153,27 @@ type SessionObserver interface {
 
 // WSSession wraps a gorilla websocket.Conn, which in turn wraps a
 // net.Conn object. Writing is done using the 'Write([]byte)' method,
-// which passes the bytes on to the write pump over a channel.
+// which passes the bytes on to the write pump over a channel. Events are
+// written with 'WriteEvent', which queues them without blocking.
 type WSSession struct {
 	sessionManager *SessionManager
 	id             uint
 	wsConn         *websocket.Conn
 	writeChan      chan []byte
 	writeCloseChan chan struct{}
+	events         *EventQueue
 	service        WebSocketService
+	role           Role
+	clientId       string
 	opened         bool
-	closed         bool
+	// set to 1 once closed, as it is read while the session is closed
+	closed    uint32
+	closeOnce sync.Once
 }
 
 // Write a text message to the client.
 func (this *WSSession) Write(msg []byte) error {
-	if this.closed {
+	if this.Closed() {
 		log.Warn("Attempting to write to closed session.", "sessionId", this.id)
 		return fmt.Errorf("Session is closed")
 	}
@@ -160,7 +181,24 @@ func (this *WSSession) Write(msg []byte) error {
 	return nil
 }
 
-// Private. Helper for writing control messages.
+// Write an event of a subscription to the client. Events are queued for the
+// write pump, so this never blocks. When the queue is full the slow client
+// policy applies, which may close the session.
+func (this *WSSession) WriteEvent(subId string, msg []byte) error {
+	if this.Closed() {
+		return fmt.Errorf("Session is closed")
+	}
+	if !this.events.Push(subId, msg) {
+		log.Info("Closing websocket session of slow client.", "sessionId", this.id)
+		CountSlowClientDisconnect()
+		this.Close()
+		return fmt.Errorf("Session closed, its client was too slow to read events")
+	}
+	return nil
+}
+
+// Private. Helper for writing messages, which fails once writeWait passes
+// so that a client that stops reading can not block the write pump.
 func (this *WSSession) write(mt int, payload []byte) error {
 	this.wsConn.SetWriteDeadline(time.Now().Add(writeWait))
 	return this.wsConn.WriteMessage(mt, payload)
@@ -171,6 +209,16 @@ func (this *WSSession) Id() uint {
 	return this.id
 }
 
+// Get the role the client authenticated as when connecting.
+func (this *WSSession) Role() Role {
+	return this.role
+}
+
+// Get the client id of the caller, by which its calls are rate limited.
+func (this *WSSession) ClientId() string {
+	return this.clientId
+}
+
 // Starts the read and write pumps. Blocks on the former.
 // Notifies all the observers.
 func (this *WSSession) Open() {
@@ -181,14 +229,16 @@ func (this *WSSession) Open() {
 }
 
 // Closes the net connection and cleans up. Notifies all the observers.
+// Closing the connection unblocks the write pump, so the session may be
+// closed while it is writing.
 func (this *WSSession) Close() {
-	if !this.closed {
-		this.closed = true
+	this.closeOnce.Do(func() {
+		atomic.StoreUint32(&this.closed, 1)
 		this.wsConn.Close()
 		this.sessionManager.removeSession(this.id)
 		log.Info("Closing websocket connection.", "sessionId", this.id, "remaining", len(this.sessionManager.activeSessions))
 		this.sessionManager.notifyClosed(this)
-	}
+	})
 }
 
 // Has the session been opened?
@@ -198,7 +248,7 @@ func (this *WSSession) Opened() bool {
 
 // Has the session been closed?
 func (this *WSSession) Closed() bool {
-	return this.closed
+	return atomic.LoadUint32(&this.closed) == 1
 }
 
 // Pump debugging
@@ -281,12 +331,18 @@ func (this *WSSession) writePump() {
 		case msg := <-this.writeChan:
 
 			// Write the bytes to the socket.
-			err := this.wsConn.WriteMessage(websocket.TextMessage, msg)
+			err := this.write(websocket.TextMessage, msg)
 			if err != nil {
 				// Could be due to the socket being closed so not really an error.
 				log.Info("Writing to socket failed. Closing.")
 				return
 			}
+		// Events queued.
+		case <-this.events.Ready():
+			if err := this.writeEvents(); err != nil {
+				log.Info("Writing events to socket failed. Closing.")
+				return
+			}
 		case <-this.writeCloseChan:
 			return
 			// Ticker run out. Time for another ping message.
@@ -302,6 +358,26 @@ func (this *WSSession) writePump() {
 	}
 }
 
+// Writes the queued events, preceded by a notice of the events of each
+// subscription that were dropped when the service can give one.
+func (this *WSSession) writeEvents() error {
+	events, dropped := this.events.Take()
+	if notifier, ok := this.service.(EventDropNotifier); ok {
+		for subId, count := range dropped {
+			notice := notifier.DroppedEventsNotice(subId, count)
+			if err := this.write(websocket.TextMessage, notice); err != nil {
+				return err
+			}
+		}
+	}
+	for _, event := range events {
+		if err := this.write(websocket.TextMessage, event.Msg.([]byte)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Session manager handles the adding, tracking and removing of session objects.
 type SessionManager struct {
 	maxSessions     uint16
@@ -311,6 +387,9 @@ type SessionManager struct {
 	service         WebSocketService
 	openEventChans  []chan *WSSession
 	closeEventChans []chan *WSSession
+	// bound and slow client policy of the event queues of sessions
+	eventQueueSize   int
+	slowClientPolicy SlowClientPolicy
 }
 
 // Create a new WebsocketManager.
@@ -326,6 +405,15 @@ func NewSessionManager(maxSessions uint16, wss WebSocketService) *SessionManager
 	}
 }
 
+// Sets the size of the event queues of new sessions, and the policy applied
+// when they are full. The defaults are used for a zero size or policy.
+func (this *SessionManager) SetEventQueue(size int, policy SlowClientPolicy) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+	this.eventQueueSize = size
+	this.slowClientPolicy = policy
+}
+
 // TODO
 func (this *SessionManager) Shutdown() {
 	this.activeSessions = nil
@@ -390,7 +478,8 @@ func (this *SessionManager) notifyClosed(session *WSSession) {
 }
 
 // Creates a new session and adds it to the manager.
-func (this *SessionManager) createSession(wsConn *websocket.Conn) (*WSSession, error) {
+func (this *SessionManager) createSession(wsConn *websocket.Conn,
+	role Role, clientId string) (*WSSession, error) {
 	// Check that the capacity hasn't been exceeded.
 	this.mtx.Lock()
 	defer this.mtx.Unlock()
@@ -406,7 +495,10 @@ func (this *SessionManager) createSession(wsConn *websocket.Conn) (*WSSession, e
 		wsConn:         wsConn,
 		writeChan:      make(chan []byte, maxMessageSize),
 		writeCloseChan: make(chan struct{}),
+		events:         NewEventQueue(this.eventQueueSize, this.slowClientPolicy),
 		service:        this.service,
+		role:           role,
+		clientId:       clientId,
 	}
 	this.activeSessions[conn.id] = conn
 	return conn, nil
@@ -0,0 +1,64 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// Writes events to the session of every message until it is closed
+type eventingService struct {
+	done chan error
+}
+
+func (service *eventingService) Process(msg []byte, session *WSSession) {
+	for {
+		if err := session.WriteEvent("sub", msg); err != nil {
+			service.done <- err
+			return
+		}
+	}
+}
+
+// Events keep being written while the client disconnects, which closes the
+// session from its pumps
+func TestWebSocketWriteEventWhileClosing(t *testing.T) {
+	service := &eventingService{done: make(chan error, 1)}
+	config := DefaultServerConfig()
+	wsServer := NewWebSocketServer(10, service, nil)
+	router := gin.New()
+	wsServer.Start(config, router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http") +
+		config.WebSocket.WebSocketEndpoint
+	wsConn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, wsConn.WriteMessage(websocket.TextMessage, []byte("event")))
+	_, msg, err := wsConn.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, "event", string(msg))
+	wsConn.Close()
+	assert.Error(t, <-service.done)
+}
@@ -68,7 +68,7 @@ func (this *ScumSocketService) Process(data []byte, session *server.WSSession) {
 
 func NewScumsocketServer(maxConnections uint16) *server.WebSocketServer {
 	sss := &ScumSocketService{}
-	return server.NewWebSocketServer(maxConnections, sss)
+	return server.NewWebSocketServer(maxConnections, sss, nil)
 }
 
 func NewServeScumbag() (*server.ServeProcess, error) {
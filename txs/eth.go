@@ -0,0 +1,211 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/hyperledger/burrow/manager/burrow-mint/evm/sha3"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/tendermint/go-wire"
+)
+
+// EthTx is an Ethereum transaction, as signed by Ethereum wallets and sent
+// RLP encoded to eth_sendRawTransaction. It is executed as a CallTx from the
+// address recovered from its secp256k1 signature, which must be replay
+// protected by EIP-155 for the chain id of this chain.
+//
+// The sender is charged no fee, so GasPrice is carried but ignored. Nonce
+// counts the txs of the sender from zero, so must equal the sequence of its
+// account. Amounts are int64 in burrow, so Value and GasLimit may not exceed
+// math.MaxInt64.
+type EthTx struct {
+	Nonce    uint64 `json:"nonce"`
+	GasPrice uint64 `json:"gas_price"`
+	GasLimit uint64 `json:"gas_limit"`
+	To       []byte `json:"to"` // Empty to create a contract
+	Value    uint64 `json:"value"`
+	Data     []byte `json:"data"`
+	V        uint64 `json:"v"`
+	R        []byte `json:"r"`
+	S        []byte `json:"s"`
+}
+
+// EIP-155 encodes the chain id in V as chainID*2 + eip155VOffset + recovery id
+const eip155VOffset = 35
+
+// Half the order of secp256k1, above which S is malleable
+var secp256k1HalfN = new(big.Int).Rsh(btcec.S256().Params().N, 1)
+
+// Decodes an EIP-155 signed EthTx from its RLP encoding
+func DecodeEthTx(bs []byte) (*EthTx, error) {
+	items, err := decodeRLPList(bs)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != 9 {
+		return nil, fmt.Errorf("Ethereum tx has %v fields, expected 9",
+			len(items))
+	}
+	tx := &EthTx{
+		To:   items[3],
+		Data: items[5],
+		R:    items[7],
+		S:    items[8],
+	}
+	for i, field := range []*uint64{&tx.Nonce, &tx.GasPrice, &tx.GasLimit,
+		nil, &tx.Value, nil, &tx.V} {
+		if field == nil {
+			continue
+		}
+		if *field, err = decodeRLPUint(items[i]); err != nil {
+			return nil, fmt.Errorf("Invalid Ethereum tx field %v: %v", i, err)
+		}
+	}
+	if err := tx.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Checks the fields of the tx can be executed, but not its signature
+func (tx *EthTx) ValidateBasic() error {
+	if len(tx.To) != 0 && len(tx.To) != 20 {
+		return ErrTxInvalidAddress
+	}
+	if tx.Value > math.MaxInt64 || tx.GasLimit > math.MaxInt64 {
+		return ErrTxInvalidAmount
+	}
+	if tx.Nonce >= math.MaxInt64 {
+		return ErrTxInvalidString{"Ethereum tx nonce exceeds the sequences " +
+			"of accounts"}
+	}
+	if tx.V < eip155VOffset {
+		return ErrTxInvalidString{"Ethereum tx is not signed for a chain id " +
+			"as required by EIP-155"}
+	}
+	// R and S are integers, so leading zeros would make the tx malleable
+	for _, bs := range [][]byte{tx.R, tx.S} {
+		if len(bs) == 0 || len(bs) > 32 || bs[0] == 0 {
+			return ErrTxInvalidSignature
+		}
+	}
+	return nil
+}
+
+// Returns the RLP encoding of the signed tx
+func (tx *EthTx) Encode() []byte {
+	return encodeRLPList(encodeRLPUint(tx.Nonce), encodeRLPUint(tx.GasPrice),
+		encodeRLPUint(tx.GasLimit), tx.To, encodeRLPUint(tx.Value), tx.Data,
+		encodeRLPUint(tx.V), tx.R, tx.S)
+}
+
+// The chain id the tx is signed for
+func (tx *EthTx) ChainID() uint64 {
+	return (tx.V - eip155VOffset) / 2
+}
+
+// The sequence the account of the sender has once the tx is run. Ethereum
+// nonces count txs from zero while sequences count them from one.
+func (tx *EthTx) Sequence() int {
+	return int(tx.Nonce) + 1
+}
+
+// Writes the EIP-155 signing payload, which commits to the Ethereum chain id
+// encoded in V rather than to chainID.
+func (tx *EthTx) WriteSignBytes(chainID string, w io.Writer, n *int, err *error) {
+	wire.WriteTo(encodeRLPList(encodeRLPUint(tx.Nonce),
+		encodeRLPUint(tx.GasPrice), encodeRLPUint(tx.GasLimit), tx.To,
+		encodeRLPUint(tx.Value), tx.Data, encodeRLPUint(tx.ChainID()), nil, nil),
+		w, n, err)
+}
+
+// The keccak256 hash signed by the sender
+func (tx *EthTx) SignHash() []byte {
+	var n int
+	var err error
+	hasher := sha3.NewKeccak256()
+	tx.WriteSignBytes("", hasher, &n, &err)
+	return hasher.Sum(nil)
+}
+
+// The keccak256 hash of the RLP encoding, by which Ethereum clients know
+// the tx
+func (tx *EthTx) Hash() []byte {
+	return sha3.Sha3(tx.Encode())
+}
+
+// Recovers the address of the sender from the signature
+func (tx *EthTx) Sender() ([]byte, error) {
+	if err := tx.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	s := new(big.Int).SetBytes(tx.S)
+	if s.Sign() == 0 || s.Cmp(secp256k1HalfN) > 0 {
+		return nil, ErrTxInvalidSignature
+	}
+	recoveryID := (tx.V - eip155VOffset) % 2
+	sig := make([]byte, 65)
+	sig[0] = 27 + byte(recoveryID)
+	copy(sig[33-len(tx.R):33], tx.R)
+	copy(sig[65-len(tx.S):], tx.S)
+	pubKey, _, err := btcec.RecoverCompact(btcec.S256(), sig, tx.SignHash())
+	if err != nil {
+		return nil, ErrTxInvalidSignature
+	}
+	return EthAddress(pubKey), nil
+}
+
+// Signs the tx for the Ethereum chain id with key
+func (tx *EthTx) Sign(chainID uint64, key *btcec.PrivateKey) error {
+	tx.V = chainID*2 + eip155VOffset
+	sig, err := btcec.SignCompact(btcec.S256(), key, tx.SignHash(), false)
+	if err != nil {
+		return err
+	}
+	tx.V += uint64(sig[0] - 27)
+	tx.R = bytes.TrimLeft(sig[1:33], "\x00")
+	tx.S = bytes.TrimLeft(sig[33:], "\x00")
+	return nil
+}
+
+func (tx *EthTx) String() string {
+	return fmt.Sprintf("EthTx{%v -> %x: %x}", tx.Nonce, tx.To, tx.Data)
+}
+
+// Returns the Ethereum address of a secp256k1 public key, the last 20 bytes
+// of the keccak256 hash of its uncompressed coordinates
+func EthAddress(pubKey *btcec.PublicKey) []byte {
+	return sha3.Sha3(pubKey.SerializeUncompressed()[1:])[12:]
+}
+
+// Returns the Ethereum chain id of a chain, which EthTxs must be signed for.
+// A numeric chainID is used as is; otherwise the id is taken from the first
+// 4 bytes of the genesis hash so that it is stable for the life of the chain.
+func EthChainID(chainID string, genesisHash []byte) uint64 {
+	if id, err := strconv.ParseUint(chainID, 10, 64); err == nil {
+		return id
+	}
+	padded := make([]byte, 4)
+	copy(padded, genesisHash)
+	return uint64(binary.BigEndian.Uint32(padded))
+}
@@ -0,0 +1,155 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txs
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The example of EIP-155, signed by the key 0x4646..46 for chain id 1
+const eip155ExampleTx = "f86c098504a817c800825208943535353535353535353535353535" +
+	"353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a1" +
+	"5d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc6421" +
+	"4b297fb1966a3b6d83"
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	bs, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return bs
+}
+
+func TestRLPList(t *testing.T) {
+	long := bytes.Repeat([]byte{0xaa}, 56)
+	items := [][]byte{{}, {0x7f}, {0x80}, []byte("dog"), long}
+	encoded := encodeRLPList(items...)
+	assert.Equal(t, mustDecodeHex(t, "f842"+"80"+"7f"+"8180"+"83646f67"+"b838"+
+		hex.EncodeToString(long)), encoded)
+	decoded, err := decodeRLPList(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, items, decoded)
+
+	for _, invalid := range []string{
+		"",
+		"83646f67",   // not a list
+		"c3646f",     // truncated
+		"c180c0",     // trailing bytes
+		"c2c180",     // nested list
+		"c28101",     // single byte not encoded as itself
+		"f80180",     // short length encoded as long
+		"f900020180", // long length with leading zeros
+	} {
+		_, err := decodeRLPList(mustDecodeHex(t, invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestRLPUint(t *testing.T) {
+	for _, i := range []uint64{0, 1, 0x7f, 0x80, 0x0400, 1<<64 - 1} {
+		decoded, err := decodeRLPUint(encodeRLPUint(i))
+		require.NoError(t, err)
+		assert.Equal(t, i, decoded)
+	}
+	assert.Empty(t, encodeRLPUint(0))
+	_, err := decodeRLPUint([]byte{0, 1})
+	assert.Error(t, err)
+	_, err = decodeRLPUint(make([]byte, 9))
+	assert.Error(t, err)
+}
+
+func TestDecodeEthTx(t *testing.T) {
+	raw := mustDecodeHex(t, eip155ExampleTx)
+	tx, err := DecodeEthTx(raw)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), tx.Nonce)
+	assert.Equal(t, uint64(20000000000), tx.GasPrice)
+	assert.Equal(t, uint64(21000), tx.GasLimit)
+	assert.Equal(t, bytes.Repeat([]byte{0x35}, 20), tx.To)
+	assert.Equal(t, uint64(1000000000000000000), tx.Value)
+	assert.Empty(t, tx.Data)
+	assert.Equal(t, uint64(1), tx.ChainID())
+	assert.Equal(t, 10, tx.Sequence())
+	assert.Equal(t, raw, tx.Encode())
+
+	assert.Equal(t, mustDecodeHex(t, "daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53"),
+		tx.SignHash())
+	sender, err := tx.Sender()
+	require.NoError(t, err)
+	assert.Equal(t, mustDecodeHex(t, "9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f"), sender)
+	assert.Equal(t, tx.Hash(), TxHash(chainID, tx))
+
+	// the signature no longer matches the sender once the tx is altered
+	tx.Value++
+	altered, err := tx.Sender()
+	if err == nil {
+		assert.NotEqual(t, sender, altered)
+	}
+}
+
+func TestDecodeEthTxInvalid(t *testing.T) {
+	tx := &EthTx{GasLimit: 21000, To: make([]byte, 20)}
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x46}, 32))
+	require.NoError(t, tx.Sign(1, key))
+
+	// pre EIP-155 signatures are not replay protected
+	unprotected := *tx
+	unprotected.V = 27
+	_, err := DecodeEthTx(unprotected.Encode())
+	assert.Error(t, err)
+
+	badTo := *tx
+	badTo.To = make([]byte, 19)
+	_, err = DecodeEthTx(badTo.Encode())
+	assert.Equal(t, ErrTxInvalidAddress, err)
+
+	tooValuable := *tx
+	tooValuable.Value = 1 << 63
+	_, err = DecodeEthTx(tooValuable.Encode())
+	assert.Equal(t, ErrTxInvalidAmount, err)
+
+	// high s values are malleable
+	highS := *tx
+	highS.S = btcec.S256().Params().N.Bytes()
+	highS.S[31]--
+	_, err = highS.Sender()
+	assert.Equal(t, ErrTxInvalidSignature, err)
+}
+
+func TestEthTxSign(t *testing.T) {
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat([]byte{0x46}, 32))
+	tx := &EthTx{
+		Nonce:    9,
+		GasPrice: 20000000000,
+		GasLimit: 21000,
+		To:       bytes.Repeat([]byte{0x35}, 20),
+		Value:    1000000000000000000,
+	}
+	require.NoError(t, tx.Sign(1, key))
+	assert.Equal(t, mustDecodeHex(t, eip155ExampleTx), tx.Encode())
+	sender, err := tx.Sender()
+	require.NoError(t, err)
+	assert.Equal(t, EthAddress(key.PubKey()), sender)
+}
+
+func TestEthChainID(t *testing.T) {
+	assert.Equal(t, uint64(256), EthChainID("256", []byte{1, 2, 3, 4, 5}))
+	assert.Equal(t, uint64(0x01020304), EthChainID("my_chain", []byte{1, 2, 3, 4, 5}))
+	assert.Equal(t, uint64(0x01000000), EthChainID("my_chain", []byte{1}))
+}
@@ -32,6 +32,7 @@ func EventStringAccCall(addr []byte) string     { return fmt.Sprintf("Acc/%X/Cal
 func EventStringLogEvent(addr []byte) string    { return fmt.Sprintf("Log/%X", addr) }
 func EventStringPermissions(name string) string { return fmt.Sprintf("Permissions/%s", name) }
 func EventStringNameReg(name string) string     { return fmt.Sprintf("NameReg/%s", name) }
+func EventStringGov() string                    { return "Gov" }
 func EventStringBond() string                   { return "Bond" }
 func EventStringUnbond() string                 { return "Unbond" }
 func EventStringRebond() string                 { return "Rebond" }
@@ -120,6 +121,7 @@ type EventDataLog struct {
 	Topics  []Word256 `json:"topics"`
 	Data    []byte    `json:"data"`
 	Height  int64     `json:"height"`
+	TxID    []byte    `json:"tx_id"`
 }
 
 // We fire the most recent round state that led to the event
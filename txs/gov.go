@@ -0,0 +1,90 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txs
+
+import (
+	"fmt"
+
+	ptypes "github.com/hyperledger/burrow/permission/types"
+)
+
+var (
+	// NOTE: these are the values a chain starts with; once running the
+	// parameters are held in the state and can only be changed by a GovTx
+	DefaultMinBondAmount         int64 = 1                  // TODO adjust
+	DefaultUnbondingPeriodBlocks int   = int(60 * 24 * 365) // TODO probably better to make it time based.
+)
+
+// ChainParams are the chain parameters that can be changed on-chain by
+// accounts with the Governance permission.
+type ChainParams struct {
+	MinBondAmount             int64 `json:"min_bond_amount"`
+	UnbondingPeriodBlocks     int   `json:"unbonding_period_blocks"`
+	MinNameRegistrationPeriod int   `json:"min_name_registration_period"`
+	NameByteCostMultiplier    int64 `json:"name_byte_cost_multiplier"`
+	NameBlockCostMultiplier   int64 `json:"name_block_cost_multiplier"`
+}
+
+func DefaultChainParams() *ChainParams {
+	return &ChainParams{
+		MinBondAmount:             DefaultMinBondAmount,
+		UnbondingPeriodBlocks:     DefaultUnbondingPeriodBlocks,
+		MinNameRegistrationPeriod: MinNameRegistrationPeriod,
+		NameByteCostMultiplier:    NameByteCostMultiplier,
+		NameBlockCostMultiplier:   NameBlockCostMultiplier,
+	}
+}
+
+func (params *ChainParams) ValidateBasic() error {
+	if params.MinBondAmount < 1 {
+		return fmt.Errorf("MinBondAmount must be positive")
+	}
+	if params.UnbondingPeriodBlocks < 0 {
+		return fmt.Errorf("UnbondingPeriodBlocks must not be negative")
+	}
+	if params.MinNameRegistrationPeriod < 1 {
+		return fmt.Errorf("MinNameRegistrationPeriod must be positive")
+	}
+	if params.NameByteCostMultiplier < 1 || params.NameBlockCostMultiplier < 1 {
+		return fmt.Errorf("Name cost multipliers must be positive")
+	}
+	return nil
+}
+
+func (params *ChainParams) Copy() *ChainParams {
+	paramsCopy := *params
+	return &paramsCopy
+}
+
+// Same as NameCostPerBlock but priced with these parameters
+func (params *ChainParams) NameCostPerBlock(baseCost int64) int64 {
+	return params.NameBlockCostMultiplier * params.NameByteCostMultiplier * baseCost
+}
+
+func (params *ChainParams) String() string {
+	return fmt.Sprintf("ChainParams{MinBond:%v UnbondingPeriod:%v MinNamePeriod:%v NameByteCost:%v NameBlockCost:%v}",
+		params.MinBondAmount, params.UnbondingPeriodBlocks, params.MinNameRegistrationPeriod,
+		params.NameByteCostMultiplier, params.NameBlockCostMultiplier)
+}
+
+// GovProposal is a parameter update accepted from a GovTx and waiting in
+// the state for the height at which it takes effect.
+type GovProposal struct {
+	Proposer          []byte                  `json:"proposer"`
+	TxHash            []byte                  `json:"tx_hash"`
+	Height            int                     `json:"height"`
+	Params            *ChainParams            `json:"params"`
+	GlobalPermissions *ptypes.BasePermissions `json:"global_permissions"`
+}
@@ -15,7 +15,14 @@
 package txs
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	. "github.com/tendermint/go-common"
 
 	core_types "github.com/hyperledger/burrow/core/types"
 )
@@ -40,13 +47,113 @@ var (
 	regexpJSON     = regexp.MustCompile(`^[a-zA-Z0-9_/ \-+"':,\n\t.{}()\[\]]*$`)
 )
 
+// Separates the segments of a hierarchical name. The owner of a name
+// controls the names beneath it, eg. the owner of foo controls foo/bar.
+const NameSeparator = "/"
+
 // filter strings
 func validateNameRegEntryName(name string) bool {
 	return regexpAlphaNum.Match([]byte(name))
 }
 
-func validateNameRegEntryData(data string) bool {
-	return regexpJSON.Match([]byte(data))
+// names must not start or end with a separator or contain empty segments
+func validateNameRegEntrySegments(name string) bool {
+	for _, segment := range strings.Split(name, NameSeparator) {
+		if segment == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the names above name in the hierarchy, nearest first, eg.
+// foo/bar and foo for foo/bar/baz
+func NameAncestors(name string) []string {
+	var ancestors []string
+	for i := strings.LastIndex(name, NameSeparator); i > 0; i = strings.LastIndex(name, NameSeparator) {
+		name = name[:i]
+		ancestors = append(ancestors, name)
+	}
+	return ancestors
+}
+
+func validateNameRegEntryData(data string, dataType core_types.NameDataType) error {
+	switch dataType {
+	case core_types.NameDataPlain:
+		if !regexpJSON.Match([]byte(data)) {
+			return ErrTxInvalidString{Fmt("Invalid characters found in data (%s). Only the kind of things found in a JSON file are allowed", data)}
+		}
+	case core_types.NameDataBytes:
+		if _, err := base64.StdEncoding.DecodeString(data); err != nil {
+			return ErrTxInvalidString{Fmt("Data of type %v must be base64 encoded: %v", dataType, err)}
+		}
+	case core_types.NameDataText:
+		if !utf8.ValidString(data) {
+			return ErrTxInvalidString{Fmt("Data of type %v must be valid UTF-8", dataType)}
+		}
+	case core_types.NameDataJSON:
+		// json.Unmarshal replaces invalid UTF-8 rather than rejecting it
+		var document interface{}
+		if !utf8.ValidString(data) {
+			return ErrTxInvalidString{Fmt("Data of type %v must be valid UTF-8", dataType)}
+		}
+		if err := json.Unmarshal([]byte(data), &document); err != nil {
+			return ErrTxInvalidString{Fmt("Data of type %v must be valid JSON: %v", dataType, err)}
+		}
+	default:
+		return ErrTxInvalidString{Fmt("Unknown data type %v", dataType)}
+	}
+	return nil
+}
+
+// Checks the name and data of a name registry entry, as set by a NameTx or
+// the NameReg SNative
+func ValidateNameRegEntry(name, data string, dataType core_types.NameDataType) error {
+	if len(name) == 0 {
+		return ErrTxInvalidString{"Name must not be empty"}
+	}
+	if len(name) > MaxNameLength {
+		return ErrTxInvalidString{Fmt("Name is too long. Max %d bytes", MaxNameLength)}
+	}
+	if len(data) > MaxDataLength {
+		return ErrTxInvalidString{Fmt("Data is too long. Max %d bytes", MaxDataLength)}
+	}
+
+	if !validateNameRegEntryName(name) {
+		return ErrTxInvalidString{Fmt("Invalid characters found in name (%s). Only alphanumeric, underscores, dashes, forward slashes, and @ are allowed", name)}
+	}
+
+	if !validateNameRegEntrySegments(name) {
+		return ErrTxInvalidString{Fmt("Name (%s) must not start or end with a forward slash or contain empty segments", name)}
+	}
+
+	return validateNameRegEntryData(data, dataType)
+}
+
+type NameRegGetter interface {
+	GetNameRegEntry(name string) *core_types.NameRegEntry
+}
+
+// Returns the nearest unexpired entry above name in the hierarchy, or nil if
+// there is none
+func ActiveNameAncestor(getter NameRegGetter, name string, lastBlockHeight int) *core_types.NameRegEntry {
+	for _, ancestor := range NameAncestors(name) {
+		if entry := getter.GetNameRegEntry(ancestor); entry != nil && entry.Expires > lastBlockHeight {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Returns true if address owns an unexpired name above name in the hierarchy
+func OwnsNameAncestor(getter NameRegGetter, name string, address []byte, lastBlockHeight int) bool {
+	for _, ancestor := range NameAncestors(name) {
+		entry := getter.GetNameRegEntry(ancestor)
+		if entry != nil && entry.Expires > lastBlockHeight && bytes.Equal(entry.Owner, address) {
+			return true
+		}
+	}
+	return false
 }
 
 // base cost is "effective" number of bytes
@@ -0,0 +1,152 @@
+// Copyright 2017 Monax Industries Limited
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txs
+
+// The subset of Ethereum's Recursive Length Prefix encoding that transactions
+// need: a list of byte strings, where integers are big endian without leading
+// zeros. Decoding only accepts the canonical encoding, so that a transaction
+// has a single encoding and so a single hash.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	rlpStringOffset = 0x80
+	rlpListOffset   = 0xc0
+	// Strings and lists longer than this are prefixed by the length of
+	// their length
+	rlpMaxShortLength = 55
+)
+
+// Encodes a list of byte strings
+func encodeRLPList(items ...[]byte) []byte {
+	payload := new(bytes.Buffer)
+	for _, item := range items {
+		if len(item) == 1 && item[0] < rlpStringOffset {
+			payload.Write(item)
+			continue
+		}
+		payload.Write(rlpLengthPrefix(rlpStringOffset, len(item)))
+		payload.Write(item)
+	}
+	return append(rlpLengthPrefix(rlpListOffset, payload.Len()), payload.Bytes()...)
+}
+
+func rlpLengthPrefix(offset byte, length int) []byte {
+	if length <= rlpMaxShortLength {
+		return []byte{offset + byte(length)}
+	}
+	lengthBytes := encodeRLPUint(uint64(length))
+	return append([]byte{offset + rlpMaxShortLength + byte(len(lengthBytes))},
+		lengthBytes...)
+}
+
+// Returns the big endian bytes of i without leading zeros, which are empty
+// for zero
+func encodeRLPUint(i uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, i)
+	return bytes.TrimLeft(buf, "\x00")
+}
+
+// Decodes a list of byte strings, which must make up the whole of bs
+func decodeRLPList(bs []byte) ([][]byte, error) {
+	if len(bs) == 0 || bs[0] < rlpListOffset {
+		return nil, fmt.Errorf("RLP is not a list")
+	}
+	payload, rest, err := decodeRLPItem(bs, rlpListOffset)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%v bytes follow the RLP list", len(rest))
+	}
+	items := [][]byte{}
+	for len(payload) > 0 {
+		if payload[0] >= rlpListOffset {
+			return nil, fmt.Errorf("RLP list item %v is a list rather than a "+
+				"string", len(items))
+		}
+		var item []byte
+		if payload[0] < rlpStringOffset {
+			item, payload = payload[:1], payload[1:]
+		} else {
+			if item, payload, err = decodeRLPItem(payload, rlpStringOffset); err != nil {
+				return nil, err
+			}
+			if len(item) == 1 && item[0] < rlpStringOffset {
+				return nil, fmt.Errorf("RLP single byte %#x is not encoded "+
+					"as itself", item[0])
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Decodes the string or list, by offset, that bs starts with, returning its
+// payload and the bytes that follow it
+func decodeRLPItem(bs []byte, offset byte) ([]byte, []byte, error) {
+	prefix := int(bs[0] - offset)
+	bs = bs[1:]
+	length := prefix
+	if prefix > rlpMaxShortLength {
+		lengthLength := prefix - rlpMaxShortLength
+		if lengthLength > 8 || len(bs) < lengthLength {
+			return nil, nil, fmt.Errorf("RLP length of %v bytes is invalid",
+				lengthLength)
+		}
+		if bs[0] == 0 {
+			return nil, nil, fmt.Errorf("RLP length has leading zeros")
+		}
+		long := uint64(0)
+		for _, b := range bs[:lengthLength] {
+			long = long<<8 | uint64(b)
+		}
+		if long <= rlpMaxShortLength {
+			return nil, nil, fmt.Errorf("RLP length %v should be encoded in "+
+				"the prefix", long)
+		}
+		if long > uint64(len(bs)-lengthLength) {
+			return nil, nil, fmt.Errorf("RLP length %v exceeds the %v bytes "+
+				"remaining", long, len(bs)-lengthLength)
+		}
+		bs = bs[lengthLength:]
+		length = int(long)
+	}
+	if length > len(bs) {
+		return nil, nil, fmt.Errorf("RLP length %v exceeds the %v bytes "+
+			"remaining", length, len(bs))
+	}
+	return bs[:length], bs[length:], nil
+}
+
+// Decodes an integer of at most 64 bits
+func decodeRLPUint(bs []byte) (uint64, error) {
+	if len(bs) > 8 {
+		return 0, fmt.Errorf("RLP integer of %v bytes exceeds 64 bits", len(bs))
+	}
+	if len(bs) > 0 && bs[0] == 0 {
+		return 0, fmt.Errorf("RLP integer has leading zeros")
+	}
+	i := uint64(0)
+	for _, b := range bs {
+		i = i<<8 | uint64(b)
+	}
+	return i, nil
+}
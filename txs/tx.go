@@ -23,6 +23,7 @@ import (
 	"golang.org/x/crypto/ripemd160"
 
 	acm "github.com/hyperledger/burrow/account"
+	core_types "github.com/hyperledger/burrow/core/types"
 	ptypes "github.com/hyperledger/burrow/permission/types"
 	. "github.com/tendermint/go-common"
 	"github.com/tendermint/go-wire"
@@ -41,6 +42,7 @@ var (
 	ErrTxInvalidPubKey        = errors.New("Error invalid pubkey")
 	ErrTxInvalidSignature     = errors.New("Error invalid signature")
 	ErrTxPermissionDenied     = errors.New("Error permission denied")
+	ErrTxExpired              = errors.New("Error tx expired")
 )
 
 type ErrTxInvalidString struct {
@@ -67,6 +69,9 @@ Account Txs:
  - SendTx         Send coins to address
  - CallTx         Send a msg to a contract that runs in the vm
  - NameTx	  Store some value under a name in the global namereg
+ - NameTransferTx Transfer ownership of a name in the global namereg
+ - NameDataTx     Store typed data under a name in the global namereg
+ - EthTx          Ethereum tx, run as a CallTx from its recovered signer
 
 Validation Txs:
  - BondTx         New validator posts a bond
@@ -75,14 +80,21 @@ Validation Txs:
 
 Admin Txs:
  - PermissionsTx
+ - GovTx          Schedule a change of chain parameters
+
+Batch Txs:
+ - BatchTx        Run several account and admin txs atomically
 */
 
 // Types of Tx implementations
 const (
 	// Account transactions
-	TxTypeSend = byte(0x01)
-	TxTypeCall = byte(0x02)
-	TxTypeName = byte(0x03)
+	TxTypeSend         = byte(0x01)
+	TxTypeCall         = byte(0x02)
+	TxTypeName         = byte(0x03)
+	TxTypeNameTransfer = byte(0x04)
+	TxTypeEth          = byte(0x05)
+	TxTypeNameData     = byte(0x06)
 
 	// Validation transactions
 	TxTypeBond    = byte(0x11)
@@ -92,19 +104,31 @@ const (
 
 	// Admin transactions
 	TxTypePermissions = byte(0x20)
+	TxTypeGov         = byte(0x21)
+
+	// Batch transactions
+	TxTypeBatch = byte(0x30)
 )
 
+// Upper bound on the number of txs in a BatchTx
+const MaxBatchTxs = 64
+
 // for wire.readReflect
 var _ = wire.RegisterInterface(
 	struct{ Tx }{},
 	wire.ConcreteType{&SendTx{}, TxTypeSend},
 	wire.ConcreteType{&CallTx{}, TxTypeCall},
 	wire.ConcreteType{&NameTx{}, TxTypeName},
+	wire.ConcreteType{&NameTransferTx{}, TxTypeNameTransfer},
+	wire.ConcreteType{&EthTx{}, TxTypeEth},
+	wire.ConcreteType{&NameDataTx{}, TxTypeNameData},
 	wire.ConcreteType{&BondTx{}, TxTypeBond},
 	wire.ConcreteType{&UnbondTx{}, TxTypeUnbond},
 	wire.ConcreteType{&RebondTx{}, TxTypeRebond},
 	wire.ConcreteType{&DupeoutTx{}, TxTypeDupeout},
 	wire.ConcreteType{&PermissionsTx{}, TxTypePermissions},
+	wire.ConcreteType{&GovTx{}, TxTypeGov},
+	wire.ConcreteType{&BatchTx{}, TxTypeBatch},
 )
 
 //-----------------------------------------------------------------------------
@@ -126,9 +150,10 @@ type (
 
 	// BroadcastTx or Transact
 	Receipt struct {
-		TxHash          []byte `json:"tx_hash"`
-		CreatesContract uint8  `json:"creates_contract"`
-		ContractAddr    []byte `json:"contract_addr"`
+		TxHash          []byte    `json:"tx_hash"`
+		CreatesContract uint8     `json:"creates_contract"`
+		ContractAddr    []byte    `json:"contract_addr"`
+		Receipts        []Receipt `json:"receipts"` // one for each tx of a BatchTx
 	}
 
 	NameTx struct {
@@ -138,6 +163,18 @@ type (
 		Fee   int64    `json:"fee"`
 	}
 
+	// NameDataTx registers data of a given type under a name as a NameTx
+	// registers plain data. It is a tx of its own so that NameTxs keep the
+	// layout they are encoded with.
+	NameDataTx struct {
+		Input *TxInput `json:"input"`
+		Name  string   `json:"name"`
+		Data  string   `json:"data"`
+		Fee   int64    `json:"fee"`
+		// How Data is validated, see core_types.NameDataType
+		DataType core_types.NameDataType `json:"data_type"`
+	}
+
 	CallTx struct {
 		Input    *TxInput `json:"input"`
 		Address  []byte   `json:"address"`
@@ -152,6 +189,10 @@ type (
 		Sequence  int              `json:"sequence"`  // Must be 1 greater than the last committed TxInput
 		Signature crypto.Signature `json:"signature"` // Depends on the PubKey type and the whole Tx
 		PubKey    crypto.PubKey    `json:"pub_key"`   // Must not be nil, may be nil
+		// Replaces Signature and PubKey when Address is that of a MultisigPubKey
+		Multisig *acm.MultisigSignature `json:"multisig"`
+		// Last block height the input may be included at, zero for no expiry
+		ValidUntilHeight int `json:"valid_until_height"`
 	}
 
 	TxOutput struct {
@@ -167,14 +208,34 @@ func (txIn *TxInput) ValidateBasic() error {
 	if txIn.Amount == 0 {
 		return ErrTxInvalidAmount
 	}
+	if txIn.Multisig != nil {
+		if txIn.Multisig.PubKey == nil || !bytes.Equal(txIn.Multisig.PubKey.Address(), txIn.Address) {
+			return ErrTxInvalidPubKey
+		}
+	}
 	return nil
 }
 
 func (txIn *TxInput) WriteSignBytes(w io.Writer, n *int, err *error) {
-	wire.WriteTo([]byte(Fmt(`{"address":"%X","amount":%v,"sequence":%v}`, txIn.Address, txIn.Amount, txIn.Sequence)), w, n, err)
+	// NOTE: the expiry is only part of the sign bytes when set, so inputs
+	// without one sign the same bytes as before it was introduced
+	if txIn.ValidUntilHeight == 0 {
+		wire.WriteTo([]byte(Fmt(`{"address":"%X","amount":%v,"sequence":%v}`, txIn.Address, txIn.Amount, txIn.Sequence)), w, n, err)
+		return
+	}
+	wire.WriteTo([]byte(Fmt(`{"address":"%X","amount":%v,"sequence":%v,"valid_until_height":%v}`,
+		txIn.Address, txIn.Amount, txIn.Sequence, txIn.ValidUntilHeight)), w, n, err)
+}
+
+// Returns true if the input may no longer be included in a block at height
+func (txIn *TxInput) ExpiredAt(height int) bool {
+	return txIn.ValidUntilHeight != 0 && height > txIn.ValidUntilHeight
 }
 
 func (txIn *TxInput) String() string {
+	if txIn.Multisig != nil {
+		return Fmt("TxInput{%X,%v,%v,%v}", txIn.Address, txIn.Amount, txIn.Sequence, txIn.Multisig)
+	}
 	return Fmt("TxInput{%X,%v,%v,%v,%v}", txIn.Address, txIn.Amount, txIn.Sequence, txIn.Signature, txIn.PubKey)
 }
 
@@ -258,29 +319,54 @@ func (tx *NameTx) WriteSignBytes(chainID string, w io.Writer, n *int, err *error
 }
 
 func (tx *NameTx) ValidateStrings() error {
-	if len(tx.Name) == 0 {
-		return ErrTxInvalidString{"Name must not be empty"}
-	}
-	if len(tx.Name) > MaxNameLength {
-		return ErrTxInvalidString{Fmt("Name is too long. Max %d bytes", MaxNameLength)}
-	}
-	if len(tx.Data) > MaxDataLength {
-		return ErrTxInvalidString{Fmt("Data is too long. Max %d bytes", MaxDataLength)}
-	}
+	return ValidateNameRegEntry(tx.Name, tx.Data, core_types.NameDataPlain)
+}
 
-	if !validateNameRegEntryName(tx.Name) {
-		return ErrTxInvalidString{Fmt("Invalid characters found in NameTx.Name (%s). Only alphanumeric, underscores, dashes, forward slashes, and @ are allowed", tx.Name)}
-	}
+func (tx *NameTx) String() string {
+	return Fmt("NameTx{%v -> %s: %s}", tx.Input, tx.Name, tx.Data)
+}
 
-	if !validateNameRegEntryData(tx.Data) {
-		return ErrTxInvalidString{Fmt("Invalid characters found in NameTx.Data (%s). Only the kind of things found in a JSON file are allowed", tx.Data)}
-	}
+//-----------------------------------------------------------------------------
 
-	return nil
+func (tx *NameDataTx) WriteSignBytes(chainID string, w io.Writer, n *int, err *error) {
+	wire.WriteTo([]byte(Fmt(`{"chain_id":%s`, jsonEscape(chainID))), w, n, err)
+	wire.WriteTo([]byte(Fmt(`,"tx":[%v,{"data":%s,"data_type":%v,"fee":%v`, TxTypeNameData,
+		jsonEscape(tx.Data), byte(tx.DataType), tx.Fee)), w, n, err)
+	wire.WriteTo([]byte(`,"input":`), w, n, err)
+	tx.Input.WriteSignBytes(w, n, err)
+	wire.WriteTo([]byte(Fmt(`,"name":%s`, jsonEscape(tx.Name))), w, n, err)
+	wire.WriteTo([]byte(`}]}`), w, n, err)
 }
 
-func (tx *NameTx) String() string {
-	return Fmt("NameTx{%v -> %s: %s}", tx.Input, tx.Name, tx.Data)
+func (tx *NameDataTx) ValidateStrings() error {
+	return ValidateNameRegEntry(tx.Name, tx.Data, tx.DataType)
+}
+
+func (tx *NameDataTx) String() string {
+	return Fmt("NameDataTx{%v -> %s: %v %s}", tx.Input, tx.Name, tx.DataType, tx.Data)
+}
+
+//-----------------------------------------------------------------------------
+
+// NameTransferTx transfers ownership of a registered name to NewOwner. The
+// entry keeps its data and expiry. The input pays Fee, which its amount must
+// equal.
+type NameTransferTx struct {
+	Input    *TxInput `json:"input"`
+	Name     string   `json:"name"`
+	NewOwner []byte   `json:"new_owner"`
+	Fee      int64    `json:"fee"`
+}
+
+func (tx *NameTransferTx) WriteSignBytes(chainID string, w io.Writer, n *int, err *error) {
+	wire.WriteTo([]byte(Fmt(`{"chain_id":%s`, jsonEscape(chainID))), w, n, err)
+	wire.WriteTo([]byte(Fmt(`,"tx":[%v,{"input":`, TxTypeNameTransfer)), w, n, err)
+	tx.Input.WriteSignBytes(w, n, err)
+	wire.WriteTo([]byte(Fmt(`,"name":%s,"new_owner":"%X","fee":%v}]}`, jsonEscape(tx.Name), tx.NewOwner, tx.Fee)), w, n, err)
+}
+
+func (tx *NameTransferTx) String() string {
+	return Fmt("NameTransferTx{%v -> %s: %X %v}", tx.Input, tx.Name, tx.NewOwner, tx.Fee)
 }
 
 //-----------------------------------------------------------------------------
@@ -320,14 +406,20 @@ func (tx *BondTx) String() string {
 //-----------------------------------------------------------------------------
 
 type UnbondTx struct {
-	Address   []byte                  `json:"address"`
-	Height    int                     `json:"height"`
-	Signature crypto.SignatureEd25519 `json:"signature"`
+	Address          []byte                  `json:"address"`
+	Height           int                     `json:"height"`
+	ValidUntilHeight int                     `json:"valid_until_height"` // zero for no expiry
+	Signature        crypto.SignatureEd25519 `json:"signature"`
 }
 
 func (tx *UnbondTx) WriteSignBytes(chainID string, w io.Writer, n *int, err *error) {
 	wire.WriteTo([]byte(Fmt(`{"chain_id":%s`, jsonEscape(chainID))), w, n, err)
-	wire.WriteTo([]byte(Fmt(`,"tx":[%v,{"address":"%X","height":%v}]}`, TxTypeUnbond, tx.Address, tx.Height)), w, n, err)
+	if tx.ValidUntilHeight == 0 {
+		wire.WriteTo([]byte(Fmt(`,"tx":[%v,{"address":"%X","height":%v}]}`, TxTypeUnbond, tx.Address, tx.Height)), w, n, err)
+		return
+	}
+	wire.WriteTo([]byte(Fmt(`,"tx":[%v,{"address":"%X","height":%v,"valid_until_height":%v}]}`, TxTypeUnbond,
+		tx.Address, tx.Height, tx.ValidUntilHeight)), w, n, err)
 }
 
 func (tx *UnbondTx) String() string {
@@ -337,14 +429,20 @@ func (tx *UnbondTx) String() string {
 //-----------------------------------------------------------------------------
 
 type RebondTx struct {
-	Address   []byte                  `json:"address"`
-	Height    int                     `json:"height"`
-	Signature crypto.SignatureEd25519 `json:"signature"`
+	Address          []byte                  `json:"address"`
+	Height           int                     `json:"height"`
+	ValidUntilHeight int                     `json:"valid_until_height"` // zero for no expiry
+	Signature        crypto.SignatureEd25519 `json:"signature"`
 }
 
 func (tx *RebondTx) WriteSignBytes(chainID string, w io.Writer, n *int, err *error) {
 	wire.WriteTo([]byte(Fmt(`{"chain_id":%s`, jsonEscape(chainID))), w, n, err)
-	wire.WriteTo([]byte(Fmt(`,"tx":[%v,{"address":"%X","height":%v}]}`, TxTypeRebond, tx.Address, tx.Height)), w, n, err)
+	if tx.ValidUntilHeight == 0 {
+		wire.WriteTo([]byte(Fmt(`,"tx":[%v,{"address":"%X","height":%v}]}`, TxTypeRebond, tx.Address, tx.Height)), w, n, err)
+		return
+	}
+	wire.WriteTo([]byte(Fmt(`,"tx":[%v,{"address":"%X","height":%v,"valid_until_height":%v}]}`, TxTypeRebond,
+		tx.Address, tx.Height, tx.ValidUntilHeight)), w, n, err)
 }
 
 func (tx *RebondTx) String() string {
@@ -391,7 +489,64 @@ func (tx *PermissionsTx) String() string {
 
 //-----------------------------------------------------------------------------
 
+// BatchTx runs its txs in order against the same state and either all of them
+// are executed or, if any fails, none are. The inputs of the inner txs sign the
+// BatchTx as a whole so that they can not be broadcast outside of the batch.
+// Its receipt holds a receipt for each of its txs, in order, giving the
+// addresses of the contracts they create.
+type BatchTx struct {
+	Txs []Tx `json:"txs"`
+}
+
+func (tx *BatchTx) WriteSignBytes(chainID string, w io.Writer, n *int, err *error) {
+	wire.WriteTo([]byte(Fmt(`{"chain_id":%s`, jsonEscape(chainID))), w, n, err)
+	wire.WriteTo([]byte(Fmt(`,"tx":[%v,{"txs":[`, TxTypeBatch)), w, n, err)
+	for i, innerTx := range tx.Txs {
+		innerTx.WriteSignBytes(chainID, w, n, err)
+		if i != len(tx.Txs)-1 {
+			wire.WriteTo([]byte(","), w, n, err)
+		}
+	}
+	wire.WriteTo([]byte(`]}]}`), w, n, err)
+}
+
+func (tx *BatchTx) String() string {
+	return Fmt("BatchTx{%v}", tx.Txs)
+}
+
+//-----------------------------------------------------------------------------
+
+// GovTx proposes new chain parameters and/or global permissions that take
+// effect from Height onwards. Either may be nil to leave it unchanged.
+type GovTx struct {
+	Input             *TxInput                `json:"input"`
+	Height            int                     `json:"height"`
+	Params            *ChainParams            `json:"params"`
+	GlobalPermissions *ptypes.BasePermissions `json:"global_permissions"`
+}
+
+func (tx *GovTx) WriteSignBytes(chainID string, w io.Writer, n *int, err *error) {
+	wire.WriteTo([]byte(Fmt(`{"chain_id":%s`, jsonEscape(chainID))), w, n, err)
+	wire.WriteTo([]byte(Fmt(`,"tx":[%v,{"global_permissions":`, TxTypeGov)), w, n, err)
+	wire.WriteTo(wire.JSONBytes(tx.GlobalPermissions), w, n, err)
+	wire.WriteTo([]byte(Fmt(`,"height":%v,"input":`, tx.Height)), w, n, err)
+	tx.Input.WriteSignBytes(w, n, err)
+	wire.WriteTo([]byte(`,"params":`), w, n, err)
+	wire.WriteTo(wire.JSONBytes(tx.Params), w, n, err)
+	wire.WriteTo([]byte(`}]}`), w, n, err)
+}
+
+func (tx *GovTx) String() string {
+	return Fmt("GovTx{%v -> @%v: %v, %v}", tx.Input, tx.Height, tx.Params, tx.GlobalPermissions)
+}
+
+//-----------------------------------------------------------------------------
+
+// EthTxs are hashed as they are by Ethereum clients
 func TxHash(chainID string, tx Tx) []byte {
+	if ethTx, ok := tx.(*EthTx); ok {
+		return ethTx.Hash()
+	}
 	signBytes := acm.SignBytes(chainID, tx)
 	hasher := ripemd160.New()
 	hasher.Write(signBytes)
@@ -399,6 +554,41 @@ func TxHash(chainID string, tx Tx) []byte {
 	return hasher.Sum(nil)
 }
 
+// Short name of the type of tx, as used by event filters: one of send, call,
+// name, name_transfer, name_data, eth, bond, unbond, rebond, dupeout,
+// permissions, gov or batch. Returns an empty string for unknown txs.
+func TxTypeName(tx Tx) string {
+	switch tx.(type) {
+	case *SendTx:
+		return "send"
+	case *CallTx:
+		return "call"
+	case *NameTx:
+		return "name"
+	case *NameTransferTx:
+		return "name_transfer"
+	case *NameDataTx:
+		return "name_data"
+	case *EthTx:
+		return "eth"
+	case *BondTx:
+		return "bond"
+	case *UnbondTx:
+		return "unbond"
+	case *RebondTx:
+		return "rebond"
+	case *DupeoutTx:
+		return "dupeout"
+	case *PermissionsTx:
+		return "permissions"
+	case *GovTx:
+		return "gov"
+	case *BatchTx:
+		return "batch"
+	}
+	return ""
+}
+
 //-----------------------------------------------------------------------------
 
 func EncodeTx(tx Tx) ([]byte, error) {
@@ -425,17 +615,79 @@ func DecodeTx(txBytes []byte) (Tx, error) {
 	return *tx, nil
 }
 
+// ValidateHeight returns ErrTxExpired if the tx may no longer be included in
+// a block at height. Txs expire through their inputs, apart from UnbondTx and
+// RebondTx which have none. A DupeoutTx carries evidence signed by others and
+// never expires.
+func ValidateHeight(tx Tx, height int) error {
+	var inputs []*TxInput
+	switch tx := tx.(type) {
+	case *SendTx:
+		inputs = tx.Inputs
+	case *CallTx:
+		inputs = []*TxInput{tx.Input}
+	case *NameTx:
+		inputs = []*TxInput{tx.Input}
+	case *NameTransferTx:
+		inputs = []*TxInput{tx.Input}
+	case *NameDataTx:
+		inputs = []*TxInput{tx.Input}
+	case *BondTx:
+		inputs = tx.Inputs
+	case *PermissionsTx:
+		inputs = []*TxInput{tx.Input}
+	case *GovTx:
+		inputs = []*TxInput{tx.Input}
+	case *UnbondTx:
+		if tx.ValidUntilHeight != 0 && height > tx.ValidUntilHeight {
+			return ErrTxExpired
+		}
+	case *RebondTx:
+		if tx.ValidUntilHeight != 0 && height > tx.ValidUntilHeight {
+			return ErrTxExpired
+		}
+	case *BatchTx:
+		for _, innerTx := range tx.Txs {
+			if err := ValidateHeight(innerTx, height); err != nil {
+				return err
+			}
+		}
+	}
+	for _, in := range inputs {
+		if in != nil && in.ExpiredAt(height) {
+			return ErrTxExpired
+		}
+	}
+	return nil
+}
+
 func GenerateReceipt(chainId string, tx Tx) Receipt {
 	receipt := Receipt{
 		TxHash:          TxHash(chainId, tx),
 		CreatesContract: 0,
 		ContractAddr:    nil,
 	}
-	if callTx, ok := tx.(*CallTx); ok {
-		if len(callTx.Address) == 0 {
+	switch tx := tx.(type) {
+	case *CallTx:
+		if len(tx.Address) == 0 {
 			receipt.CreatesContract = 1
-			receipt.ContractAddr = NewContractAddress(callTx.Input.Address,
-				callTx.Input.Sequence)
+			receipt.ContractAddr = NewContractAddress(tx.Input.Address,
+				tx.Input.Sequence)
+		}
+	case *EthTx:
+		if len(tx.To) == 0 {
+			// the sender is only known from the signature, so a tx with an
+			// invalid one has no contract address
+			if sender, err := tx.Sender(); err == nil {
+				receipt.CreatesContract = 1
+				receipt.ContractAddr = NewContractAddress(sender,
+					tx.Sequence())
+			}
+		}
+	case *BatchTx:
+		receipt.Receipts = make([]Receipt, len(tx.Txs))
+		for i, innerTx := range tx.Txs {
+			receipt.Receipts[i] = GenerateReceipt(chainId, innerTx)
 		}
 	}
 	return receipt
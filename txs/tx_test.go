@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	acm "github.com/hyperledger/burrow/account"
+	core_types "github.com/hyperledger/burrow/core/types"
 	ptypes "github.com/hyperledger/burrow/permission/types"
 
 	"github.com/stretchr/testify/assert"
@@ -103,6 +104,88 @@ func TestNameTxSignable(t *testing.T) {
 	}
 }
 
+func TestNameDataTxSignable(t *testing.T) {
+	nameDataTx := &NameDataTx{
+		Input: &TxInput{
+			Address:  []byte("input1"),
+			Amount:   12345,
+			Sequence: 250,
+		},
+		Name:     "google.com",
+		Data:     `{"host":"secretly.not.google.com"}`,
+		Fee:      1000,
+		DataType: core_types.NameDataJSON,
+	}
+	signBytes := acm.SignBytes(chainID, nameDataTx)
+	signStr := string(signBytes)
+	expected := Fmt(`{"chain_id":"%s","tx":[6,{"data":"{\"host\":\"secretly.not.google.com\"}","data_type":3,"fee":1000,"input":{"address":"696E70757431","amount":12345,"sequence":250},"name":"google.com"}]}`,
+		chainID)
+	if signStr != expected {
+		t.Errorf("Got unexpected sign string for NameDataTx. Expected:\n%v\nGot:\n%v", expected, signStr)
+	}
+
+	txBytes, err := EncodeTx(nameDataTx)
+	assert.NoError(t, err)
+	decoded, err := DecodeTx(txBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, nameDataTx, decoded)
+}
+
+func TestValidateNameRegEntryData(t *testing.T) {
+	valid := map[core_types.NameDataType][]string{
+		core_types.NameDataPlain: {"", "some data", `{"a":[1,2]}`},
+		core_types.NameDataBytes: {"", "3q2+7w==", "AAEC/w=="},
+		core_types.NameDataText:  {"", "héllo wörld = ∞", "tabs\tand\nnewlines"},
+		core_types.NameDataJSON:  {`{"name":"wörld","tags":["a=b"]}`, `[1,2,3]`, `"string"`},
+	}
+	invalid := map[core_types.NameDataType][]string{
+		core_types.NameDataPlain:   {"a=b", "héllo"},
+		core_types.NameDataBytes:   {"not base64!", "3q2+7w="},
+		core_types.NameDataText:    {"\xff\xfe"},
+		core_types.NameDataJSON:    {"", "{", `{"a":1}}`, "\"\xff\""},
+		core_types.NameDataType(9): {""},
+	}
+	for dataType, datas := range valid {
+		for _, data := range datas {
+			assert.NoError(t, ValidateNameRegEntry("name", data, dataType), "%v: %q", dataType, data)
+		}
+	}
+	for dataType, datas := range invalid {
+		for _, data := range datas {
+			assert.Error(t, ValidateNameRegEntry("name", data, dataType), "%v: %q", dataType, data)
+		}
+	}
+}
+
+func TestNameTransferTxSignable(t *testing.T) {
+	nameTransferTx := &NameTransferTx{
+		Input: &TxInput{
+			Address:  []byte("input1"),
+			Amount:   12345,
+			Sequence: 250,
+		},
+		Name:     "google.com",
+		NewOwner: []byte("owner1"),
+		Fee:      12345,
+	}
+	signBytes := acm.SignBytes(chainID, nameTransferTx)
+	signStr := string(signBytes)
+	expected := Fmt(`{"chain_id":"%s","tx":[4,{"input":{"address":"696E70757431","amount":12345,"sequence":250},"name":"google.com","new_owner":"6F776E657231","fee":12345}]}`,
+		chainID)
+	if signStr != expected {
+		t.Errorf("Got unexpected sign string for NameTransferTx. Expected:\n%v\nGot:\n%v", expected, signStr)
+	}
+}
+
+func TestNameAncestors(t *testing.T) {
+	assert.Equal(t, []string{"foo/bar", "foo"}, NameAncestors("foo/bar/baz"))
+	assert.Empty(t, NameAncestors("foo"))
+	assert.True(t, validateNameRegEntrySegments("foo/bar"))
+	for _, name := range []string{"/foo", "foo/", "foo//bar"} {
+		assert.False(t, validateNameRegEntrySegments(name), name)
+	}
+}
+
 func TestBondTxSignable(t *testing.T) {
 	privKeyBytes := make([]byte, 64)
 	privAccount := acm.GenPrivAccountFromPrivKeyBytes(privKeyBytes)
@@ -168,6 +251,57 @@ func TestRebondTxSignable(t *testing.T) {
 	}
 }
 
+func TestValidUntilHeightSignable(t *testing.T) {
+	sendTx := &SendTx{
+		Inputs: []*TxInput{
+			&TxInput{
+				Address:          []byte("input1"),
+				Amount:           12345,
+				Sequence:         67890,
+				ValidUntilHeight: 100,
+			},
+		},
+		Outputs: []*TxOutput{
+			&TxOutput{
+				Address: []byte("output1"),
+				Amount:  333,
+			},
+		},
+	}
+	signStr := string(acm.SignBytes(chainID, sendTx))
+	expected := Fmt(`{"chain_id":"%s","tx":[1,{"inputs":[{"address":"696E70757431","amount":12345,"sequence":67890,"valid_until_height":100}],"outputs":[{"address":"6F757470757431","amount":333}]}]}`,
+		chainID)
+	assert.Equal(t, expected, signStr)
+
+	unbondTx := &UnbondTx{
+		Address:          []byte("address1"),
+		Height:           111,
+		ValidUntilHeight: 120,
+	}
+	signStr = string(acm.SignBytes(chainID, unbondTx))
+	expected = Fmt(`{"chain_id":"%s","tx":[18,{"address":"6164647265737331","height":111,"valid_until_height":120}]}`,
+		chainID)
+	assert.Equal(t, expected, signStr)
+}
+
+func TestValidateHeight(t *testing.T) {
+	in := &TxInput{Address: []byte("input1"), Amount: 1, Sequence: 1}
+	callTx := &CallTx{Input: in, Address: []byte("contract1")}
+	batchTx := &BatchTx{Txs: []Tx{&SendTx{Inputs: []*TxInput{}}, callTx}}
+
+	// no expiry
+	assert.NoError(t, ValidateHeight(callTx, 1000))
+
+	in.ValidUntilHeight = 10
+	assert.NoError(t, ValidateHeight(callTx, 10))
+	assert.Equal(t, ErrTxExpired, ValidateHeight(callTx, 11))
+	assert.Equal(t, ErrTxExpired, ValidateHeight(batchTx, 11))
+
+	rebondTx := &RebondTx{Address: []byte("address1"), Height: 5, ValidUntilHeight: 10}
+	assert.NoError(t, ValidateHeight(rebondTx, 10))
+	assert.Equal(t, ErrTxExpired, ValidateHeight(rebondTx, 11))
+}
+
 func TestPermissionsTxSignable(t *testing.T) {
 	permsTx := &PermissionsTx{
 		Input: &TxInput{
@@ -191,6 +325,71 @@ func TestPermissionsTxSignable(t *testing.T) {
 	}
 }
 
+func TestGovTxSignable(t *testing.T) {
+	govTx := &GovTx{
+		Input: &TxInput{
+			Address:  []byte("input1"),
+			Amount:   12345,
+			Sequence: 250,
+		},
+		Height: 100,
+		Params: &ChainParams{
+			MinBondAmount:             10,
+			UnbondingPeriodBlocks:     20,
+			MinNameRegistrationPeriod: 5,
+			NameByteCostMultiplier:    1,
+			NameBlockCostMultiplier:   1,
+		},
+	}
+
+	signBytes := acm.SignBytes(chainID, govTx)
+	signStr := string(signBytes)
+	expected := Fmt(`{"chain_id":"%s","tx":[33,{"global_permissions":null,"height":100,"input":{"address":"696E70757431","amount":12345,"sequence":250},"params":{"min_bond_amount":10,"unbonding_period_blocks":20,"min_name_registration_period":5,"name_byte_cost_multiplier":1,"name_block_cost_multiplier":1}}]}`,
+		chainID)
+	if signStr != expected {
+		t.Errorf("Got unexpected sign string for GovTx. Expected:\n%v\nGot:\n%v", expected, signStr)
+	}
+}
+
+func TestBatchTxSignable(t *testing.T) {
+	batchTx := &BatchTx{
+		Txs: []Tx{
+			&SendTx{
+				Inputs: []*TxInput{
+					{
+						Address:  []byte("input1"),
+						Amount:   12345,
+						Sequence: 67890,
+					},
+				},
+				Outputs: []*TxOutput{
+					{
+						Address: []byte("output1"),
+						Amount:  12345,
+					},
+				},
+			},
+			&NameTx{
+				Input: &TxInput{
+					Address:  []byte("input1"),
+					Amount:   12345,
+					Sequence: 67891,
+				},
+				Name: "google.com",
+				Data: "secretly.not.google.com",
+				Fee:  1000,
+			},
+		},
+	}
+	signBytes := acm.SignBytes(chainID, batchTx)
+	signStr := string(signBytes)
+	expected := Fmt(`{"chain_id":"%s","tx":[48,{"txs":[{"chain_id":"%s","tx":[1,{"inputs":[{"address":"696E70757431","amount":12345,"sequence":67890}],"outputs":[{"address":"6F757470757431","amount":12345}]}]},{"chain_id":"%s","tx":[3,{"data":"secretly.not.google.com","fee":1000,"input":{"address":"696E70757431","amount":12345,"sequence":67891},"name":"google.com"}]}]}]}`,
+		chainID, chainID, chainID)
+	if signStr != expected {
+		t.Errorf("Got unexpected sign string for BatchTx. Expected:\n%v\nGot:\n%v", expected, signStr)
+	}
+}
+
 func TestEncodeTxDecodeTx(t *testing.T) {
 	inputAddress := []byte{1, 2, 3, 4, 5}
 	outputAddress := []byte{5, 4, 3, 2, 1}
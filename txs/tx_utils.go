@@ -15,9 +15,11 @@
 package txs
 
 import (
+	"bytes"
 	"fmt"
 
 	acm "github.com/hyperledger/burrow/account"
+	core_types "github.com/hyperledger/burrow/core/types"
 	ptypes "github.com/hyperledger/burrow/permission/types"
 
 	"github.com/tendermint/go-crypto"
@@ -58,6 +60,13 @@ func (tx *SendTx) AddInputWithNonce(pubkey crypto.PubKey, amt int64, nonce int)
 	return nil
 }
 
+// Adds an input spending from the address of the multisig. Its signatures
+// are added with SignMultisigInput by the holders of the keys.
+func (tx *SendTx) AddMultisigInputWithNonce(multisig *acm.MultisigPubKey, amt int64, nonce int) error {
+	tx.Inputs = append(tx.Inputs, NewMultisigTxInput(multisig, amt, nonce))
+	return nil
+}
+
 func (tx *SendTx) AddOutput(addr []byte, amt int64) error {
 	tx.Outputs = append(tx.Outputs, &TxOutput{
 		Address: addr,
@@ -75,6 +84,42 @@ func (tx *SendTx) SignInput(chainID string, i int, privAccount *acm.PrivAccount)
 	return nil
 }
 
+func (tx *SendTx) SignMultisigInput(chainID string, i int, index int, privAccount *acm.PrivAccount) error {
+	if i >= len(tx.Inputs) {
+		return fmt.Errorf("Index %v is greater than number of inputs (%v)", i, len(tx.Inputs))
+	}
+	return SignMultisigInput(chainID, tx, tx.Inputs[i], index, privAccount)
+}
+
+//----------------------------------------------------------------------------
+// Multisig inputs
+
+func NewMultisigTxInput(multisig *acm.MultisigPubKey, amt int64, nonce int) *TxInput {
+	return &TxInput{
+		Address:  multisig.Address(),
+		Amount:   amt,
+		Sequence: nonce,
+		Multisig: acm.NewMultisigSignature(multisig),
+	}
+}
+
+// Adds the partial signature of the key at index in the input's
+// MultisigPubKey. The key must be that of privAccount.
+func SignMultisigInput(chainID string, tx Tx, in *TxInput, index int, privAccount *acm.PrivAccount) error {
+	if in.Multisig == nil || in.Multisig.PubKey == nil {
+		return fmt.Errorf("Input %X is not a multisig input", in.Address)
+	}
+	pubKeys := in.Multisig.PubKey.PubKeys
+	if index < 0 || index >= len(pubKeys) {
+		return fmt.Errorf("Index %v is out of range for a multisig of %v keys", index, len(pubKeys))
+	}
+	if !bytes.Equal(pubKeys[index].Address(), privAccount.PubKey.Address()) {
+		return fmt.Errorf("Key %v is not the multisig key at index %v", privAccount.PubKey, index)
+	}
+	in.Multisig.AddSignature(index, privAccount.Sign(chainID, tx))
+	return nil
+}
+
 //----------------------------------------------------------------------------
 // CallTx interface for creating tx
 
@@ -150,6 +195,81 @@ func (tx *NameTx) Sign(chainID string, privAccount *acm.PrivAccount) {
 	tx.Input.Signature = privAccount.Sign(chainID, tx)
 }
 
+//----------------------------------------------------------------------------
+// NameDataTx interface for creating tx
+
+func NewNameDataTx(st AccountGetter, from crypto.PubKey, name, data string, dataType core_types.NameDataType, amt, fee int64) (*NameDataTx, error) {
+	addr := from.Address()
+	acc := st.GetAccount(addr)
+	if acc == nil {
+		return nil, fmt.Errorf("Invalid address %X from pubkey %X", addr, from)
+	}
+
+	nonce := acc.Sequence + 1
+	return NewNameDataTxWithNonce(from, name, data, dataType, amt, fee, nonce), nil
+}
+
+func NewNameDataTxWithNonce(from crypto.PubKey, name, data string, dataType core_types.NameDataType, amt, fee int64, nonce int) *NameDataTx {
+	addr := from.Address()
+	input := &TxInput{
+		Address:   addr,
+		Amount:    amt,
+		Sequence:  nonce,
+		Signature: crypto.SignatureEd25519{},
+		PubKey:    from,
+	}
+
+	return &NameDataTx{
+		Input:    input,
+		Name:     name,
+		Data:     data,
+		Fee:      fee,
+		DataType: dataType,
+	}
+}
+
+func (tx *NameDataTx) Sign(chainID string, privAccount *acm.PrivAccount) {
+	tx.Input.PubKey = privAccount.PubKey
+	tx.Input.Signature = privAccount.Sign(chainID, tx)
+}
+
+//----------------------------------------------------------------------------
+// NameTransferTx interface for creating tx
+
+func NewNameTransferTx(st AccountGetter, from crypto.PubKey, name string, newOwner []byte, fee int64) (*NameTransferTx, error) {
+	addr := from.Address()
+	acc := st.GetAccount(addr)
+	if acc == nil {
+		return nil, fmt.Errorf("Invalid address %X from pubkey %X", addr, from)
+	}
+
+	nonce := acc.Sequence + 1
+	return NewNameTransferTxWithNonce(from, name, newOwner, fee, nonce), nil
+}
+
+func NewNameTransferTxWithNonce(from crypto.PubKey, name string, newOwner []byte, fee int64, nonce int) *NameTransferTx {
+	addr := from.Address()
+	input := &TxInput{
+		Address:   addr,
+		Amount:    fee,
+		Sequence:  nonce,
+		Signature: crypto.SignatureEd25519{},
+		PubKey:    from,
+	}
+
+	return &NameTransferTx{
+		Input:    input,
+		Name:     name,
+		NewOwner: newOwner,
+		Fee:      fee,
+	}
+}
+
+func (tx *NameTransferTx) Sign(chainID string, privAccount *acm.PrivAccount) {
+	tx.Input.PubKey = privAccount.PubKey
+	tx.Input.Signature = privAccount.Sign(chainID, tx)
+}
+
 //----------------------------------------------------------------------------
 // BondTx interface for adding inputs/outputs and adding signatures
 
@@ -275,3 +395,93 @@ func (tx *PermissionsTx) Sign(chainID string, privAccount *acm.PrivAccount) {
 	tx.Input.PubKey = privAccount.PubKey
 	tx.Input.Signature = privAccount.Sign(chainID, tx)
 }
+
+//----------------------------------------------------------------------------
+// BatchTx interface for creating and signing tx
+
+func NewBatchTx(txs ...Tx) *BatchTx {
+	return &BatchTx{
+		Txs: txs,
+	}
+}
+
+// Returns the inputs of all the txs in the batch
+func (tx *BatchTx) Inputs() []*TxInput {
+	inputs := []*TxInput{}
+	for _, innerTx := range tx.Txs {
+		switch innerTx := innerTx.(type) {
+		case *SendTx:
+			inputs = append(inputs, innerTx.Inputs...)
+		case *CallTx:
+			inputs = append(inputs, innerTx.Input)
+		case *NameTx:
+			inputs = append(inputs, innerTx.Input)
+		case *NameTransferTx:
+			inputs = append(inputs, innerTx.Input)
+		case *NameDataTx:
+			inputs = append(inputs, innerTx.Input)
+		case *PermissionsTx:
+			inputs = append(inputs, innerTx.Input)
+		}
+	}
+	return inputs
+}
+
+// Signs every input of the txs in the batch that spends from the account
+// of privAccount. Inputs are signed over the whole BatchTx.
+func (tx *BatchTx) SignInputs(chainID string, privAccount *acm.PrivAccount) error {
+	address := privAccount.PubKey.Address()
+	// signatures and pub keys are not part of the sign bytes
+	signature := privAccount.Sign(chainID, tx)
+	signed := false
+	for _, in := range tx.Inputs() {
+		if bytes.Equal(in.Address, address) {
+			in.PubKey = privAccount.PubKey
+			in.Signature = signature
+			signed = true
+		}
+	}
+	if !signed {
+		return fmt.Errorf("No input of the batch spends from %X", address)
+	}
+	return nil
+}
+
+//----------------------------------------------------------------------------
+// GovTx interface for creating tx
+
+func NewGovTx(st AccountGetter, from crypto.PubKey, height int, params *ChainParams,
+	globalPerms *ptypes.BasePermissions) (*GovTx, error) {
+	addr := from.Address()
+	acc := st.GetAccount(addr)
+	if acc == nil {
+		return nil, fmt.Errorf("Invalid address %X from pubkey %X", addr, from)
+	}
+
+	nonce := acc.Sequence + 1
+	return NewGovTxWithNonce(from, height, params, globalPerms, nonce), nil
+}
+
+func NewGovTxWithNonce(from crypto.PubKey, height int, params *ChainParams,
+	globalPerms *ptypes.BasePermissions, nonce int) *GovTx {
+	addr := from.Address()
+	input := &TxInput{
+		Address:   addr,
+		Amount:    1, // NOTE: amounts can't be 0 ...
+		Sequence:  nonce,
+		Signature: crypto.SignatureEd25519{},
+		PubKey:    from,
+	}
+
+	return &GovTx{
+		Input:             input,
+		Height:            height,
+		Params:            params,
+		GlobalPermissions: globalPerms,
+	}
+}
+
+func (tx *GovTx) Sign(chainID string, privAccount *acm.PrivAccount) {
+	tx.Input.PubKey = privAccount.PubKey
+	tx.Input.Signature = privAccount.Sign(chainID, tx)
+}